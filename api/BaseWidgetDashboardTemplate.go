@@ -1,7 +1,39 @@
 package api
 
-type BaseWidgetDashboardTemplateRegistry struct {
-	BaseWidgetDashboardTemplates map[string]BaseWidgetDashboardTemplate `json:"baseWidgetDashboardTemplates" yaml:"baseWidgetDashboardTemplates"` // List of base widget dashboard templates
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// BaseTemplateVersion is a snapshot of the configuration of a base template. Dashboard templates only
+// record the version of the base template they were forked from, the snapshot is the common ancestor
+// used to merge later versions of the base template into them.
+type BaseTemplateVersion struct {
+	ID             uint                    `json:"id" yaml:"id" gorm:"primarykey"`
+	Name           string                  `json:"name" yaml:"name" gorm:"not null;uniqueIndex:idx_base_template_version"`
+	Version        string                  `json:"version" yaml:"version" gorm:"not null;uniqueIndex:idx_base_template_version"`
+	CreatedAt      time.Time               `json:"createdAt" yaml:"createdAt"`
+	TemplateConfig DashboardTemplateConfig `json:"templateConfig" yaml:"templateConfig" gorm:"not null;default null;embedded"`
+}
+
+// ConfigVersion returns the version of the configuration of the base template, it only changes when the layouts change.
+func (b *BaseWidgetDashboardTemplate) ConfigVersion() string {
+	// the configuration only consists of slices, encoding it cannot fail
+	data, _ := json.Marshal(b.TemplateConfig)
+	return fmt.Sprintf("%x", sha256.Sum256(data))[:32]
+}
+
+// ToBaseTemplate returns the base template of the organization as a base template, it takes the place
+// of the global base template of the same name for the members of the organization.
+func (o OrgBaseTemplate) ToBaseTemplate() BaseWidgetDashboardTemplate {
+	return BaseWidgetDashboardTemplate{
+		Name:           o.Name,
+		DisplayName:    o.DisplayName,
+		TemplateConfig: o.TemplateConfig,
+		Policy:         o.Policy,
+	}
 }
 
 func (b *BaseWidgetDashboardTemplate) ToDashboardTemplate() DashboardTemplate {
@@ -11,24 +43,6 @@ func (b *BaseWidgetDashboardTemplate) ToDashboardTemplate() DashboardTemplate {
 			DisplayName: b.DisplayName,
 		},
 		TemplateConfig: b.TemplateConfig,
+		BaseVersion:    b.ConfigVersion(),
 	}
 }
-
-func (br *BaseWidgetDashboardTemplateRegistry) AddBase(bt BaseWidgetDashboardTemplate) {
-	if br.BaseWidgetDashboardTemplates == nil {
-		br.BaseWidgetDashboardTemplates = make(map[string]BaseWidgetDashboardTemplate)
-	}
-	br.BaseWidgetDashboardTemplates[bt.Name] = bt
-}
-
-func (br *BaseWidgetDashboardTemplateRegistry) GetBase(name string) (BaseWidgetDashboardTemplate, bool) {
-	bt, exists := br.BaseWidgetDashboardTemplates[name]
-	return bt, exists
-}
-
-func (br *BaseWidgetDashboardTemplateRegistry) GetAllBases() map[string]BaseWidgetDashboardTemplate {
-	if br.BaseWidgetDashboardTemplates == nil {
-		return make(map[string]BaseWidgetDashboardTemplate)
-	}
-	return br.BaseWidgetDashboardTemplates
-}
@@ -29,3 +29,30 @@ func TestWidgetUnmarshaling(t *testing.T) {
 		}
 	})
 }
+
+func TestBaseTemplateConfigVersion(t *testing.T) {
+	var base api.BaseWidgetDashboardTemplate
+	if err := json.Unmarshal([]byte(`{"name":"base","displayName":"Base","templateConfig":{"sm":[{"w":1,"h":1,"x":0,"y":0,"i":"a"}]}}`), &base); err != nil {
+		t.Fatalf("Failed to unmarshal base template: %v", err)
+	}
+	version := base.ConfigVersion()
+
+	t.Run("should record the version on forks", func(t *testing.T) {
+		if fork := base.ToDashboardTemplate(); fork.BaseVersion != version {
+			t.Errorf("Expected fork to record version %s, got %s", version, fork.BaseVersion)
+		}
+	})
+
+	t.Run("should only change with the configuration", func(t *testing.T) {
+		renamed := base
+		renamed.DisplayName = "Renamed"
+		if renamed.ConfigVersion() != version {
+			t.Error("Version should not change with the display name")
+		}
+		changed := base
+		changed.TemplateConfig.Md = changed.TemplateConfig.Sm
+		if changed.ConfigVersion() == version {
+			t.Error("Version should change with the layouts")
+		}
+	})
+}
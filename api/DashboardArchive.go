@@ -0,0 +1,33 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+)
+
+// DashboardArchiveSchemaVersion is the version of the format of the archives created by GET /export. Archives share
+// the schema version of exported templates, their templates are upgraded by the same migrations.
+const DashboardArchiveSchemaVersion = ExportSchemaVersion
+
+// CheckSchemaVersion returns an error if the archive is written in a format which cannot be restored.
+func (a DashboardArchive) CheckSchemaVersion() error {
+	if a.SchemaVersion < 1 {
+		return errors.New("schemaVersion is required")
+	}
+	if a.SchemaVersion > DashboardArchiveSchemaVersion {
+		return fmt.Errorf("archive schema version %d is not supported, the latest supported version is %d", a.SchemaVersion, DashboardArchiveSchemaVersion)
+	}
+	return nil
+}
+
+// ToArchivedTemplate returns the archived form of the template, which keeps everything but its identity and owner.
+func (dt DashboardTemplate) ToArchivedTemplate() ArchivedDashboardTemplate {
+	return ArchivedDashboardTemplate{
+		DashboardName:  dt.DashboardName,
+		TemplateBase:   dt.TemplateBase,
+		TemplateConfig: dt.TemplateConfig,
+		Default:        dt.Default,
+		Visibility:     dt.Visibility,
+		BaseVersion:    dt.BaseVersion,
+	}
+}
@@ -0,0 +1,44 @@
+package api_test
+
+import (
+	"testing"
+
+	"github.com/RedHatInsights/widget-layout-backend/api"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/datatypes"
+)
+
+func TestDashboardArchiveCheckSchemaVersion(t *testing.T) {
+	assert.NoError(t, api.DashboardArchive{SchemaVersion: api.DashboardArchiveSchemaVersion}.CheckSchemaVersion())
+
+	err := api.DashboardArchive{}.CheckSchemaVersion()
+	assert.ErrorContains(t, err, "schemaVersion is required")
+
+	err = api.DashboardArchive{SchemaVersion: api.DashboardArchiveSchemaVersion + 1}.CheckSchemaVersion()
+	assert.ErrorContains(t, err, "not supported")
+}
+
+func TestDashboardTemplateToArchivedTemplate(t *testing.T) {
+	layout := datatypes.NewJSONType([]api.WidgetItem{{WidgetType: "widget1", Width: 1, Height: 1}})
+	template := api.DashboardTemplate{
+		ID:             42,
+		UserId:         "user-123",
+		OrgId:          "org-123",
+		DashboardName:  "Operations",
+		TemplateBase:   api.DashboardTemplateBase{Name: "landing", DisplayName: "Landing"},
+		TemplateConfig: api.DashboardTemplateConfig{Sm: layout, Md: layout, Lg: layout, Xl: layout},
+		Default:        true,
+		Visibility:     api.VisibilityOrg,
+		BaseVersion:    "abc123",
+		Version:        7,
+	}
+
+	assert.Equal(t, api.ArchivedDashboardTemplate{
+		DashboardName:  "Operations",
+		TemplateBase:   template.TemplateBase,
+		TemplateConfig: template.TemplateConfig,
+		Default:        true,
+		Visibility:     api.VisibilityOrg,
+		BaseVersion:    "abc123",
+	}, template.ToArchivedTemplate())
+}
@@ -0,0 +1,180 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ExportSchemaVersion is the version of the format of exported dashboard templates and archives.
+// It is the number of migrations in exportMigrations.
+const ExportSchemaVersion = 1
+
+// exportMigration upgrades the JSON object of an exported dashboard template from the previous schema version.
+type exportMigration func(template map[string]any) error
+
+// exportMigrations upgrade exported dashboard templates to the current format, exportMigrations[v] upgrades a
+// template of version v to version v+1. Migrations are only ever appended, and ExportSchemaVersion is bumped with
+// every new one.
+var exportMigrations = []exportMigration{
+	// 0: templates exported before the schema was versioned
+	migrateUnversionedTemplate,
+}
+
+// chromeWidgetIDs maps the service IDs of the widgets of the Chrome dashboard to their FEO widget IDs,
+// see docs/WIDGET_MIGRATION.md
+var chromeWidgetIDs = map[string]string{
+	"acs":                 "landing-./AcsWidget",
+	"ansible":             "landing-./AnsibleWidget",
+	"edge":                "landing-./EdgeWidget",
+	"exploreCapabilities": "landing-./ExploreCapabilities",
+	"favoriteServices":    "chrome-./DashboardFavorites",
+	"imageBuilder":        "landing-./ImageBuilderWidget",
+	"integrations":        "sources-./IntegrationsWidget",
+	"learningResources":   "learningResources-./BookmarkedLearningResourcesWidget",
+	"notificationsEvents": "notifications-./DashboardWidget",
+	"openshift":           "landing-./OpenShiftWidget",
+	"openshiftAi":         "landing-./OpenShiftAiWidget",
+	"quay":                "landing-./QuayWidget",
+	"recentlyVisited":     "landing-./RecentlyVisited",
+	"rhel":                "landing-./RhelWidget",
+	"subscriptions":       "subscriptionInventory-./SubscriptionsWidget",
+	"supportCases":        "landing-./SupportCaseWidget",
+}
+
+// migrateUnversionedTemplate upgrades templates exported before the schema was versioned. Their widgets may still
+// use the cx/cy coordinates of the ConfigMaps and the "<service>#<id>" identifiers of the Chrome dashboard.
+func migrateUnversionedTemplate(template map[string]any) error {
+	return forEachExportedWidget(template, func(widget map[string]any) {
+		for legacy, current := range map[string]string{"cx": "x", "cy": "y"} {
+			if value, ok := widget[legacy]; ok {
+				if _, exists := widget[current]; !exists {
+					widget[current] = value
+				}
+				delete(widget, legacy)
+			}
+		}
+		if id, ok := widget["i"].(string); ok {
+			widget["i"] = migrateChromeWidgetID(id)
+		}
+	})
+}
+
+// migrateChromeWidgetID returns the FEO widget ID of a widget of the Chrome dashboard, other IDs are kept.
+func migrateChromeWidgetID(id string) string {
+	service, _, _ := strings.Cut(id, "#")
+	if feoID, ok := chromeWidgetIDs[service]; ok {
+		return feoID
+	}
+	return id
+}
+
+// forEachExportedWidget calls fn with every widget of every breakpoint of the template configuration.
+func forEachExportedWidget(template map[string]any, fn func(widget map[string]any)) error {
+	config, ok := template["templateConfig"].(map[string]any)
+	if !ok {
+		return nil
+	}
+	for _, gs := range gridSizes {
+		items, ok := config[string(gs)].([]any)
+		if !ok {
+			continue
+		}
+		for i, item := range items {
+			widget, ok := item.(map[string]any)
+			if !ok {
+				return fmt.Errorf("widget[%d] in %s is not an object", i, gs)
+			}
+			fn(widget)
+		}
+	}
+	return nil
+}
+
+// schemaVersion returns the schema version of a payload, payloads without one are of version 0.
+func schemaVersion(payload map[string]any) (int, error) {
+	value, ok := payload["schemaVersion"]
+	if !ok || value == nil {
+		return 0, nil
+	}
+	number, ok := value.(json.Number)
+	if !ok {
+		return 0, errors.New("schemaVersion must be an integer")
+	}
+	version, err := number.Int64()
+	if err != nil || version < 0 {
+		return 0, errors.New("schemaVersion must be a non-negative integer")
+	}
+	if version > ExportSchemaVersion {
+		return 0, fmt.Errorf("schema version %d is not supported, the latest supported version is %d", version, ExportSchemaVersion)
+	}
+	return int(version), nil
+}
+
+func migrateTemplate(template map[string]any, from int) error {
+	for version := from; version < len(exportMigrations); version++ {
+		if err := exportMigrations[version](template); err != nil {
+			return fmt.Errorf("failed to upgrade from schema version %d: %w", version, err)
+		}
+	}
+	return nil
+}
+
+func decodeExportPayload(data []byte) (map[string]any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	// keep the numbers as they are written
+	decoder.UseNumber()
+	var payload map[string]any
+	if err := decoder.Decode(&payload); err != nil {
+		return nil, err
+	}
+	if payload == nil {
+		return nil, errors.New("payload must be a JSON object")
+	}
+	return payload, nil
+}
+
+// MigrateExportedTemplate upgrades the JSON of an exported dashboard template to the current schema version.
+// Templates without schemaVersion were exported before the schema was versioned and are upgraded from version 0.
+func MigrateExportedTemplate(data []byte) ([]byte, error) {
+	template, err := decodeExportPayload(data)
+	if err != nil {
+		return nil, err
+	}
+	version, err := schemaVersion(template)
+	if err != nil {
+		return nil, err
+	}
+	if err := migrateTemplate(template, version); err != nil {
+		return nil, err
+	}
+	template["schemaVersion"] = ExportSchemaVersion
+	return json.Marshal(template)
+}
+
+// MigrateDashboardArchive upgrades the JSON of an archive and all of its templates to the current schema version.
+// Archives have always been versioned, archives without schemaVersion are left to CheckSchemaVersion to reject.
+func MigrateDashboardArchive(data []byte) ([]byte, error) {
+	archive, err := decodeExportPayload(data)
+	if err != nil {
+		return nil, err
+	}
+	version, err := schemaVersion(archive)
+	if err != nil || version == 0 {
+		return data, err
+	}
+	templates, _ := archive["templates"].([]any)
+	for i, item := range templates {
+		template, ok := item.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("template %d is not an object", i)
+		}
+		if err := migrateTemplate(template, version); err != nil {
+			return nil, fmt.Errorf("template %d: %w", i, err)
+		}
+	}
+	archive["schemaVersion"] = ExportSchemaVersion
+	return json.Marshal(archive)
+}
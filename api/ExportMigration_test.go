@@ -0,0 +1,180 @@
+package api_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/RedHatInsights/widget-layout-backend/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// exportedTemplate returns the JSON of an exported template with the widgets on the sm breakpoint
+func exportedTemplate(version string, widgets string) string {
+	schemaVersion := ""
+	if version != "" {
+		schemaVersion = fmt.Sprintf(`"schemaVersion": %s, `, version)
+	}
+	return fmt.Sprintf(`{%s"templateBase": {"name": "landing", "displayName": "Landing"}, "templateConfig": {"sm": %s}}`, schemaVersion, widgets)
+}
+
+func TestMigrateExportedTemplateFromVersion0(t *testing.T) {
+	tests := []struct {
+		name    string
+		widgets string
+		want    string
+	}{
+		{
+			name:    "renames cx and cy coordinates",
+			widgets: `[{"i": "landing-./RhelWidget", "w": 1, "h": 1, "cx": 2, "cy": 3}]`,
+			want:    `[{"i": "landing-./RhelWidget", "w": 1, "h": 1, "x": 2, "y": 3}]`,
+		},
+		{
+			name:    "keeps x and y over cx and cy",
+			widgets: `[{"i": "landing-./RhelWidget", "w": 1, "h": 1, "x": 0, "y": 1, "cx": 2, "cy": 3}]`,
+			want:    `[{"i": "landing-./RhelWidget", "w": 1, "h": 1, "x": 0, "y": 1}]`,
+		},
+		{
+			name:    "maps Chrome widget ids",
+			widgets: `[{"i": "rhel#rhel", "w": 1, "h": 1, "x": 0, "y": 0}, {"i": "favoriteServices#abc", "w": 1, "h": 1, "x": 0, "y": 1}]`,
+			want:    `[{"i": "landing-./RhelWidget", "w": 1, "h": 1, "x": 0, "y": 0}, {"i": "chrome-./DashboardFavorites", "w": 1, "h": 1, "x": 0, "y": 1}]`,
+		},
+		{
+			name:    "maps Chrome service ids without instance",
+			widgets: `[{"i": "openshiftAi", "w": 1, "h": 1, "x": 0, "y": 0}]`,
+			want:    `[{"i": "landing-./OpenShiftAiWidget", "w": 1, "h": 1, "x": 0, "y": 0}]`,
+		},
+		{
+			name:    "keeps unknown widget ids",
+			widgets: `[{"i": "unknown#widget", "w": 1, "h": 1, "x": 0, "y": 0}, {"i": "landing-./AcsWidget#1", "w": 1, "h": 1, "x": 0, "y": 1}]`,
+			want:    `[{"i": "unknown#widget", "w": 1, "h": 1, "x": 0, "y": 0}, {"i": "landing-./AcsWidget#1", "w": 1, "h": 1, "x": 0, "y": 1}]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, version := range []string{"", "0", "null"} {
+				migrated, err := api.MigrateExportedTemplate([]byte(exportedTemplate(version, tt.widgets)))
+				require.NoError(t, err)
+				assert.JSONEq(t, exportedTemplate("1", tt.want), string(migrated))
+			}
+		})
+	}
+}
+
+func TestMigrateExportedTemplateVersion(t *testing.T) {
+	widgets := `[{"i": "rhel#rhel", "w": 1, "h": 1, "x": 0, "y": 0}]`
+	tests := []struct {
+		name    string
+		payload string
+		want    string
+		wantErr string
+	}{
+		{
+			name:    "current version is not migrated",
+			payload: exportedTemplate("1", widgets),
+			want:    exportedTemplate("1", widgets),
+		},
+		{
+			name:    "future version",
+			payload: exportedTemplate("2", widgets),
+			wantErr: "schema version 2 is not supported",
+		},
+		{
+			name:    "negative version",
+			payload: exportedTemplate("-1", widgets),
+			wantErr: "schemaVersion must be a non-negative integer",
+		},
+		{
+			name:    "fractional version",
+			payload: exportedTemplate("1.5", widgets),
+			wantErr: "schemaVersion must be a non-negative integer",
+		},
+		{
+			name:    "string version",
+			payload: exportedTemplate(`"1"`, widgets),
+			wantErr: "schemaVersion must be an integer",
+		},
+		{
+			name:    "widget is not an object",
+			payload: exportedTemplate("", `["rhel#rhel"]`),
+			wantErr: "widget[0] in sm is not an object",
+		},
+		{
+			name:    "payload is not an object",
+			payload: `null`,
+			wantErr: "payload must be a JSON object",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			migrated, err := api.MigrateExportedTemplate([]byte(tt.payload))
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.want, string(migrated))
+		})
+	}
+}
+
+func TestMigrateDashboardArchive(t *testing.T) {
+	archive := func(version string, templates ...string) string {
+		payload := `{"exportedAt": "2025-01-01T00:00:00Z", "userId": "user-123", "templates": [`
+		for i, template := range templates {
+			if i > 0 {
+				payload += ", "
+			}
+			payload += template
+		}
+		payload += "]"
+		if version != "" {
+			payload += `, "schemaVersion": ` + version
+		}
+		return payload + "}"
+	}
+	legacy := exportedTemplate("", `[{"i": "rhel#rhel", "w": 1, "h": 1, "cx": 0, "cy": 1}]`)
+	current := exportedTemplate("", `[{"i": "landing-./RhelWidget", "w": 1, "h": 1, "x": 0, "y": 1}]`)
+
+	tests := []struct {
+		name    string
+		payload string
+		want    string
+		wantErr string
+	}{
+		{
+			name:    "current version is not migrated",
+			payload: archive("1", current),
+			want:    archive("1", current),
+		},
+		{
+			name:    "archive without version is left to be rejected",
+			payload: archive("", legacy),
+			want:    archive("", legacy),
+		},
+		{
+			name:    "future version",
+			payload: archive("2", current),
+			wantErr: "schema version 2 is not supported",
+		},
+		{
+			name:    "template is not an object",
+			payload: archive("1", current, "[]"),
+			wantErr: "template 1 is not an object",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			migrated, err := api.MigrateDashboardArchive([]byte(tt.payload))
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.want, string(migrated))
+		})
+	}
+}
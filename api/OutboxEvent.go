@@ -0,0 +1,16 @@
+package api
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// OutboxEvent is a change event waiting to be published. It is written in the transaction of the change
+// it describes, so an event is never lost when publishing fails after the change was committed.
+type OutboxEvent struct {
+	ID uint `json:"id" yaml:"id" gorm:"primarykey"`
+	// Payload is the encoded CloudEvent
+	Payload   datatypes.JSON `json:"payload" yaml:"payload" gorm:"not null"`
+	CreatedAt time.Time      `json:"createdAt" yaml:"createdAt"`
+}
@@ -0,0 +1,29 @@
+package api
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// Subscribes reports whether events of the type are delivered to the webhook, webhooks without event filters
+// receive all events.
+func (w Webhook) Subscribes(eventType WebhookEventType) bool {
+	if len(w.Events) == 0 {
+		return true
+	}
+	for _, subscribed := range w.Events {
+		if subscribed == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// Sign returns the signature of the payload sent in the X-Webhook-Signature-256 header, the hex encoded
+// HMAC-SHA256 of the payload keyed with the secret of the webhook, prefixed with "sha256=".
+func (w Webhook) Sign(payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(w.Secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
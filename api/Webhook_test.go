@@ -0,0 +1,23 @@
+package api_test
+
+import (
+	"testing"
+
+	"github.com/RedHatInsights/widget-layout-backend/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookSubscribes(t *testing.T) {
+	all := api.Webhook{}
+	assert.True(t, all.Subscribes(api.WebhookEventTemplateDeleted), "Webhooks without filters should receive all events")
+
+	filtered := api.Webhook{Events: []api.WebhookEventType{api.WebhookEventTemplateCreated}}
+	assert.True(t, filtered.Subscribes(api.WebhookEventTemplateCreated))
+	assert.False(t, filtered.Subscribes(api.WebhookEventTemplateUpdated))
+}
+
+func TestWebhookSign(t *testing.T) {
+	webhook := api.Webhook{Secret: "It's a Secret to Everybody"}
+	// example of the GitHub webhook documentation, which uses the same signature scheme
+	assert.Equal(t, "sha256=757107ea0eb2509fc211221cce984b8a37570b6d7586c22c46f4379c8b043e17", webhook.Sign([]byte("Hello, World!")))
+}
@@ -0,0 +1,148 @@
+package api
+
+import (
+	"fmt"
+	"slices"
+
+	"gorm.io/datatypes"
+)
+
+var gridSizes = []GridSizes{Sm, Md, Lg, Xl}
+
+// GetBreakpoint returns a copy of the widgets of a single layout of the configuration.
+func (tc *DashboardTemplateConfig) GetBreakpoint(gs GridSizes) ([]WidgetItem, error) {
+	switch gs {
+	case Sm:
+		return slices.Clone(tc.Sm.Data()), nil
+	case Md:
+		return slices.Clone(tc.Md.Data()), nil
+	case Lg:
+		return slices.Clone(tc.Lg.Data()), nil
+	case Xl:
+		return slices.Clone(tc.Xl.Data()), nil
+	default:
+		return nil, gs.IsValid()
+	}
+}
+
+// SetBreakpoint replaces the widgets of a single layout of the configuration.
+func (tc *DashboardTemplateConfig) SetBreakpoint(gs GridSizes, items []WidgetItem) error {
+	switch gs {
+	case Sm:
+		tc.Sm = datatypes.NewJSONType(items)
+	case Md:
+		tc.Md = datatypes.NewJSONType(items)
+	case Lg:
+		tc.Lg = datatypes.NewJSONType(items)
+	case Xl:
+		tc.Xl = datatypes.NewJSONType(items)
+	default:
+		return gs.IsValid()
+	}
+	return nil
+}
+
+// ApplyOperations applies the widget operations to the configuration in order.
+// The configuration is only modified if all operations succeed.
+func (tc *DashboardTemplateConfig) ApplyOperations(operations []WidgetLayoutOperation) error {
+	result := *tc
+	for idx, op := range operations {
+		if err := result.applyOperation(op); err != nil {
+			return fmt.Errorf("operation[%d] %s: %w", idx, op.Op, err)
+		}
+	}
+	*tc = result
+	return nil
+}
+
+func (tc *DashboardTemplateConfig) applyOperation(op WidgetLayoutOperation) error {
+	breakpoints := gridSizes
+	if op.Breakpoint != nil {
+		if err := op.Breakpoint.IsValid(); err != nil {
+			return err
+		}
+		breakpoints = []GridSizes{*op.Breakpoint}
+	} else if op.Op == WidgetOperationMove || op.Op == WidgetOperationResize {
+		return fmt.Errorf("breakpoint is required")
+	}
+
+	var widgetType string
+	switch op.Op {
+	case WidgetOperationAdd:
+		if op.Widget == nil || op.Widget.WidgetType == "" {
+			return fmt.Errorf("widget with a widgetType is required")
+		}
+		widgetType = op.Widget.WidgetType
+	case WidgetOperationRemove, WidgetOperationMove, WidgetOperationResize:
+		if op.WidgetType == nil || *op.WidgetType == "" {
+			return fmt.Errorf("widgetType is required")
+		}
+		widgetType = *op.WidgetType
+	default:
+		return fmt.Errorf("unknown operation, expected one of %s, %s, %s, %s", WidgetOperationAdd, WidgetOperationRemove, WidgetOperationMove, WidgetOperationResize)
+	}
+
+	if op.Op == WidgetOperationMove && (op.X == nil || op.Y == nil) {
+		return fmt.Errorf("x and y are required")
+	}
+	if op.Op == WidgetOperationResize && op.Width == nil && op.Height == nil {
+		return fmt.Errorf("width or height is required")
+	}
+
+	found := false
+	for _, gs := range breakpoints {
+		items, err := tc.GetBreakpoint(gs)
+		if err != nil {
+			return err
+		}
+		idx := slices.IndexFunc(items, func(wi WidgetItem) bool { return wi.WidgetType == widgetType })
+
+		switch op.Op {
+		case WidgetOperationAdd:
+			if idx >= 0 {
+				return fmt.Errorf("widget %s already exists in %s", widgetType, gs)
+			}
+			widget := *op.Widget
+			// a widget added to all layouts has to fit the narrower ones as well
+			if maxWidth, _ := gs.GetMaxWidth(); op.Breakpoint == nil {
+				widget.Width = min(widget.Width, maxWidth)
+				if widget.X != nil && *widget.X+widget.Width > maxWidth {
+					x := max(maxWidth-widget.Width, 0)
+					widget.X = &x
+				}
+			}
+			items = append(items, widget)
+		case WidgetOperationRemove:
+			if idx < 0 {
+				continue
+			}
+			items = slices.Delete(items, idx, idx+1)
+		case WidgetOperationMove:
+			if idx < 0 {
+				return fmt.Errorf("widget %s not found in %s", widgetType, gs)
+			}
+			x, y := *op.X, *op.Y
+			items[idx].X, items[idx].Y = &x, &y
+		case WidgetOperationResize:
+			if idx < 0 {
+				return fmt.Errorf("widget %s not found in %s", widgetType, gs)
+			}
+			if op.Width != nil {
+				items[idx].Width = *op.Width
+			}
+			if op.Height != nil {
+				items[idx].Height = *op.Height
+			}
+		}
+		found = true
+
+		if err := tc.SetBreakpoint(gs, items); err != nil {
+			return err
+		}
+	}
+
+	if !found {
+		return fmt.Errorf("widget %s not found", widgetType)
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package api_test
+
+import (
+	"testing"
+
+	"github.com/RedHatInsights/widget-layout-backend/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+)
+
+func operationTestConfig() api.DashboardTemplateConfig {
+	items := datatypes.NewJSONType([]api.WidgetItem{
+		{Width: 1, Height: 2, X: intPtr(0), Y: intPtr(0), WidgetType: "widget1"},
+		{Width: 1, Height: 2, X: intPtr(0), Y: intPtr(2), WidgetType: "widget2"},
+	})
+	return api.DashboardTemplateConfig{Sm: items, Md: items, Lg: items, Xl: items}
+}
+
+func breakpointPtr(gs api.GridSizes) *api.GridSizes {
+	return &gs
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
+
+func TestDashboardTemplateConfigApplyOperations(t *testing.T) {
+	t.Run("should add a widget to all layouts and clamp its width", func(t *testing.T) {
+		config := operationTestConfig()
+		err := config.ApplyOperations([]api.WidgetLayoutOperation{
+			{Op: api.WidgetOperationAdd, Widget: &api.WidgetItem{Width: 3, Height: 1, X: intPtr(0), Y: intPtr(4), WidgetType: "widget3"}},
+		})
+		require.NoError(t, err)
+
+		sm := config.Sm.Data()
+		require.Len(t, sm, 3)
+		assert.Equal(t, "widget3", sm[2].WidgetType)
+		assert.Equal(t, 1, sm[2].Width, "Width should be clamped to the sm layout")
+		assert.Equal(t, 3, config.Xl.Data()[2].Width)
+		assert.NoError(t, config.IsValid())
+	})
+
+	t.Run("should add a widget to all layouts and clamp its position", func(t *testing.T) {
+		config := operationTestConfig()
+		err := config.ApplyOperations([]api.WidgetLayoutOperation{
+			{Op: api.WidgetOperationAdd, Widget: &api.WidgetItem{Width: 1, Height: 1, X: intPtr(3), Y: intPtr(4), WidgetType: "widget3"}},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, 0, *config.Sm.Data()[2].X, "X should be clamped to the sm layout")
+		assert.Equal(t, 1, *config.Md.Data()[2].X, "X should be clamped to the md layout")
+		assert.Equal(t, 2, *config.Lg.Data()[2].X, "X should be clamped to the lg layout")
+		assert.Equal(t, 3, *config.Xl.Data()[2].X)
+		assert.NoError(t, config.IsValid())
+	})
+
+	t.Run("should remove a widget from a single layout", func(t *testing.T) {
+		config := operationTestConfig()
+		err := config.ApplyOperations([]api.WidgetLayoutOperation{
+			{Op: api.WidgetOperationRemove, Breakpoint: breakpointPtr(api.Lg), WidgetType: stringPtr("widget1")},
+		})
+		require.NoError(t, err)
+
+		require.Len(t, config.Lg.Data(), 1)
+		assert.Equal(t, "widget2", config.Lg.Data()[0].WidgetType)
+		assert.Len(t, config.Md.Data(), 2, "Other layouts should not change")
+	})
+
+	t.Run("should move and resize a widget", func(t *testing.T) {
+		config := operationTestConfig()
+		err := config.ApplyOperations([]api.WidgetLayoutOperation{
+			{Op: api.WidgetOperationMove, Breakpoint: breakpointPtr(api.Xl), WidgetType: stringPtr("widget2"), X: intPtr(2), Y: intPtr(0)},
+			{Op: api.WidgetOperationResize, Breakpoint: breakpointPtr(api.Xl), WidgetType: stringPtr("widget2"), Width: intPtr(2), Height: intPtr(3)},
+		})
+		require.NoError(t, err)
+
+		widget := config.Xl.Data()[1]
+		assert.Equal(t, 2, *widget.X)
+		assert.Equal(t, 0, *widget.Y)
+		assert.Equal(t, 2, widget.Width)
+		assert.Equal(t, 3, widget.Height)
+		assert.Equal(t, 2, *config.Lg.Data()[1].Y, "Other layouts should not change")
+	})
+
+	t.Run("should leave the configuration untouched if an operation fails", func(t *testing.T) {
+		config := operationTestConfig()
+		err := config.ApplyOperations([]api.WidgetLayoutOperation{
+			{Op: api.WidgetOperationRemove, WidgetType: stringPtr("widget1")},
+			{Op: api.WidgetOperationMove, Breakpoint: breakpointPtr(api.Xl), WidgetType: stringPtr("missing"), X: intPtr(0), Y: intPtr(0)},
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "operation[1]")
+		assert.Equal(t, operationTestConfig(), config)
+	})
+
+	t.Run("should reject invalid operations", func(t *testing.T) {
+		invalid := map[string]api.WidgetLayoutOperation{
+			"unknown operation":         {Op: "swap", WidgetType: stringPtr("widget1")},
+			"add without widget":        {Op: api.WidgetOperationAdd},
+			"add of an existing widget": {Op: api.WidgetOperationAdd, Widget: &api.WidgetItem{Width: 1, Height: 1, X: intPtr(0), Y: intPtr(0), WidgetType: "widget1"}},
+			"remove of unknown widget":  {Op: api.WidgetOperationRemove, WidgetType: stringPtr("missing")},
+			"move without breakpoint":   {Op: api.WidgetOperationMove, WidgetType: stringPtr("widget1"), X: intPtr(0), Y: intPtr(0)},
+			"move without coordinates":  {Op: api.WidgetOperationMove, Breakpoint: breakpointPtr(api.Lg), WidgetType: stringPtr("widget1")},
+			"resize without dimensions": {Op: api.WidgetOperationResize, Breakpoint: breakpointPtr(api.Lg), WidgetType: stringPtr("widget1")},
+			"invalid breakpoint":        {Op: api.WidgetOperationRemove, Breakpoint: breakpointPtr("xxl"), WidgetType: stringPtr("widget1")},
+		}
+		for name, op := range invalid {
+			config := operationTestConfig()
+			assert.Error(t, config.ApplyOperations([]api.WidgetLayoutOperation{op}), name)
+		}
+	})
+}
@@ -2,8 +2,10 @@ package api
 
 import "fmt"
 
-type WidgetMappingRegistry struct {
-	WidgetMappings map[string]WidgetModuleFederationMetadata `json:"widgetMappings" yaml:"widgetMappings"`
+// WidgetMappingLookup resolves widget mappings by their widget key, see GetWidgetKey.
+type WidgetMappingLookup interface {
+	Get(key string) (WidgetModuleFederationMetadata, bool)
+	Len() int
 }
 
 type WidgetMappingResponse struct {
@@ -21,21 +23,86 @@ func (wc *WidgetModuleFederationMetadata) GetWidgetKey() string {
 	return key
 }
 
-func (wmr *WidgetMappingRegistry) AddWidgetMapping(wc WidgetModuleFederationMetadata) {
-	if wmr.WidgetMappings == nil {
-		wmr.WidgetMappings = make(map[string]WidgetModuleFederationMetadata)
+// CheckWidgetMappings cross-references every widget of the configuration with the widget mapping registry.
+// Widgets have to be registered and their height has to stay within the minHeight and maxHeight of their mapping.
+// Widths are intentionally not checked: mappings only carry the width widgets start with, users resize widgets
+// freely and derived breakpoints scale widths below it. Widths are limited by the columns of the layout in IsValid.
+// In strict mode every violation is returned in a *LayoutValidationError. In lenient mode unknown widgets are
+// removed, heights are clamped and a warning is returned for every change. Empty mappings disable the check.
+func (tc *DashboardTemplateConfig) CheckWidgetMappings(mappings WidgetMappingLookup, mode ValidationMode) ([]string, error) {
+	if mode != ValidationModeStrict && mode != ValidationModeLenient {
+		return nil, fmt.Errorf("invalid validation mode, expected one of %s, %s, got %s", ValidationModeStrict, ValidationModeLenient, mode)
 	}
-	wmr.WidgetMappings[wc.GetWidgetKey()] = wc
+	if mappings.Len() == 0 {
+		return nil, nil
+	}
+
+	var issues []LayoutIssue
+	var warnings []string
+	result := *tc
+	for _, gs := range gridSizes {
+		items, err := result.GetBreakpoint(gs)
+		if err != nil {
+			return nil, err
+		}
+		if items == nil {
+			continue
+		}
+
+		checked := make([]WidgetItem, 0, len(items))
+		for idx, wi := range items {
+			mapping, ok := mappings.Get(wi.WidgetType)
+			if !ok {
+				message := fmt.Sprintf("widget[%d] %s in %s: widget is not registered in the widget mapping", idx, wi.WidgetType, gs)
+				if mode == ValidationModeLenient {
+					warnings = append(warnings, message+", removed")
+					continue
+				}
+				issues = append(issues, LayoutIssue{Kind: LayoutIssueUnknown, Breakpoint: gs, Widgets: []string{wi.WidgetType}, Message: message})
+			} else if height, ok := mapping.Defaults.clampHeight(wi.Height); !ok {
+				message := fmt.Sprintf("widget[%d] %s in %s: height %d is outside of the range %s of the widget mapping", idx, wi.WidgetType, gs, wi.Height, mapping.Defaults.heightRange())
+				if mode == ValidationModeLenient {
+					warnings = append(warnings, fmt.Sprintf("%s, changed to %d", message, height))
+					wi.Height = height
+				} else {
+					issues = append(issues, LayoutIssue{Kind: LayoutIssueHeight, Breakpoint: gs, Widgets: []string{wi.WidgetType}, Message: message})
+				}
+			}
+			checked = append(checked, wi)
+		}
+
+		if err := result.SetBreakpoint(gs, checked); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(issues) > 0 {
+		return nil, &LayoutValidationError{Issues: issues}
+	}
+	*tc = result
+	return warnings, nil
 }
 
-func (wmr *WidgetMappingRegistry) GetWidgetMapping(name string) (WidgetModuleFederationMetadata, bool) {
-	wm, exists := wmr.WidgetMappings[name]
-	return wm, exists
+// clampHeight returns the height limited to the minHeight and maxHeight of the dimensions
+// and whether the height was within the limits in the first place.
+func (wbd WidgetBaseDimensions) clampHeight(height int) (int, bool) {
+	clamped := height
+	if wbd.MinHeight != nil && clamped < *wbd.MinHeight {
+		clamped = *wbd.MinHeight
+	}
+	if wbd.MaxHeight != nil && clamped > *wbd.MaxHeight {
+		clamped = *wbd.MaxHeight
+	}
+	return clamped, clamped == height
 }
 
-func (wmr *WidgetMappingRegistry) GetAllWidgetMappings() map[string]WidgetModuleFederationMetadata {
-	if wmr.WidgetMappings == nil {
-		return make(map[string]WidgetModuleFederationMetadata)
+func (wbd WidgetBaseDimensions) heightRange() string {
+	minHeight, maxHeight := "1", "unbounded"
+	if wbd.MinHeight != nil {
+		minHeight = fmt.Sprint(*wbd.MinHeight)
+	}
+	if wbd.MaxHeight != nil {
+		maxHeight = fmt.Sprint(*wbd.MaxHeight)
 	}
-	return wmr.WidgetMappings
+	return fmt.Sprintf("%s-%s", minHeight, maxHeight)
 }
@@ -0,0 +1,146 @@
+package api
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+type PolicyRule string
+
+const (
+	PolicyRuleMandatory PolicyRule = "mandatory"
+	PolicyRuleLocked    PolicyRule = "locked"
+	PolicyRuleForbidden PolicyRule = "forbidden"
+)
+
+// PolicyViolation describes a widget of a layout breaking a rule of a widget policy.
+type PolicyViolation struct {
+	Rule       PolicyRule
+	Breakpoint GridSizes
+	Widget     string
+	Message    string
+}
+
+// PolicyViolationError lists every rule of a widget policy a template configuration breaks.
+type PolicyViolationError struct {
+	Violations []PolicyViolation
+}
+
+func (e *PolicyViolationError) Error() string {
+	messages := make([]string, 0, len(e.Violations))
+	for _, violation := range e.Violations {
+		messages = append(messages, violation.Message)
+	}
+	return "widget policy violated: " + strings.Join(messages, "; ")
+}
+
+// Merge returns a policy enforcing the rules of both policies.
+func (p WidgetPolicy) Merge(other WidgetPolicy) WidgetPolicy {
+	union := func(a, b []string) []string {
+		result := slices.Clone(a)
+		for _, widget := range b {
+			if !slices.Contains(result, widget) {
+				result = append(result, widget)
+			}
+		}
+		return result
+	}
+	return WidgetPolicy{
+		MandatoryWidgets: union(p.MandatoryWidgets, other.MandatoryWidgets),
+		LockedWidgets:    union(p.LockedWidgets, other.LockedWidgets),
+		ForbiddenWidgets: union(p.ForbiddenWidgets, other.ForbiddenWidgets),
+	}
+}
+
+// IsValid rejects policies requiring and forbidding the same widget.
+func (p WidgetPolicy) IsValid() error {
+	for _, widget := range p.ForbiddenWidgets {
+		if slices.Contains(p.MandatoryWidgets, widget) || slices.Contains(p.LockedWidgets, widget) {
+			return fmt.Errorf("widget %s cannot be forbidden and mandatory or locked at the same time", widget)
+		}
+	}
+	return nil
+}
+
+// Check validates the layouts of the configuration against the policy. Locked widgets keep the position and
+// size they have in the layouts of the base template, widgets missing from the base template are only required.
+// All violations are returned together as a *PolicyViolationError.
+func (p WidgetPolicy) Check(tc DashboardTemplateConfig, base *DashboardTemplateConfig) error {
+	var violations []PolicyViolation
+	for _, gs := range gridSizes {
+		items, err := tc.GetBreakpoint(gs)
+		if err != nil {
+			return err
+		}
+		var baseItems []WidgetItem
+		if base != nil {
+			if baseItems, err = base.GetBreakpoint(gs); err != nil {
+				return err
+			}
+		}
+
+		for _, widget := range p.MandatoryWidgets {
+			if _, ok := findWidget(items, widget); !ok {
+				violations = append(violations, PolicyViolation{
+					Rule:       PolicyRuleMandatory,
+					Breakpoint: gs,
+					Widget:     widget,
+					Message:    fmt.Sprintf("%s rule: widget %s cannot be removed from %s", PolicyRuleMandatory, widget, gs),
+				})
+			}
+		}
+		for _, widget := range p.LockedWidgets {
+			item, ok := findWidget(items, widget)
+			if !ok {
+				violations = append(violations, PolicyViolation{
+					Rule:       PolicyRuleLocked,
+					Breakpoint: gs,
+					Widget:     widget,
+					Message:    fmt.Sprintf("%s rule: widget %s cannot be removed from %s", PolicyRuleLocked, widget, gs),
+				})
+				continue
+			}
+			if baseItem, ok := findWidget(baseItems, widget); ok && !samePlacement(item, baseItem) {
+				violations = append(violations, PolicyViolation{
+					Rule:       PolicyRuleLocked,
+					Breakpoint: gs,
+					Widget:     widget,
+					Message:    fmt.Sprintf("%s rule: widget %s cannot be moved or resized in %s", PolicyRuleLocked, widget, gs),
+				})
+			}
+		}
+		for _, widget := range p.ForbiddenWidgets {
+			if _, ok := findWidget(items, widget); ok {
+				violations = append(violations, PolicyViolation{
+					Rule:       PolicyRuleForbidden,
+					Breakpoint: gs,
+					Widget:     widget,
+					Message:    fmt.Sprintf("%s rule: widget %s cannot be added to %s", PolicyRuleForbidden, widget, gs),
+				})
+			}
+		}
+	}
+	if len(violations) > 0 {
+		return &PolicyViolationError{Violations: violations}
+	}
+	return nil
+}
+
+func findWidget(items []WidgetItem, widget string) (WidgetItem, bool) {
+	idx := slices.IndexFunc(items, func(wi WidgetItem) bool { return wi.WidgetType == widget })
+	if idx < 0 {
+		return WidgetItem{}, false
+	}
+	return items[idx], true
+}
+
+func samePlacement(a, b WidgetItem) bool {
+	coordinate := func(c *int) int {
+		if c == nil {
+			return 0
+		}
+		return *c
+	}
+	return coordinate(a.X) == coordinate(b.X) && coordinate(a.Y) == coordinate(b.Y) && a.Width == b.Width && a.Height == b.Height
+}
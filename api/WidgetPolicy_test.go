@@ -0,0 +1,99 @@
+package api_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/RedHatInsights/widget-layout-backend/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+)
+
+func policyViolations(t *testing.T, err error) []api.PolicyViolation {
+	var policyErr *api.PolicyViolationError
+	require.True(t, errors.As(err, &policyErr), "Expected a policy violation, got %v", err)
+	return policyErr.Violations
+}
+
+func TestWidgetPolicyCheck(t *testing.T) {
+	t.Run("should accept layouts following the policy", func(t *testing.T) {
+		config := operationTestConfig()
+		policy := api.WidgetPolicy{
+			MandatoryWidgets: []string{"widget1"},
+			LockedWidgets:    []string{"widget2"},
+			ForbiddenWidgets: []string{"widget3"},
+		}
+		assert.NoError(t, policy.Check(config, &config))
+	})
+
+	t.Run("should report removed mandatory widgets per breakpoint", func(t *testing.T) {
+		config := operationTestConfig()
+		config.Md = datatypes.NewJSONType([]api.WidgetItem{
+			{Width: 1, Height: 2, X: intPtr(0), Y: intPtr(2), WidgetType: "widget2"},
+		})
+		policy := api.WidgetPolicy{MandatoryWidgets: []string{"widget1"}}
+
+		violations := policyViolations(t, policy.Check(config, nil))
+		require.Len(t, violations, 1)
+		assert.Equal(t, api.PolicyRuleMandatory, violations[0].Rule)
+		assert.Equal(t, api.Md, violations[0].Breakpoint)
+		assert.Equal(t, "widget1", violations[0].Widget)
+		assert.Contains(t, violations[0].Message, "mandatory rule")
+	})
+
+	t.Run("should keep locked widgets at their position in the base template", func(t *testing.T) {
+		base := operationTestConfig()
+		config := operationTestConfig()
+		config.Lg = datatypes.NewJSONType([]api.WidgetItem{
+			{Width: 1, Height: 2, X: intPtr(0), Y: intPtr(0), WidgetType: "widget1"},
+			{Width: 2, Height: 2, X: intPtr(1), Y: intPtr(2), WidgetType: "widget2"},
+		})
+		config.Xl = datatypes.NewJSONType([]api.WidgetItem{
+			{Width: 1, Height: 2, X: intPtr(0), Y: intPtr(0), WidgetType: "widget1"},
+		})
+		policy := api.WidgetPolicy{LockedWidgets: []string{"widget2"}}
+
+		violations := policyViolations(t, policy.Check(config, &base))
+		require.Len(t, violations, 2)
+		assert.Equal(t, api.Lg, violations[0].Breakpoint)
+		assert.Contains(t, violations[0].Message, "cannot be moved or resized")
+		assert.Equal(t, api.Xl, violations[1].Breakpoint)
+		assert.Contains(t, violations[1].Message, "cannot be removed")
+
+		// without a base template locked widgets can be placed freely
+		violations = policyViolations(t, policy.Check(config, nil))
+		require.Len(t, violations, 1)
+		assert.Equal(t, api.Xl, violations[0].Breakpoint)
+	})
+
+	t.Run("should report forbidden widgets", func(t *testing.T) {
+		config := operationTestConfig()
+		policy := api.WidgetPolicy{ForbiddenWidgets: []string{"widget2"}}
+
+		violations := policyViolations(t, policy.Check(config, nil))
+		assert.Len(t, violations, 4)
+		for _, violation := range violations {
+			assert.Equal(t, api.PolicyRuleForbidden, violation.Rule)
+		}
+	})
+}
+
+func TestWidgetPolicyMerge(t *testing.T) {
+	merged := api.WidgetPolicy{
+		MandatoryWidgets: []string{"widget1"},
+		ForbiddenWidgets: []string{"widget3"},
+	}.Merge(api.WidgetPolicy{
+		MandatoryWidgets: []string{"widget1", "widget2"},
+		LockedWidgets:    []string{"widget4"},
+	})
+
+	assert.Equal(t, []string{"widget1", "widget2"}, merged.MandatoryWidgets)
+	assert.Equal(t, []string{"widget4"}, merged.LockedWidgets)
+	assert.Equal(t, []string{"widget3"}, merged.ForbiddenWidgets)
+}
+
+func TestWidgetPolicyIsValid(t *testing.T) {
+	assert.NoError(t, api.WidgetPolicy{MandatoryWidgets: []string{"widget1"}, ForbiddenWidgets: []string{"widget2"}}.IsValid())
+	assert.Error(t, api.WidgetPolicy{LockedWidgets: []string{"widget1"}, ForbiddenWidgets: []string{"widget1"}}.IsValid())
+}
@@ -1,12 +1,32 @@
 package api
 
 import (
+	"crypto/sha256"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"strings"
 
 	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
 )
 
+// IsReadableBy reports whether a user of an organization can see the template. Templates visible to
+// the organization can be seen by all of its members, every other template only by its owner.
+func (t DashboardTemplate) IsReadableBy(userID, orgID string) bool {
+	return t.UserId == userID || t.sharedWith(orgID)
+}
+
+// IsEditableBy reports whether a user of an organization can modify the template. Templates visible
+// to the organization can be modified by its org admins, every other template only by its owner.
+func (t DashboardTemplate) IsEditableBy(userID, orgID string, orgAdmin bool) bool {
+	return t.UserId == userID || (orgAdmin && t.sharedWith(orgID))
+}
+
+func (t DashboardTemplate) sharedWith(orgID string) bool {
+	return t.Visibility == VisibilityOrg && t.OrgId != "" && t.OrgId == orgID
+}
+
 func (t DashboardTemplate) IsAuthorized(userID string) bool {
 	// This method checks if the user is authorized to access the template.
 	// For this example, we will assume that the user is authorized if their ID matches the UserId in the template.
@@ -16,6 +36,70 @@ func (t DashboardTemplate) IsAuthorized(userID string) bool {
 	return t.UserId == userID
 }
 
+// New templates always start at version 1, the version is then incremented by every modification.
+func (t *DashboardTemplate) BeforeCreate(tx *gorm.DB) error {
+	if t.Version == 0 {
+		t.Version = 1
+	}
+	// new templates are private unless they are shared explicitly
+	if t.Visibility == "" {
+		t.Visibility = VisibilityPrivate
+	}
+	return nil
+}
+
+// ETag returns the entity tag of the template, it changes with every modification of the template.
+func (t DashboardTemplate) ETag() string {
+	return fmt.Sprintf("\"%d-%d\"", t.ID, t.Version)
+}
+
+// MatchesETag reports whether the value of an If-Match header matches the current version of the template.
+// Weak validators are compared as strong ones since the version changes on every modification.
+func (t DashboardTemplate) MatchesETag(ifMatch string) bool {
+	etag := t.ETag()
+	for _, candidate := range strings.Split(ifMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// DashboardTemplatesETag returns the entity tag of a list of templates, it changes whenever a template is
+// added to or removed from the list, or when any of them is modified.
+func DashboardTemplatesETag(templates []DashboardTemplate) string {
+	hash := sha256.New()
+	for _, t := range templates {
+		fmt.Fprintf(hash, "%d-%d;", t.ID, t.Version)
+	}
+	return fmt.Sprintf("\"%x\"", hash.Sum(nil)[:16])
+}
+
+// Summary returns the fields of the template needed to list it, without its layouts.
+func (t DashboardTemplate) Summary() DashboardTemplateSummary {
+	return DashboardTemplateSummary{
+		ID:            t.ID,
+		DashboardName: t.DashboardName,
+		TemplateBase:  t.TemplateBase,
+		Default:       t.Default,
+		Visibility:    t.Visibility,
+		UpdatedAt:     t.UpdatedAt,
+		Version:       t.Version,
+	}
+}
+
+// DashboardTemplateSummariesETag returns the entity tag of a list of template summaries, see DashboardTemplatesETag.
+// It differs from the entity tag of the same templates listed in full.
+func DashboardTemplateSummariesETag(summaries []DashboardTemplateSummary) string {
+	hash := sha256.New()
+	hash.Write([]byte("summary;"))
+	for _, s := range summaries {
+		fmt.Fprintf(hash, "%d-%d;", s.ID, s.Version)
+	}
+	return fmt.Sprintf("\"%x\"", hash.Sum(nil)[:16])
+}
+
 // We have to ensure the CX and CY attributes gets unmarshaled into x and y attributes
 // There is an issue with the yaml parser that has the character "y" as a reserved character which translates into "true" value and it causes issues
 // when unmarshaling the yaml file into the DashboardTemplateConfig struct.
@@ -0,0 +1,37 @@
+package api_test
+
+import (
+	"testing"
+
+	"github.com/RedHatInsights/widget-layout-backend/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDashboardTemplateETag(t *testing.T) {
+	t.Run("should change with the template version", func(t *testing.T) {
+		template := api.DashboardTemplate{ID: 1, Version: 1}
+		etag := template.ETag()
+		template.Version++
+		assert.NotEqual(t, etag, template.ETag())
+	})
+
+	t.Run("should match If-Match values", func(t *testing.T) {
+		template := api.DashboardTemplate{ID: 7, Version: 3}
+		assert.True(t, template.MatchesETag(template.ETag()))
+		assert.True(t, template.MatchesETag("W/"+template.ETag()), "Weak validators should match")
+		assert.True(t, template.MatchesETag(`"1-1", `+template.ETag()), "Any entry of a list should match")
+		assert.True(t, template.MatchesETag("*"))
+		assert.False(t, template.MatchesETag(`"7-2"`))
+		assert.False(t, template.MatchesETag(""))
+	})
+
+	t.Run("should change the list ETag when any template changes", func(t *testing.T) {
+		templates := []api.DashboardTemplate{{ID: 1, Version: 1}, {ID: 2, Version: 1}}
+		etag := api.DashboardTemplatesETag(templates)
+		assert.Equal(t, etag, api.DashboardTemplatesETag(templates))
+
+		templates[1].Version++
+		assert.NotEqual(t, etag, api.DashboardTemplatesETag(templates))
+		assert.NotEqual(t, etag, api.DashboardTemplatesETag(templates[:1]))
+	})
+}
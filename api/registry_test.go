@@ -4,96 +4,12 @@ import (
 	"testing"
 
 	"github.com/RedHatInsights/widget-layout-backend/api"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/registry"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"gorm.io/datatypes"
 )
 
-func TestBaseWidgetDashboardTemplateRegistry(t *testing.T) {
-	t.Run("AddBase should initialize map and add template", func(t *testing.T) {
-		registry := api.BaseWidgetDashboardTemplateRegistry{}
-		bt := api.BaseWidgetDashboardTemplate{
-			Name:        "test-base",
-			DisplayName: "Test Base",
-			TemplateConfig: api.DashboardTemplateConfig{
-				Sm: datatypes.NewJSONType([]api.WidgetItem{}),
-				Md: datatypes.NewJSONType([]api.WidgetItem{}),
-				Lg: datatypes.NewJSONType([]api.WidgetItem{}),
-				Xl: datatypes.NewJSONType([]api.WidgetItem{}),
-			},
-		}
-
-		registry.AddBase(bt)
-
-		result, exists := registry.GetBase("test-base")
-		assert.True(t, exists)
-		assert.Equal(t, "test-base", result.Name)
-		assert.Equal(t, "Test Base", result.DisplayName)
-	})
-
-	t.Run("GetBase should return false for non-existent template", func(t *testing.T) {
-		registry := api.BaseWidgetDashboardTemplateRegistry{}
-		registry.AddBase(api.BaseWidgetDashboardTemplate{
-			Name:        "existing",
-			DisplayName: "Existing",
-			TemplateConfig: api.DashboardTemplateConfig{
-				Sm: datatypes.NewJSONType([]api.WidgetItem{}),
-				Md: datatypes.NewJSONType([]api.WidgetItem{}),
-				Lg: datatypes.NewJSONType([]api.WidgetItem{}),
-				Xl: datatypes.NewJSONType([]api.WidgetItem{}),
-			},
-		})
-
-		_, exists := registry.GetBase("non-existent")
-		assert.False(t, exists)
-	})
-
-	t.Run("GetAllBases should return all templates", func(t *testing.T) {
-		registry := api.BaseWidgetDashboardTemplateRegistry{}
-		emptyConfig := api.DashboardTemplateConfig{
-			Sm: datatypes.NewJSONType([]api.WidgetItem{}),
-			Md: datatypes.NewJSONType([]api.WidgetItem{}),
-			Lg: datatypes.NewJSONType([]api.WidgetItem{}),
-			Xl: datatypes.NewJSONType([]api.WidgetItem{}),
-		}
-
-		registry.AddBase(api.BaseWidgetDashboardTemplate{Name: "base-1", DisplayName: "Base 1", TemplateConfig: emptyConfig})
-		registry.AddBase(api.BaseWidgetDashboardTemplate{Name: "base-2", DisplayName: "Base 2", TemplateConfig: emptyConfig})
-
-		all := registry.GetAllBases()
-		assert.Len(t, all, 2)
-		assert.Contains(t, all, "base-1")
-		assert.Contains(t, all, "base-2")
-	})
-
-	t.Run("GetAllBases should return empty map when nil", func(t *testing.T) {
-		registry := api.BaseWidgetDashboardTemplateRegistry{}
-		all := registry.GetAllBases()
-		assert.NotNil(t, all)
-		assert.Len(t, all, 0)
-	})
-
-	t.Run("AddBase should overwrite existing template with same name", func(t *testing.T) {
-		registry := api.BaseWidgetDashboardTemplateRegistry{}
-		emptyConfig := api.DashboardTemplateConfig{
-			Sm: datatypes.NewJSONType([]api.WidgetItem{}),
-			Md: datatypes.NewJSONType([]api.WidgetItem{}),
-			Lg: datatypes.NewJSONType([]api.WidgetItem{}),
-			Xl: datatypes.NewJSONType([]api.WidgetItem{}),
-		}
-
-		registry.AddBase(api.BaseWidgetDashboardTemplate{Name: "overwrite-test", DisplayName: "Original", TemplateConfig: emptyConfig})
-		registry.AddBase(api.BaseWidgetDashboardTemplate{Name: "overwrite-test", DisplayName: "Updated", TemplateConfig: emptyConfig})
-
-		result, exists := registry.GetBase("overwrite-test")
-		assert.True(t, exists)
-		assert.Equal(t, "Updated", result.DisplayName)
-
-		all := registry.GetAllBases()
-		assert.Len(t, all, 1)
-	})
-}
-
 func TestToDashboardTemplate(t *testing.T) {
 	t.Run("should convert base template to dashboard template", func(t *testing.T) {
 		bt := api.BaseWidgetDashboardTemplate{
@@ -121,50 +37,6 @@ func TestToDashboardTemplate(t *testing.T) {
 	})
 }
 
-func TestWidgetMappingRegistry(t *testing.T) {
-	t.Run("AddWidgetMapping should initialize map and add mapping", func(t *testing.T) {
-		registry := api.WidgetMappingRegistry{}
-		mapping := api.WidgetModuleFederationMetadata{
-			Scope:  "testScope",
-			Module: "./TestModule",
-		}
-
-		registry.AddWidgetMapping(mapping)
-
-		result, exists := registry.GetWidgetMapping("testScope-./TestModule")
-		assert.True(t, exists)
-		assert.Equal(t, "testScope", result.Scope)
-		assert.Equal(t, "./TestModule", result.Module)
-	})
-
-	t.Run("GetWidgetMapping should return false for non-existent mapping", func(t *testing.T) {
-		registry := api.WidgetMappingRegistry{}
-		registry.AddWidgetMapping(api.WidgetModuleFederationMetadata{
-			Scope:  "scope1",
-			Module: "./Module1",
-		})
-
-		_, exists := registry.GetWidgetMapping("non-existent-key")
-		assert.False(t, exists)
-	})
-
-	t.Run("GetAllWidgetMappings should return all mappings", func(t *testing.T) {
-		registry := api.WidgetMappingRegistry{}
-		registry.AddWidgetMapping(api.WidgetModuleFederationMetadata{Scope: "scope1", Module: "./Mod1"})
-		registry.AddWidgetMapping(api.WidgetModuleFederationMetadata{Scope: "scope2", Module: "./Mod2"})
-
-		all := registry.GetAllWidgetMappings()
-		assert.Len(t, all, 2)
-	})
-
-	t.Run("GetAllWidgetMappings should return empty map when nil", func(t *testing.T) {
-		registry := api.WidgetMappingRegistry{}
-		all := registry.GetAllWidgetMappings()
-		assert.NotNil(t, all)
-		assert.Len(t, all, 0)
-	})
-}
-
 func TestGetWidgetKey(t *testing.T) {
 	t.Run("should generate key without importName", func(t *testing.T) {
 		wm := api.WidgetModuleFederationMetadata{
@@ -194,3 +66,69 @@ func TestGetWidgetKey(t *testing.T) {
 		assert.Equal(t, "myScope-./MyModule", wm.GetWidgetKey())
 	})
 }
+
+func TestCheckWidgetMappings(t *testing.T) {
+	mappings := registry.New[string, api.WidgetModuleFederationMetadata]()
+	mappings.Put("landing-./RhelWidget", api.WidgetModuleFederationMetadata{
+		Scope:  "landing",
+		Module: "./RhelWidget",
+		Defaults: api.WidgetBaseDimensions{
+			Width:     intPtr(1),
+			Height:    intPtr(4),
+			MaxHeight: intPtr(6),
+			MinHeight: intPtr(2),
+		},
+	})
+
+	config := func() api.DashboardTemplateConfig {
+		items := datatypes.NewJSONType([]api.WidgetItem{
+			{WidgetType: "landing-./RhelWidget", Width: 1, Height: 8, X: intPtr(0), Y: intPtr(0)},
+			{WidgetType: "landing-./UnknownWidget", Width: 1, Height: 2, X: intPtr(0), Y: intPtr(8)},
+		})
+		return api.DashboardTemplateConfig{Sm: items, Md: items, Lg: items, Xl: items}
+	}
+
+	t.Run("should report every violation in strict mode", func(t *testing.T) {
+		tc := config()
+		warnings, err := tc.CheckWidgetMappings(mappings, api.ValidationModeStrict)
+		require.Error(t, err)
+		assert.Empty(t, warnings)
+
+		var validationErr *api.LayoutValidationError
+		require.ErrorAs(t, err, &validationErr)
+		require.Len(t, validationErr.Issues, 8)
+		assert.Equal(t, api.LayoutIssueHeight, validationErr.Issues[0].Kind)
+		assert.Equal(t, api.Sm, validationErr.Issues[0].Breakpoint)
+		assert.Equal(t, api.LayoutIssueUnknown, validationErr.Issues[1].Kind)
+		assert.Equal(t, []string{"landing-./UnknownWidget"}, validationErr.Issues[1].Widgets)
+		assert.Len(t, tc.Sm.Data(), 2, "The configuration should not change in strict mode")
+	})
+
+	t.Run("should remove unknown widgets and clamp heights in lenient mode", func(t *testing.T) {
+		tc := config()
+		warnings, err := tc.CheckWidgetMappings(mappings, api.ValidationModeLenient)
+		require.NoError(t, err)
+		assert.Len(t, warnings, 8)
+		assert.Contains(t, warnings[0], "changed to 6")
+		assert.Contains(t, warnings[1], "removed")
+
+		for _, items := range [][]api.WidgetItem{tc.Sm.Data(), tc.Md.Data(), tc.Lg.Data(), tc.Xl.Data()} {
+			require.Len(t, items, 1)
+			assert.Equal(t, "landing-./RhelWidget", items[0].WidgetType)
+			assert.Equal(t, 6, items[0].Height)
+		}
+	})
+
+	t.Run("should skip the check when there are no mappings", func(t *testing.T) {
+		tc := config()
+		warnings, err := tc.CheckWidgetMappings(registry.New[string, api.WidgetModuleFederationMetadata](), api.ValidationModeStrict)
+		assert.NoError(t, err)
+		assert.Empty(t, warnings)
+	})
+
+	t.Run("should reject unknown validation modes", func(t *testing.T) {
+		tc := config()
+		_, err := tc.CheckWidgetMappings(mappings, api.ValidationMode("relaxed"))
+		assert.Error(t, err)
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"strings"
 )
 
 const (
@@ -30,6 +31,15 @@ func (gs GridSizes) IsValid() error {
 	}
 }
 
+func (v TemplateVisibility) IsValid() error {
+	switch v {
+	case VisibilityPrivate, VisibilityOrg:
+		return nil
+	default:
+		return fmt.Errorf("invalid visibility, expected one of %s, %s, got %s", VisibilityPrivate, VisibilityOrg, v)
+	}
+}
+
 func (gs GridSizes) GetMaxWidth() (int, error) {
 	if err := gs.IsValid(); err != nil {
 		return 0, err
@@ -66,8 +76,96 @@ func (dt *DashboardTemplate) IsValid() error {
 	return nil
 }
 
-// IsValid validates the template configuration
+type LayoutIssueKind string
+
+const (
+	LayoutIssueOverlap   LayoutIssueKind = "overlap"
+	LayoutIssueOverflow  LayoutIssueKind = "overflow"
+	LayoutIssueDuplicate LayoutIssueKind = "duplicate"
+	LayoutIssueUnknown   LayoutIssueKind = "unknown"
+	LayoutIssueHeight    LayoutIssueKind = "height"
+)
+
+// LayoutIssue describes a conflict between widgets of a single layout, it is reported as an ErrorPayload.
+// Widgets holds the widgetType of every widget involved, in the order they appear in the layout.
+type LayoutIssue struct {
+	Kind       LayoutIssueKind `json:"kind"`
+	Breakpoint GridSizes       `json:"breakpoint"`
+	Widgets    []string        `json:"widgets"`
+	Message    string          `json:"message"`
+}
+
+// LayoutValidationError lists every widget conflict found in a template configuration.
+type LayoutValidationError struct {
+	Issues []LayoutIssue
+}
+
+func (e *LayoutValidationError) Error() string {
+	messages := make([]string, 0, len(e.Issues))
+	for _, issue := range e.Issues {
+		messages = append(messages, issue.Message)
+	}
+	return "invalid layout: " + strings.Join(messages, "; ")
+}
+
+// layoutIssues checks the widgets of a layout against each other and against the column count of the layout.
+func layoutIssues(variant GridSizes, items []WidgetItem) []LayoutIssue {
+	var issues []LayoutIssue
+	maxWidth, err := variant.GetMaxWidth()
+	if err != nil {
+		return issues
+	}
+
+	position := func(wi WidgetItem) (int, int) {
+		x, y := 0, 0
+		if wi.X != nil {
+			x = *wi.X
+		}
+		if wi.Y != nil {
+			y = *wi.Y
+		}
+		return x, y
+	}
+
+	for i, a := range items {
+		ax, ay := position(a)
+		if ax+a.Width > maxWidth {
+			issues = append(issues, LayoutIssue{
+				Kind:       LayoutIssueOverflow,
+				Breakpoint: variant,
+				Widgets:    []string{a.WidgetType},
+				Message:    fmt.Sprintf("widget[%d] %s in %s: x %d + width %d overflows the %d columns of the layout", i, a.WidgetType, variant, ax, a.Width, maxWidth),
+			})
+		}
+		for j := i + 1; j < len(items); j++ {
+			b := items[j]
+			if a.WidgetType == b.WidgetType {
+				issues = append(issues, LayoutIssue{
+					Kind:       LayoutIssueDuplicate,
+					Breakpoint: variant,
+					Widgets:    []string{a.WidgetType, b.WidgetType},
+					Message:    fmt.Sprintf("widget[%d] and widget[%d] in %s: widgetType %s is used more than once", i, j, variant, a.WidgetType),
+				})
+			}
+			bx, by := position(b)
+			if ax < bx+b.Width && bx < ax+a.Width && ay < by+b.Height && by < ay+a.Height {
+				issues = append(issues, LayoutIssue{
+					Kind:       LayoutIssueOverlap,
+					Breakpoint: variant,
+					Widgets:    []string{a.WidgetType, b.WidgetType},
+					Message:    fmt.Sprintf("widget[%d] %s and widget[%d] %s in %s: widgets overlap", i, a.WidgetType, j, b.WidgetType, variant),
+				})
+			}
+		}
+	}
+	return issues
+}
+
+// IsValid validates the template configuration.
+// Invalid widgets are reported one at a time, conflicts between widgets are collected
+// over all layouts and returned together as a *LayoutValidationError.
 func (tc *DashboardTemplateConfig) IsValid() error {
+	var issues []LayoutIssue
 	configs := reflect.ValueOf(*tc)
 	typeOfS := configs.Type()
 
@@ -117,8 +215,13 @@ func (tc *DashboardTemplateConfig) IsValid() error {
 				return err
 			}
 		}
+
+		issues = append(issues, layoutIssues(gridSize, items)...)
 	}
 
+	if len(issues) > 0 {
+		return &LayoutValidationError{Issues: issues}
+	}
 	return nil
 }
 
@@ -5,6 +5,7 @@ import (
 
 	"github.com/RedHatInsights/widget-layout-backend/api"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"gorm.io/datatypes"
 )
 
@@ -145,6 +146,54 @@ func TestDashboardTemplateConfigIsValid(t *testing.T) {
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "height must be at least 1")
 	})
+
+	t.Run("should accept adjacent widgets", func(t *testing.T) {
+		config := &api.DashboardTemplateConfig{
+			Sm: datatypes.NewJSONType([]api.WidgetItem{}),
+			Md: datatypes.NewJSONType([]api.WidgetItem{}),
+			Lg: datatypes.NewJSONType([]api.WidgetItem{
+				{Width: 2, Height: 2, WidgetType: "widget1", X: intPtr(0), Y: intPtr(0)},
+				{Width: 1, Height: 2, WidgetType: "widget2", X: intPtr(2), Y: intPtr(0)},
+				{Width: 3, Height: 1, WidgetType: "widget3", X: intPtr(0), Y: intPtr(2)},
+			}),
+			Xl: datatypes.NewJSONType([]api.WidgetItem{}),
+		}
+		assert.NoError(t, config.IsValid())
+	})
+
+	t.Run("should report every widget conflict", func(t *testing.T) {
+		config := &api.DashboardTemplateConfig{
+			Sm: datatypes.NewJSONType([]api.WidgetItem{}),
+			Md: datatypes.NewJSONType([]api.WidgetItem{
+				{Width: 2, Height: 2, WidgetType: "widget1", X: intPtr(1), Y: intPtr(0)}, // overflows the 2 columns
+			}),
+			Lg: datatypes.NewJSONType([]api.WidgetItem{
+				{Width: 2, Height: 2, WidgetType: "widget1", X: intPtr(0), Y: intPtr(0)},
+				{Width: 1, Height: 2, WidgetType: "widget2", X: intPtr(1), Y: intPtr(1)}, // overlaps widget1
+			}),
+			Xl: datatypes.NewJSONType([]api.WidgetItem{
+				{Width: 1, Height: 1, WidgetType: "widget1", X: intPtr(0), Y: intPtr(0)},
+				{Width: 1, Height: 1, WidgetType: "widget1", X: intPtr(1), Y: intPtr(0)}, // duplicate
+			}),
+		}
+		err := config.IsValid()
+		require.Error(t, err)
+
+		var layoutErr *api.LayoutValidationError
+		require.ErrorAs(t, err, &layoutErr)
+		require.Len(t, layoutErr.Issues, 3)
+
+		assert.Equal(t, api.LayoutIssueOverlap, layoutErr.Issues[0].Kind)
+		assert.Equal(t, api.Lg, layoutErr.Issues[0].Breakpoint)
+		assert.Equal(t, []string{"widget1", "widget2"}, layoutErr.Issues[0].Widgets)
+
+		assert.Equal(t, api.LayoutIssueOverflow, layoutErr.Issues[1].Kind)
+		assert.Equal(t, api.Md, layoutErr.Issues[1].Breakpoint)
+		assert.Equal(t, []string{"widget1"}, layoutErr.Issues[1].Widgets)
+
+		assert.Equal(t, api.LayoutIssueDuplicate, layoutErr.Issues[2].Kind)
+		assert.Equal(t, api.Xl, layoutErr.Issues[2].Breakpoint)
+	})
 }
 
 func TestWidgetItemIsValid(t *testing.T) {
@@ -368,3 +417,41 @@ func TestIsAuthorized(t *testing.T) {
 		assert.False(t, dt.IsAuthorized(""))
 	})
 }
+
+func TestIsReadableBy(t *testing.T) {
+	t.Run("should let the owner read private templates", func(t *testing.T) {
+		dt := api.DashboardTemplate{UserId: "user-abc", OrgId: "org-1", Visibility: api.VisibilityPrivate}
+		assert.True(t, dt.IsReadableBy("user-abc", "org-1"))
+		assert.False(t, dt.IsReadableBy("user-xyz", "org-1"))
+	})
+
+	t.Run("should let members of the organization read shared templates", func(t *testing.T) {
+		dt := api.DashboardTemplate{UserId: "user-abc", OrgId: "org-1", Visibility: api.VisibilityOrg}
+		assert.True(t, dt.IsReadableBy("user-xyz", "org-1"))
+		assert.False(t, dt.IsReadableBy("user-xyz", "org-2"))
+	})
+
+	t.Run("should not share templates without an organization", func(t *testing.T) {
+		dt := api.DashboardTemplate{UserId: "user-abc", Visibility: api.VisibilityOrg}
+		assert.False(t, dt.IsReadableBy("user-xyz", ""))
+	})
+}
+
+func TestIsEditableBy(t *testing.T) {
+	t.Run("should let the owner edit templates", func(t *testing.T) {
+		dt := api.DashboardTemplate{UserId: "user-abc", OrgId: "org-1", Visibility: api.VisibilityOrg}
+		assert.True(t, dt.IsEditableBy("user-abc", "org-1", false))
+	})
+
+	t.Run("should only let org admins edit shared templates of others", func(t *testing.T) {
+		dt := api.DashboardTemplate{UserId: "user-abc", OrgId: "org-1", Visibility: api.VisibilityOrg}
+		assert.True(t, dt.IsEditableBy("user-xyz", "org-1", true))
+		assert.False(t, dt.IsEditableBy("user-xyz", "org-1", false))
+		assert.False(t, dt.IsEditableBy("user-xyz", "org-2", true))
+	})
+
+	t.Run("should not let org admins edit private templates of others", func(t *testing.T) {
+		dt := api.DashboardTemplate{UserId: "user-abc", OrgId: "org-1", Visibility: api.VisibilityPrivate}
+		assert.False(t, dt.IsEditableBy("user-xyz", "org-1", true))
+	})
+}
@@ -43,6 +43,15 @@ func main() {
 	// migrate the base models
 	if err := tx.AutoMigrate(
 		&models.DashboardTemplate{},
+		&models.DashboardTemplateRevision{},
+		&models.BaseTemplateVersion{},
+		&models.DashboardTemplateShare{},
+		&models.OrgBaseTemplate{},
+		&models.OrgWidgetPolicy{},
+		&models.AuditEvent{},
+		&models.OutboxEvent{},
+		&models.Webhook{},
+		&models.WebhookDelivery{},
 	); err != nil {
 		logrus.Errorln("Failed to migrate models:", err.Error())
 		tx.Rollback()
@@ -3,6 +3,7 @@ package main
 //go:generate go run github.com/oapi-codegen/oapi-codegen/v2/cmd/oapi-codegen -config server.cfg.yaml spec/openapi.yaml
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/http"
@@ -14,9 +15,12 @@ import (
 	"github.com/RedHatInsights/widget-layout-backend/api"
 	"github.com/RedHatInsights/widget-layout-backend/pkg/config"
 	"github.com/RedHatInsights/widget-layout-backend/pkg/database"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/events"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/featureflags"
 	"github.com/RedHatInsights/widget-layout-backend/pkg/logger"
 	"github.com/RedHatInsights/widget-layout-backend/pkg/middlewares"
 	"github.com/RedHatInsights/widget-layout-backend/pkg/server"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/service"
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/getkin/kin-openapi/openapi3filter"
 	chi "github.com/go-chi/chi/v5"
@@ -74,10 +78,19 @@ func main() {
 		Options: openapi3filter.Options{},
 	})
 
+	registries, err := service.LoadRegistries(cfg)
+	if err != nil {
+		logrus.Fatalln("Failed to load base widget dashboard templates and widget mappings, shutting down the service", err)
+	}
+	registries.FlagProvider, err = featureflags.NewFlagProvider(cfg)
+	if err != nil {
+		logrus.Fatalln("Failed to create the feature flag provider, shutting down the service", err)
+	}
+
 	r := chi.NewRouter()
 	r.Use(
 		chiMiddleware.RequestLogger(logger.NewLogger(logrus.New())))
-	server := server.NewServer(r)
+	server := server.NewServer(r, registries)
 
 	r.Get("/healthz", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
@@ -92,7 +105,9 @@ func main() {
 	api.HandlerWithOptions(server, api.ChiServerOptions{
 		BaseURL:    apiPrefix,
 		BaseRouter: r,
+		// the middlewares are applied in reverse order, the identity is injected before the flags are
 		Middlewares: []api.MiddlewareFunc{
+			middlewares.InjectRequestFlags(registries.FlagProvider),
 			middlewares.InjectUserIdentity,
 		},
 	})
@@ -105,6 +120,23 @@ func main() {
 	filesDir := http.Dir(filepath.Join(workDir, "/spec"))
 	SpecServer(r, apiPrefix, filesDir)
 
+	service.StartTrashPurgeJob(context.Background(), cfg.TrashPurgeInterval, cfg.TrashRetention)
+
+	publisher, err := events.NewPublisher(cfg)
+	if err != nil {
+		logrus.Fatalln("Failed to create the publisher of dashboard template events", err)
+	}
+	defer publisher.Close()
+	service.StartOutboxRelay(context.Background(), publisher, cfg.OutboxRelayInterval)
+	service.StartWebhookDelivery(context.Background(), service.WebhookDeliveryOptions{
+		Client:      service.NewWebhookClient(cfg.WebhookAllowPrivateNetworks),
+		MaxAttempts: cfg.WebhookMaxAttempts,
+		RetryDelay:  cfg.WebhookRetryDelay,
+	}, cfg.WebhookDeliveryInterval)
+	if err := registries.WatchFiles(context.Background(), cfg.BaseWidgetDashboardFile, cfg.WidgetMappingFile); err != nil {
+		logrus.Fatalln("Failed to watch the base template and widget mapping files", err)
+	}
+
 	metricsRouter := chi.NewRouter()
 	metricsRouter.Handle("/metrics", promhttp.Handler())
 	go func() {
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -25,6 +26,19 @@ type DatabaseConfig struct {
 	ConnMaxLifetime time.Duration
 }
 
+// KafkaConfig describes the Kafka cluster change events of dashboard templates are published to.
+// Events are not published when no brokers are configured.
+type KafkaConfig struct {
+	Brokers []string
+	Topic   string
+	// CACert is the PEM encoded CA certificate of the brokers, the system trust store is used when it is empty
+	CACert           string
+	SecurityProtocol string
+	SASLMechanism    string
+	SASLUsername     string
+	SASLPassword     string
+}
+
 type WidgetLayoutConfig struct {
 	LogLevel                     string
 	WebPort                      int
@@ -33,6 +47,20 @@ type WidgetLayoutConfig struct {
 	TestMode                     bool
 	BaseWidgetDashboardTemplates string
 	WidgetMappingConfig          string
+	BaseWidgetDashboardFile      string
+	WidgetMappingFile            string
+	TemplateRevisionRetention    int
+	TrashRetention               time.Duration
+	TrashPurgeInterval           time.Duration
+	FeatureFlagsFile             string
+	UnleashURL                   string
+	UnleashToken                 string
+	KafkaConfig                  KafkaConfig
+	OutboxRelayInterval          time.Duration
+	WebhookDeliveryInterval      time.Duration
+	WebhookMaxAttempts           int
+	WebhookRetryDelay            time.Duration
+	WebhookAllowPrivateNetworks  bool
 }
 
 var config *WidgetLayoutConfig
@@ -40,6 +68,43 @@ var config *WidgetLayoutConfig
 // The location of certificates is dictated by Clowder
 const RdsCaLocation = "/app/rdsca.cert"
 
+// TemplateEventsTopic is the requested name of the topic change events of dashboard templates are published to
+const TemplateEventsTopic = "platform.widget-layout.template-events"
+
+func (c *WidgetLayoutConfig) getKafkaConfig(cfg *clowder.AppConfig) KafkaConfig {
+	kafkaConfig := KafkaConfig{Topic: TemplateEventsTopic}
+	if cfg.Kafka == nil || len(cfg.Kafka.Brokers) == 0 {
+		return kafkaConfig
+	}
+	kafkaConfig.Brokers = clowder.KafkaServers
+	if topic, ok := clowder.KafkaTopics[TemplateEventsTopic]; ok {
+		kafkaConfig.Topic = topic.Name
+	}
+	// all brokers of a cluster share the same authentication
+	broker := cfg.Kafka.Brokers[0]
+	if broker.Cacert != nil {
+		kafkaConfig.CACert = *broker.Cacert
+	}
+	if broker.SecurityProtocol != nil {
+		kafkaConfig.SecurityProtocol = *broker.SecurityProtocol
+	}
+	if broker.Sasl != nil {
+		if broker.Sasl.SaslMechanism != nil {
+			kafkaConfig.SASLMechanism = *broker.Sasl.SaslMechanism
+		}
+		if broker.Sasl.Username != nil {
+			kafkaConfig.SASLUsername = *broker.Sasl.Username
+		}
+		if broker.Sasl.Password != nil {
+			kafkaConfig.SASLPassword = *broker.Sasl.Password
+		}
+		if kafkaConfig.SecurityProtocol == "" && broker.Sasl.SecurityProtocol != nil {
+			kafkaConfig.SecurityProtocol = *broker.Sasl.SecurityProtocol
+		}
+	}
+	return kafkaConfig
+}
+
 func (c *WidgetLayoutConfig) getCert(cfg *clowder.AppConfig) string {
 	cert := ""
 	if cfg.Database.SslMode != "verify-full" {
@@ -91,6 +156,7 @@ func init() {
 		if config.DatabaseConfig.DBSSLRootCert != "" {
 			config.DatabaseConfig.DBDNS = fmt.Sprintf("%s sslrootcert=%s", config.DatabaseConfig.DBDNS, config.DatabaseConfig.DBSSLRootCert)
 		}
+		config.KafkaConfig = config.getKafkaConfig(cfg)
 	} else {
 		config.WebPort = 8000
 		config.MetricsPort = 9000
@@ -105,6 +171,15 @@ func init() {
 		// Disable SSL mode for local development
 		config.DatabaseConfig.DBSSLMode = "disable"
 		config.DatabaseConfig.DBDNS = fmt.Sprintf("host=%v user=%v password=%v dbname=%v port=%v sslmode=%v", config.DatabaseConfig.DBHost, config.DatabaseConfig.DBUser, config.DatabaseConfig.DBPassword, config.DatabaseConfig.DBName, config.DatabaseConfig.DBPort, config.DatabaseConfig.DBSSLMode)
+
+		// A local Kafka without authentication, events are not published when KAFKA_BROKERS is unset
+		config.KafkaConfig.Topic = os.Getenv("KAFKA_TOPIC")
+		if config.KafkaConfig.Topic == "" {
+			config.KafkaConfig.Topic = TemplateEventsTopic
+		}
+		if brokers := os.Getenv("KAFKA_BROKERS"); brokers != "" {
+			config.KafkaConfig.Brokers = strings.Split(brokers, ",")
+		}
 	}
 
 	maxIdleConns, _ := strconv.Atoi(os.Getenv("DB_MAX_IDLE_CONNS"))
@@ -127,6 +202,60 @@ func init() {
 
 	config.BaseWidgetDashboardTemplates = os.Getenv("BASE_LAYOUTS")
 	config.WidgetMappingConfig = os.Getenv("WIDGET_MAPPING")
+	// Mounted ConfigMap files take precedence over the variables above and are reloaded when they change
+	config.BaseWidgetDashboardFile = os.Getenv("BASE_LAYOUTS_FILE")
+	config.WidgetMappingFile = os.Getenv("WIDGET_MAPPING_FILE")
+
+	// Number of revisions kept per dashboard template, older revisions are pruned on write
+	revisionRetention, err := strconv.Atoi(os.Getenv("TEMPLATE_REVISION_RETENTION"))
+	if err != nil || revisionRetention <= 0 {
+		revisionRetention = 20
+	}
+	config.TemplateRevisionRetention = revisionRetention
+
+	// Deleted templates stay in the trash for this long before the purge job removes them permanently
+	trashRetentionHours, _ := strconv.Atoi(os.Getenv("TRASH_RETENTION_HOURS"))
+	if trashRetentionHours <= 0 {
+		trashRetentionHours = 720
+	}
+	config.TrashRetention = time.Duration(trashRetentionHours) * time.Hour
+
+	trashPurgeIntervalMinutes, _ := strconv.Atoi(os.Getenv("TRASH_PURGE_INTERVAL_MINUTES"))
+	if trashPurgeIntervalMinutes <= 0 {
+		trashPurgeIntervalMinutes = 60
+	}
+	config.TrashPurgeInterval = time.Duration(trashPurgeIntervalMinutes) * time.Minute
+
+	// Feature flags of widgets are evaluated with a static file for local development or with Unleash
+	config.FeatureFlagsFile = os.Getenv("FEATURE_FLAGS_FILE")
+	config.UnleashURL = os.Getenv("UNLEASH_URL")
+	config.UnleashToken = os.Getenv("UNLEASH_TOKEN")
+
+	// Change events are written to the outbox with the change and published by the relay at this interval
+	outboxRelayIntervalSeconds, _ := strconv.Atoi(os.Getenv("OUTBOX_RELAY_INTERVAL_SECONDS"))
+	if outboxRelayIntervalSeconds <= 0 {
+		outboxRelayIntervalSeconds = 5
+	}
+	config.OutboxRelayInterval = time.Duration(outboxRelayIntervalSeconds) * time.Second
+
+	// Failed webhook deliveries are retried with an exponential backoff starting at the retry delay
+	webhookDeliveryIntervalSeconds, _ := strconv.Atoi(os.Getenv("WEBHOOK_DELIVERY_INTERVAL_SECONDS"))
+	if webhookDeliveryIntervalSeconds <= 0 {
+		webhookDeliveryIntervalSeconds = 5
+	}
+	config.WebhookDeliveryInterval = time.Duration(webhookDeliveryIntervalSeconds) * time.Second
+	webhookMaxAttempts, _ := strconv.Atoi(os.Getenv("WEBHOOK_MAX_ATTEMPTS"))
+	if webhookMaxAttempts <= 0 {
+		webhookMaxAttempts = 8
+	}
+	config.WebhookMaxAttempts = webhookMaxAttempts
+	webhookRetryDelaySeconds, _ := strconv.Atoi(os.Getenv("WEBHOOK_RETRY_DELAY_SECONDS"))
+	if webhookRetryDelaySeconds <= 0 {
+		webhookRetryDelaySeconds = 30
+	}
+	config.WebhookRetryDelay = time.Duration(webhookRetryDelaySeconds) * time.Second
+	// Only meant for local development, webhooks can otherwise not reach services of the private network
+	config.WebhookAllowPrivateNetworks = os.Getenv("WEBHOOK_ALLOW_PRIVATE_NETWORKS") == "true"
 }
 
 func GetConfig() *WidgetLayoutConfig {
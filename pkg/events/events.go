@@ -0,0 +1,84 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/RedHatInsights/widget-layout-backend/pkg/config"
+	"github.com/google/uuid"
+)
+
+const (
+	// Source identifies this service as the producer of the events
+	Source = "urn:redhat:source:console:app:widget-layout"
+
+	TypeTemplateCreated        = "dashboard.template.created"
+	TypeTemplateUpdated        = "dashboard.template.updated"
+	TypeTemplateDeleted        = "dashboard.template.deleted"
+	TypeTemplateDefaultChanged = "dashboard.template.default-changed"
+)
+
+// CloudEvent is an event in the structured JSON format of the CloudEvents 1.0 specification.
+type CloudEvent struct {
+	SpecVersion     string    `json:"specversion"`
+	ID              string    `json:"id"`
+	Source          string    `json:"source"`
+	Type            string    `json:"type"`
+	Subject         string    `json:"subject,omitempty"`
+	Time            time.Time `json:"time"`
+	DataContentType string    `json:"datacontenttype"`
+	// RedHatOrgID is the organization the event belongs to, an extension attribute shared by console services
+	RedHatOrgID string          `json:"redhatorgid,omitempty"`
+	Data        json.RawMessage `json:"data"`
+}
+
+// TemplateEventData is the data of the change events of dashboard templates. The widgets are the ones added to,
+// removed from or moved within any layout of the template by the change.
+type TemplateEventData struct {
+	DashboardTemplateID uint     `json:"dashboardTemplateId"`
+	DashboardType       string   `json:"dashboardType"`
+	UserID              string   `json:"userId"`
+	OrgID               string   `json:"orgId,omitempty"`
+	ActorID             string   `json:"actorId"`
+	Action              string   `json:"action"`
+	Default             bool     `json:"default"`
+	AddedWidgets        []string `json:"addedWidgets,omitempty"`
+	RemovedWidgets      []string `json:"removedWidgets,omitempty"`
+	ChangedWidgets      []string `json:"changedWidgets,omitempty"`
+}
+
+// NewCloudEvent creates an event of the type with a unique ID, the data is encoded as JSON.
+func NewCloudEvent(eventType, subject, orgID string, data any) (CloudEvent, error) {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return CloudEvent{}, err
+	}
+	return CloudEvent{
+		SpecVersion:     "1.0",
+		ID:              uuid.NewString(),
+		Source:          Source,
+		Type:            eventType,
+		Subject:         subject,
+		Time:            time.Now().UTC(),
+		DataContentType: "application/json",
+		RedHatOrgID:     orgID,
+		Data:            encoded,
+	}, nil
+}
+
+// Publisher delivers events to their consumers.
+type Publisher interface {
+	// Publish delivers the events in order, an error means some of them may not have been delivered.
+	Publish(ctx context.Context, events ...CloudEvent) error
+	Close() error
+}
+
+// NewPublisher creates the publisher configured by the environment.
+// Without Kafka brokers events are discarded.
+func NewPublisher(cfg *config.WidgetLayoutConfig) (Publisher, error) {
+	if len(cfg.KafkaConfig.Brokers) == 0 {
+		return NoopPublisher{}, nil
+	}
+	return NewKafkaPublisher(cfg.KafkaConfig)
+}
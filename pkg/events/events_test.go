@@ -0,0 +1,93 @@
+package events_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/RedHatInsights/widget-layout-backend/pkg/config"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewCloudEvent(t *testing.T) {
+	event, err := events.NewCloudEvent(events.TypeTemplateCreated, "1", "org-123", events.TemplateEventData{DashboardTemplateID: 1})
+	require.NoError(t, err)
+	assert.Equal(t, "1.0", event.SpecVersion)
+	assert.NotEmpty(t, event.ID)
+	assert.Equal(t, events.Source, event.Source)
+	assert.Equal(t, "org-123", event.RedHatOrgID)
+	assert.JSONEq(t, `{"dashboardTemplateId":1,"dashboardType":"","userId":"","actorId":"","action":"","default":false}`, string(event.Data))
+
+	other, err := events.NewCloudEvent(events.TypeTemplateCreated, "1", "org-123", nil)
+	require.NoError(t, err)
+	assert.NotEqual(t, event.ID, other.ID, "Every event should have a unique ID")
+}
+
+func TestKafkaMessage(t *testing.T) {
+	event, err := events.NewCloudEvent(events.TypeTemplateUpdated, "42", "", map[string]string{"key": "value"})
+	require.NoError(t, err)
+
+	message, err := events.KafkaMessage(event)
+	require.NoError(t, err)
+	assert.Equal(t, "42", string(message.Key), "The subject should be the key so events of a template stay ordered")
+	require.Len(t, message.Headers, 1)
+	assert.Equal(t, "content-type", message.Headers[0].Key)
+	assert.Contains(t, string(message.Headers[0].Value), "application/cloudevents+json")
+
+	var decoded map[string]any
+	require.NoError(t, json.Unmarshal(message.Value, &decoded))
+	assert.Equal(t, "dashboard.template.updated", decoded["type"])
+	assert.Equal(t, map[string]any{"key": "value"}, decoded["data"])
+}
+
+func TestNewPublisher(t *testing.T) {
+	t.Run("should discard events without brokers", func(t *testing.T) {
+		publisher, err := events.NewPublisher(&config.WidgetLayoutConfig{})
+		require.NoError(t, err)
+		assert.IsType(t, events.NoopPublisher{}, publisher)
+	})
+
+	t.Run("should publish to Kafka when brokers are configured", func(t *testing.T) {
+		publisher, err := events.NewPublisher(&config.WidgetLayoutConfig{KafkaConfig: config.KafkaConfig{
+			Brokers:       []string{"localhost:9092"},
+			Topic:         "topic",
+			SASLMechanism: "SCRAM-SHA-512",
+			SASLUsername:  "user",
+			SASLPassword:  "password",
+		}})
+		require.NoError(t, err)
+		assert.IsType(t, &events.KafkaPublisher{}, publisher)
+		assert.NoError(t, publisher.Close())
+	})
+
+	t.Run("should reject invalid Kafka configurations", func(t *testing.T) {
+		_, err := events.NewPublisher(&config.WidgetLayoutConfig{KafkaConfig: config.KafkaConfig{
+			Brokers: []string{"localhost:9092"},
+			CACert:  "not a certificate",
+		}})
+		assert.Error(t, err)
+
+		_, err = events.NewPublisher(&config.WidgetLayoutConfig{KafkaConfig: config.KafkaConfig{
+			Brokers:       []string{"localhost:9092"},
+			SASLMechanism: "GSSAPI",
+			SASLUsername:  "user",
+		}})
+		assert.Error(t, err)
+	})
+}
+
+func TestMemoryPublisher(t *testing.T) {
+	publisher := &events.MemoryPublisher{}
+	event, err := events.NewCloudEvent(events.TypeTemplateDeleted, "1", "", nil)
+	require.NoError(t, err)
+
+	require.NoError(t, publisher.Publish(context.Background(), event))
+	assert.Equal(t, []events.CloudEvent{event}, publisher.Events())
+
+	publisher.Err = errors.New("unavailable")
+	assert.Error(t, publisher.Publish(context.Background(), event))
+	assert.Len(t, publisher.Events(), 1)
+}
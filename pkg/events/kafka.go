@@ -0,0 +1,101 @@
+package events
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/RedHatInsights/widget-layout-backend/pkg/config"
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl"
+	"github.com/segmentio/kafka-go/sasl/plain"
+	"github.com/segmentio/kafka-go/sasl/scram"
+)
+
+const kafkaWriteTimeout = 10 * time.Second
+
+// KafkaPublisher publishes events in the structured content mode of the CloudEvents Kafka binding.
+// The subject is used as the message key, so the events of a template keep their order.
+type KafkaPublisher struct {
+	writer *kafka.Writer
+}
+
+// NewKafkaPublisher creates a publisher for the topic of the configuration.
+func NewKafkaPublisher(cfg config.KafkaConfig) (*KafkaPublisher, error) {
+	transport := &kafka.Transport{}
+	if strings.HasSuffix(cfg.SecurityProtocol, "SSL") || cfg.CACert != "" {
+		tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+		if cfg.CACert != "" {
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM([]byte(cfg.CACert)) {
+				return nil, errors.New("failed to parse the CA certificate of the Kafka brokers")
+			}
+			tlsConfig.RootCAs = pool
+		}
+		transport.TLS = tlsConfig
+	}
+	if cfg.SASLUsername != "" {
+		mechanism, err := saslMechanism(cfg)
+		if err != nil {
+			return nil, err
+		}
+		transport.SASL = mechanism
+	}
+	return &KafkaPublisher{
+		writer: &kafka.Writer{
+			Addr:         kafka.TCP(cfg.Brokers...),
+			Topic:        cfg.Topic,
+			Balancer:     &kafka.Hash{},
+			RequiredAcks: kafka.RequireAll,
+			WriteTimeout: kafkaWriteTimeout,
+			Transport:    transport,
+		},
+	}, nil
+}
+
+func saslMechanism(cfg config.KafkaConfig) (sasl.Mechanism, error) {
+	switch strings.ToUpper(cfg.SASLMechanism) {
+	case "", "PLAIN":
+		return plain.Mechanism{Username: cfg.SASLUsername, Password: cfg.SASLPassword}, nil
+	case "SCRAM-SHA-512":
+		return scram.Mechanism(scram.SHA512, cfg.SASLUsername, cfg.SASLPassword)
+	case "SCRAM-SHA-256":
+		return scram.Mechanism(scram.SHA256, cfg.SASLUsername, cfg.SASLPassword)
+	default:
+		return nil, fmt.Errorf("unsupported Kafka SASL mechanism %s", cfg.SASLMechanism)
+	}
+}
+
+// KafkaMessage encodes the event as a Kafka message.
+func KafkaMessage(event CloudEvent) (kafka.Message, error) {
+	value, err := json.Marshal(event)
+	if err != nil {
+		return kafka.Message{}, err
+	}
+	return kafka.Message{
+		Key:     []byte(event.Subject),
+		Value:   value,
+		Headers: []kafka.Header{{Key: "content-type", Value: []byte("application/cloudevents+json; charset=UTF-8")}},
+	}, nil
+}
+
+func (kp *KafkaPublisher) Publish(ctx context.Context, events ...CloudEvent) error {
+	messages := make([]kafka.Message, 0, len(events))
+	for _, event := range events {
+		message, err := KafkaMessage(event)
+		if err != nil {
+			return err
+		}
+		messages = append(messages, message)
+	}
+	return kp.writer.WriteMessages(ctx, messages...)
+}
+
+func (kp *KafkaPublisher) Close() error {
+	return kp.writer.Close()
+}
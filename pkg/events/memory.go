@@ -0,0 +1,46 @@
+package events
+
+import (
+	"context"
+	"sync"
+)
+
+// NoopPublisher discards all events, it is used when no Kafka brokers are configured.
+type NoopPublisher struct{}
+
+func (NoopPublisher) Publish(ctx context.Context, events ...CloudEvent) error {
+	return nil
+}
+
+func (NoopPublisher) Close() error {
+	return nil
+}
+
+// MemoryPublisher keeps the published events in memory, for tests. Publish fails with Err when it is set.
+type MemoryPublisher struct {
+	Err error
+
+	mu     sync.Mutex
+	events []CloudEvent
+}
+
+func (mp *MemoryPublisher) Publish(ctx context.Context, events ...CloudEvent) error {
+	mp.mu.Lock()
+	defer mp.mu.Unlock()
+	if mp.Err != nil {
+		return mp.Err
+	}
+	mp.events = append(mp.events, events...)
+	return nil
+}
+
+func (mp *MemoryPublisher) Close() error {
+	return nil
+}
+
+// Events returns the events published so far.
+func (mp *MemoryPublisher) Events() []CloudEvent {
+	mp.mu.Lock()
+	defer mp.mu.Unlock()
+	return append([]CloudEvent(nil), mp.events...)
+}
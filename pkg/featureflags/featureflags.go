@@ -0,0 +1,81 @@
+package featureflags
+
+import (
+	"context"
+	"sync"
+
+	"github.com/RedHatInsights/widget-layout-backend/pkg/config"
+	"github.com/redhatinsights/platform-go-middlewares/v2/identity"
+	"github.com/sirupsen/logrus"
+)
+
+// FlagProvider evaluates feature flags for the caller of a request.
+type FlagProvider interface {
+	// Flags returns the state of the flags for the identity. Flags missing from the result are disabled.
+	Flags(ctx context.Context, id identity.XRHID) (map[string]bool, error)
+}
+
+// NewFlagProvider creates the provider configured by the environment.
+// A flag file takes precedence over Unleash, without either no provider is returned and flags are not evaluated.
+func NewFlagProvider(cfg *config.WidgetLayoutConfig) (FlagProvider, error) {
+	switch {
+	case cfg.FeatureFlagsFile != "":
+		provider, err := NewStaticProvider(cfg.FeatureFlagsFile)
+		if err != nil {
+			return nil, err
+		}
+		return provider, nil
+	case cfg.UnleashURL != "":
+		return NewUnleashProvider(cfg.UnleashURL, cfg.UnleashToken, nil), nil
+	default:
+		return nil, nil
+	}
+}
+
+// RequestFlags caches the flags of a single request, the provider is called at most once.
+// When there is no provider or it fails, every flag is treated as enabled, the frontend still
+// evaluates the flags of the widgets it renders.
+type RequestFlags struct {
+	ctx      context.Context
+	provider FlagProvider
+	id       identity.XRHID
+	once     sync.Once
+	flags    map[string]bool
+}
+
+func NewRequestFlags(ctx context.Context, provider FlagProvider, id identity.XRHID) *RequestFlags {
+	return &RequestFlags{ctx: ctx, provider: provider, id: id}
+}
+
+// IsEnabled reports whether the flag is enabled for the caller of the request.
+func (rf *RequestFlags) IsEnabled(flag string) bool {
+	if rf.provider == nil {
+		return true
+	}
+	rf.once.Do(func() {
+		flags, err := rf.provider.Flags(rf.ctx, rf.id)
+		if err != nil {
+			logrus.Warnf("Failed to evaluate feature flags, treating all flags as enabled: %v", err)
+			return
+		}
+		rf.flags = flags
+	})
+	if rf.flags == nil {
+		return true
+	}
+	return rf.flags[flag]
+}
+
+type requestFlagsContextKey struct{}
+
+// WithRequestFlags returns a context carrying the flags of the request, the handlers and the services
+// of a request read them from there so the provider is called once and every decision agrees.
+func WithRequestFlags(ctx context.Context, flags *RequestFlags) context.Context {
+	return context.WithValue(ctx, requestFlagsContextKey{}, flags)
+}
+
+// RequestFlagsFromContext returns the flags stored by WithRequestFlags and whether they are present.
+func RequestFlagsFromContext(ctx context.Context) (*RequestFlags, bool) {
+	flags, ok := ctx.Value(requestFlagsContextKey{}).(*RequestFlags)
+	return flags, ok && flags != nil
+}
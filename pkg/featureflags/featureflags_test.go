@@ -0,0 +1,144 @@
+package featureflags_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/RedHatInsights/widget-layout-backend/pkg/config"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/featureflags"
+	"github.com/redhatinsights/platform-go-middlewares/v2/identity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type countingProvider struct {
+	calls int
+	flags map[string]bool
+	err   error
+}
+
+func (cp *countingProvider) Flags(context.Context, identity.XRHID) (map[string]bool, error) {
+	cp.calls++
+	return cp.flags, cp.err
+}
+
+var testIdentity = identity.XRHID{Identity: identity.Identity{
+	OrgID:         "12345",
+	AccountNumber: "67890",
+	User:          &identity.User{UserID: "user-1"},
+}}
+
+func TestRequestFlags(t *testing.T) {
+	t.Run("should call the provider once per request", func(t *testing.T) {
+		provider := &countingProvider{flags: map[string]bool{"enabled-flag": true, "disabled-flag": false}}
+		flags := featureflags.NewRequestFlags(context.Background(), provider, testIdentity)
+
+		assert.True(t, flags.IsEnabled("enabled-flag"))
+		assert.False(t, flags.IsEnabled("disabled-flag"))
+		assert.False(t, flags.IsEnabled("unknown-flag"), "Flags missing from the provider should be disabled")
+		assert.Equal(t, 1, provider.calls)
+	})
+
+	t.Run("should enable all flags when the provider fails", func(t *testing.T) {
+		provider := &countingProvider{err: errors.New("unavailable")}
+		flags := featureflags.NewRequestFlags(context.Background(), provider, testIdentity)
+
+		assert.True(t, flags.IsEnabled("any-flag"))
+		assert.True(t, flags.IsEnabled("other-flag"))
+		assert.Equal(t, 1, provider.calls)
+	})
+
+	t.Run("should enable all flags without a provider", func(t *testing.T) {
+		flags := featureflags.NewRequestFlags(context.Background(), nil, testIdentity)
+		assert.True(t, flags.IsEnabled("any-flag"))
+	})
+}
+
+func TestStaticProvider(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "flags.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"enabled-flag": true, "disabled-flag": false}`), 0o600))
+
+	provider, err := featureflags.NewStaticProvider(path)
+	require.NoError(t, err)
+	flags, err := provider.Flags(context.Background(), testIdentity)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{"enabled-flag": true, "disabled-flag": false}, flags)
+
+	t.Run("should fail on an invalid file", func(t *testing.T) {
+		invalid := filepath.Join(t.TempDir(), "invalid.json")
+		require.NoError(t, os.WriteFile(invalid, []byte(`["enabled-flag"]`), 0o600))
+		_, err := featureflags.NewStaticProvider(invalid)
+		assert.Error(t, err)
+
+		_, err = featureflags.NewStaticProvider(filepath.Join(t.TempDir(), "missing.json"))
+		assert.Error(t, err)
+	})
+}
+
+func TestUnleashProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "frontend-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		assert.Equal(t, "/api/frontend", r.URL.Path)
+		assert.Equal(t, "user-1", r.URL.Query().Get("userId"))
+		assert.Equal(t, "12345", r.URL.Query().Get("properties[orgId]"))
+		assert.Equal(t, "67890", r.URL.Query().Get("properties[accountNumber]"))
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"toggles": []map[string]interface{}{
+				{"name": "enabled-flag", "enabled": true},
+			},
+		})
+	}))
+	defer server.Close()
+
+	t.Run("should return the flags evaluated by unleash", func(t *testing.T) {
+		provider := featureflags.NewUnleashProvider(server.URL+"/", "frontend-token", nil)
+		flags, err := provider.Flags(context.Background(), testIdentity)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]bool{"enabled-flag": true}, flags)
+	})
+
+	t.Run("should fail on an unexpected status", func(t *testing.T) {
+		provider := featureflags.NewUnleashProvider(server.URL, "wrong-token", nil)
+		_, err := provider.Flags(context.Background(), testIdentity)
+		assert.Error(t, err)
+	})
+}
+
+func TestNewFlagProvider(t *testing.T) {
+	provider, err := featureflags.NewFlagProvider(&config.WidgetLayoutConfig{})
+	require.NoError(t, err)
+	assert.Nil(t, provider, "No provider should be created without configuration")
+
+	provider, err = featureflags.NewFlagProvider(&config.WidgetLayoutConfig{UnleashURL: "http://unleash"})
+	require.NoError(t, err)
+	assert.IsType(t, &featureflags.UnleashProvider{}, provider)
+
+	_, err = featureflags.NewFlagProvider(&config.WidgetLayoutConfig{FeatureFlagsFile: filepath.Join(t.TempDir(), "missing.json"), UnleashURL: "http://unleash"})
+	assert.Error(t, err, "The flag file should take precedence over unleash")
+}
+
+func TestRequestFlagsContext(t *testing.T) {
+	t.Run("should return the flags stored in the context", func(t *testing.T) {
+		provider := &countingProvider{flags: map[string]bool{"enabled-flag": true}}
+		flags := featureflags.NewRequestFlags(context.Background(), provider, testIdentity)
+		ctx := featureflags.WithRequestFlags(context.Background(), flags)
+
+		stored, ok := featureflags.RequestFlagsFromContext(ctx)
+		require.True(t, ok)
+		assert.Same(t, flags, stored)
+	})
+
+	t.Run("should report missing flags", func(t *testing.T) {
+		_, ok := featureflags.RequestFlagsFromContext(context.Background())
+		assert.False(t, ok)
+	})
+}
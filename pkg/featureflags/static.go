@@ -0,0 +1,33 @@
+package featureflags
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+
+	"github.com/redhatinsights/platform-go-middlewares/v2/identity"
+)
+
+// StaticProvider serves flags from a JSON file mapping flag names to their state, e.g. {"my-flag": true}.
+// It is meant for local development and testing, every identity gets the same flags.
+type StaticProvider struct {
+	flags map[string]bool
+}
+
+func NewStaticProvider(path string) (*StaticProvider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read feature flags file: %w", err)
+	}
+	flags := map[string]bool{}
+	if err := json.Unmarshal(data, &flags); err != nil {
+		return nil, fmt.Errorf("failed to parse feature flags file %s: %w", path, err)
+	}
+	return &StaticProvider{flags: flags}, nil
+}
+
+func (sp *StaticProvider) Flags(context.Context, identity.XRHID) (map[string]bool, error) {
+	return maps.Clone(sp.flags), nil
+}
@@ -0,0 +1,82 @@
+package featureflags
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/redhatinsights/platform-go-middlewares/v2/identity"
+)
+
+const unleashTimeout = 2 * time.Second
+
+// UnleashProvider evaluates flags with the frontend API of Unleash or Unleash Edge.
+// The API evaluates all flags for the context of the caller and only returns the enabled ones.
+type UnleashProvider struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+type unleashToggle struct {
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+}
+
+type unleashResponse struct {
+	Toggles []unleashToggle `json:"toggles"`
+}
+
+// NewUnleashProvider creates a provider for the Unleash instance at baseURL using a frontend API token.
+// A default client with a short timeout is used when client is nil.
+func NewUnleashProvider(baseURL, token string, client *http.Client) *UnleashProvider {
+	if client == nil {
+		client = &http.Client{Timeout: unleashTimeout}
+	}
+	return &UnleashProvider{url: strings.TrimSuffix(baseURL, "/"), token: token, client: client}
+}
+
+func (up *UnleashProvider) Flags(ctx context.Context, id identity.XRHID) (map[string]bool, error) {
+	query := url.Values{}
+	if id.Identity.User != nil && id.Identity.User.UserID != "" {
+		query.Set("userId", id.Identity.User.UserID)
+	}
+	if id.Identity.OrgID != "" {
+		query.Set("properties[orgId]", id.Identity.OrgID)
+	}
+	if id.Identity.AccountNumber != "" {
+		query.Set("properties[accountNumber]", id.Identity.AccountNumber)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, up.url+"/api/frontend?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if up.token != "" {
+		req.Header.Set("Authorization", up.token)
+	}
+
+	resp, err := up.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to request feature flags from unleash: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d from unleash", resp.StatusCode)
+	}
+
+	var body unleashResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to decode feature flags from unleash: %w", err)
+	}
+	flags := make(map[string]bool, len(body.Toggles))
+	for _, toggle := range body.Toggles {
+		flags[toggle.Name] = toggle.Enabled
+	}
+	return flags, nil
+}
@@ -0,0 +1,152 @@
+package layout
+
+import (
+	"sort"
+
+	"github.com/RedHatInsights/widget-layout-backend/api"
+	"gorm.io/datatypes"
+)
+
+// CompactConfig corrects the bounds of every widget and vertically compacts all layouts of the configuration.
+func CompactConfig(tc api.DashboardTemplateConfig) api.DashboardTemplateConfig {
+	return api.DashboardTemplateConfig{
+		Sm: compactBreakpoint(tc.Sm, api.Sm),
+		Md: compactBreakpoint(tc.Md, api.Md),
+		Lg: compactBreakpoint(tc.Lg, api.Lg),
+		Xl: compactBreakpoint(tc.Xl, api.Xl),
+	}
+}
+
+func compactBreakpoint(items datatypes.JSONType[[]api.WidgetItem], gs api.GridSizes) datatypes.JSONType[[]api.WidgetItem] {
+	// a missing layout is kept as is, so validation can still reject it
+	if items.Data() == nil {
+		return items
+	}
+	cols, _ := gs.GetMaxWidth()
+	return datatypes.NewJSONType(Compact(CorrectBounds(items.Data(), cols), cols))
+}
+
+type rect struct {
+	x, y, w, h int
+}
+
+func toRect(wi api.WidgetItem) rect {
+	r := rect{w: wi.Width, h: wi.Height}
+	if wi.X != nil {
+		r.x = *wi.X
+	}
+	if wi.Y != nil {
+		r.y = *wi.Y
+	}
+	return r
+}
+
+func (r rect) collides(o rect) bool {
+	return r.x < o.x+o.w && o.x < r.x+r.w && r.y < o.y+o.h && o.y < r.y+r.h
+}
+
+func setPosition(wi *api.WidgetItem, r rect) {
+	x, y := r.x, r.y
+	wi.X, wi.Y = &x, &y
+	wi.Width = r.w
+}
+
+// CorrectBounds moves widgets overflowing the right edge back into the grid, widgets wider than
+// the grid are shrunk to its width. Static widgets overlapping each other are pushed down.
+// This mirrors correctBounds of react-grid-layout.
+func CorrectBounds(items []api.WidgetItem, cols int) []api.WidgetItem {
+	out := make([]api.WidgetItem, len(items))
+	var statics []rect
+	for i, wi := range items {
+		r := toRect(wi)
+		if r.x+r.w > cols {
+			r.x = cols - r.w
+		}
+		if r.x < 0 {
+			r.x = 0
+			r.w = cols
+		}
+		if r.y < 0 {
+			r.y = 0
+		}
+		if wi.Static {
+			for firstCollision(statics, r) != nil {
+				r.y++
+			}
+			statics = append(statics, r)
+		}
+		out[i] = wi
+		setPosition(&out[i], r)
+	}
+	return out
+}
+
+// Compact moves every widget as far up as possible without overlapping another widget, processing
+// the widgets from top to bottom and left to right. Static widgets are never moved. The order of the
+// widgets is preserved. This mirrors the vertical compaction of react-grid-layout.
+func Compact(items []api.WidgetItem, cols int) []api.WidgetItem {
+	out := make([]api.WidgetItem, len(items))
+	rects := make([]rect, len(items))
+	var compareWith []rect
+	for i, wi := range items {
+		rects[i] = toRect(wi)
+		if wi.Static {
+			compareWith = append(compareWith, rects[i])
+		}
+	}
+
+	order := make([]int, len(items))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		ra, rb := rects[order[a]], rects[order[b]]
+		if ra.y != rb.y {
+			return ra.y < rb.y
+		}
+		return ra.x < rb.x
+	})
+
+	for _, i := range order {
+		r := rects[i]
+		if !items[i].Static {
+			r = compactItem(compareWith, r)
+			compareWith = append(compareWith, r)
+		}
+		out[i] = items[i]
+		setPosition(&out[i], r)
+	}
+	return out
+}
+
+func compactItem(compareWith []rect, r rect) rect {
+	if b := bottom(compareWith); r.y > b {
+		r.y = b
+	}
+	for r.y > 0 && firstCollision(compareWith, rect{r.x, r.y - 1, r.w, r.h}) == nil {
+		r.y--
+	}
+	for c := firstCollision(compareWith, r); c != nil; c = firstCollision(compareWith, r) {
+		r.y = c.y + c.h
+	}
+	return r
+}
+
+func firstCollision(rects []rect, r rect) *rect {
+	for i := range rects {
+		if rects[i].collides(r) {
+			return &rects[i]
+		}
+	}
+	return nil
+}
+
+func bottom(rects []rect) int {
+	maxY := 0
+	for _, r := range rects {
+		if r.y+r.h > maxY {
+			maxY = r.y + r.h
+		}
+	}
+	return maxY
+}
@@ -0,0 +1,112 @@
+package layout_test
+
+import (
+	"testing"
+
+	"github.com/RedHatInsights/widget-layout-backend/api"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/layout"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/test_util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+)
+
+func widget(widgetType string, x, y, w, h int) api.WidgetItem {
+	return api.WidgetItem{WidgetType: widgetType, X: test_util.IntPTR(x), Y: test_util.IntPTR(y), Width: w, Height: h}
+}
+
+func positions(items []api.WidgetItem) map[string][2]int {
+	result := make(map[string][2]int, len(items))
+	for _, wi := range items {
+		result[wi.WidgetType] = [2]int{*wi.X, *wi.Y}
+	}
+	return result
+}
+
+func TestCompact(t *testing.T) {
+	t.Run("should move floating widgets up", func(t *testing.T) {
+		items := []api.WidgetItem{
+			widget("a", 0, 5, 1, 2),
+			widget("b", 1, 10, 2, 3),
+		}
+		compacted := layout.Compact(items, 3)
+		assert.Equal(t, map[string][2]int{"a": {0, 0}, "b": {1, 0}}, positions(compacted))
+	})
+
+	t.Run("should stack widgets in the same column and close gaps", func(t *testing.T) {
+		items := []api.WidgetItem{
+			widget("a", 0, 0, 1, 2),
+			widget("b", 0, 6, 1, 3),
+			widget("c", 0, 12, 1, 1),
+		}
+		compacted := layout.Compact(items, 1)
+		assert.Equal(t, map[string][2]int{"a": {0, 0}, "b": {0, 2}, "c": {0, 5}}, positions(compacted))
+	})
+
+	t.Run("should resolve overlapping widgets by pushing them down", func(t *testing.T) {
+		items := []api.WidgetItem{
+			widget("a", 0, 0, 1, 4),
+			widget("b", 0, 1, 1, 4),
+			widget("c", 0, 2, 1, 4),
+		}
+		compacted := layout.Compact(items, 1)
+		assert.Equal(t, map[string][2]int{"a": {0, 0}, "b": {0, 4}, "c": {0, 8}}, positions(compacted))
+	})
+
+	t.Run("should keep static widgets in place and compact around them", func(t *testing.T) {
+		static := widget("static", 0, 2, 2, 2)
+		static.Static = true
+		items := []api.WidgetItem{
+			static,
+			widget("a", 0, 0, 1, 3),
+			widget("b", 1, 8, 1, 1),
+		}
+		compacted := layout.Compact(items, 2)
+		// widgets cannot pass through a static widget while moving up
+		assert.Equal(t, map[string][2]int{"static": {0, 2}, "a": {0, 4}, "b": {1, 4}}, positions(compacted))
+		assert.Equal(t, "static", compacted[0].WidgetType, "Order of widgets should be preserved")
+	})
+
+	t.Run("should not modify the input", func(t *testing.T) {
+		items := []api.WidgetItem{widget("a", 0, 5, 1, 2)}
+		layout.Compact(items, 1)
+		assert.Equal(t, 5, *items[0].Y)
+	})
+}
+
+func TestCorrectBounds(t *testing.T) {
+	t.Run("should move overflowing widgets back into the grid", func(t *testing.T) {
+		corrected := layout.CorrectBounds([]api.WidgetItem{widget("a", 2, 0, 2, 1)}, 3)
+		assert.Equal(t, 1, *corrected[0].X)
+		assert.Equal(t, 2, corrected[0].Width)
+	})
+
+	t.Run("should shrink widgets wider than the grid", func(t *testing.T) {
+		corrected := layout.CorrectBounds([]api.WidgetItem{widget("a", 0, 0, 3, 1)}, 1)
+		assert.Equal(t, 0, *corrected[0].X)
+		assert.Equal(t, 1, corrected[0].Width)
+	})
+
+	t.Run("should push overlapping static widgets down", func(t *testing.T) {
+		a, b := widget("a", 0, 0, 1, 2), widget("b", 0, 1, 1, 2)
+		a.Static, b.Static = true, true
+		corrected := layout.CorrectBounds([]api.WidgetItem{a, b}, 1)
+		assert.Equal(t, map[string][2]int{"a": {0, 0}, "b": {0, 2}}, positions(corrected))
+	})
+}
+
+func TestCompactConfig(t *testing.T) {
+	t.Run("should produce valid layouts from overlapping ones", func(t *testing.T) {
+		items := datatypes.NewJSONType([]api.WidgetItem{
+			widget("a", 0, 0, 1, 4),
+			widget("b", 0, 1, 2, 4),
+			widget("c", 1, 1, 1, 4),
+		})
+		config := api.DashboardTemplateConfig{Sm: items, Md: items, Lg: items, Xl: items}
+		require.Error(t, config.IsValid())
+
+		compacted := layout.CompactConfig(config)
+		assert.NoError(t, compacted.IsValid())
+		assert.Equal(t, 1, compacted.Sm.Data()[1].Width, "Widths should be corrected for the sm layout")
+	})
+}
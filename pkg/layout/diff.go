@@ -0,0 +1,67 @@
+package layout
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/RedHatInsights/widget-layout-backend/api"
+)
+
+// ConfigDiff lists the widgets which differ between two configurations, each widget is listed once no matter in how
+// many layouts it differs.
+type ConfigDiff struct {
+	Added   []string
+	Removed []string
+	// Changed are the widgets moved or resized in at least one layout
+	Changed []string
+}
+
+// DiffConfig compares the layouts of two configurations widget by widget.
+func DiffConfig(before, after api.DashboardTemplateConfig) ConfigDiff {
+	added, removed, changed := map[string]bool{}, map[string]bool{}, map[string]bool{}
+	for _, gs := range breakpoints {
+		beforeItems, _ := before.GetBreakpoint(gs)
+		afterItems, _ := after.GetBreakpoint(gs)
+		beforeRects := make(map[string]rect, len(beforeItems))
+		for _, wi := range beforeItems {
+			beforeRects[wi.WidgetType] = toRect(wi)
+		}
+		for _, wi := range afterItems {
+			r, ok := beforeRects[wi.WidgetType]
+			switch {
+			case !ok:
+				added[wi.WidgetType] = true
+			case r != toRect(wi):
+				changed[wi.WidgetType] = true
+			}
+			delete(beforeRects, wi.WidgetType)
+		}
+		for widgetType := range beforeRects {
+			removed[widgetType] = true
+		}
+	}
+	return ConfigDiff{Added: sortedKeys(added), Removed: sortedKeys(removed), Changed: sortedKeys(changed)}
+}
+
+// IsEmpty reports whether both configurations have the same widgets at the same positions.
+func (d ConfigDiff) IsEmpty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// String summarizes the differences, e.g. "added a, b; moved or resized c".
+func (d ConfigDiff) String() string {
+	var parts []string
+	for _, part := range []struct {
+		label   string
+		widgets []string
+	}{
+		{"added", d.Added},
+		{"removed", d.Removed},
+		{"moved or resized", d.Changed},
+	} {
+		if len(part.widgets) > 0 {
+			parts = append(parts, fmt.Sprintf("%s %s", part.label, strings.Join(part.widgets, ", ")))
+		}
+	}
+	return strings.Join(parts, "; ")
+}
@@ -0,0 +1,38 @@
+package layout_test
+
+import (
+	"testing"
+
+	"github.com/RedHatInsights/widget-layout-backend/api"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/layout"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/datatypes"
+)
+
+func TestDiffConfig(t *testing.T) {
+	t.Run("should list every differing widget once", func(t *testing.T) {
+		before := datatypes.NewJSONType([]api.WidgetItem{widget("a", 0, 0, 1, 1), widget("b", 0, 1, 1, 1), widget("c", 0, 2, 1, 1)})
+		after := datatypes.NewJSONType([]api.WidgetItem{widget("b", 0, 0, 1, 1), widget("c", 0, 2, 1, 1), widget("d", 0, 3, 1, 1)})
+
+		diff := layout.DiffConfig(
+			api.DashboardTemplateConfig{Sm: before, Md: before, Lg: before, Xl: before},
+			api.DashboardTemplateConfig{Sm: after, Md: after, Lg: after, Xl: after},
+		)
+
+		assert.Equal(t, []string{"d"}, diff.Added)
+		assert.Equal(t, []string{"a"}, diff.Removed)
+		assert.Equal(t, []string{"b"}, diff.Changed)
+		assert.False(t, diff.IsEmpty())
+		assert.Equal(t, "added d; removed a; moved or resized b", diff.String())
+	})
+
+	t.Run("should report identical layouts as empty", func(t *testing.T) {
+		items := datatypes.NewJSONType([]api.WidgetItem{widget("a", 0, 0, 1, 1)})
+		config := api.DashboardTemplateConfig{Sm: items, Md: items, Lg: items, Xl: items}
+
+		diff := layout.DiffConfig(config, config)
+
+		assert.True(t, diff.IsEmpty())
+		assert.Empty(t, diff.String())
+	})
+}
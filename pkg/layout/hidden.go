@@ -0,0 +1,54 @@
+package layout
+
+import (
+	"slices"
+
+	"github.com/RedHatInsights/widget-layout-backend/api"
+)
+
+// RestoreHiddenConfig adds the widgets of the stored configuration for which hidden returns true back into a
+// configuration edited by a caller who could not see them, see RestoreHidden. Layouts missing from the edited
+// configuration are left missing.
+func RestoreHiddenConfig(edited, stored api.DashboardTemplateConfig, hidden func(widgetType string) bool) api.DashboardTemplateConfig {
+	result := edited
+	for _, gs := range breakpoints {
+		items, _ := edited.GetBreakpoint(gs)
+		storedItems, _ := stored.GetBreakpoint(gs)
+		if items == nil {
+			continue
+		}
+		hiddenItems := slices.DeleteFunc(slices.Clone(storedItems), func(wi api.WidgetItem) bool { return !hidden(wi.WidgetType) })
+		if len(hiddenItems) == 0 {
+			continue
+		}
+		cols, _ := gs.GetMaxWidth()
+		_ = result.SetBreakpoint(gs, RestoreHidden(items, hiddenItems, cols))
+	}
+	return result
+}
+
+// RestoreHidden adds hidden widgets back into a layout edited by a caller who could not see them. Widgets of the
+// edited layout with the type of a hidden widget are dropped, the caller cannot have changed them. Hidden widgets
+// keep their stored position if it is free, otherwise they are placed at the first free position in reading order.
+func RestoreHidden(edited, hidden []api.WidgetItem, cols int) []api.WidgetItem {
+	isHidden := widgetTypes(hidden)
+	restored := make([]api.WidgetItem, 0, len(edited)+len(hidden))
+	var occupied []rect
+	for _, wi := range edited {
+		if isHidden[wi.WidgetType] {
+			continue
+		}
+		restored = append(restored, wi)
+		occupied = append(occupied, toRect(wi))
+	}
+	for _, wi := range hidden {
+		r := toRect(wi)
+		if firstCollision(occupied, r) != nil {
+			r = freeSpace(occupied, min(r.w, cols), r.h, cols)
+			setPosition(&wi, r)
+		}
+		restored = append(restored, wi)
+		occupied = append(occupied, r)
+	}
+	return restored
+}
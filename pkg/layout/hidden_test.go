@@ -0,0 +1,60 @@
+package layout_test
+
+import (
+	"testing"
+
+	"github.com/RedHatInsights/widget-layout-backend/api"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/layout"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+)
+
+func TestRestoreHidden(t *testing.T) {
+	t.Run("should keep hidden widgets at their position when it is free", func(t *testing.T) {
+		edited := []api.WidgetItem{widget("a", 0, 0, 1, 1)}
+		hidden := []api.WidgetItem{widget("hidden", 1, 0, 1, 2)}
+
+		restored := layout.RestoreHidden(edited, hidden, 2)
+
+		assert.Equal(t, map[string][2]int{"a": {0, 0}, "hidden": {1, 0}}, positions(restored))
+	})
+
+	t.Run("should move hidden widgets to free space when their position was taken", func(t *testing.T) {
+		edited := []api.WidgetItem{widget("a", 0, 0, 2, 1)}
+		hidden := []api.WidgetItem{widget("hidden", 1, 0, 1, 2)}
+
+		restored := layout.RestoreHidden(edited, hidden, 2)
+
+		assert.Equal(t, map[string][2]int{"a": {0, 0}, "hidden": {0, 1}}, positions(restored))
+		assert.Equal(t, 2, restored[1].Height)
+	})
+
+	t.Run("should replace edited widgets of a hidden type", func(t *testing.T) {
+		edited := []api.WidgetItem{widget("hidden", 0, 0, 2, 4)}
+		hidden := []api.WidgetItem{widget("hidden", 1, 0, 1, 2)}
+
+		restored := layout.RestoreHidden(edited, hidden, 2)
+
+		require.Len(t, restored, 1)
+		assert.Equal(t, hidden[0], restored[0])
+	})
+}
+
+func TestRestoreHiddenConfig(t *testing.T) {
+	stored := datatypes.NewJSONType([]api.WidgetItem{widget("a", 0, 0, 1, 1), widget("hidden", 0, 1, 1, 1)})
+	edited := datatypes.NewJSONType([]api.WidgetItem{widget("a", 0, 3, 1, 1)})
+	isHidden := func(widgetType string) bool { return widgetType == "hidden" }
+
+	restored := layout.RestoreHiddenConfig(
+		api.DashboardTemplateConfig{Sm: edited, Md: edited},
+		api.DashboardTemplateConfig{Sm: stored, Md: stored, Lg: stored, Xl: stored},
+		isHidden,
+	)
+
+	for _, items := range [][]api.WidgetItem{restored.Sm.Data(), restored.Md.Data()} {
+		assert.Equal(t, map[string][2]int{"a": {0, 3}, "hidden": {0, 1}}, positions(items))
+	}
+	assert.Nil(t, restored.Lg.Data(), "Missing layouts should be left missing")
+	assert.Equal(t, map[string][2]int{"a": {0, 0}, "hidden": {0, 1}}, positions(stored.Data()), "The stored layout should not change")
+}
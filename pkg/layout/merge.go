@@ -0,0 +1,128 @@
+package layout
+
+import (
+	"slices"
+	"sort"
+
+	"github.com/RedHatInsights/widget-layout-backend/api"
+)
+
+// MergeResult is the outcome of merging a new version of a base template into a layout forked from an older one.
+type MergeResult struct {
+	Config api.DashboardTemplateConfig
+	// Added are the widgets of the new base template placed into the layout
+	Added []string
+	// Removed are the widgets retired from the base template removed from the layout
+	Removed []string
+}
+
+// MergeBaseConfig is a three-way merge of a new version of a base template into a layout forked from an old version.
+// Widgets the new version adds are placed into free space of the layout, widgets the new version retires are removed
+// from it. Every other widget keeps the position and size chosen by the user, including widgets the user removed.
+// Layouts missing from the user configuration are left missing.
+func MergeBaseConfig(oldBase, newBase, user api.DashboardTemplateConfig) (MergeResult, error) {
+	result := MergeResult{Config: user}
+	added, removed := map[string]bool{}, map[string]bool{}
+	for _, gs := range breakpoints {
+		items, err := user.GetBreakpoint(gs)
+		if err != nil {
+			return MergeResult{}, err
+		}
+		if items == nil {
+			continue
+		}
+		oldItems, _ := oldBase.GetBreakpoint(gs)
+		newItems, _ := newBase.GetBreakpoint(gs)
+		cols, _ := gs.GetMaxWidth()
+
+		merged, bpAdded, bpRemoved := MergeBase(oldItems, newItems, items, cols)
+		for _, widgetType := range bpAdded {
+			added[widgetType] = true
+		}
+		for _, widgetType := range bpRemoved {
+			removed[widgetType] = true
+		}
+		if err := result.Config.SetBreakpoint(gs, merged); err != nil {
+			return MergeResult{}, err
+		}
+	}
+	result.Added, result.Removed = sortedKeys(added), sortedKeys(removed)
+	return result, nil
+}
+
+// MergeBase merges the widgets of a single layout, see MergeBaseConfig. Added widgets keep their position in the
+// new base template if it is free, otherwise they are placed at the first free position in reading order.
+func MergeBase(oldBase, newBase, user []api.WidgetItem, cols int) (merged []api.WidgetItem, added, removed []string) {
+	inOld := widgetTypes(oldBase)
+	inNew := widgetTypes(newBase)
+	inUser := widgetTypes(user)
+
+	merged = make([]api.WidgetItem, 0, len(user))
+	var occupied []rect
+	for _, wi := range user {
+		if inOld[wi.WidgetType] && !inNew[wi.WidgetType] {
+			removed = append(removed, wi.WidgetType)
+			continue
+		}
+		merged = append(merged, wi)
+		occupied = append(occupied, toRect(wi))
+	}
+
+	// place the new widgets in reading order of the new base template, so they keep their relative order
+	candidates := slices.Clone(newBase)
+	sort.SliceStable(candidates, func(a, b int) bool {
+		ra, rb := toRect(candidates[a]), toRect(candidates[b])
+		if ra.y != rb.y {
+			return ra.y < rb.y
+		}
+		return ra.x < rb.x
+	})
+	for _, wi := range candidates {
+		if inOld[wi.WidgetType] || inUser[wi.WidgetType] {
+			continue
+		}
+		r := toRect(wi)
+		r.w = max(1, min(r.w, cols))
+		r.h = max(1, r.h)
+		r.x = max(0, min(r.x, cols-r.w))
+		r.y = max(0, r.y)
+		if firstCollision(occupied, r) != nil {
+			r = freeSpace(occupied, r.w, r.h, cols)
+		}
+		setPosition(&wi, r)
+		wi.Height = r.h
+		merged = append(merged, wi)
+		occupied = append(occupied, r)
+		added = append(added, wi.WidgetType)
+	}
+	return merged, added, removed
+}
+
+// freeSpace returns the first position in reading order where a widget of the given size fits without overlapping.
+func freeSpace(occupied []rect, w, h, cols int) rect {
+	for y := 0; ; y++ {
+		for x := 0; x+w <= cols; x++ {
+			r := rect{x, y, w, h}
+			if firstCollision(occupied, r) == nil {
+				return r
+			}
+		}
+	}
+}
+
+func widgetTypes(items []api.WidgetItem) map[string]bool {
+	types := make(map[string]bool, len(items))
+	for _, wi := range items {
+		types[wi.WidgetType] = true
+	}
+	return types
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,93 @@
+package layout_test
+
+import (
+	"testing"
+
+	"github.com/RedHatInsights/widget-layout-backend/api"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/layout"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+)
+
+func TestMergeBase(t *testing.T) {
+	t.Run("should keep the layout of the user for unchanged widgets", func(t *testing.T) {
+		base := []api.WidgetItem{widget("a", 0, 0, 1, 1), widget("b", 1, 0, 1, 1)}
+		user := []api.WidgetItem{widget("b", 0, 0, 2, 3), widget("a", 2, 0, 1, 1)}
+
+		merged, added, removed := layout.MergeBase(base, base, user, 4)
+
+		assert.Equal(t, user, merged)
+		assert.Empty(t, added)
+		assert.Empty(t, removed)
+	})
+
+	t.Run("should add new widgets at their base position when it is free", func(t *testing.T) {
+		oldBase := []api.WidgetItem{widget("a", 0, 0, 1, 1)}
+		newBase := []api.WidgetItem{widget("a", 0, 0, 1, 1), widget("new", 3, 0, 1, 2)}
+		user := []api.WidgetItem{widget("a", 0, 0, 2, 2)}
+
+		merged, added, removed := layout.MergeBase(oldBase, newBase, user, 4)
+
+		assert.Equal(t, []string{"new"}, added)
+		assert.Empty(t, removed)
+		assert.Equal(t, map[string][2]int{"a": {0, 0}, "new": {3, 0}}, positions(merged))
+		assert.Equal(t, 2, merged[1].Height)
+	})
+
+	t.Run("should add new widgets in free space when their base position is taken", func(t *testing.T) {
+		oldBase := []api.WidgetItem{widget("a", 0, 0, 1, 1)}
+		newBase := []api.WidgetItem{widget("a", 0, 0, 1, 1), widget("new", 1, 0, 2, 1)}
+		user := []api.WidgetItem{widget("a", 0, 0, 3, 1), widget("custom", 0, 1, 1, 1)}
+
+		merged, added, _ := layout.MergeBase(oldBase, newBase, user, 4)
+
+		assert.Equal(t, []string{"new"}, added)
+		assert.Equal(t, [2]int{1, 1}, positions(merged)["new"])
+	})
+
+	t.Run("should remove retired widgets and not restore widgets removed by the user", func(t *testing.T) {
+		oldBase := []api.WidgetItem{widget("a", 0, 0, 1, 1), widget("retired", 1, 0, 1, 1), widget("dismissed", 2, 0, 1, 1)}
+		newBase := []api.WidgetItem{widget("a", 0, 0, 1, 1), widget("dismissed", 2, 0, 1, 1)}
+		user := []api.WidgetItem{widget("retired", 0, 0, 1, 1), widget("a", 1, 0, 1, 1), widget("custom", 2, 0, 1, 1)}
+
+		merged, added, removed := layout.MergeBase(oldBase, newBase, user, 4)
+
+		assert.Empty(t, added)
+		assert.Equal(t, []string{"retired"}, removed)
+		assert.Equal(t, map[string][2]int{"a": {1, 0}, "custom": {2, 0}}, positions(merged))
+	})
+
+	t.Run("should not duplicate new widgets the user already added", func(t *testing.T) {
+		newBase := []api.WidgetItem{widget("new", 0, 0, 1, 1)}
+		user := []api.WidgetItem{widget("new", 3, 3, 1, 1)}
+
+		merged, added, _ := layout.MergeBase(nil, newBase, user, 4)
+
+		assert.Empty(t, added)
+		assert.Equal(t, user, merged)
+	})
+}
+
+func TestMergeBaseConfig(t *testing.T) {
+	t.Run("should merge every authored layout and report the changes once", func(t *testing.T) {
+		oldItems := []api.WidgetItem{widget("a", 0, 0, 1, 1), widget("retired", 1, 0, 1, 1)}
+		newItems := []api.WidgetItem{widget("a", 0, 0, 1, 1), widget("new", 1, 0, 3, 1)}
+		userItems := []api.WidgetItem{widget("a", 0, 0, 1, 1), widget("retired", 0, 1, 1, 1)}
+		oldBase := api.DashboardTemplateConfig{Sm: datatypes.NewJSONType(oldItems), Md: datatypes.NewJSONType(oldItems), Lg: datatypes.NewJSONType(oldItems), Xl: datatypes.NewJSONType(oldItems)}
+		newBase := api.DashboardTemplateConfig{Sm: datatypes.NewJSONType(newItems), Md: datatypes.NewJSONType(newItems), Lg: datatypes.NewJSONType(newItems), Xl: datatypes.NewJSONType(newItems)}
+		user := api.DashboardTemplateConfig{Sm: datatypes.NewJSONType(userItems), Xl: datatypes.NewJSONType(userItems)}
+
+		result, err := layout.MergeBaseConfig(oldBase, newBase, user)
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"new"}, result.Added)
+		assert.Equal(t, []string{"retired"}, result.Removed)
+		assert.Equal(t, map[string][2]int{"a": {0, 0}, "new": {1, 0}}, positions(result.Config.Xl.Data()))
+		sm := result.Config.Sm.Data()
+		require.Len(t, sm, 2)
+		assert.Equal(t, 1, sm[1].Width, "New widgets should be narrowed to the layout")
+		assert.Equal(t, [2]int{0, 1}, positions(sm)["new"])
+		assert.Nil(t, result.Config.Md.Data(), "Missing layouts should stay missing")
+	})
+}
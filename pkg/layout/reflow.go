@@ -0,0 +1,147 @@
+package layout
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/RedHatInsights/widget-layout-backend/api"
+)
+
+// breakpoints ordered from the widest to the narrowest, the widest authored one carries the most information
+var breakpoints = []api.GridSizes{api.Xl, api.Lg, api.Md, api.Sm}
+
+// ReflowConfig generates every missing layout of the configuration from a single authored layout.
+// The source defaults to the widest authored breakpoint, authored layouts are never changed.
+// Widget dimensions missing in the source are taken from the defaults of the widget mappings.
+func ReflowConfig(tc api.DashboardTemplateConfig, source *api.GridSizes, mappings map[string]api.WidgetModuleFederationMetadata) (api.DashboardTemplateConfig, error) {
+	var from api.GridSizes
+	if source != nil {
+		if err := source.IsValid(); err != nil {
+			return tc, err
+		}
+		if items, _ := tc.GetBreakpoint(*source); items == nil {
+			return tc, fmt.Errorf("breakpoint %s has no layout to reflow", *source)
+		}
+		from = *source
+	} else {
+		for _, gs := range breakpoints {
+			if items, _ := tc.GetBreakpoint(gs); items != nil {
+				from = gs
+				break
+			}
+		}
+		if from == "" {
+			return tc, errors.New("at least one breakpoint layout is required")
+		}
+	}
+
+	items, _ := tc.GetBreakpoint(from)
+	result := tc
+	for _, gs := range breakpoints {
+		if current, _ := tc.GetBreakpoint(gs); current != nil {
+			continue
+		}
+		reflowed, err := Reflow(items, from, gs, mappings)
+		if err != nil {
+			return tc, err
+		}
+		if err := result.SetBreakpoint(gs, reflowed); err != nil {
+			return tc, err
+		}
+	}
+	return result, nil
+}
+
+// Reflow derives the layout of the target breakpoint from the layout of the source breakpoint.
+// The column edges of every widget are scaled to the columns of the target, heights are kept
+// within the minHeight and maxHeight of the widget, and the result is compacted so widgets which
+// no longer fit next to each other are stacked in reading order of the source layout.
+func Reflow(items []api.WidgetItem, from, to api.GridSizes, mappings map[string]api.WidgetModuleFederationMetadata) ([]api.WidgetItem, error) {
+	fromCols, err := from.GetMaxWidth()
+	if err != nil {
+		return nil, err
+	}
+	toCols, err := to.GetMaxWidth()
+	if err != nil {
+		return nil, err
+	}
+
+	// compaction breaks ties of the scaled positions by order, process the widgets in reading order of the source
+	order := make([]int, len(items))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		ra, rb := toRect(items[order[a]]), toRect(items[order[b]])
+		if ra.y != rb.y {
+			return ra.y < rb.y
+		}
+		return ra.x < rb.x
+	})
+
+	scaled := make([]api.WidgetItem, len(items))
+	for i, idx := range order {
+		scaled[i] = reflowItem(items[idx], fromCols, toCols, mappings)
+	}
+	compacted := Compact(CorrectBounds(scaled, toCols), toCols)
+
+	out := make([]api.WidgetItem, len(items))
+	for i, idx := range order {
+		out[idx] = compacted[i]
+	}
+	return out, nil
+}
+
+func reflowItem(wi api.WidgetItem, fromCols, toCols int, mappings map[string]api.WidgetModuleFederationMetadata) api.WidgetItem {
+	defaults, hasDefaults := api.WidgetBaseDimensions{}, false
+	if mapping, ok := mappings[wi.WidgetType]; ok {
+		defaults, hasDefaults = mapping.Defaults, true
+	}
+
+	if hasDefaults {
+		if wi.Width < 1 && defaults.Width != nil {
+			wi.Width = *defaults.Width
+		}
+		if wi.Height < 1 && defaults.Height != nil {
+			wi.Height = *defaults.Height
+		}
+		if wi.MinHeight == nil && defaults.MinHeight != nil {
+			minHeight := *defaults.MinHeight
+			wi.MinHeight = &minHeight
+		}
+		if wi.MaxHeight == nil && defaults.MaxHeight != nil {
+			maxHeight := *defaults.MaxHeight
+			wi.MaxHeight = &maxHeight
+		}
+	}
+
+	// scale a column edge of the source to the closest column edge of the target, rounding half up
+	scale := func(edge int) int {
+		return (2*edge*toCols + fromCols) / (2 * fromCols)
+	}
+
+	r := toRect(wi)
+	left, right := scale(r.x), scale(r.x+r.w)
+	// a narrower source says little about the intended width, prefer the default width of the widget
+	if fromCols < toCols && hasDefaults && defaults.Width != nil {
+		r.w = *defaults.Width
+	} else {
+		r.w = right - left
+	}
+	r.w = max(1, min(r.w, toCols))
+	r.x = max(0, min(left, toCols-r.w))
+
+	if wi.Height < 1 {
+		wi.Height = 1
+	}
+	if wi.MinHeight != nil && wi.Height < *wi.MinHeight {
+		wi.Height = *wi.MinHeight
+	}
+	if wi.MaxHeight != nil && wi.Height > *wi.MaxHeight {
+		wi.Height = *wi.MaxHeight
+	}
+
+	setPosition(&wi, r)
+	return wi
+}
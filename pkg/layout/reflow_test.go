@@ -0,0 +1,135 @@
+package layout_test
+
+import (
+	"testing"
+
+	"github.com/RedHatInsights/widget-layout-backend/api"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/layout"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/test_util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+)
+
+func TestReflow(t *testing.T) {
+	t.Run("should stack widgets in reading order on a single column", func(t *testing.T) {
+		items := []api.WidgetItem{
+			widget("b", 2, 0, 2, 3),
+			widget("a", 0, 0, 2, 2),
+			widget("c", 0, 2, 4, 1),
+		}
+		reflowed, err := layout.Reflow(items, api.Xl, api.Sm, nil)
+		require.NoError(t, err)
+
+		require.Len(t, reflowed, 3)
+		assert.Equal(t, "b", reflowed[0].WidgetType, "Order of the widgets should be preserved")
+		assert.Equal(t, map[string][2]int{"a": {0, 0}, "b": {0, 2}, "c": {0, 5}}, positions(reflowed))
+		for _, wi := range reflowed {
+			assert.Equal(t, 1, wi.Width)
+		}
+	})
+
+	t.Run("should scale widths and positions to the target columns", func(t *testing.T) {
+		items := []api.WidgetItem{
+			widget("a", 0, 0, 2, 2),
+			widget("b", 2, 0, 2, 2),
+		}
+		reflowed, err := layout.Reflow(items, api.Xl, api.Md, nil)
+		require.NoError(t, err)
+
+		assert.Equal(t, map[string][2]int{"a": {0, 0}, "b": {1, 0}}, positions(reflowed))
+		assert.Equal(t, 1, reflowed[0].Width)
+		assert.Equal(t, 1, reflowed[1].Width)
+	})
+
+	t.Run("should use the default width of the widget mapping when widening a layout", func(t *testing.T) {
+		mappings := map[string]api.WidgetModuleFederationMetadata{
+			"a": {Defaults: api.WidgetBaseDimensions{Width: test_util.IntPTR(2), Height: test_util.IntPTR(3)}},
+		}
+		items := []api.WidgetItem{
+			widget("a", 0, 0, 1, 2),
+			widget("b", 0, 2, 1, 2),
+		}
+		reflowed, err := layout.Reflow(items, api.Sm, api.Xl, mappings)
+		require.NoError(t, err)
+
+		assert.Equal(t, 2, reflowed[0].Width)
+		assert.Equal(t, 4, reflowed[1].Width, "Widgets without a mapping keep filling the row")
+	})
+
+	t.Run("should keep heights within the limits of the widget mapping", func(t *testing.T) {
+		mappings := map[string]api.WidgetModuleFederationMetadata{
+			"a": {Defaults: api.WidgetBaseDimensions{
+				Width:     test_util.IntPTR(1),
+				Height:    test_util.IntPTR(3),
+				MinHeight: test_util.IntPTR(2),
+				MaxHeight: test_util.IntPTR(4),
+			}},
+			"b": {Defaults: api.WidgetBaseDimensions{Width: test_util.IntPTR(1), Height: test_util.IntPTR(3)}},
+		}
+		items := []api.WidgetItem{
+			widget("a", 0, 0, 1, 8),
+			{WidgetType: "b", X: test_util.IntPTR(1), Y: test_util.IntPTR(0)},
+		}
+		reflowed, err := layout.Reflow(items, api.Lg, api.Md, mappings)
+		require.NoError(t, err)
+
+		assert.Equal(t, 4, reflowed[0].Height)
+		require.NotNil(t, reflowed[0].MaxHeight)
+		assert.Equal(t, 4, *reflowed[0].MaxHeight)
+		assert.Equal(t, 3, reflowed[1].Height, "Missing dimensions should be taken from the defaults")
+		assert.Equal(t, 1, reflowed[1].Width)
+	})
+
+	t.Run("should reject invalid breakpoints", func(t *testing.T) {
+		_, err := layout.Reflow(nil, api.Xl, api.GridSizes("xxl"), nil)
+		assert.Error(t, err)
+	})
+}
+
+func TestReflowConfig(t *testing.T) {
+	t.Run("should derive missing breakpoints from the widest authored one", func(t *testing.T) {
+		tc := api.DashboardTemplateConfig{
+			Md: datatypes.NewJSONType([]api.WidgetItem{widget("md-only", 0, 0, 2, 1)}),
+			Xl: datatypes.NewJSONType([]api.WidgetItem{
+				widget("a", 0, 0, 2, 2),
+				widget("b", 2, 0, 2, 2),
+			}),
+		}
+		reflowed, err := layout.ReflowConfig(tc, nil, nil)
+		require.NoError(t, err)
+
+		assert.Equal(t, tc.Xl.Data(), reflowed.Xl.Data())
+		assert.Equal(t, tc.Md.Data(), reflowed.Md.Data(), "Authored breakpoints should not change")
+		assert.Equal(t, map[string][2]int{"a": {0, 0}, "b": {2, 0}}, positions(reflowed.Lg.Data()))
+		assert.Equal(t, map[string][2]int{"a": {0, 0}, "b": {0, 2}}, positions(reflowed.Sm.Data()))
+		assert.NoError(t, reflowed.IsValid())
+	})
+
+	t.Run("should derive from the requested breakpoint", func(t *testing.T) {
+		tc := api.DashboardTemplateConfig{
+			Sm: datatypes.NewJSONType([]api.WidgetItem{widget("sm", 0, 0, 1, 1)}),
+			Xl: datatypes.NewJSONType([]api.WidgetItem{widget("xl", 0, 0, 1, 1)}),
+		}
+		source := api.Sm
+		reflowed, err := layout.ReflowConfig(tc, &source, nil)
+		require.NoError(t, err)
+
+		assert.Equal(t, "sm", reflowed.Lg.Data()[0].WidgetType)
+		assert.Equal(t, "xl", reflowed.Xl.Data()[0].WidgetType)
+	})
+
+	t.Run("should reject a requested breakpoint without a layout", func(t *testing.T) {
+		tc := api.DashboardTemplateConfig{
+			Xl: datatypes.NewJSONType([]api.WidgetItem{widget("xl", 0, 0, 1, 1)}),
+		}
+		source := api.Sm
+		_, err := layout.ReflowConfig(tc, &source, nil)
+		assert.Error(t, err)
+	})
+
+	t.Run("should require at least one breakpoint", func(t *testing.T) {
+		_, err := layout.ReflowConfig(api.DashboardTemplateConfig{}, nil, nil)
+		assert.Error(t, err)
+	})
+}
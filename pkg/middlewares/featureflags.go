@@ -0,0 +1,24 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"github.com/RedHatInsights/widget-layout-backend/pkg/featureflags"
+)
+
+// InjectRequestFlags stores the feature flags of the caller in the context of the request. The flags are
+// evaluated lazily and at most once per request, it must run after InjectUserIdentity.
+func InjectRequestFlags(provider featureflags.FlagProvider) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			id, ok := LookupUserIdentity(ctx)
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+			ctx = featureflags.WithRequestFlags(ctx, featureflags.NewRequestFlags(ctx, provider, id))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
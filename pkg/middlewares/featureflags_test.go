@@ -0,0 +1,64 @@
+package middlewares
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/RedHatInsights/widget-layout-backend/pkg/config"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/featureflags"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/test_util"
+	"github.com/redhatinsights/platform-go-middlewares/v2/identity"
+)
+
+type countingProvider struct {
+	calls int
+}
+
+func (cp *countingProvider) Flags(context.Context, identity.XRHID) (map[string]bool, error) {
+	cp.calls++
+	return map[string]bool{"enabled-flag": true}, nil
+}
+
+func TestInjectRequestFlagsMiddleware(t *testing.T) {
+	t.Run("should share the flags of the request", func(t *testing.T) {
+		provider := &countingProvider{}
+		req := httptest.NewRequest("GET", "/test", nil)
+		req = req.WithContext(context.WithValue(req.Context(), config.IdentityContextKey, test_util.GenerateIdentityStruct()))
+		rr := httptest.NewRecorder()
+
+		InjectRequestFlags(provider)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for range 2 {
+				flags, ok := featureflags.RequestFlagsFromContext(r.Context())
+				if !ok || !flags.IsEnabled("enabled-flag") {
+					http.Error(w, "Flags not found in context", http.StatusInternalServerError)
+					return
+				}
+			}
+			w.WriteHeader(http.StatusOK)
+		})).ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Errorf("Expected status code %d, got %d", http.StatusOK, rr.Code)
+		}
+		if provider.calls != 1 {
+			t.Errorf("Expected the provider to be called once, got %d", provider.calls)
+		}
+	})
+
+	t.Run("should skip requests without an identity", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		InjectRequestFlags(&countingProvider{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if _, ok := featureflags.RequestFlagsFromContext(r.Context()); ok {
+				http.Error(w, "Unexpected flags in context", http.StatusInternalServerError)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		})).ServeHTTP(rr, httptest.NewRequest("GET", "/test", nil))
+
+		if rr.Code != http.StatusOK {
+			t.Errorf("Expected status code %d, got %d", http.StatusOK, rr.Code)
+		}
+	})
+}
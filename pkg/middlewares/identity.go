@@ -33,3 +33,9 @@ func GetUserIdentity(ctx context.Context) identity.XRHID {
 	}
 	return id
 }
+
+// LookupUserIdentity returns the identity of the request and whether it is present.
+func LookupUserIdentity(ctx context.Context) (identity.XRHID, bool) {
+	id, ok := ctx.Value(config.IdentityContextKey).(identity.XRHID)
+	return id, ok
+}
@@ -0,0 +1,7 @@
+package models
+
+import (
+	"github.com/RedHatInsights/widget-layout-backend/api"
+)
+
+type DashboardTemplateRevision = api.DashboardTemplateRevision
@@ -21,6 +21,15 @@ func TestMain(m *testing.M) {
 	database.InitDb()
 	if err := database.DB.AutoMigrate(
 		&DashboardTemplate{},
+		&DashboardTemplateRevision{},
+		&BaseTemplateVersion{},
+		&DashboardTemplateShare{},
+		&OrgBaseTemplate{},
+		&OrgWidgetPolicy{},
+		&AuditEvent{},
+		&OutboxEvent{},
+		&Webhook{},
+		&WebhookDelivery{},
 	); err != nil {
 		log.Fatalf("Failed to migrate test database: %v", err)
 	}
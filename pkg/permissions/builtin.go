@@ -0,0 +1,78 @@
+package permissions
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/redhatinsights/platform-go-middlewares/v2/identity"
+)
+
+// builtinEvaluators implement the permission methods of the frontend which can be derived from the identity alone.
+var builtinEvaluators = map[string]Evaluator{
+	"isOrgAdmin": func(id identity.XRHID, _ []interface{}) (bool, error) {
+		return id.Identity.User != nil && id.Identity.User.OrgAdmin, nil
+	},
+	"isInternal": func(id identity.XRHID, _ []interface{}) (bool, error) {
+		return id.Identity.User != nil && id.Identity.User.Internal, nil
+	},
+	"isActive": func(id identity.XRHID, _ []interface{}) (bool, error) {
+		return id.Identity.User != nil && id.Identity.User.Active, nil
+	},
+	"isHidden": func(identity.XRHID, []interface{}) (bool, error) {
+		return false, nil
+	},
+	"isEntitled": isEntitled,
+	"withEmail":  withEmail,
+}
+
+// isEntitled requires the organization to be entitled to every service in args,
+// without args it requires an entitlement to any service.
+func isEntitled(id identity.XRHID, args []interface{}) (bool, error) {
+	services, err := stringArgs(args)
+	if err != nil {
+		return false, err
+	}
+	if len(services) == 0 {
+		for _, details := range id.Entitlements {
+			if details.IsEntitled {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	for _, service := range services {
+		if !id.Entitlements[service].IsEntitled {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// withEmail requires the email of the user to contain any of the args, e.g. a domain.
+func withEmail(id identity.XRHID, args []interface{}) (bool, error) {
+	emails, err := stringArgs(args)
+	if err != nil {
+		return false, err
+	}
+	if id.Identity.User == nil || id.Identity.User.Email == "" {
+		return false, nil
+	}
+	for _, email := range emails {
+		if strings.Contains(id.Identity.User.Email, email) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func stringArgs(args []interface{}) ([]string, error) {
+	values := make([]string, 0, len(args))
+	for idx, arg := range args {
+		value, ok := arg.(string)
+		if !ok {
+			return nil, fmt.Errorf("argument %d must be a string, got %T", idx, arg)
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
@@ -0,0 +1,66 @@
+package permissions
+
+import (
+	"sync"
+
+	"github.com/RedHatInsights/widget-layout-backend/api"
+	"github.com/redhatinsights/platform-go-middlewares/v2/identity"
+	"github.com/sirupsen/logrus"
+)
+
+// Evaluator checks a single widget permission against the identity of the caller.
+// The args are the arguments of the permission as configured in the widget mapping.
+type Evaluator func(id identity.XRHID, args []interface{}) (bool, error)
+
+// Registry maps permission methods to their evaluators.
+type Registry struct {
+	mu         sync.RWMutex
+	evaluators map[string]Evaluator
+}
+
+// NewRegistry creates a registry with the built-in evaluators.
+func NewRegistry() *Registry {
+	r := &Registry{evaluators: make(map[string]Evaluator)}
+	for method, evaluator := range builtinEvaluators {
+		r.Register(method, evaluator)
+	}
+	return r
+}
+
+// DefaultRegistry is used to evaluate the permissions of the widget mappings.
+var DefaultRegistry = NewRegistry()
+
+// Register adds the evaluator for a permission method, replacing an existing one.
+func (r *Registry) Register(method string, evaluator Evaluator) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.evaluators[method] = evaluator
+}
+
+// Allowed reports whether the identity satisfies all permissions.
+// Methods without an evaluator, e.g. ones requiring API calls, can only be checked by the frontend
+// and are treated as satisfied. A permission whose evaluation fails is treated as not satisfied.
+func (r *Registry) Allowed(id identity.XRHID, permissions []api.Permission) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, permission := range permissions {
+		evaluator, ok := r.evaluators[permission.Method]
+		if !ok {
+			logrus.Debugf("No evaluator for permission method %s, leaving it to the frontend", permission.Method)
+			continue
+		}
+		var args []interface{}
+		if permission.Args != nil {
+			args = *permission.Args
+		}
+		allowed, err := evaluator(id, args)
+		if err != nil {
+			logrus.Warnf("Failed to evaluate permission method %s: %v", permission.Method, err)
+			return false
+		}
+		if !allowed {
+			return false
+		}
+	}
+	return true
+}
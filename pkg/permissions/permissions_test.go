@@ -0,0 +1,100 @@
+package permissions_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/RedHatInsights/widget-layout-backend/api"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/permissions"
+	"github.com/redhatinsights/platform-go-middlewares/v2/identity"
+	"github.com/stretchr/testify/assert"
+)
+
+func userIdentity(user identity.User, entitlements map[string]identity.ServiceDetails) identity.XRHID {
+	return identity.XRHID{
+		Identity:     identity.Identity{OrgID: "12345", Type: "User", User: &user},
+		Entitlements: entitlements,
+	}
+}
+
+func permission(method string, args ...interface{}) api.Permission {
+	p := api.Permission{Method: method}
+	if len(args) > 0 {
+		p.Args = &args
+	}
+	return p
+}
+
+func TestBuiltinEvaluators(t *testing.T) {
+	registry := permissions.NewRegistry()
+	admin := userIdentity(identity.User{OrgAdmin: true, Internal: true, Active: true, Email: "admin@redhat.com"}, map[string]identity.ServiceDetails{
+		"rhel":      {IsEntitled: true},
+		"openshift": {IsEntitled: false},
+	})
+	user := userIdentity(identity.User{Email: "user@example.com"}, nil)
+
+	tests := []struct {
+		name       string
+		permission api.Permission
+		admin      bool
+		user       bool
+	}{
+		{"isOrgAdmin", permission("isOrgAdmin"), true, false},
+		{"isInternal", permission("isInternal"), true, false},
+		{"isActive", permission("isActive"), true, false},
+		{"isHidden", permission("isHidden"), false, false},
+		{"isEntitled without args", permission("isEntitled"), true, false},
+		{"isEntitled to an entitled service", permission("isEntitled", "rhel"), true, false},
+		{"isEntitled to a service without entitlement", permission("isEntitled", "openshift"), false, false},
+		{"withEmail", permission("withEmail", "@redhat.com", "@ibm.com"), true, false},
+		{"unknown methods are left to the frontend", permission("apiRequest", "/api/foo"), true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.admin, registry.Allowed(admin, []api.Permission{tt.permission}))
+			assert.Equal(t, tt.user, registry.Allowed(user, []api.Permission{tt.permission}))
+		})
+	}
+
+	t.Run("should deny identities without a user", func(t *testing.T) {
+		system := identity.XRHID{Identity: identity.Identity{OrgID: "12345", Type: "System"}}
+		assert.False(t, registry.Allowed(system, []api.Permission{permission("isOrgAdmin")}))
+		assert.False(t, registry.Allowed(system, []api.Permission{permission("withEmail", "@redhat.com")}))
+	})
+
+	t.Run("should deny invalid arguments", func(t *testing.T) {
+		assert.False(t, registry.Allowed(admin, []api.Permission{permission("isEntitled", 42)}))
+	})
+}
+
+func TestRegistry(t *testing.T) {
+	id := userIdentity(identity.User{OrgAdmin: true}, nil)
+
+	t.Run("should require all permissions", func(t *testing.T) {
+		registry := permissions.NewRegistry()
+		assert.True(t, registry.Allowed(id, nil))
+		assert.True(t, registry.Allowed(id, []api.Permission{permission("isOrgAdmin")}))
+		assert.False(t, registry.Allowed(id, []api.Permission{permission("isOrgAdmin"), permission("isInternal")}))
+	})
+
+	t.Run("should use registered evaluators", func(t *testing.T) {
+		registry := permissions.NewRegistry()
+		var received []interface{}
+		registry.Register("hasOrgId", func(id identity.XRHID, args []interface{}) (bool, error) {
+			received = args
+			return id.Identity.OrgID == args[0], nil
+		})
+
+		assert.True(t, registry.Allowed(id, []api.Permission{permission("hasOrgId", "12345")}))
+		assert.Equal(t, []interface{}{"12345"}, received)
+		assert.False(t, registry.Allowed(id, []api.Permission{permission("hasOrgId", "54321")}))
+	})
+
+	t.Run("should deny when an evaluator fails", func(t *testing.T) {
+		registry := permissions.NewRegistry()
+		registry.Register("isOrgAdmin", func(identity.XRHID, []interface{}) (bool, error) {
+			return true, errors.New("evaluation failed")
+		})
+		assert.False(t, registry.Allowed(id, []api.Permission{permission("isOrgAdmin")}))
+	})
+}
@@ -0,0 +1,146 @@
+package registry
+
+import (
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// Registry holds entries which are read through immutable snapshots. Writers never change a
+// published snapshot, they publish a new one, so a reader holding a snapshot always sees a
+// consistent set of entries while the registry is swapped underneath it.
+type Registry[K cmp.Ordered, V any] interface {
+	// Snapshot returns the current snapshot of the registry.
+	Snapshot() *Snapshot[K, V]
+	// Get returns the entry of the key from the current snapshot.
+	Get(key K) (V, bool)
+	// All returns a copy of the entries of the current snapshot.
+	All() map[K]V
+	// Len returns the number of entries of the current snapshot.
+	Len() int
+	// Version returns the content hash of the current snapshot.
+	Version() string
+	// Replace publishes a snapshot holding exactly the entries.
+	Replace(entries map[K]V) *Snapshot[K, V]
+	// Put publishes a snapshot with the entry of the key added or replaced.
+	Put(key K, value V) *Snapshot[K, V]
+}
+
+// Snapshot is an immutable set of registry entries. Its version is a hash of the content,
+// two snapshots with the same entries have the same version.
+type Snapshot[K cmp.Ordered, V any] struct {
+	entries    map[K]V
+	version    string
+	generation uint64
+}
+
+func newSnapshot[K cmp.Ordered, V any](entries map[K]V, generation uint64) *Snapshot[K, V] {
+	if entries == nil {
+		entries = make(map[K]V)
+	}
+	return &Snapshot[K, V]{entries: entries, version: hashEntries(entries), generation: generation}
+}
+
+func (s *Snapshot[K, V]) Get(key K) (V, bool) {
+	v, ok := s.entries[key]
+	return v, ok
+}
+
+// All returns a copy of the entries, changes to the copy do not affect the snapshot.
+func (s *Snapshot[K, V]) All() map[K]V {
+	return maps.Clone(s.entries)
+}
+
+// Keys returns the keys of the snapshot in ascending order.
+func (s *Snapshot[K, V]) Keys() []K {
+	return slices.Sorted(maps.Keys(s.entries))
+}
+
+func (s *Snapshot[K, V]) Len() int {
+	return len(s.entries)
+}
+
+// Version returns the hex encoded SHA-256 hash of the entries.
+func (s *Snapshot[K, V]) Version() string {
+	return s.version
+}
+
+// Generation returns the number of snapshots published by the registry before this one.
+func (s *Snapshot[K, V]) Generation() uint64 {
+	return s.generation
+}
+
+// hashEntries hashes the entries in key order, values are hashed by their JSON encoding.
+func hashEntries[K cmp.Ordered, V any](entries map[K]V) string {
+	h := sha256.New()
+	for _, key := range slices.Sorted(maps.Keys(entries)) {
+		value, err := json.Marshal(entries[key])
+		if err != nil {
+			value = fmt.Appendf(nil, "%#v", entries[key])
+		}
+		fmt.Fprintf(h, "%v\x00%s\x00", key, value)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Store is the in-memory Registry. Reads are lock free, writes are serialized.
+type Store[K cmp.Ordered, V any] struct {
+	mu      sync.Mutex
+	current atomic.Pointer[Snapshot[K, V]]
+}
+
+// New creates an empty store.
+func New[K cmp.Ordered, V any]() *Store[K, V] {
+	s := &Store[K, V]{}
+	s.current.Store(newSnapshot[K, V](nil, 0))
+	return s
+}
+
+func (s *Store[K, V]) Snapshot() *Snapshot[K, V] {
+	return s.current.Load()
+}
+
+func (s *Store[K, V]) Get(key K) (V, bool) {
+	return s.Snapshot().Get(key)
+}
+
+func (s *Store[K, V]) All() map[K]V {
+	return s.Snapshot().All()
+}
+
+func (s *Store[K, V]) Len() int {
+	return s.Snapshot().Len()
+}
+
+func (s *Store[K, V]) Version() string {
+	return s.Snapshot().Version()
+}
+
+// Replace publishes a snapshot holding a copy of the entries.
+func (s *Store[K, V]) Replace(entries map[K]V) *Snapshot[K, V] {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.publish(maps.Clone(entries))
+}
+
+func (s *Store[K, V]) Put(key K, value V) *Snapshot[K, V] {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entries := s.Snapshot().All()
+	entries[key] = value
+	return s.publish(entries)
+}
+
+func (s *Store[K, V]) publish(entries map[K]V) *Snapshot[K, V] {
+	snapshot := newSnapshot(entries, s.Snapshot().generation+1)
+	s.current.Store(snapshot)
+	return snapshot
+}
+
+var _ Registry[string, any] = (*Store[string, any])(nil)
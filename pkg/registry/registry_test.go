@@ -0,0 +1,127 @@
+package registry_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/RedHatInsights/widget-layout-backend/pkg/registry"
+	"github.com/stretchr/testify/assert"
+)
+
+type entry struct {
+	Title string `json:"title"`
+	Width int    `json:"w"`
+}
+
+func TestStore(t *testing.T) {
+	t.Run("should start empty", func(t *testing.T) {
+		store := registry.New[string, entry]()
+		all := store.All()
+		assert.NotNil(t, all)
+		assert.Empty(t, all)
+		assert.Equal(t, 0, store.Len())
+		assert.NotEmpty(t, store.Version())
+	})
+
+	t.Run("should add and retrieve entries", func(t *testing.T) {
+		store := registry.New[string, entry]()
+		store.Put("first", entry{Title: "First", Width: 1})
+		store.Put("second", entry{Title: "Second", Width: 2})
+
+		first, exists := store.Get("first")
+		assert.True(t, exists)
+		assert.Equal(t, "First", first.Title)
+		_, exists = store.Get("non-existent")
+		assert.False(t, exists)
+		assert.Len(t, store.All(), 2)
+	})
+
+	t.Run("should overwrite entries with the same key", func(t *testing.T) {
+		store := registry.New[string, entry]()
+		store.Put("same", entry{Title: "Original"})
+		store.Put("same", entry{Title: "Updated"})
+
+		result, exists := store.Get("same")
+		assert.True(t, exists)
+		assert.Equal(t, "Updated", result.Title)
+		assert.Equal(t, 1, store.Len())
+	})
+
+	t.Run("should replace all entries", func(t *testing.T) {
+		store := registry.New[string, entry]()
+		store.Put("old", entry{Title: "Old"})
+
+		entries := map[string]entry{"new": {Title: "New"}}
+		store.Replace(entries)
+		entries["changed"] = entry{Title: "Changed"}
+
+		assert.Equal(t, []string{"new"}, store.Snapshot().Keys(), "Changes to the replaced map should not leak into the registry")
+		store.Replace(nil)
+		assert.Equal(t, 0, store.Len())
+	})
+}
+
+func TestSnapshot(t *testing.T) {
+	t.Run("should not change when the registry is swapped", func(t *testing.T) {
+		store := registry.New[string, entry]()
+		store.Put("first", entry{Title: "First"})
+		snapshot := store.Snapshot()
+
+		store.Replace(map[string]entry{"second": {Title: "Second"}})
+
+		_, exists := snapshot.Get("first")
+		assert.True(t, exists)
+		_, exists = snapshot.Get("second")
+		assert.False(t, exists)
+		assert.Greater(t, store.Snapshot().Generation(), snapshot.Generation())
+	})
+
+	t.Run("should return copies of the entries", func(t *testing.T) {
+		store := registry.New[string, entry]()
+		store.Put("first", entry{Title: "First"})
+
+		all := store.All()
+		all["second"] = entry{Title: "Second"}
+
+		assert.Equal(t, 1, store.Len())
+	})
+
+	t.Run("should version snapshots by their content", func(t *testing.T) {
+		store := registry.New[string, entry]()
+		empty := store.Version()
+
+		store.Put("first", entry{Title: "First", Width: 1})
+		first := store.Version()
+		assert.NotEqual(t, empty, first)
+
+		store.Put("first", entry{Title: "First", Width: 2})
+		assert.NotEqual(t, first, store.Version(), "Changed values should change the version")
+
+		store.Replace(map[string]entry{"first": {Title: "First", Width: 1}})
+		assert.Equal(t, first, store.Version(), "The same content should have the same version")
+
+		other := registry.New[string, entry]()
+		other.Replace(map[string]entry{"first": {Title: "First", Width: 1}})
+		assert.Equal(t, first, other.Version())
+	})
+}
+
+func TestStoreConcurrency(t *testing.T) {
+	store := registry.New[string, entry]()
+	var wg sync.WaitGroup
+	for i := range 10 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			store.Put(fmt.Sprintf("entry-%d", i), entry{Width: i})
+		}()
+		go func() {
+			defer wg.Done()
+			snapshot := store.Snapshot()
+			assert.Equal(t, snapshot.Len(), len(snapshot.All()))
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 10, store.Len(), "Concurrent puts should not lose entries")
+}
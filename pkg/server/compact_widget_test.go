@@ -0,0 +1,69 @@
+package server_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/RedHatInsights/widget-layout-backend/api"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/database"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/test_util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+)
+
+func TestCompactWidgetLayoutById(t *testing.T) {
+	t.Run("should compact the layouts of a template", func(t *testing.T) {
+		server := setupRouter()
+
+		req, userID := withUniqueUserIdentityContext(httptest.NewRequest("POST", "/", nil))
+		items := datatypes.NewJSONType([]api.WidgetItem{
+			{Width: 1, Height: 2, X: intPtr(0), Y: intPtr(3), WidgetType: "widget1"},
+			{Width: 1, Height: 2, X: intPtr(0), Y: intPtr(9), WidgetType: "widget2"},
+		})
+		mockDashboard := test_util.MockDashboardTemplateWithSpecificUser(userID)
+		mockDashboard.TemplateConfig = api.DashboardTemplateConfig{Sm: items, Md: items, Lg: items, Xl: items}
+		require.NoError(t, database.DB.Create(&mockDashboard).Error)
+
+		w := httptest.NewRecorder()
+		server.CompactWidgetLayoutById(w, req, int64(mockDashboard.ID), api.CompactWidgetLayoutByIdParams{})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NotEmpty(t, w.Header().Get("ETag"))
+		var resp api.DashboardTemplate
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		xl := resp.TemplateConfig.Xl.Data()
+		require.Len(t, xl, 2)
+		assert.Equal(t, 0, *xl[0].Y)
+		assert.Equal(t, 2, *xl[1].Y)
+
+		var dbTemplate api.DashboardTemplate
+		require.NoError(t, database.DB.First(&dbTemplate, mockDashboard.ID).Error)
+		assert.Equal(t, 2, *dbTemplate.TemplateConfig.Xl.Data()[1].Y)
+	})
+
+	t.Run("should return 404 for non-existent template", func(t *testing.T) {
+		server := setupRouter()
+
+		req, _ := withUniqueUserIdentityContext(httptest.NewRequest("POST", "/", nil))
+		w := httptest.NewRecorder()
+		server.CompactWidgetLayoutById(w, req, int64(test_util.NonExistentID), api.CompactWidgetLayoutByIdParams{})
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("should return 403 for unauthorized user", func(t *testing.T) {
+		server := setupRouter()
+
+		mockDashboard := test_util.MockDashboardTemplateWithSpecificUser(test_util.GetUniqueUserID())
+		require.NoError(t, database.DB.Create(&mockDashboard).Error)
+
+		req, _ := withUniqueUserIdentityContext(httptest.NewRequest("POST", "/", nil))
+		w := httptest.NewRecorder()
+		server.CompactWidgetLayoutById(w, req, int64(mockDashboard.ID), api.CompactWidgetLayoutByIdParams{})
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
@@ -0,0 +1,54 @@
+package server_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/RedHatInsights/widget-layout-backend/api"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/database"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/test_util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCopySharedWidgetLayout(t *testing.T) {
+	t.Run("should copy the shared template for the user", func(t *testing.T) {
+		server := setupRouter()
+
+		ownerID := test_util.GetUniqueUserID()
+		mockDashboard := test_util.MockDashboardTemplateWithSpecificUser(ownerID)
+		require.NoError(t, database.DB.Create(&mockDashboard).Error)
+		share := api.DashboardTemplateShare{DashboardTemplateId: mockDashboard.ID, UserId: ownerID, Token: "copy-" + ownerID}
+		require.NoError(t, database.DB.Create(&share).Error)
+
+		body, _ := json.Marshal(api.CopyWidgetDashboardTemplateRequest{DashboardName: stringPtr("My Copy")})
+		req, _ := http.NewRequest("POST", "/shared/"+share.Token+"/copy", bytes.NewReader(body))
+		req, copierID := withUniqueUserIdentityContext(req)
+		w := httptest.NewRecorder()
+
+		server.CopySharedWidgetLayout(w, req, share.Token)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var copied api.DashboardTemplate
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&copied))
+		assert.NotEqual(t, mockDashboard.ID, copied.ID)
+		assert.Equal(t, copierID, copied.UserId)
+		assert.Equal(t, "My Copy", copied.DashboardName)
+		assert.Equal(t, mockDashboard.TemplateConfig, copied.TemplateConfig)
+	})
+
+	t.Run("should return 404 for unknown tokens", func(t *testing.T) {
+		server := setupRouter()
+
+		req, _ := http.NewRequest("POST", "/shared/unknown/copy", nil)
+		req = withIdentityContext(req)
+		w := httptest.NewRecorder()
+
+		server.CopySharedWidgetLayout(w, req, "unknown")
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/RedHatInsights/widget-layout-backend/pkg/database"
 	"github.com/RedHatInsights/widget-layout-backend/pkg/test_util"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/subpop/xrhidgen"
 	"gorm.io/datatypes"
 )
@@ -23,8 +24,12 @@ func TestCopyWidgetLayoutById(t *testing.T) {
 		originalUserID := test_util.GetUniqueUserID()
 		copyingUserID := test_util.GetUniqueUserID()
 
+		orgID := "org-" + originalUserID
+
 		originalDashboard := test_util.MockDashboardTemplate()
 		originalDashboard.UserId = originalUserID // Different user - to verify copying works across users
+		originalDashboard.OrgId = orgID           // Shared with the organization of the copying user
+		originalDashboard.Visibility = api.VisibilityOrg
 		originalDashboard.TemplateBase.Name = "Original Template"
 		originalDashboard.TemplateBase.DisplayName = "Original Display Name"
 		result := database.DB.Create(&originalDashboard)
@@ -35,7 +40,7 @@ func TestCopyWidgetLayoutById(t *testing.T) {
 		// Perform the COPY request
 		req, _ := http.NewRequest("POST", fmt.Sprintf("/%d/copy", templateID), nil)
 		req = withCustomIdentityContext(req, test_util.GenerateIdentityStructFromTemplate(
-			xrhidgen.Identity{},
+			xrhidgen.Identity{OrgID: stringPtr(orgID)},
 			xrhidgen.User{UserID: stringPtr(copyingUserID)},
 			xrhidgen.Entitlements{},
 		))
@@ -134,7 +139,11 @@ func TestCopyWidgetLayoutById(t *testing.T) {
 
 		// Copy the template
 		req, _ := http.NewRequest("POST", fmt.Sprintf("/%d/copy", templateIDInt64), nil)
-		req, _ = withUniqueUserIdentityContext(req)
+		req = withCustomIdentityContext(req, test_util.GenerateIdentityStructFromTemplate(
+			xrhidgen.Identity{},
+			xrhidgen.User{UserID: stringPtr(originalUserID)},
+			xrhidgen.Entitlements{},
+		))
 		w := httptest.NewRecorder()
 
 		server.CopyWidgetLayoutById(w, req, templateIDInt64)
@@ -161,4 +170,19 @@ func TestCopyWidgetLayoutById(t *testing.T) {
 			assert.Equal(t, originalWidgets[0].MinHeight, copiedWidgets[0].MinHeight, "Widget min height should match")
 		}
 	})
+	t.Run("should return 403 for templates not shared with the user", func(t *testing.T) {
+		server := setupRouter()
+
+		originalDashboard := test_util.MockDashboardTemplateWithSpecificUser(test_util.GetUniqueUserID())
+		require.NoError(t, database.DB.Create(&originalDashboard).Error)
+
+		templateID := int64(originalDashboard.ID)
+		req, _ := http.NewRequest("POST", fmt.Sprintf("/%d/copy", templateID), nil)
+		req, _ = withUniqueUserIdentityContext(req)
+		w := httptest.NewRecorder()
+
+		server.CopyWidgetLayoutById(w, req, templateID)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
 }
@@ -0,0 +1,88 @@
+package server_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/RedHatInsights/widget-layout-backend/api"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/database"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/test_util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/subpop/xrhidgen"
+)
+
+func TestCreateWebhook(t *testing.T) {
+	t.Run("should register a webhook for the organization", func(t *testing.T) {
+		server := setupRouter()
+
+		orgID := "org-" + test_util.GetUniqueUserID()
+		adminID := test_util.GetUniqueUserID()
+		events := []api.WebhookEventType{api.WebhookEventTemplateDeleted}
+		body, _ := json.Marshal(api.WebhookRequest{Url: "https://example.com/hook", Secret: "0123456789abcdef", Events: &events})
+
+		req, _ := http.NewRequest("POST", "/webhooks", bytes.NewReader(body))
+		req = withCustomIdentityContext(req, test_util.GenerateIdentityStructFromTemplate(
+			xrhidgen.Identity{OrgID: stringPtr(orgID)},
+			xrhidgen.User{UserID: stringPtr(adminID), IsOrgAdmin: test_util.BoolPTR(true)},
+			xrhidgen.Entitlements{},
+		))
+		w := httptest.NewRecorder()
+
+		server.CreateWebhook(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+		assert.NotContains(t, w.Body.String(), "0123456789abcdef", "The secret should not be returned")
+
+		var resp api.Webhook
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		assert.Equal(t, orgID, resp.OrgId)
+		assert.Equal(t, adminID, resp.CreatedBy)
+		assert.Equal(t, events, resp.Events)
+
+		var stored api.Webhook
+		require.NoError(t, database.DB.First(&stored, resp.ID).Error)
+		assert.Equal(t, "0123456789abcdef", stored.Secret)
+	})
+
+	t.Run("should return 400 for invalid webhooks", func(t *testing.T) {
+		server := setupRouter()
+
+		body, _ := json.Marshal(api.WebhookRequest{Url: "https://example.com/hook", Secret: "short"})
+		req, _ := http.NewRequest("POST", "/webhooks", bytes.NewReader(body))
+		req = withCustomIdentityContext(req, test_util.GenerateIdentityStructFromTemplate(
+			xrhidgen.Identity{OrgID: stringPtr("org-" + test_util.GetUniqueUserID())},
+			xrhidgen.User{UserID: stringPtr(test_util.GetUniqueUserID()), IsOrgAdmin: test_util.BoolPTR(true)},
+			xrhidgen.Entitlements{},
+		))
+		w := httptest.NewRecorder()
+
+		server.CreateWebhook(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		var errorResponse api.ErrorResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&errorResponse))
+		assert.Contains(t, errorResponse.Errors[0].Message, "secret")
+	})
+
+	t.Run("should return 400 for invalid request body", func(t *testing.T) {
+		server := setupRouter()
+
+		req, _ := http.NewRequest("POST", "/webhooks", bytes.NewReader([]byte("invalid json")))
+		req = withIdentityContext(req)
+		w := httptest.NewRecorder()
+
+		server.CreateWebhook(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		var errorResponse api.ErrorResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&errorResponse))
+		assert.Contains(t, errorResponse.Errors[0].Message, "Invalid request body")
+	})
+}
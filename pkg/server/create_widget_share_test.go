@@ -0,0 +1,102 @@
+package server_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/RedHatInsights/widget-layout-backend/api"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/database"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/test_util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/subpop/xrhidgen"
+)
+
+func TestCreateWidgetLayoutShareById(t *testing.T) {
+	t.Run("should create a share link", func(t *testing.T) {
+		server := setupRouter()
+
+		testUserID := test_util.GetUniqueUserID()
+		mockDashboard := test_util.MockDashboardTemplateWithSpecificUser(testUserID)
+		require.NoError(t, database.DB.Create(&mockDashboard).Error)
+
+		templateID := int64(mockDashboard.ID)
+		expiresAt := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+		body, _ := json.Marshal(api.CreateWidgetDashboardTemplateShareRequest{ExpiresAt: &expiresAt})
+
+		req, _ := http.NewRequest("POST", fmt.Sprintf("/%d/shares", templateID), bytes.NewReader(body))
+		req = withCustomIdentityContext(req, test_util.GenerateIdentityStructFromTemplate(
+			xrhidgen.Identity{},
+			xrhidgen.User{UserID: stringPtr(testUserID)},
+			xrhidgen.Entitlements{},
+		))
+		w := httptest.NewRecorder()
+
+		server.CreateWidgetLayoutShareById(w, req, templateID)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var share api.DashboardTemplateShare
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&share))
+		assert.NotEmpty(t, share.Token)
+		assert.Equal(t, mockDashboard.ID, share.DashboardTemplateId)
+		require.NotNil(t, share.ExpiresAt)
+		assert.True(t, expiresAt.Equal(*share.ExpiresAt))
+	})
+
+	t.Run("should create a share link without a request body", func(t *testing.T) {
+		server := setupRouter()
+
+		testUserID := test_util.GetUniqueUserID()
+		mockDashboard := test_util.MockDashboardTemplateWithSpecificUser(testUserID)
+		require.NoError(t, database.DB.Create(&mockDashboard).Error)
+
+		templateID := int64(mockDashboard.ID)
+		req, _ := http.NewRequest("POST", fmt.Sprintf("/%d/shares", templateID), nil)
+		req = withCustomIdentityContext(req, test_util.GenerateIdentityStructFromTemplate(
+			xrhidgen.Identity{},
+			xrhidgen.User{UserID: stringPtr(testUserID)},
+			xrhidgen.Entitlements{},
+		))
+		w := httptest.NewRecorder()
+
+		server.CreateWidgetLayoutShareById(w, req, templateID)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var share api.DashboardTemplateShare
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&share))
+		assert.Nil(t, share.ExpiresAt)
+	})
+
+	t.Run("should return 400 for invalid request body", func(t *testing.T) {
+		server := setupRouter()
+
+		req, _ := http.NewRequest("POST", "/123/shares", bytes.NewReader([]byte("invalid json")))
+		req = withIdentityContext(req)
+		w := httptest.NewRecorder()
+
+		server.CreateWidgetLayoutShareById(w, req, int64(test_util.NoDBTestID))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("should return 403 for unauthorized user", func(t *testing.T) {
+		server := setupRouter()
+
+		mockDashboard := test_util.MockDashboardTemplateWithSpecificUser(test_util.GetUniqueUserID())
+		require.NoError(t, database.DB.Create(&mockDashboard).Error)
+
+		templateID := int64(mockDashboard.ID)
+		req, _ := http.NewRequest("POST", fmt.Sprintf("/%d/shares", templateID), nil)
+		req, _ = withUniqueUserIdentityContext(req)
+		w := httptest.NewRecorder()
+
+		server.CreateWidgetLayoutShareById(w, req, templateID)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
@@ -0,0 +1,58 @@
+package server_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/RedHatInsights/widget-layout-backend/api"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/database"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/test_util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/subpop/xrhidgen"
+)
+
+func TestDeleteOrgBaseWidgetDashboardTemplateByName(t *testing.T) {
+	t.Run("should delete the base template of the organization", func(t *testing.T) {
+		server := setupRouter()
+
+		orgID := "org-" + test_util.GetUniqueUserID()
+		template := api.OrgBaseTemplate{OrgId: orgID, Name: "landing-landing", DisplayName: "Org Landing"}
+		require.NoError(t, database.DB.Create(&template).Error)
+
+		req, _ := http.NewRequest("DELETE", "/org-base-templates/landing-landing", nil)
+		req = withCustomIdentityContext(req, test_util.GenerateIdentityStructFromTemplate(
+			xrhidgen.Identity{OrgID: stringPtr(orgID)},
+			xrhidgen.User{UserID: stringPtr(test_util.GetUniqueUserID()), IsOrgAdmin: test_util.BoolPTR(true)},
+			xrhidgen.Entitlements{},
+		))
+		w := httptest.NewRecorder()
+
+		server.DeleteOrgBaseWidgetDashboardTemplateByName(w, req, "landing-landing")
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		var count int64
+		require.NoError(t, database.DB.Model(&api.OrgBaseTemplate{}).Where("id = ?", template.ID).Count(&count).Error)
+		assert.Zero(t, count)
+	})
+
+	t.Run("should return 403 for users who are not org admins", func(t *testing.T) {
+		server := setupRouter()
+
+		orgID := "org-" + test_util.GetUniqueUserID()
+		require.NoError(t, database.DB.Create(&api.OrgBaseTemplate{OrgId: orgID, Name: "landing-landing", DisplayName: "Org Landing"}).Error)
+
+		req, _ := http.NewRequest("DELETE", "/org-base-templates/landing-landing", nil)
+		req = withCustomIdentityContext(req, test_util.GenerateIdentityStructFromTemplate(
+			xrhidgen.Identity{OrgID: stringPtr(orgID)},
+			xrhidgen.User{UserID: stringPtr(test_util.GetUniqueUserID()), IsOrgAdmin: test_util.BoolPTR(false)},
+			xrhidgen.Entitlements{},
+		))
+		w := httptest.NewRecorder()
+
+		server.DeleteOrgBaseWidgetDashboardTemplateByName(w, req, "landing-landing")
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
@@ -0,0 +1,70 @@
+package server_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/RedHatInsights/widget-layout-backend/api"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/database"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/test_util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/subpop/xrhidgen"
+)
+
+func TestDeleteWebhookById(t *testing.T) {
+	t.Run("should delete the webhook together with its deliveries", func(t *testing.T) {
+		server := setupRouter()
+
+		orgID := "org-" + test_util.GetUniqueUserID()
+		webhook := api.Webhook{OrgId: orgID, Url: "https://example.com/hook", Secret: "0123456789abcdef"}
+		require.NoError(t, database.DB.Create(&webhook).Error)
+		require.NoError(t, database.DB.Create(&api.WebhookDelivery{
+			WebhookId: webhook.ID,
+			EventId:   "event-1",
+			EventType: api.WebhookEventTemplateCreated,
+			Payload:   []byte("{}"),
+			Status:    api.WebhookDeliveryPending,
+		}).Error)
+
+		req, _ := http.NewRequest("DELETE", "/webhooks/1", nil)
+		req = withCustomIdentityContext(req, test_util.GenerateIdentityStructFromTemplate(
+			xrhidgen.Identity{OrgID: stringPtr(orgID)},
+			xrhidgen.User{UserID: stringPtr(test_util.GetUniqueUserID()), IsOrgAdmin: test_util.BoolPTR(true)},
+			xrhidgen.Entitlements{},
+		))
+		w := httptest.NewRecorder()
+
+		server.DeleteWebhookById(w, req, int64(webhook.ID))
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.Empty(t, w.Body.String())
+
+		var remaining int64
+		require.NoError(t, database.DB.Model(&api.WebhookDelivery{}).Where("webhook_id = ?", webhook.ID).Count(&remaining).Error)
+		assert.Zero(t, remaining)
+		assert.Error(t, database.DB.First(&api.Webhook{}, webhook.ID).Error)
+	})
+
+	t.Run("should not let other members of the organization delete webhooks", func(t *testing.T) {
+		server := setupRouter()
+
+		orgID := "org-" + test_util.GetUniqueUserID()
+		webhook := api.Webhook{OrgId: orgID, Url: "https://example.com/hook", Secret: "0123456789abcdef"}
+		require.NoError(t, database.DB.Create(&webhook).Error)
+
+		req, _ := http.NewRequest("DELETE", "/webhooks/1", nil)
+		req = withCustomIdentityContext(req, test_util.GenerateIdentityStructFromTemplate(
+			xrhidgen.Identity{OrgID: stringPtr(orgID)},
+			xrhidgen.User{UserID: stringPtr(test_util.GetUniqueUserID()), IsOrgAdmin: test_util.BoolPTR(false)},
+			xrhidgen.Entitlements{},
+		))
+		w := httptest.NewRecorder()
+
+		server.DeleteWebhookById(w, req, int64(webhook.ID))
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.NoError(t, database.DB.First(&api.Webhook{}, webhook.ID).Error)
+	})
+}
@@ -0,0 +1,55 @@
+package server_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/RedHatInsights/widget-layout-backend/api"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/database"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/test_util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/subpop/xrhidgen"
+)
+
+func TestExportDashboardArchive(t *testing.T) {
+	t.Run("should export all templates of the user with their names and defaults", func(t *testing.T) {
+		server := setupRouter()
+		testUserID := test_util.GetUniqueUserID()
+
+		template := test_util.MockDashboardTemplateWithSpecificUser(testUserID)
+		template.DashboardName = "Operations"
+		template.Default = true
+		require.NoError(t, database.DB.Create(&template).Error)
+		require.NoError(t, database.DB.Create(&api.DashboardTemplate{
+			UserId:         test_util.GetUniqueUserID(),
+			TemplateBase:   template.TemplateBase,
+			TemplateConfig: template.TemplateConfig,
+		}).Error)
+
+		req, _ := http.NewRequest("GET", "/export", nil)
+		req = withCustomIdentityContext(req, test_util.GenerateIdentityStructFromTemplate(
+			xrhidgen.Identity{},
+			xrhidgen.User{UserID: stringPtr(testUserID)},
+			xrhidgen.Entitlements{},
+		))
+		w := httptest.NewRecorder()
+
+		server.ExportDashboardArchive(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+		var archive api.DashboardArchive
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&archive))
+		assert.Equal(t, api.DashboardArchiveSchemaVersion, archive.SchemaVersion)
+		assert.Equal(t, testUserID, archive.UserId)
+		assert.False(t, archive.ExportedAt.IsZero())
+		require.Len(t, archive.Templates, 1, "Templates of other users should not be exported")
+		assert.Equal(t, "Operations", archive.Templates[0].DashboardName)
+		assert.True(t, archive.Templates[0].Default)
+		assert.Equal(t, template.TemplateBase, archive.Templates[0].TemplateBase)
+	})
+}
@@ -75,6 +75,7 @@ func TestExportWidgetLayoutById(t *testing.T) {
 		var exportResp api.ExportWidgetDashboardTemplateResponse
 		err := json.Unmarshal(w.Body.Bytes(), &exportResp)
 		assert.NoError(t, err, "Response should be valid JSON")
+		assert.Equal(t, api.ExportSchemaVersion, exportResp.SchemaVersion, "Expected the current schema version")
 		assert.Equal(t, testTemplateBase, exportResp.TemplateBase, "Expected template base to match")
 		assert.Equal(t, testTemplateConfig, exportResp.TemplateConfig, "Expected template config to match")
 	})
@@ -144,6 +145,20 @@ func TestExportWidgetLayoutById(t *testing.T) {
 		assert.NotContains(t, rawResp, "deletedAt", "Export should not contain deletedAt")
 	})
 
+	t.Run("should not export widgets the user is not permitted to see", func(t *testing.T) {
+		server := setupRouter()
+		mockDashboard, userIdentity := createHiddenWidgetDashboard(t)
+
+		req := withCustomIdentityContext(httptest.NewRequest("GET", "/", nil), userIdentity)
+		w := httptest.NewRecorder()
+		server.ExportWidgetLayoutById(w, req, int64(mockDashboard.ID))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var exported api.ExportWidgetDashboardTemplateResponse
+		assert.NoError(t, json.NewDecoder(w.Body).Decode(&exported))
+		assertOnlyPublicWidget(t, exported.TemplateConfig)
+	})
+
 	t.Run("should return 404 for non-existent widget ID", func(t *testing.T) {
 		server := setupRouter()
 
@@ -8,7 +8,6 @@ import (
 
 	"github.com/RedHatInsights/widget-layout-backend/api"
 	"github.com/RedHatInsights/widget-layout-backend/pkg/database"
-	"github.com/RedHatInsights/widget-layout-backend/pkg/service"
 	"github.com/RedHatInsights/widget-layout-backend/pkg/test_util"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -21,7 +20,7 @@ func TestForkBaseWidgetDashboardTemplateByName(t *testing.T) {
 		server := setupRouter()
 
 		// Reset registry and add a base template
-		service.BaseTemplateRegistry = api.BaseWidgetDashboardTemplateRegistry{}
+		testRegistries.BaseTemplates.Replace(nil)
 
 		baseTemplate := api.BaseWidgetDashboardTemplate{
 			Name:        "fork-test-template",
@@ -44,7 +43,7 @@ func TestForkBaseWidgetDashboardTemplateByName(t *testing.T) {
 			},
 		}
 
-		service.BaseTemplateRegistry.AddBase(baseTemplate)
+		testRegistries.AddBaseTemplate(baseTemplate)
 
 		testUserID := test_util.GetUniqueUserID()
 
@@ -91,7 +90,7 @@ func TestForkBaseWidgetDashboardTemplateByName(t *testing.T) {
 		server := setupRouter()
 
 		// Reset registry to ensure no templates exist
-		service.BaseTemplateRegistry = api.BaseWidgetDashboardTemplateRegistry{}
+		testRegistries.BaseTemplates.Replace(nil)
 
 		req, _ := http.NewRequest("GET", "/base-templates/non-existent/fork", nil)
 		req = withIdentityContext(req)
@@ -115,7 +114,7 @@ func TestForkBaseWidgetDashboardTemplateByName(t *testing.T) {
 		server := setupRouter()
 
 		// Reset registry and add a base template
-		service.BaseTemplateRegistry = api.BaseWidgetDashboardTemplateRegistry{}
+		testRegistries.BaseTemplates.Replace(nil)
 
 		baseTemplate := api.BaseWidgetDashboardTemplate{
 			Name:        "shared-base-template",
@@ -128,7 +127,7 @@ func TestForkBaseWidgetDashboardTemplateByName(t *testing.T) {
 			},
 		}
 
-		service.BaseTemplateRegistry.AddBase(baseTemplate)
+		testRegistries.AddBaseTemplate(baseTemplate)
 
 		user1ID := test_util.GetUniqueUserID()
 		user2ID := test_util.GetUniqueUserID()
@@ -179,7 +178,7 @@ func TestForkBaseWidgetDashboardTemplateByName(t *testing.T) {
 		server := setupRouter()
 
 		// Reset registry and add a complex base template
-		service.BaseTemplateRegistry = api.BaseWidgetDashboardTemplateRegistry{}
+		testRegistries.BaseTemplates.Replace(nil)
 
 		complexWidgets := []api.WidgetItem{
 			{
@@ -225,7 +224,7 @@ func TestForkBaseWidgetDashboardTemplateByName(t *testing.T) {
 			},
 		}
 
-		service.BaseTemplateRegistry.AddBase(baseTemplate)
+		testRegistries.AddBaseTemplate(baseTemplate)
 
 		testUserID := test_util.GetUniqueUserID()
 
@@ -269,7 +268,7 @@ func TestForkBaseWidgetDashboardTemplateByName(t *testing.T) {
 		server := setupRouter()
 
 		// Reset registry and add a base template
-		service.BaseTemplateRegistry = api.BaseWidgetDashboardTemplateRegistry{}
+		testRegistries.BaseTemplates.Replace(nil)
 
 		baseTemplate := api.BaseWidgetDashboardTemplate{
 			Name:        "timestamp-test-template",
@@ -282,7 +281,7 @@ func TestForkBaseWidgetDashboardTemplateByName(t *testing.T) {
 			},
 		}
 
-		service.BaseTemplateRegistry.AddBase(baseTemplate)
+		testRegistries.AddBaseTemplate(baseTemplate)
 
 		testUserID := test_util.GetUniqueUserID()
 
@@ -317,7 +316,7 @@ func TestForkBaseWidgetDashboardTemplateByName(t *testing.T) {
 		server := setupRouter()
 
 		// Reset registry and add a base template with special characters in name
-		service.BaseTemplateRegistry = api.BaseWidgetDashboardTemplateRegistry{}
+		testRegistries.BaseTemplates.Replace(nil)
 
 		baseTemplate := api.BaseWidgetDashboardTemplate{
 			Name:        "special-chars_template.v1-2",
@@ -330,7 +329,7 @@ func TestForkBaseWidgetDashboardTemplateByName(t *testing.T) {
 			},
 		}
 
-		service.BaseTemplateRegistry.AddBase(baseTemplate)
+		testRegistries.AddBaseTemplate(baseTemplate)
 
 		testUserID := test_util.GetUniqueUserID()
 
@@ -358,7 +357,7 @@ func TestForkBaseWidgetDashboardTemplateByName(t *testing.T) {
 		server := setupRouter()
 
 		// Reset registry and add a base template
-		service.BaseTemplateRegistry = api.BaseWidgetDashboardTemplateRegistry{}
+		testRegistries.BaseTemplates.Replace(nil)
 
 		baseTemplate := api.BaseWidgetDashboardTemplate{
 			Name:        "identity-test-template",
@@ -371,7 +370,7 @@ func TestForkBaseWidgetDashboardTemplateByName(t *testing.T) {
 			},
 		}
 
-		service.BaseTemplateRegistry.AddBase(baseTemplate)
+		testRegistries.AddBaseTemplate(baseTemplate)
 
 		// Create request without identity context
 		req, _ := http.NewRequest("GET", "/base-templates/identity-test-template/fork", nil)
@@ -395,7 +394,7 @@ func TestForkBaseWidgetDashboardTemplateByName(t *testing.T) {
 		server := setupRouter()
 
 		// Reset registry and add a base template
-		service.BaseTemplateRegistry = api.BaseWidgetDashboardTemplateRegistry{}
+		testRegistries.BaseTemplates.Replace(nil)
 
 		baseTemplate := api.BaseWidgetDashboardTemplate{
 			Name:        "malformed-identity-test",
@@ -408,7 +407,7 @@ func TestForkBaseWidgetDashboardTemplateByName(t *testing.T) {
 			},
 		}
 
-		service.BaseTemplateRegistry.AddBase(baseTemplate)
+		testRegistries.AddBaseTemplate(baseTemplate)
 
 		// Create request with invalid identity structure (not the expected type)
 		req, _ := http.NewRequest("GET", "/base-templates/malformed-identity-test/fork", nil)
@@ -430,7 +429,7 @@ func TestForkBaseWidgetDashboardTemplateByName(t *testing.T) {
 		server := setupRouter()
 
 		// Reset registry and add a base template
-		service.BaseTemplateRegistry = api.BaseWidgetDashboardTemplateRegistry{}
+		testRegistries.BaseTemplates.Replace(nil)
 
 		baseTemplate := api.BaseWidgetDashboardTemplate{
 			Name:        "db-error-test-template",
@@ -443,7 +442,7 @@ func TestForkBaseWidgetDashboardTemplateByName(t *testing.T) {
 			},
 		}
 
-		service.BaseTemplateRegistry.AddBase(baseTemplate)
+		testRegistries.AddBaseTemplate(baseTemplate)
 
 		testUserID := test_util.GetUniqueUserID()
 
@@ -0,0 +1,75 @@
+package server_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/RedHatInsights/widget-layout-backend/api"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/database"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/test_util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/subpop/xrhidgen"
+)
+
+func TestGetAuditEvents(t *testing.T) {
+	t.Run("should return the audit log of the organization to org admins", func(t *testing.T) {
+		server := setupRouter()
+
+		orgID := "org-" + test_util.GetUniqueUserID()
+		userID := test_util.GetUniqueUserID()
+		for _, action := range []api.AuditAction{api.AuditActionCreate, api.AuditActionRename} {
+			require.NoError(t, database.DB.Create(&api.AuditEvent{
+				OrgId:               orgID,
+				UserId:              userID,
+				Action:              action,
+				DashboardTemplateId: 1,
+			}).Error)
+		}
+
+		req, _ := http.NewRequest("GET", "/audit?limit=1&userId="+userID, nil)
+		req = withCustomIdentityContext(req, test_util.GenerateIdentityStructFromTemplate(
+			xrhidgen.Identity{OrgID: stringPtr(orgID)},
+			xrhidgen.User{UserID: stringPtr(test_util.GetUniqueUserID()), IsOrgAdmin: test_util.BoolPTR(true)},
+			xrhidgen.Entitlements{},
+		))
+		w := httptest.NewRecorder()
+
+		server.GetAuditEvents(w, req, api.GetAuditEventsParams{Limit: intPtr(1), UserId: &userID})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+		var resp api.AuditEventListResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		require.Len(t, resp.Data, 1)
+		assert.Equal(t, api.AuditActionRename, resp.Data[0].Action)
+		assert.Equal(t, 2, *resp.Meta.Total)
+		require.NotNil(t, resp.Meta.Links)
+		require.NotNil(t, resp.Meta.Links.Next)
+		assert.Contains(t, *resp.Meta.Links.Next, "offset=1")
+	})
+
+	t.Run("should not return the audit log to other members of the organization", func(t *testing.T) {
+		server := setupRouter()
+
+		req, _ := http.NewRequest("GET", "/audit", nil)
+		req = withCustomIdentityContext(req, test_util.GenerateIdentityStructFromTemplate(
+			xrhidgen.Identity{OrgID: stringPtr("org-" + test_util.GetUniqueUserID())},
+			xrhidgen.User{UserID: stringPtr(test_util.GetUniqueUserID()), IsOrgAdmin: test_util.BoolPTR(false)},
+			xrhidgen.Entitlements{},
+		))
+		w := httptest.NewRecorder()
+
+		server.GetAuditEvents(w, req, api.GetAuditEventsParams{})
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		var resp api.ErrorResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		require.Len(t, resp.Errors, 1)
+		assert.Equal(t, http.StatusForbidden, resp.Errors[0].Code)
+	})
+}
@@ -4,10 +4,12 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/RedHatInsights/widget-layout-backend/api"
-	"github.com/RedHatInsights/widget-layout-backend/pkg/service"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/featureflags"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"gorm.io/datatypes"
@@ -18,7 +20,7 @@ func TestGetBaseWidgetDashboardTemplates(t *testing.T) {
 		server := setupRouter()
 
 		// Reset registry to ensure no templates exist
-		service.BaseTemplateRegistry = api.BaseWidgetDashboardTemplateRegistry{}
+		testRegistries.BaseTemplates.Replace(nil)
 
 		req, _ := http.NewRequest("GET", "/base-templates", nil)
 		w := httptest.NewRecorder()
@@ -39,7 +41,7 @@ func TestGetBaseWidgetDashboardTemplates(t *testing.T) {
 		server := setupRouter()
 
 		// Reset registry and add test templates
-		service.BaseTemplateRegistry = api.BaseWidgetDashboardTemplateRegistry{}
+		testRegistries.BaseTemplates.Replace(nil)
 
 		baseTemplate1 := api.BaseWidgetDashboardTemplate{
 			Name:        "template-1",
@@ -63,8 +65,8 @@ func TestGetBaseWidgetDashboardTemplates(t *testing.T) {
 			},
 		}
 
-		service.BaseTemplateRegistry.AddBase(baseTemplate1)
-		service.BaseTemplateRegistry.AddBase(baseTemplate2)
+		testRegistries.AddBaseTemplate(baseTemplate1)
+		testRegistries.AddBaseTemplate(baseTemplate2)
 
 		req, _ := http.NewRequest("GET", "/base-templates", nil)
 		w := httptest.NewRecorder()
@@ -96,7 +98,7 @@ func TestGetBaseWidgetDashboardTemplateByName(t *testing.T) {
 		server := setupRouter()
 
 		// Reset registry and add a test template
-		service.BaseTemplateRegistry = api.BaseWidgetDashboardTemplateRegistry{}
+		testRegistries.BaseTemplates.Replace(nil)
 
 		baseTemplate := api.BaseWidgetDashboardTemplate{
 			Name:        "test-template",
@@ -119,7 +121,7 @@ func TestGetBaseWidgetDashboardTemplateByName(t *testing.T) {
 			},
 		}
 
-		service.BaseTemplateRegistry.AddBase(baseTemplate)
+		testRegistries.AddBaseTemplate(baseTemplate)
 
 		req, _ := http.NewRequest("GET", "/base-templates/test-template", nil)
 		w := httptest.NewRecorder()
@@ -146,7 +148,7 @@ func TestGetBaseWidgetDashboardTemplateByName(t *testing.T) {
 		server := setupRouter()
 
 		// Reset registry to ensure no templates exist
-		service.BaseTemplateRegistry = api.BaseWidgetDashboardTemplateRegistry{}
+		testRegistries.BaseTemplates.Replace(nil)
 
 		req, _ := http.NewRequest("GET", "/base-templates/non-existent", nil)
 		w := httptest.NewRecorder()
@@ -168,7 +170,7 @@ func TestGetBaseWidgetDashboardTemplateByName(t *testing.T) {
 		server := setupRouter()
 
 		// Reset registry and add multiple templates
-		service.BaseTemplateRegistry = api.BaseWidgetDashboardTemplateRegistry{}
+		testRegistries.BaseTemplates.Replace(nil)
 
 		template1 := api.BaseWidgetDashboardTemplate{
 			Name:        "template-1",
@@ -192,8 +194,8 @@ func TestGetBaseWidgetDashboardTemplateByName(t *testing.T) {
 			},
 		}
 
-		service.BaseTemplateRegistry.AddBase(template1)
-		service.BaseTemplateRegistry.AddBase(template2)
+		testRegistries.AddBaseTemplate(template1)
+		testRegistries.AddBaseTemplate(template2)
 
 		req, _ := http.NewRequest("GET", "/base-templates/template-2", nil)
 		w := httptest.NewRecorder()
@@ -209,4 +211,55 @@ func TestGetBaseWidgetDashboardTemplateByName(t *testing.T) {
 		assert.Equal(t, "template-2", template.Name, "Should return the correct template")
 		assert.Equal(t, "Template 2", template.DisplayName, "Should return the correct template display name")
 	})
+
+	t.Run("should hide widgets whose feature flag is disabled", func(t *testing.T) {
+		server := setupRouter()
+
+		testRegistries.BaseTemplates.Replace(nil)
+		testRegistries.WidgetMappings.Replace(nil)
+		previousProvider := testRegistries.FlagProvider
+		t.Cleanup(func() {
+			testRegistries.WidgetMappings.Replace(nil)
+			testRegistries.FlagProvider = previousProvider
+		})
+
+		flagsFile := filepath.Join(t.TempDir(), "flags.json")
+		require.NoError(t, os.WriteFile(flagsFile, []byte(`{"landing.preview-widget": false}`), 0o600))
+		provider, err := featureflags.NewStaticProvider(flagsFile)
+		require.NoError(t, err)
+		testRegistries.FlagProvider = provider
+
+		previewFlag := "landing.preview-widget"
+		testRegistries.AddWidgetMapping(api.WidgetModuleFederationMetadata{
+			Scope:       "landing",
+			Module:      "./PreviewWidget",
+			FeatureFlag: &previewFlag,
+			Config:      api.WidgetConfiguration{Title: "Preview"},
+		})
+		items := datatypes.NewJSONType([]api.WidgetItem{
+			{WidgetType: "landing-./PreviewWidget", Width: 1, Height: 1, X: intPtr(0), Y: intPtr(0)},
+			{WidgetType: "landing-./OtherWidget", Width: 1, Height: 1, X: intPtr(0), Y: intPtr(1)},
+		})
+		testRegistries.AddBaseTemplate(api.BaseWidgetDashboardTemplate{
+			Name:           "flagged-template",
+			DisplayName:    "Flagged Template",
+			TemplateConfig: api.DashboardTemplateConfig{Sm: items, Md: items, Lg: items, Xl: items},
+		})
+
+		req, _ := http.NewRequest("GET", "/base-templates/flagged-template", nil)
+		req, _ = withUniqueUserIdentityContext(req)
+		w := httptest.NewRecorder()
+
+		server.GetBaseWidgetDashboardTemplateByName(w, req, "flagged-template")
+
+		assert.Equal(t, http.StatusOK, w.Code, "Expected status code 200")
+		var response api.BaseWidgetDashboardTemplate
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+		for _, layout := range [][]api.WidgetItem{response.TemplateConfig.Sm.Data(), response.TemplateConfig.Xl.Data()} {
+			require.Len(t, layout, 1)
+			assert.Equal(t, "landing-./OtherWidget", layout[0].WidgetType)
+		}
+		stored, _ := testRegistries.BaseTemplates.Get("flagged-template")
+		assert.Len(t, stored.TemplateConfig.Lg.Data(), 2, "The registered base template should not change")
+	})
 }
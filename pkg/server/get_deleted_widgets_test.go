@@ -0,0 +1,51 @@
+package server_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/RedHatInsights/widget-layout-backend/api"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/database"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/test_util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetDeletedWidgetLayouts(t *testing.T) {
+	t.Run("should return the deleted templates of the user", func(t *testing.T) {
+		server := setupRouter()
+
+		req, userID := withUniqueUserIdentityContext(httptest.NewRequest("GET", "/trash", nil))
+		mockDashboard := test_util.MockDashboardTemplateWithSpecificUser(userID)
+		require.NoError(t, database.DB.Create(&mockDashboard).Error)
+		liveDashboard := test_util.MockDashboardTemplateWithSpecificUser(userID)
+		require.NoError(t, database.DB.Create(&liveDashboard).Error)
+		require.NoError(t, database.DB.Delete(&mockDashboard).Error)
+
+		w := httptest.NewRecorder()
+		server.GetDeletedWidgetLayouts(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var resp api.DashboardTemplateListResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		require.Len(t, resp.Data, 1)
+		assert.Equal(t, 1, resp.Meta.Count)
+		assert.Equal(t, mockDashboard.ID, resp.Data[0].ID)
+	})
+
+	t.Run("should return an empty list when the trash is empty", func(t *testing.T) {
+		server := setupRouter()
+
+		req, _ := withUniqueUserIdentityContext(httptest.NewRequest("GET", "/trash", nil))
+		w := httptest.NewRecorder()
+		server.GetDeletedWidgetLayouts(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var resp api.DashboardTemplateListResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		assert.Empty(t, resp.Data)
+		assert.Equal(t, 0, resp.Meta.Count)
+	})
+}
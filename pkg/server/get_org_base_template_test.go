@@ -0,0 +1,58 @@
+package server_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/RedHatInsights/widget-layout-backend/api"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/database"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/test_util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/subpop/xrhidgen"
+)
+
+func TestGetOrgBaseWidgetDashboardTemplateByName(t *testing.T) {
+	t.Run("should return the base template of the organization", func(t *testing.T) {
+		server := setupRouter()
+
+		orgID := "org-" + test_util.GetUniqueUserID()
+		require.NoError(t, database.DB.Create(&api.OrgBaseTemplate{OrgId: orgID, Name: "landing-landing", DisplayName: "Org Landing"}).Error)
+
+		req, _ := http.NewRequest("GET", "/org-base-templates/landing-landing", nil)
+		req = withCustomIdentityContext(req, test_util.GenerateIdentityStructFromTemplate(
+			xrhidgen.Identity{OrgID: stringPtr(orgID)},
+			xrhidgen.User{UserID: stringPtr(test_util.GetUniqueUserID()), IsOrgAdmin: test_util.BoolPTR(true)},
+			xrhidgen.Entitlements{},
+		))
+		w := httptest.NewRecorder()
+
+		server.GetOrgBaseWidgetDashboardTemplateByName(w, req, "landing-landing")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var resp api.OrgBaseTemplate
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		assert.Equal(t, "Org Landing", resp.DisplayName)
+	})
+
+	t.Run("should return 404 for base templates of other organizations", func(t *testing.T) {
+		server := setupRouter()
+
+		require.NoError(t, database.DB.Create(&api.OrgBaseTemplate{OrgId: "org-" + test_util.GetUniqueUserID(), Name: "landing-landing", DisplayName: "Other Landing"}).Error)
+
+		req, _ := http.NewRequest("GET", "/org-base-templates/landing-landing", nil)
+		req = withCustomIdentityContext(req, test_util.GenerateIdentityStructFromTemplate(
+			xrhidgen.Identity{OrgID: stringPtr("org-" + test_util.GetUniqueUserID())},
+			xrhidgen.User{UserID: stringPtr(test_util.GetUniqueUserID()), IsOrgAdmin: test_util.BoolPTR(true)},
+			xrhidgen.Entitlements{},
+		))
+		w := httptest.NewRecorder()
+
+		server.GetOrgBaseWidgetDashboardTemplateByName(w, req, "landing-landing")
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
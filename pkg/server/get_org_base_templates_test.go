@@ -0,0 +1,60 @@
+package server_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/RedHatInsights/widget-layout-backend/api"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/database"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/test_util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/subpop/xrhidgen"
+)
+
+func TestGetOrgBaseWidgetDashboardTemplates(t *testing.T) {
+	t.Run("should list the base templates of the organization only", func(t *testing.T) {
+		server := setupRouter()
+
+		orgID := "org-" + test_util.GetUniqueUserID()
+		require.NoError(t, database.DB.Create(&api.OrgBaseTemplate{OrgId: orgID, Name: "landing-landing", DisplayName: "Org Landing"}).Error)
+		require.NoError(t, database.DB.Create(&api.OrgBaseTemplate{OrgId: "org-" + test_util.GetUniqueUserID(), Name: "landing-landing", DisplayName: "Other Landing"}).Error)
+
+		req, _ := http.NewRequest("GET", "/org-base-templates", nil)
+		req = withCustomIdentityContext(req, test_util.GenerateIdentityStructFromTemplate(
+			xrhidgen.Identity{OrgID: stringPtr(orgID)},
+			xrhidgen.User{UserID: stringPtr(test_util.GetUniqueUserID()), IsOrgAdmin: test_util.BoolPTR(true)},
+			xrhidgen.Entitlements{},
+		))
+		w := httptest.NewRecorder()
+
+		server.GetOrgBaseWidgetDashboardTemplates(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+		var resp api.OrgBaseTemplateListResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		require.Len(t, resp.Data, 1)
+		assert.Equal(t, 1, resp.Meta.Count)
+		assert.Equal(t, "Org Landing", resp.Data[0].DisplayName)
+	})
+
+	t.Run("should return 403 for users who are not org admins", func(t *testing.T) {
+		server := setupRouter()
+
+		req, _ := http.NewRequest("GET", "/org-base-templates", nil)
+		req = withCustomIdentityContext(req, test_util.GenerateIdentityStructFromTemplate(
+			xrhidgen.Identity{OrgID: stringPtr("org-" + test_util.GetUniqueUserID())},
+			xrhidgen.User{UserID: stringPtr(test_util.GetUniqueUserID()), IsOrgAdmin: test_util.BoolPTR(false)},
+			xrhidgen.Entitlements{},
+		))
+		w := httptest.NewRecorder()
+
+		server.GetOrgBaseWidgetDashboardTemplates(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
@@ -0,0 +1,68 @@
+package server_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/RedHatInsights/widget-layout-backend/api"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/database"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/test_util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/subpop/xrhidgen"
+)
+
+func TestGetOrgWidgetPolicy(t *testing.T) {
+	t.Run("should return the widget policy of the organization to its members", func(t *testing.T) {
+		server := setupRouter()
+
+		orgID := "org-" + test_util.GetUniqueUserID()
+		require.NoError(t, database.DB.Create(&api.OrgWidgetPolicy{
+			OrgId:  orgID,
+			Policy: api.WidgetPolicy{MandatoryWidgets: []string{"widget1"}},
+		}).Error)
+
+		req, _ := http.NewRequest("GET", "/org-policy", nil)
+		req = withCustomIdentityContext(req, test_util.GenerateIdentityStructFromTemplate(
+			xrhidgen.Identity{OrgID: stringPtr(orgID)},
+			xrhidgen.User{UserID: stringPtr(test_util.GetUniqueUserID()), IsOrgAdmin: test_util.BoolPTR(false)},
+			xrhidgen.Entitlements{},
+		))
+		w := httptest.NewRecorder()
+
+		server.GetOrgWidgetPolicy(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+		var resp api.OrgWidgetPolicy
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		assert.Equal(t, orgID, resp.OrgId)
+		assert.Equal(t, []string{"widget1"}, resp.Policy.MandatoryWidgets)
+	})
+
+	t.Run("should return an empty policy for organizations without one", func(t *testing.T) {
+		server := setupRouter()
+
+		orgID := "org-" + test_util.GetUniqueUserID()
+		req, _ := http.NewRequest("GET", "/org-policy", nil)
+		req = withCustomIdentityContext(req, test_util.GenerateIdentityStructFromTemplate(
+			xrhidgen.Identity{OrgID: stringPtr(orgID)},
+			xrhidgen.User{UserID: stringPtr(test_util.GetUniqueUserID())},
+			xrhidgen.Entitlements{},
+		))
+		w := httptest.NewRecorder()
+
+		server.GetOrgWidgetPolicy(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var resp api.OrgWidgetPolicy
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		assert.Equal(t, orgID, resp.OrgId)
+		assert.Empty(t, resp.Policy.MandatoryWidgets)
+		assert.Empty(t, resp.Policy.ForbiddenWidgets)
+	})
+}
@@ -0,0 +1,52 @@
+package server_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/RedHatInsights/widget-layout-backend/api"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/database"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/test_util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetSharedWidgetLayout(t *testing.T) {
+	t.Run("should return a read-only view of the shared template", func(t *testing.T) {
+		server := setupRouter()
+
+		ownerID := test_util.GetUniqueUserID()
+		mockDashboard := test_util.MockDashboardTemplateWithSpecificUser(ownerID)
+		mockDashboard.DashboardName = "Shared By Link"
+		require.NoError(t, database.DB.Create(&mockDashboard).Error)
+		share := api.DashboardTemplateShare{DashboardTemplateId: mockDashboard.ID, UserId: ownerID, Token: "view-" + ownerID}
+		require.NoError(t, database.DB.Create(&share).Error)
+
+		req, _ := http.NewRequest("GET", "/shared/"+share.Token, nil)
+		req, _ = withUniqueUserIdentityContext(req)
+		w := httptest.NewRecorder()
+
+		server.GetSharedWidgetLayout(w, req, share.Token)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var shared map[string]any
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&shared))
+		assert.Equal(t, "Shared By Link", shared["dashboardName"])
+		assert.NotContains(t, shared, "userId", "The owner should not be disclosed")
+		assert.NotContains(t, shared, "id")
+	})
+
+	t.Run("should return 404 for unknown tokens", func(t *testing.T) {
+		server := setupRouter()
+
+		req, _ := http.NewRequest("GET", "/shared/unknown", nil)
+		req = withIdentityContext(req)
+		w := httptest.NewRecorder()
+
+		server.GetSharedWidgetLayout(w, req, "unknown")
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
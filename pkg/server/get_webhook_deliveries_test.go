@@ -0,0 +1,76 @@
+package server_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/RedHatInsights/widget-layout-backend/api"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/database"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/test_util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/subpop/xrhidgen"
+)
+
+func TestGetWebhookDeliveries(t *testing.T) {
+	t.Run("should return the deliveries of the webhook filtered by status", func(t *testing.T) {
+		server := setupRouter()
+
+		orgID := "org-" + test_util.GetUniqueUserID()
+		webhook := api.Webhook{OrgId: orgID, Url: "https://example.com/hook", Secret: "0123456789abcdef"}
+		require.NoError(t, database.DB.Create(&webhook).Error)
+		for _, status := range []api.WebhookDeliveryStatus{api.WebhookDeliveryDeadLetter, api.WebhookDeliveryDelivered, api.WebhookDeliveryDeadLetter} {
+			require.NoError(t, database.DB.Create(&api.WebhookDelivery{
+				WebhookId: webhook.ID,
+				EventId:   test_util.GetUniqueUserID(),
+				EventType: api.WebhookEventTemplateUpdated,
+				Payload:   []byte("{}"),
+				Status:    status,
+			}).Error)
+		}
+
+		req, _ := http.NewRequest("GET", "/webhooks/1/deliveries?status=dead-letter&limit=1", nil)
+		req = withCustomIdentityContext(req, test_util.GenerateIdentityStructFromTemplate(
+			xrhidgen.Identity{OrgID: stringPtr(orgID)},
+			xrhidgen.User{UserID: stringPtr(test_util.GetUniqueUserID()), IsOrgAdmin: test_util.BoolPTR(true)},
+			xrhidgen.Entitlements{},
+		))
+		w := httptest.NewRecorder()
+
+		deadLetter := api.WebhookDeliveryDeadLetter
+		server.GetWebhookDeliveries(w, req, int64(webhook.ID), api.GetWebhookDeliveriesParams{Status: &deadLetter, Limit: intPtr(1)})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+		var resp api.WebhookDeliveryListResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		require.Len(t, resp.Data, 1)
+		assert.Equal(t, api.WebhookDeliveryDeadLetter, resp.Data[0].Status)
+		assert.Equal(t, 2, *resp.Meta.Total)
+		require.NotNil(t, resp.Meta.Links)
+		require.NotNil(t, resp.Meta.Links.Next)
+		assert.Contains(t, *resp.Meta.Links.Next, "offset=1")
+	})
+
+	t.Run("should return 404 for webhooks of other organizations", func(t *testing.T) {
+		server := setupRouter()
+
+		webhook := api.Webhook{OrgId: "org-" + test_util.GetUniqueUserID(), Url: "https://example.com/hook", Secret: "0123456789abcdef"}
+		require.NoError(t, database.DB.Create(&webhook).Error)
+
+		req, _ := http.NewRequest("GET", "/webhooks/1/deliveries", nil)
+		req = withCustomIdentityContext(req, test_util.GenerateIdentityStructFromTemplate(
+			xrhidgen.Identity{OrgID: stringPtr("org-" + test_util.GetUniqueUserID())},
+			xrhidgen.User{UserID: stringPtr(test_util.GetUniqueUserID()), IsOrgAdmin: test_util.BoolPTR(true)},
+			xrhidgen.Entitlements{},
+		))
+		w := httptest.NewRecorder()
+
+		server.GetWebhookDeliveries(w, req, int64(webhook.ID), api.GetWebhookDeliveriesParams{})
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
@@ -0,0 +1,64 @@
+package server_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/RedHatInsights/widget-layout-backend/api"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/database"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/test_util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/subpop/xrhidgen"
+)
+
+func TestGetWebhookById(t *testing.T) {
+	orgID := "org-" + test_util.GetUniqueUserID()
+	webhook := api.Webhook{OrgId: orgID, Url: "https://example.com/hook", Secret: "0123456789abcdef"}
+	require.NoError(t, database.DB.Create(&webhook).Error)
+
+	t.Run("should return the webhook to org admins of its organization", func(t *testing.T) {
+		server := setupRouter()
+
+		req, _ := http.NewRequest("GET", "/webhooks/1", nil)
+		req = withCustomIdentityContext(req, test_util.GenerateIdentityStructFromTemplate(
+			xrhidgen.Identity{OrgID: stringPtr(orgID)},
+			xrhidgen.User{UserID: stringPtr(test_util.GetUniqueUserID()), IsOrgAdmin: test_util.BoolPTR(true)},
+			xrhidgen.Entitlements{},
+		))
+		w := httptest.NewRecorder()
+
+		server.GetWebhookById(w, req, int64(webhook.ID))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+		var resp api.Webhook
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		assert.Equal(t, webhook.ID, resp.ID)
+		assert.Equal(t, webhook.Url, resp.Url)
+	})
+
+	t.Run("should return 404 for webhooks of other organizations", func(t *testing.T) {
+		server := setupRouter()
+
+		req, _ := http.NewRequest("GET", "/webhooks/1", nil)
+		req = withCustomIdentityContext(req, test_util.GenerateIdentityStructFromTemplate(
+			xrhidgen.Identity{OrgID: stringPtr("org-" + test_util.GetUniqueUserID())},
+			xrhidgen.User{UserID: stringPtr(test_util.GetUniqueUserID()), IsOrgAdmin: test_util.BoolPTR(true)},
+			xrhidgen.Entitlements{},
+		))
+		w := httptest.NewRecorder()
+
+		server.GetWebhookById(w, req, int64(webhook.ID))
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		var resp api.ErrorResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		require.Len(t, resp.Errors, 1)
+		assert.Equal(t, http.StatusNotFound, resp.Errors[0].Code)
+	})
+}
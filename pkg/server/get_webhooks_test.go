@@ -0,0 +1,62 @@
+package server_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/RedHatInsights/widget-layout-backend/api"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/database"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/test_util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/subpop/xrhidgen"
+)
+
+func TestGetWebhooks(t *testing.T) {
+	t.Run("should return the webhooks of the organization without their secrets", func(t *testing.T) {
+		server := setupRouter()
+
+		orgID := "org-" + test_util.GetUniqueUserID()
+		webhook := api.Webhook{OrgId: orgID, Url: "https://example.com/hook", Events: []api.WebhookEventType{}, Secret: "0123456789abcdef"}
+		require.NoError(t, database.DB.Create(&webhook).Error)
+		require.NoError(t, database.DB.Create(&api.Webhook{OrgId: "org-" + test_util.GetUniqueUserID(), Url: "https://example.com/other", Secret: "0123456789abcdef"}).Error)
+
+		req, _ := http.NewRequest("GET", "/webhooks", nil)
+		req = withCustomIdentityContext(req, test_util.GenerateIdentityStructFromTemplate(
+			xrhidgen.Identity{OrgID: stringPtr(orgID)},
+			xrhidgen.User{UserID: stringPtr(test_util.GetUniqueUserID()), IsOrgAdmin: test_util.BoolPTR(true)},
+			xrhidgen.Entitlements{},
+		))
+		w := httptest.NewRecorder()
+
+		server.GetWebhooks(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+		assert.NotContains(t, w.Body.String(), "0123456789abcdef")
+
+		var resp api.WebhookListResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		require.Len(t, resp.Data, 1)
+		assert.Equal(t, webhook.ID, resp.Data[0].ID)
+		assert.Equal(t, 1, resp.Meta.Count)
+	})
+
+	t.Run("should not return the webhooks to other members of the organization", func(t *testing.T) {
+		server := setupRouter()
+
+		req, _ := http.NewRequest("GET", "/webhooks", nil)
+		req = withCustomIdentityContext(req, test_util.GenerateIdentityStructFromTemplate(
+			xrhidgen.Identity{OrgID: stringPtr("org-" + test_util.GetUniqueUserID())},
+			xrhidgen.User{UserID: stringPtr(test_util.GetUniqueUserID()), IsOrgAdmin: test_util.BoolPTR(false)},
+			xrhidgen.Entitlements{},
+		))
+		w := httptest.NewRecorder()
+
+		server.GetWebhooks(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/RedHatInsights/widget-layout-backend/pkg/database"
 	"github.com/RedHatInsights/widget-layout-backend/pkg/test_util"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/subpop/xrhidgen"
 	"gorm.io/datatypes"
 )
@@ -70,6 +71,7 @@ func TestGetWidgetLayoutById(t *testing.T) {
 		assert.Equal(t, testTemplate.ID, parsedResp.ID, "Expected widget ID to match")
 		assert.Equal(t, testTemplate.UserId, parsedResp.UserId, "Expected user ID to match")
 		assert.Equal(t, testTemplate.TemplateConfig, parsedResp.TemplateConfig, "Expected template config to match")
+		assert.Equal(t, parsedResp.ETag(), w.Header().Get("ETag"), "ETag should be derived from the template version")
 	})
 
 	t.Run("should return 404 for non-existent widget ID", func(t *testing.T) {
@@ -210,4 +212,48 @@ func TestGetWidgetLayoutById(t *testing.T) {
 			assert.Equal(t, originalWidgets[0].WidgetType, responseWidgets[0].WidgetType, "Widget type should match")
 		}
 	})
+	t.Run("should not return widgets the user is not permitted to see", func(t *testing.T) {
+		testRegistries.WidgetMappings.Replace(nil)
+		t.Cleanup(func() { testRegistries.WidgetMappings.Replace(nil) })
+		testRegistries.AddWidgetMapping(api.WidgetModuleFederationMetadata{
+			Scope:  "landing",
+			Module: "./AdminWidget",
+			Config: api.WidgetConfiguration{
+				Title:       "Admin",
+				Permissions: &[]api.Permission{{Method: "isOrgAdmin"}},
+			},
+		})
+
+		server := setupRouter()
+		testUserID := test_util.GetUniqueUserID()
+		tm := datatypes.NewJSONType([]api.WidgetItem{
+			{Width: 1, Height: 2, X: test_util.IntPTR(0), Y: test_util.IntPTR(0), WidgetType: "landing-./AdminWidget"},
+			{Width: 1, Height: 2, X: test_util.IntPTR(0), Y: test_util.IntPTR(2), WidgetType: "widget2"},
+		})
+		testTemplate := test_util.MockDashboardTemplateWithSpecificUser(testUserID)
+		testTemplate.TemplateConfig = api.DashboardTemplateConfig{Lg: tm, Md: tm, Sm: tm, Xl: tm}
+		require.NoError(t, database.DB.Create(&testTemplate).Error)
+
+		isOrgAdmin := false
+		req, _ := http.NewRequest("GET", fmt.Sprintf("/%d", testTemplate.ID), nil)
+		req = withCustomIdentityContext(req, test_util.GenerateIdentityStructFromTemplate(
+			xrhidgen.Identity{},
+			xrhidgen.User{UserID: stringPtr(testUserID), IsOrgAdmin: &isOrgAdmin},
+			xrhidgen.Entitlements{},
+		))
+		w := httptest.NewRecorder()
+
+		server.GetWidgetLayoutById(w, req, int64(testTemplate.ID))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var parsedTemplate api.DashboardTemplate
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &parsedTemplate))
+		responseWidgets := parsedTemplate.TemplateConfig.Lg.Data()
+		require.Len(t, responseWidgets, 1)
+		assert.Equal(t, "widget2", responseWidgets[0].WidgetType)
+
+		var dbTemplate api.DashboardTemplate
+		require.NoError(t, database.DB.First(&dbTemplate, testTemplate.ID).Error)
+		assert.Len(t, dbTemplate.TemplateConfig.Lg.Data(), 2, "The stored layout should keep all widgets")
+	})
 }
@@ -7,16 +7,19 @@ import (
 	"testing"
 
 	"github.com/RedHatInsights/widget-layout-backend/api"
-	"github.com/RedHatInsights/widget-layout-backend/pkg/service"
 	"github.com/RedHatInsights/widget-layout-backend/pkg/test_util"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/subpop/xrhidgen"
 )
 
 func TestGetWidgetMapping(t *testing.T) {
+	// other tests validate layouts against the registry, leave it empty for them
+	t.Cleanup(func() { testRegistries.WidgetMappings.Replace(nil) })
+
 	t.Run("should return empty map when no widget mappings exist", func(t *testing.T) {
 		// Reset registry to ensure clean state
-		service.WidgetMappingRegistry = api.WidgetMappingRegistry{}
+		testRegistries.WidgetMappings.Replace(nil)
 
 		server := setupRouter()
 		req, _ := http.NewRequest("GET", "/widget-mapping", nil)
@@ -36,7 +39,7 @@ func TestGetWidgetMapping(t *testing.T) {
 
 	t.Run("should return all widget mappings when they exist", func(t *testing.T) {
 		// Reset registry and add test widget mappings
-		service.WidgetMappingRegistry = api.WidgetMappingRegistry{}
+		testRegistries.WidgetMappings.Replace(nil)
 
 		// Create test widget mappings
 		widget1 := api.WidgetModuleFederationMetadata{
@@ -70,8 +73,8 @@ func TestGetWidgetMapping(t *testing.T) {
 		}
 
 		// Add widgets to registry
-		service.WidgetMappingRegistry.AddWidgetMapping(widget1)
-		service.WidgetMappingRegistry.AddWidgetMapping(widget2)
+		testRegistries.AddWidgetMapping(widget1)
+		testRegistries.AddWidgetMapping(widget2)
 
 		server := setupRouter()
 		req, _ := http.NewRequest("GET", "/widget-mapping", nil)
@@ -108,7 +111,7 @@ func TestGetWidgetMapping(t *testing.T) {
 
 	t.Run("should return widget mapping with all optional fields", func(t *testing.T) {
 		// Reset registry
-		service.WidgetMappingRegistry = api.WidgetMappingRegistry{}
+		testRegistries.WidgetMappings.Replace(nil)
 
 		importName := "CustomComponent"
 		featureFlag := "enable-advanced-widgets"
@@ -140,7 +143,7 @@ func TestGetWidgetMapping(t *testing.T) {
 			},
 		}
 
-		service.WidgetMappingRegistry.AddWidgetMapping(widget)
+		testRegistries.AddWidgetMapping(widget)
 
 		server := setupRouter()
 		req, _ := http.NewRequest("GET", "/widget-mapping", nil)
@@ -184,7 +187,7 @@ func TestGetWidgetMapping(t *testing.T) {
 
 	t.Run("should handle complex widget keys correctly", func(t *testing.T) {
 		// Reset registry
-		service.WidgetMappingRegistry = api.WidgetMappingRegistry{}
+		testRegistries.WidgetMappings.Replace(nil)
 
 		// Create widgets with different key combinations
 		importName1 := "ImportedComponent"
@@ -219,8 +222,8 @@ func TestGetWidgetMapping(t *testing.T) {
 			},
 		}
 
-		service.WidgetMappingRegistry.AddWidgetMapping(widget1)
-		service.WidgetMappingRegistry.AddWidgetMapping(widget2)
+		testRegistries.AddWidgetMapping(widget1)
+		testRegistries.AddWidgetMapping(widget2)
 
 		server := setupRouter()
 		req, _ := http.NewRequest("GET", "/widget-mapping", nil)
@@ -256,7 +259,7 @@ func TestGetWidgetMapping(t *testing.T) {
 
 	t.Run("should return proper content type header", func(t *testing.T) {
 		// Reset registry
-		service.WidgetMappingRegistry = api.WidgetMappingRegistry{}
+		testRegistries.WidgetMappings.Replace(nil)
 
 		server := setupRouter()
 		req, _ := http.NewRequest("GET", "/widget-mapping", nil)
@@ -270,7 +273,7 @@ func TestGetWidgetMapping(t *testing.T) {
 
 	t.Run("should preserve widget mapping data integrity", func(t *testing.T) {
 		// Reset registry
-		service.WidgetMappingRegistry = api.WidgetMappingRegistry{}
+		testRegistries.WidgetMappings.Replace(nil)
 
 		// Add widget with specific data
 		widget := api.WidgetModuleFederationMetadata{
@@ -288,7 +291,7 @@ func TestGetWidgetMapping(t *testing.T) {
 			},
 		}
 
-		service.WidgetMappingRegistry.AddWidgetMapping(widget)
+		testRegistries.AddWidgetMapping(widget)
 
 		// Make multiple requests to ensure data consistency
 		server := setupRouter()
@@ -316,7 +319,7 @@ func TestGetWidgetMapping(t *testing.T) {
 
 	t.Run("should return overwritten widget when duplicate keys are added", func(t *testing.T) {
 		// Reset registry
-		service.WidgetMappingRegistry = api.WidgetMappingRegistry{}
+		testRegistries.WidgetMappings.Replace(nil)
 
 		// Create first widget
 		widget1 := api.WidgetModuleFederationMetadata{
@@ -351,7 +354,7 @@ func TestGetWidgetMapping(t *testing.T) {
 		}
 
 		// Add first widget
-		service.WidgetMappingRegistry.AddWidgetMapping(widget1)
+		testRegistries.AddWidgetMapping(widget1)
 
 		server := setupRouter()
 		req1, _ := http.NewRequest("GET", "/widget-mapping", nil)
@@ -371,7 +374,7 @@ func TestGetWidgetMapping(t *testing.T) {
 		assert.Equal(t, "Original Widget", retrievedWidget1.Config.Title, "Should have original widget")
 
 		// Add second widget with same key (overwrites first)
-		service.WidgetMappingRegistry.AddWidgetMapping(widget2)
+		testRegistries.AddWidgetMapping(widget2)
 
 		req2, _ := http.NewRequest("GET", "/widget-mapping", nil)
 		w2 := httptest.NewRecorder()
@@ -391,4 +394,43 @@ func TestGetWidgetMapping(t *testing.T) {
 		assert.Equal(t, 3, *retrievedWidget2.Defaults.Width, "Should have overwritten width")
 		assert.Equal(t, 3, *retrievedWidget2.Defaults.Height, "Should have overwritten height")
 	})
+	t.Run("should only return widget mappings the user is permitted to see", func(t *testing.T) {
+		testRegistries.WidgetMappings.Replace(nil)
+		testRegistries.AddWidgetMapping(api.WidgetModuleFederationMetadata{
+			Scope:  "landing",
+			Module: "./AdminWidget",
+			Config: api.WidgetConfiguration{
+				Title:       "Admin",
+				Permissions: &[]api.Permission{{Method: "isOrgAdmin"}},
+			},
+		})
+		testRegistries.AddWidgetMapping(api.WidgetModuleFederationMetadata{
+			Scope:  "landing",
+			Module: "./PublicWidget",
+			Config: api.WidgetConfiguration{Title: "Public"},
+		})
+
+		server := setupRouter()
+		for _, isOrgAdmin := range []bool{false, true} {
+			req, _ := http.NewRequest("GET", "/widget-mapping", nil)
+			req = withCustomIdentityContext(req, test_util.GenerateIdentityStructFromTemplate(
+				xrhidgen.Identity{},
+				xrhidgen.User{IsOrgAdmin: &isOrgAdmin},
+				xrhidgen.Entitlements{},
+			))
+			w := httptest.NewRecorder()
+
+			server.GetWidgetMapping(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			var response api.WidgetMappingResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Contains(t, response.Data, "landing-./PublicWidget")
+			if isOrgAdmin {
+				assert.Len(t, response.Data, 2, "Org admins should see the admin widget")
+			} else {
+				assert.Len(t, response.Data, 1, "Other users should not see the admin widget")
+			}
+		}
+	})
 }
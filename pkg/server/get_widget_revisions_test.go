@@ -0,0 +1,120 @@
+package server_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/RedHatInsights/widget-layout-backend/api"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/database"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/service"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/test_util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/subpop/xrhidgen"
+)
+
+func TestGetWidgetLayoutRevisionsById(t *testing.T) {
+	t.Run("should list revisions newest first", func(t *testing.T) {
+		server := setupRouter()
+
+		testUserID := test_util.GetUniqueUserID()
+		testIdentity := test_util.GenerateIdentityStructFromTemplate(
+			xrhidgen.Identity{},
+			xrhidgen.User{UserID: stringPtr(testUserID)},
+			xrhidgen.Entitlements{},
+		)
+		mockDashboard := test_util.MockDashboardTemplateWithSpecificUser(testUserID)
+		mockDashboard.DashboardName = "Original"
+		require.NoError(t, database.DB.Create(&mockDashboard).Error)
+
+		templateID := int64(mockDashboard.ID)
+		_, _, err := service.RenameDashboardTemplate(templateID, "First Rename", testIdentity, nil)
+		require.NoError(t, err)
+		_, _, err = service.RenameDashboardTemplate(templateID, "Second Rename", testIdentity, nil)
+		require.NoError(t, err)
+
+		req, _ := http.NewRequest("GET", fmt.Sprintf("/%d/revisions", templateID), nil)
+		req = withCustomIdentityContext(req, testIdentity)
+		w := httptest.NewRecorder()
+
+		server.GetWidgetLayoutRevisionsById(w, req, templateID)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+		var resp api.DashboardTemplateRevisionListResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		assert.Equal(t, 2, resp.Meta.Count)
+		require.Len(t, resp.Data, 2)
+		assert.Equal(t, "First Rename", resp.Data[0].DashboardName)
+		assert.Equal(t, "Original", resp.Data[1].DashboardName)
+		assert.Equal(t, mockDashboard.ID, resp.Data[0].DashboardTemplateId)
+	})
+
+	t.Run("should not return widgets the user is not permitted to see", func(t *testing.T) {
+		server := setupRouter()
+		mockDashboard, userIdentity := createHiddenWidgetDashboard(t)
+		templateID := int64(mockDashboard.ID)
+		_, _, err := service.RenameDashboardTemplate(templateID, "Renamed", userIdentity, nil)
+		require.NoError(t, err)
+
+		req := withCustomIdentityContext(httptest.NewRequest("GET", "/", nil), userIdentity)
+		w := httptest.NewRecorder()
+		server.GetWidgetLayoutRevisionsById(w, req, templateID)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		var resp api.DashboardTemplateRevisionListResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		require.Len(t, resp.Data, 1)
+		assertOnlyPublicWidget(t, resp.Data[0].TemplateConfig)
+	})
+
+	t.Run("should return empty list for template without revisions", func(t *testing.T) {
+		server := setupRouter()
+
+		req, userID := withUniqueUserIdentityContext(httptest.NewRequest("GET", "/", nil))
+		mockDashboard := test_util.MockDashboardTemplateWithSpecificUser(userID)
+		require.NoError(t, database.DB.Create(&mockDashboard).Error)
+
+		w := httptest.NewRecorder()
+		server.GetWidgetLayoutRevisionsById(w, req, int64(mockDashboard.ID))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var resp api.DashboardTemplateRevisionListResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		assert.Equal(t, 0, resp.Meta.Count)
+		assert.NotNil(t, resp.Data)
+	})
+
+	t.Run("should return 404 for non-existent template", func(t *testing.T) {
+		server := setupRouter()
+
+		req, _ := http.NewRequest("GET", fmt.Sprintf("/%d/revisions", test_util.NonExistentID), nil)
+		req = withIdentityContext(req)
+		w := httptest.NewRecorder()
+
+		server.GetWidgetLayoutRevisionsById(w, req, int64(test_util.NonExistentID))
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("should return 403 for unauthorized user", func(t *testing.T) {
+		server := setupRouter()
+
+		mockDashboard := test_util.MockDashboardTemplateWithSpecificUser(test_util.GetUniqueUserID())
+		require.NoError(t, database.DB.Create(&mockDashboard).Error)
+
+		req, _ := withUniqueUserIdentityContext(httptest.NewRequest("GET", "/", nil))
+		w := httptest.NewRecorder()
+
+		server.GetWidgetLayoutRevisionsById(w, req, int64(mockDashboard.ID))
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		var errorResponse api.ErrorResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&errorResponse))
+		assert.Contains(t, errorResponse.Errors[0].Message, "unauthorized")
+	})
+}
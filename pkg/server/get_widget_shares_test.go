@@ -0,0 +1,60 @@
+package server_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/RedHatInsights/widget-layout-backend/api"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/database"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/test_util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/subpop/xrhidgen"
+)
+
+func TestGetWidgetLayoutSharesById(t *testing.T) {
+	t.Run("should list the share links of a template", func(t *testing.T) {
+		server := setupRouter()
+
+		testUserID := test_util.GetUniqueUserID()
+		mockDashboard := test_util.MockDashboardTemplateWithSpecificUser(testUserID)
+		require.NoError(t, database.DB.Create(&mockDashboard).Error)
+		for i := range 2 {
+			share := api.DashboardTemplateShare{DashboardTemplateId: mockDashboard.ID, UserId: testUserID, Token: fmt.Sprintf("list-%s-%d", testUserID, i)}
+			require.NoError(t, database.DB.Create(&share).Error)
+		}
+
+		templateID := int64(mockDashboard.ID)
+		req, _ := http.NewRequest("GET", fmt.Sprintf("/%d/shares", templateID), nil)
+		req = withCustomIdentityContext(req, test_util.GenerateIdentityStructFromTemplate(
+			xrhidgen.Identity{},
+			xrhidgen.User{UserID: stringPtr(testUserID)},
+			xrhidgen.Entitlements{},
+		))
+		w := httptest.NewRecorder()
+
+		server.GetWidgetLayoutSharesById(w, req, templateID)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var listResp api.DashboardTemplateShareListResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&listResp))
+		require.Len(t, listResp.Data, 2)
+		assert.Equal(t, 2, listResp.Meta.Count)
+		assert.Greater(t, listResp.Data[0].ID, listResp.Data[1].ID, "Newest share links should come first")
+	})
+
+	t.Run("should return 404 for non-existent template", func(t *testing.T) {
+		server := setupRouter()
+
+		req, _ := http.NewRequest("GET", fmt.Sprintf("/%d/shares", test_util.NonExistentID), nil)
+		req = withIdentityContext(req)
+		w := httptest.NewRecorder()
+
+		server.GetWidgetLayoutSharesById(w, req, int64(test_util.NonExistentID))
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
@@ -0,0 +1,45 @@
+package server_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/RedHatInsights/widget-layout-backend/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetWidgetLayoutUpstreamChangesById(t *testing.T) {
+	t.Run("should preview the changes of the base template", func(t *testing.T) {
+		server := setupRouter()
+		fork, testIdentity := forkOutdatedTemplate(t, "upstream-server-base")
+
+		req := withCustomIdentityContext(httptest.NewRequest("GET", fmt.Sprintf("/%d/upstream-changes", fork.ID), nil), testIdentity)
+		w := httptest.NewRecorder()
+		server.GetWidgetLayoutUpstreamChangesById(w, req, int64(fork.ID))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var resp api.BaseTemplateChanges
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		assert.Equal(t, "upstream-server-base", resp.BaseTemplate)
+		assert.Equal(t, fork.BaseVersion, resp.FromVersion)
+		assert.False(t, resp.UpToDate)
+		assert.Equal(t, []string{"new-widget"}, resp.Added)
+		assert.Equal(t, []string{"old-widget"}, resp.Removed)
+		assert.Len(t, resp.TemplateConfig.Xl.Data(), 2)
+	})
+
+	t.Run("should return 403 for a template of another user", func(t *testing.T) {
+		server := setupRouter()
+		fork, _ := forkOutdatedTemplate(t, "upstream-server-other-base")
+
+		req, _ := withUniqueUserIdentityContext(httptest.NewRequest("GET", fmt.Sprintf("/%d/upstream-changes", fork.ID), nil))
+		w := httptest.NewRecorder()
+		server.GetWidgetLayoutUpstreamChangesById(w, req, int64(fork.ID))
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
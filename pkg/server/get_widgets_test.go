@@ -8,9 +8,9 @@ import (
 
 	"github.com/RedHatInsights/widget-layout-backend/api"
 	"github.com/RedHatInsights/widget-layout-backend/pkg/database"
-	"github.com/RedHatInsights/widget-layout-backend/pkg/service"
 	"github.com/RedHatInsights/widget-layout-backend/pkg/test_util"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/subpop/xrhidgen"
 	"gorm.io/datatypes"
 )
@@ -254,7 +254,7 @@ func TestGetWidgets(t *testing.T) {
 		)
 
 		// Reset and add a base template to the registry
-		service.BaseTemplateRegistry = api.BaseWidgetDashboardTemplateRegistry{}
+		testRegistries.BaseTemplates.Replace(nil)
 		baseTemplate := api.BaseWidgetDashboardTemplate{
 			Name:        "server-auto-test",
 			DisplayName: "Server Auto Test",
@@ -265,7 +265,7 @@ func TestGetWidgets(t *testing.T) {
 				Xl: datatypes.NewJSONType([]api.WidgetItem{}),
 			},
 		}
-		service.BaseTemplateRegistry.AddBase(baseTemplate)
+		testRegistries.AddBaseTemplate(baseTemplate)
 
 		// Test with dashboardType filter for base template (user has no templates)
 		req, _ := http.NewRequest("GET", "/?dashboardType=server-auto-test", nil)
@@ -285,6 +285,134 @@ func TestGetWidgets(t *testing.T) {
 		assert.Equal(t, testUserID, autoResp.Data[0].UserId)
 		assert.True(t, autoResp.Data[0].Default, "Auto-created template should be default")
 	})
+
+	t.Run("should return pagination meta and links to the adjacent pages", func(t *testing.T) {
+		server := setupRouter()
+		testUserID := test_util.GetUniqueUserID()
+		testIdentity := test_util.GenerateIdentityStructFromTemplate(
+			xrhidgen.Identity{},
+			xrhidgen.User{UserID: stringPtr(testUserID)},
+			xrhidgen.Entitlements{},
+		)
+		for range 5 {
+			template := createServerTestTemplate(testUserID, "paginated-server-dashboard")
+			database.DB.Create(&template)
+		}
+
+		req, _ := http.NewRequest("GET", "/api/widget-layout/v1/?dashboardType=paginated-server-dashboard&limit=2&offset=2", nil)
+		req = withCustomIdentityContext(req, testIdentity)
+		w := httptest.NewRecorder()
+
+		server.GetWidgetLayout(w, req, api.GetWidgetLayoutParams{DashboardType: stringPtr("paginated-server-dashboard"), Limit: intPtr(2), Offset: intPtr(2)})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var pageResp api.DashboardTemplateListResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &pageResp))
+		assert.Len(t, pageResp.Data, 2)
+		assert.Equal(t, 2, pageResp.Meta.Count)
+		assert.Equal(t, 5, *pageResp.Meta.Total)
+		assert.Equal(t, 2, *pageResp.Meta.Limit)
+		assert.Equal(t, 2, *pageResp.Meta.Offset)
+		require.NotNil(t, pageResp.Meta.Links)
+		assert.Equal(t, "/api/widget-layout/v1/?dashboardType=paginated-server-dashboard&limit=2&offset=4", *pageResp.Meta.Links.Next)
+		assert.Equal(t, "/api/widget-layout/v1/?dashboardType=paginated-server-dashboard&limit=2&offset=0", *pageResp.Meta.Links.Prev)
+
+		req, _ = http.NewRequest("GET", "/?dashboardType=paginated-server-dashboard&limit=2&offset=4", nil)
+		req = withCustomIdentityContext(req, testIdentity)
+		w = httptest.NewRecorder()
+
+		server.GetWidgetLayout(w, req, api.GetWidgetLayoutParams{DashboardType: stringPtr("paginated-server-dashboard"), Limit: intPtr(2), Offset: intPtr(4)})
+
+		var lastResp api.DashboardTemplateListResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &lastResp))
+		assert.Len(t, lastResp.Data, 1)
+		assert.Nil(t, lastResp.Meta.Links.Next, "The last page should not link to a next page")
+	})
+
+	t.Run("should only return summaries when fields is summary", func(t *testing.T) {
+		server := setupRouter()
+		testUserID := test_util.GetUniqueUserID()
+		testIdentity := test_util.GenerateIdentityStructFromTemplate(
+			xrhidgen.Identity{},
+			xrhidgen.User{UserID: stringPtr(testUserID)},
+			xrhidgen.Entitlements{},
+		)
+		template := createServerTestTemplate(testUserID, "summary-server-dashboard")
+		database.DB.Create(&template)
+
+		fields := api.FieldsSummary
+		req, _ := http.NewRequest("GET", "/?fields=summary", nil)
+		req = withCustomIdentityContext(req, testIdentity)
+		w := httptest.NewRecorder()
+
+		server.GetWidgetLayout(w, req, api.GetWidgetLayoutParams{Fields: &fields})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NotEmpty(t, w.Header().Get("ETag"))
+		assert.NotContains(t, w.Body.String(), "templateConfig")
+		var summaryResp api.DashboardTemplateSummaryListResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &summaryResp))
+		require.Len(t, summaryResp.Data, 1)
+		assert.Equal(t, template.ID, summaryResp.Data[0].ID)
+		assert.Equal(t, "summary-server-dashboard", summaryResp.Data[0].TemplateBase.Name)
+		assert.Equal(t, 1, summaryResp.Meta.Count)
+	})
+
+	t.Run("should list templates shared with the organization when scope is org", func(t *testing.T) {
+		server := setupRouter()
+		orgID := "org-" + test_util.GetUniqueUserID()
+		shared := createServerTestTemplate(test_util.GetUniqueUserID(), "shared-server-dashboard")
+		shared.OrgId = orgID
+		shared.Visibility = api.VisibilityOrg
+		require.NoError(t, database.DB.Create(&shared).Error)
+		private := createServerTestTemplate(test_util.GetUniqueUserID(), "shared-server-dashboard")
+		private.OrgId = orgID
+		require.NoError(t, database.DB.Create(&private).Error)
+
+		scope := api.ScopeOrg
+		req, _ := http.NewRequest("GET", "/?scope=org", nil)
+		req = withCustomIdentityContext(req, test_util.GenerateIdentityStructFromTemplate(
+			xrhidgen.Identity{OrgID: stringPtr(orgID)},
+			xrhidgen.User{UserID: stringPtr(test_util.GetUniqueUserID())},
+			xrhidgen.Entitlements{},
+		))
+		w := httptest.NewRecorder()
+
+		server.GetWidgetLayout(w, req, api.GetWidgetLayoutParams{Scope: &scope})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var listResp api.DashboardTemplateListResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &listResp))
+		require.Len(t, listResp.Data, 1)
+		assert.Equal(t, shared.ID, listResp.Data[0].ID)
+		assert.Equal(t, api.VisibilityOrg, listResp.Data[0].Visibility)
+	})
+
+	t.Run("should return 400 for unknown fields", func(t *testing.T) {
+		server := setupRouter()
+
+		fields := api.GetWidgetLayoutParamsFields("layouts")
+		req, _ := withUniqueUserIdentityContext(httptest.NewRequest("GET", "/?fields=layouts", nil))
+		w := httptest.NewRecorder()
+
+		server.GetWidgetLayout(w, req, api.GetWidgetLayoutParams{Fields: &fields})
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("should return 400 for a limit above the maximum", func(t *testing.T) {
+		server := setupRouter()
+
+		req, _ := withUniqueUserIdentityContext(httptest.NewRequest("GET", "/?limit=1000", nil))
+		w := httptest.NewRecorder()
+
+		server.GetWidgetLayout(w, req, api.GetWidgetLayoutParams{Limit: intPtr(1000)})
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		var errorResponse api.ErrorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &errorResponse))
+		require.NotEmpty(t, errorResponse.Errors)
+	})
 }
 
 // Helper function to create test template for server tests
@@ -0,0 +1,141 @@
+package server_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/RedHatInsights/widget-layout-backend/api"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/database"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/test_util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/subpop/xrhidgen"
+)
+
+func archiveTestTemplate(dashboardName string, isDefault bool) api.ArchivedDashboardTemplate {
+	template := bulkImportTemplate("archive-base")
+	return api.ArchivedDashboardTemplate{
+		DashboardName:  dashboardName,
+		TemplateBase:   template.TemplateBase,
+		TemplateConfig: template.TemplateConfig,
+		Default:        isDefault,
+	}
+}
+
+func TestImportDashboardArchive(t *testing.T) {
+	t.Run("should restore the templates of the archive", func(t *testing.T) {
+		server := setupRouter()
+		testUserID := test_util.GetUniqueUserID()
+		existing := test_util.MockDashboardTemplateWithSpecificUser(testUserID)
+		existing.TemplateBase.Name = "archive-base"
+		existing.DashboardName = "Operations"
+		require.NoError(t, database.DB.Create(&existing).Error)
+
+		body, _ := json.Marshal(api.DashboardArchive{
+			SchemaVersion: api.DashboardArchiveSchemaVersion,
+			Templates:     []api.ArchivedDashboardTemplate{archiveTestTemplate("Operations", false), archiveTestTemplate("Security", true)},
+		})
+		req, _ := http.NewRequest("POST", "/import/archive?conflict=rename", bytes.NewReader(body))
+		req = withCustomIdentityContext(req, test_util.GenerateIdentityStructFromTemplate(
+			xrhidgen.Identity{},
+			xrhidgen.User{UserID: stringPtr(testUserID)},
+			xrhidgen.Entitlements{},
+		))
+		w := httptest.NewRecorder()
+
+		rename := api.ArchiveConflictRename
+		server.ImportDashboardArchive(w, req, api.ImportDashboardArchiveParams{Conflict: &rename})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+		var resp api.ArchiveImportResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		assert.Equal(t, api.ArchiveImportMeta{Count: 2, Created: 1, Renamed: 1}, resp.Meta)
+		require.Len(t, resp.Data, 2)
+		assert.Equal(t, api.ArchiveImportRenamed, resp.Data[0].Action)
+		require.NotNil(t, resp.Data[0].ConflictingTemplateId)
+		assert.Equal(t, existing.ID, *resp.Data[0].ConflictingTemplateId)
+		require.NotNil(t, resp.Data[0].DashboardTemplate)
+		assert.Equal(t, "Operations (2)", resp.Data[0].DashboardTemplate.DashboardName)
+		assert.Equal(t, api.ArchiveImportCreated, resp.Data[1].Action)
+		require.NotNil(t, resp.Data[1].DashboardTemplate)
+		assert.NotZero(t, resp.Data[1].DashboardTemplate.ID)
+		assert.True(t, resp.Data[1].DashboardTemplate.Default)
+	})
+
+	t.Run("should report the plan without storing anything in a dry run", func(t *testing.T) {
+		server := setupRouter()
+		testUserID := test_util.GetUniqueUserID()
+
+		body, _ := json.Marshal(api.DashboardArchive{
+			SchemaVersion: api.DashboardArchiveSchemaVersion,
+			Templates:     []api.ArchivedDashboardTemplate{archiveTestTemplate("Operations", false)},
+		})
+		req, _ := http.NewRequest("POST", "/import/archive?dryRun=true", bytes.NewReader(body))
+		req = withCustomIdentityContext(req, test_util.GenerateIdentityStructFromTemplate(
+			xrhidgen.Identity{},
+			xrhidgen.User{UserID: stringPtr(testUserID)},
+			xrhidgen.Entitlements{},
+		))
+		w := httptest.NewRecorder()
+
+		server.ImportDashboardArchive(w, req, api.ImportDashboardArchiveParams{DryRun: test_util.BoolPTR(true)})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var resp api.ArchiveImportResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		assert.Equal(t, api.ArchiveImportMeta{Count: 1, Created: 1, DryRun: true}, resp.Meta)
+
+		var stored int64
+		require.NoError(t, database.DB.Model(&api.DashboardTemplate{}).Where("user_id = ?", testUserID).Count(&stored).Error)
+		assert.Zero(t, stored)
+	})
+
+	t.Run("should report every template when an archive has invalid templates", func(t *testing.T) {
+		server := setupRouter()
+
+		invalid := archiveTestTemplate("Invalid", false)
+		invalid.TemplateBase.Name = ""
+		body, _ := json.Marshal(api.DashboardArchive{
+			SchemaVersion: api.DashboardArchiveSchemaVersion,
+			Templates:     []api.ArchivedDashboardTemplate{archiveTestTemplate("Operations", false), invalid},
+		})
+		req, _ := http.NewRequest("POST", "/import/archive", bytes.NewReader(body))
+		req = withIdentityContext(req)
+		w := httptest.NewRecorder()
+
+		server.ImportDashboardArchive(w, req, api.ImportDashboardArchiveParams{})
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+		var resp api.ArchiveImportResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		assert.Equal(t, 1, resp.Meta.Failed)
+		require.Len(t, resp.Data, 2)
+		assert.Equal(t, api.ArchiveImportFailed, resp.Data[1].Action)
+		require.NotNil(t, resp.Data[1].Errors)
+		assert.Equal(t, http.StatusBadRequest, (*resp.Data[1].Errors)[0].Code)
+	})
+
+	t.Run("should return 400 for unsupported schema versions", func(t *testing.T) {
+		server := setupRouter()
+
+		body, _ := json.Marshal(api.DashboardArchive{SchemaVersion: api.DashboardArchiveSchemaVersion + 1})
+		req, _ := http.NewRequest("POST", "/import/archive", bytes.NewReader(body))
+		req = withIdentityContext(req)
+		w := httptest.NewRecorder()
+
+		server.ImportDashboardArchive(w, req, api.ImportDashboardArchiveParams{})
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		var errorResponse api.ErrorResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&errorResponse))
+		assert.Contains(t, errorResponse.Errors[0].Message, "not supported")
+	})
+}
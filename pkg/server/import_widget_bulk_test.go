@@ -0,0 +1,143 @@
+package server_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/RedHatInsights/widget-layout-backend/api"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/test_util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/subpop/xrhidgen"
+	"gorm.io/datatypes"
+)
+
+func bulkImportTemplate(name string) api.ImportWidgetDashboardTemplateRequest {
+	layout := datatypes.NewJSONType([]api.WidgetItem{
+		{Width: 1, Height: 2, X: test_util.IntPTR(0), Y: test_util.IntPTR(0), WidgetType: "import-widget"},
+	})
+	return api.ImportWidgetDashboardTemplateRequest{
+		DashboardName:  "Imported Dashboard",
+		TemplateBase:   api.DashboardTemplateBase{Name: name, DisplayName: "Imported Dashboard"},
+		TemplateConfig: api.DashboardTemplateConfig{Sm: layout, Md: layout, Lg: layout, Xl: layout},
+	}
+}
+
+func TestImportWidgetLayoutBulk(t *testing.T) {
+	t.Run("should import a JSON array of templates", func(t *testing.T) {
+		server := setupRouter()
+		testUserID := test_util.GetUniqueUserID()
+
+		body, _ := json.Marshal([]api.ImportWidgetDashboardTemplateRequest{bulkImportTemplate("bulk-a"), bulkImportTemplate("bulk-b")})
+		req, _ := http.NewRequest("POST", "/import/bulk", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req = withCustomIdentityContext(req, test_util.GenerateIdentityStructFromTemplate(
+			xrhidgen.Identity{},
+			xrhidgen.User{UserID: stringPtr(testUserID)},
+			xrhidgen.Entitlements{},
+		))
+		w := httptest.NewRecorder()
+
+		server.ImportWidgetLayoutBulk(w, req, api.ImportWidgetLayoutBulkParams{})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+		var resp api.BulkImportResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		assert.Equal(t, api.BulkImportMeta{Count: 2, Imported: 2, Failed: 0}, resp.Meta)
+		require.Len(t, resp.Data, 2)
+		for i, name := range []string{"bulk-a", "bulk-b"} {
+			assert.Equal(t, i, resp.Data[i].Index)
+			assert.Equal(t, api.BulkImportItemImported, resp.Data[i].Status)
+			require.NotNil(t, resp.Data[i].DashboardTemplate)
+			assert.Equal(t, name, resp.Data[i].DashboardTemplate.TemplateBase.Name)
+			assert.Equal(t, testUserID, resp.Data[i].DashboardTemplate.UserId)
+		}
+	})
+
+	t.Run("should import an NDJSON stream of templates in best-effort mode", func(t *testing.T) {
+		server := setupRouter()
+
+		var body bytes.Buffer
+		encoder := json.NewEncoder(&body)
+		for _, name := range []string{"bulk-a", "", "bulk-c"} {
+			require.NoError(t, encoder.Encode(bulkImportTemplate(name)))
+		}
+		req, _ := http.NewRequest("POST", "/import/bulk?mode=best-effort", &body)
+		req.Header.Set("Content-Type", "application/x-ndjson")
+		req = withIdentityContext(req)
+		w := httptest.NewRecorder()
+
+		bestEffort := api.BulkImportBestEffort
+		server.ImportWidgetLayoutBulk(w, req, api.ImportWidgetLayoutBulkParams{Mode: &bestEffort})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var resp api.BulkImportResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		assert.Equal(t, api.BulkImportMeta{Count: 3, Imported: 2, Failed: 1}, resp.Meta)
+		require.Len(t, resp.Data, 3)
+		assert.Equal(t, api.BulkImportItemImported, resp.Data[0].Status)
+		assert.Equal(t, api.BulkImportItemFailed, resp.Data[1].Status)
+		require.NotNil(t, resp.Data[1].Errors)
+		assert.Equal(t, http.StatusBadRequest, (*resp.Data[1].Errors)[0].Code)
+		assert.Nil(t, resp.Data[1].DashboardTemplate)
+		assert.Equal(t, api.BulkImportItemImported, resp.Data[2].Status)
+	})
+
+	t.Run("should report every template when an atomic import is rejected", func(t *testing.T) {
+		server := setupRouter()
+
+		body, _ := json.Marshal([]api.ImportWidgetDashboardTemplateRequest{bulkImportTemplate("bulk-a"), bulkImportTemplate("")})
+		req, _ := http.NewRequest("POST", "/import/bulk", bytes.NewReader(body))
+		req = withIdentityContext(req)
+		w := httptest.NewRecorder()
+
+		server.ImportWidgetLayoutBulk(w, req, api.ImportWidgetLayoutBulkParams{})
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+		var resp api.BulkImportResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		assert.Equal(t, api.BulkImportMeta{Count: 2, Imported: 0, Failed: 1}, resp.Meta)
+		require.Len(t, resp.Data, 2)
+		assert.Equal(t, api.BulkImportItemSkipped, resp.Data[0].Status)
+		assert.Nil(t, resp.Data[0].DashboardTemplate)
+		assert.Equal(t, api.BulkImportItemFailed, resp.Data[1].Status)
+	})
+
+	t.Run("should return 400 for invalid request body", func(t *testing.T) {
+		server := setupRouter()
+
+		req, _ := http.NewRequest("POST", "/import/bulk", bytes.NewReader([]byte(`{"not": "an array"}`)))
+		req = withIdentityContext(req)
+		w := httptest.NewRecorder()
+
+		server.ImportWidgetLayoutBulk(w, req, api.ImportWidgetLayoutBulkParams{})
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "Invalid request body")
+	})
+
+	t.Run("should report the template which cannot be upgraded", func(t *testing.T) {
+		server := setupRouter()
+
+		template, _ := json.Marshal(bulkImportTemplate("bulk-version"))
+		body := `[` + string(template) + `, {"schemaVersion": 99}]`
+		req, _ := http.NewRequest("POST", "/import/bulk", bytes.NewReader([]byte(body)))
+		req = withIdentityContext(req)
+		w := httptest.NewRecorder()
+
+		server.ImportWidgetLayoutBulk(w, req, api.ImportWidgetLayoutBulkParams{})
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		var errorResponse api.ErrorResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&errorResponse))
+		require.Len(t, errorResponse.Errors, 1)
+		assert.Contains(t, errorResponse.Errors[0].Message, "template 1: schema version 99 is not supported")
+	})
+}
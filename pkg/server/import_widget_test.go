@@ -60,7 +60,7 @@ func TestImportWidgetLayoutById(t *testing.T) {
 		))
 		w := httptest.NewRecorder()
 
-		server.ImportWidgetLayout(w, req)
+		server.ImportWidgetLayout(w, req, api.ImportWidgetLayoutParams{})
 
 		// Verify response
 		assert.Equal(t, http.StatusOK, w.Code, "Expected status code 200 for successful import")
@@ -137,7 +137,7 @@ func TestImportWidgetLayoutById(t *testing.T) {
 		))
 		w := httptest.NewRecorder()
 
-		server.ImportWidgetLayout(w, req)
+		server.ImportWidgetLayout(w, req, api.ImportWidgetLayoutParams{})
 
 		assert.Equal(t, http.StatusBadRequest, w.Code, "Expected status code 400 for invalid data")
 		assert.Equal(t, "application/json", w.Header().Get("Content-Type"), "Content-Type should be application/json")
@@ -190,7 +190,7 @@ func TestImportWidgetLayoutById(t *testing.T) {
 		))
 		w := httptest.NewRecorder()
 
-		server.ImportWidgetLayout(w, req)
+		server.ImportWidgetLayout(w, req, api.ImportWidgetLayoutParams{})
 
 		assert.Equal(t, http.StatusBadRequest, w.Code, "Expected status code 400 for invalid data")
 		assert.Equal(t, "application/json", w.Header().Get("Content-Type"), "Content-Type should be application/json")
@@ -243,7 +243,7 @@ func TestImportWidgetLayoutById(t *testing.T) {
 		))
 		w := httptest.NewRecorder()
 
-		server.ImportWidgetLayout(w, req)
+		server.ImportWidgetLayout(w, req, api.ImportWidgetLayoutParams{})
 
 		assert.Equal(t, http.StatusBadRequest, w.Code, "Expected status code 400 for invalid data")
 		assert.Equal(t, "application/json", w.Header().Get("Content-Type"), "Content-Type should be application/json")
@@ -296,7 +296,7 @@ func TestImportWidgetLayoutById(t *testing.T) {
 		))
 		w := httptest.NewRecorder()
 
-		server.ImportWidgetLayout(w, req)
+		server.ImportWidgetLayout(w, req, api.ImportWidgetLayoutParams{})
 
 		assert.Equal(t, http.StatusBadRequest, w.Code, "Expected status code 400 for invalid data")
 		assert.Equal(t, "application/json", w.Header().Get("Content-Type"), "Content-Type should be application/json")
@@ -349,7 +349,7 @@ func TestImportWidgetLayoutById(t *testing.T) {
 		))
 		w := httptest.NewRecorder()
 
-		server.ImportWidgetLayout(w, req)
+		server.ImportWidgetLayout(w, req, api.ImportWidgetLayoutParams{})
 
 		assert.Equal(t, http.StatusBadRequest, w.Code, "Expected status code 400 for invalid data")
 		assert.Equal(t, "application/json", w.Header().Get("Content-Type"), "Content-Type should be application/json")
@@ -402,7 +402,7 @@ func TestImportWidgetLayoutById(t *testing.T) {
 		))
 		w := httptest.NewRecorder()
 
-		server.ImportWidgetLayout(w, req)
+		server.ImportWidgetLayout(w, req, api.ImportWidgetLayoutParams{})
 
 		assert.Equal(t, http.StatusBadRequest, w.Code, "Expected status code 400 for invalid data")
 		assert.Equal(t, "application/json", w.Header().Get("Content-Type"), "Content-Type should be application/json")
@@ -455,7 +455,7 @@ func TestImportWidgetLayoutById(t *testing.T) {
 		))
 		w := httptest.NewRecorder()
 
-		server.ImportWidgetLayout(w, req)
+		server.ImportWidgetLayout(w, req, api.ImportWidgetLayoutParams{})
 
 		assert.Equal(t, http.StatusBadRequest, w.Code, "Expected status code 400 for invalid data")
 		assert.Equal(t, "application/json", w.Header().Get("Content-Type"), "Content-Type should be application/json")
@@ -508,7 +508,7 @@ func TestImportWidgetLayoutById(t *testing.T) {
 		))
 		w := httptest.NewRecorder()
 
-		server.ImportWidgetLayout(w, req)
+		server.ImportWidgetLayout(w, req, api.ImportWidgetLayoutParams{})
 
 		assert.Equal(t, http.StatusBadRequest, w.Code, "Expected status code 400 for invalid data")
 		assert.Equal(t, "application/json", w.Header().Get("Content-Type"), "Content-Type should be application/json")
@@ -561,7 +561,7 @@ func TestImportWidgetLayoutById(t *testing.T) {
 		))
 		w := httptest.NewRecorder()
 
-		server.ImportWidgetLayout(w, req)
+		server.ImportWidgetLayout(w, req, api.ImportWidgetLayoutParams{})
 
 		assert.Equal(t, http.StatusBadRequest, w.Code, "Expected status code 400 for invalid data")
 		assert.Equal(t, "application/json", w.Header().Get("Content-Type"), "Content-Type should be application/json")
@@ -614,7 +614,7 @@ func TestImportWidgetLayoutById(t *testing.T) {
 		))
 		w := httptest.NewRecorder()
 
-		server.ImportWidgetLayout(w, req)
+		server.ImportWidgetLayout(w, req, api.ImportWidgetLayoutParams{})
 
 		assert.Equal(t, http.StatusBadRequest, w.Code, "Expected status code 400 for invalid data")
 		assert.Equal(t, "application/json", w.Header().Get("Content-Type"), "Content-Type should be application/json")
@@ -667,7 +667,7 @@ func TestImportWidgetLayoutById(t *testing.T) {
 		))
 		w := httptest.NewRecorder()
 
-		server.ImportWidgetLayout(w, req)
+		server.ImportWidgetLayout(w, req, api.ImportWidgetLayoutParams{})
 
 		assert.Equal(t, http.StatusBadRequest, w.Code, "Expected status code 400 for invalid data")
 		assert.Equal(t, "application/json", w.Header().Get("Content-Type"), "Content-Type should be application/json")
@@ -720,7 +720,7 @@ func TestImportWidgetLayoutById(t *testing.T) {
 		))
 		w := httptest.NewRecorder()
 
-		server.ImportWidgetLayout(w, req)
+		server.ImportWidgetLayout(w, req, api.ImportWidgetLayoutParams{})
 
 		assert.Equal(t, http.StatusBadRequest, w.Code, "Expected status code 400 for invalid data")
 		assert.Equal(t, "application/json", w.Header().Get("Content-Type"), "Content-Type should be application/json")
@@ -773,7 +773,7 @@ func TestImportWidgetLayoutById(t *testing.T) {
 		))
 		w := httptest.NewRecorder()
 
-		server.ImportWidgetLayout(w, req)
+		server.ImportWidgetLayout(w, req, api.ImportWidgetLayoutParams{})
 
 		assert.Equal(t, http.StatusBadRequest, w.Code, "Expected status code 400 for invalid data")
 		assert.Equal(t, "application/json", w.Header().Get("Content-Type"), "Content-Type should be application/json")
@@ -792,7 +792,7 @@ func TestImportWidgetLayoutById(t *testing.T) {
 		req = withIdentityContext(req)
 		w := httptest.NewRecorder()
 
-		server.ImportWidgetLayout(w, req)
+		server.ImportWidgetLayout(w, req, api.ImportWidgetLayoutParams{})
 
 		assert.Equal(t, http.StatusBadRequest, w.Code, "Expected status code 400 for empty body")
 	})
@@ -808,8 +808,137 @@ func TestImportWidgetLayoutById(t *testing.T) {
 		req = withIdentityContext(req)
 		w := httptest.NewRecorder()
 
-		server.ImportWidgetLayout(w, req)
+		server.ImportWidgetLayout(w, req, api.ImportWidgetLayoutParams{})
 
 		assert.Equal(t, http.StatusBadRequest, w.Code, "Expected status code 400 for malformed JSON")
 	})
+
+	t.Run("should compact the imported layouts when requested", func(t *testing.T) {
+		server := setupRouter()
+
+		overlapping := datatypes.NewJSONType([]api.WidgetItem{
+			{Width: 1, Height: 4, X: test_util.IntPTR(0), Y: test_util.IntPTR(0), WidgetType: "widget1"},
+			{Width: 1, Height: 4, X: test_util.IntPTR(0), Y: test_util.IntPTR(1), WidgetType: "widget2"},
+		})
+		importData := api.ExportWidgetDashboardTemplateResponse{
+			TemplateBase: api.DashboardTemplateBase{
+				Name:        "imported-dashboard",
+				DisplayName: "Imported Dashboard",
+			},
+			TemplateConfig: api.DashboardTemplateConfig{Sm: overlapping, Md: overlapping, Lg: overlapping, Xl: overlapping},
+		}
+		requestBody, err := json.Marshal(importData)
+		require.NoError(t, err)
+
+		// without compaction the overlapping widgets are rejected
+		req, _ := withUniqueUserIdentityContext(httptest.NewRequest("POST", "/import", bytes.NewReader(requestBody)))
+		w := httptest.NewRecorder()
+		server.ImportWidgetLayout(w, req, api.ImportWidgetLayoutParams{})
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		compact := true
+		req, _ = withUniqueUserIdentityContext(httptest.NewRequest("POST", "/import?compact=true", bytes.NewReader(requestBody)))
+		w = httptest.NewRecorder()
+		server.ImportWidgetLayout(w, req, api.ImportWidgetLayoutParams{Compact: &compact})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var importedTemplate api.DashboardTemplate
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&importedTemplate))
+		lg := importedTemplate.TemplateConfig.Lg.Data()
+		require.Len(t, lg, 2)
+		assert.Equal(t, 4, *lg[1].Y, "Second widget should be pushed below the first one")
+	})
+
+	t.Run("should validate widgets against the widget mapping registry", func(t *testing.T) {
+		server := setupRouter()
+		testRegistries.WidgetMappings.Replace(nil)
+		t.Cleanup(func() { testRegistries.WidgetMappings.Replace(nil) })
+		testRegistries.AddWidgetMapping(api.WidgetModuleFederationMetadata{
+			Scope:    "landing",
+			Module:   "./RhelWidget",
+			Config:   api.WidgetConfiguration{Title: "RHEL"},
+			Defaults: api.WidgetBaseDimensions{Width: test_util.IntPTR(1), Height: test_util.IntPTR(2)},
+		})
+
+		body := `{
+			"dashboardName": "Imported Dashboard",
+			"templateBase": {"name": "imported-dashboard", "displayName": "Imported Dashboard"},
+			"templateConfig": {"xl": [
+				{"w": 1, "h": 2, "x": 0, "y": 0, "i": "landing-./RhelWidget"},
+				{"w": 1, "h": 2, "x": 1, "y": 0, "i": "landing-./RemovedWidget"}
+			]}
+		}`
+
+		req, _ := withUniqueUserIdentityContext(httptest.NewRequest("POST", "/import", strings.NewReader(body)))
+		w := httptest.NewRecorder()
+		server.ImportWidgetLayout(w, req, api.ImportWidgetLayoutParams{})
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		var errorResponse api.ErrorResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&errorResponse))
+		assert.Len(t, errorResponse.Errors, 4, "Expected one error per layout")
+
+		lenient := api.ValidationModeLenient
+		req, _ = withUniqueUserIdentityContext(httptest.NewRequest("POST", "/import?validationMode=lenient", strings.NewReader(body)))
+		w = httptest.NewRecorder()
+		server.ImportWidgetLayout(w, req, api.ImportWidgetLayoutParams{ValidationMode: &lenient})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var importedTemplate api.DashboardTemplate
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&importedTemplate))
+		assert.Len(t, importedTemplate.Warnings, 4)
+		assert.Len(t, importedTemplate.TemplateConfig.Xl.Data(), 1)
+	})
+
+	t.Run("should upgrade templates exported before exports were versioned", func(t *testing.T) {
+		server := setupRouter()
+		testRegistries.WidgetMappings.Replace(nil)
+		t.Cleanup(func() { testRegistries.WidgetMappings.Replace(nil) })
+		testRegistries.AddWidgetMapping(api.WidgetModuleFederationMetadata{
+			Scope:    "landing",
+			Module:   "./RhelWidget",
+			Config:   api.WidgetConfiguration{Title: "RHEL"},
+			Defaults: api.WidgetBaseDimensions{Width: test_util.IntPTR(1), Height: test_util.IntPTR(2)},
+		})
+
+		body := `{
+			"dashboardName": "Imported Dashboard",
+			"templateBase": {"name": "imported-dashboard", "displayName": "Imported Dashboard"},
+			"templateConfig": {"xl": [{"w": 1, "h": 2, "cx": 1, "cy": 0, "i": "rhel#rhel"}]}
+		}`
+
+		req, _ := withUniqueUserIdentityContext(httptest.NewRequest("POST", "/import", strings.NewReader(body)))
+		w := httptest.NewRecorder()
+		server.ImportWidgetLayout(w, req, api.ImportWidgetLayoutParams{})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var importedTemplate api.DashboardTemplate
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&importedTemplate))
+		xl := importedTemplate.TemplateConfig.Xl.Data()
+		require.Len(t, xl, 1)
+		assert.Equal(t, "landing-./RhelWidget", xl[0].WidgetType)
+		assert.Equal(t, 1, *xl[0].X)
+		assert.Equal(t, 0, *xl[0].Y)
+	})
+
+	t.Run("should return 400 for templates of an unsupported schema version", func(t *testing.T) {
+		server := setupRouter()
+
+		body := `{
+			"schemaVersion": 99,
+			"dashboardName": "Imported Dashboard",
+			"templateBase": {"name": "imported-dashboard", "displayName": "Imported Dashboard"},
+			"templateConfig": {"xl": [{"w": 1, "h": 2, "x": 0, "y": 0, "i": "landing-./RhelWidget"}]}
+		}`
+
+		req, _ := withUniqueUserIdentityContext(httptest.NewRequest("POST", "/import", strings.NewReader(body)))
+		w := httptest.NewRecorder()
+		server.ImportWidgetLayout(w, req, api.ImportWidgetLayoutParams{})
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		var errorResponse api.ErrorResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&errorResponse))
+		require.Len(t, errorResponse.Errors, 1)
+		assert.Contains(t, errorResponse.Errors[0].Message, "schema version 99 is not supported")
+	})
 }
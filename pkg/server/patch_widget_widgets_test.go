@@ -0,0 +1,118 @@
+package server_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/RedHatInsights/widget-layout-backend/api"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/database"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/test_util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+)
+
+func createPatchableDashboard(t *testing.T, userID string) api.DashboardTemplate {
+	items := datatypes.NewJSONType([]api.WidgetItem{
+		{Width: 1, Height: 2, X: intPtr(0), Y: intPtr(0), WidgetType: "widget1"},
+	})
+	mockDashboard := test_util.MockDashboardTemplateWithSpecificUser(userID)
+	mockDashboard.TemplateConfig = api.DashboardTemplateConfig{Sm: items, Md: items, Lg: items, Xl: items}
+	require.NoError(t, database.DB.Create(&mockDashboard).Error)
+	return mockDashboard
+}
+
+func TestPatchWidgetLayoutWidgetsById(t *testing.T) {
+	t.Run("should apply widget operations", func(t *testing.T) {
+		server := setupRouter()
+
+		body := `{"operations": [
+			{"op": "add", "breakpoint": "xl", "widget": {"w": 2, "h": 1, "x": 1, "y": 0, "i": "widget2"}},
+			{"op": "move", "breakpoint": "xl", "widgetType": "widget1", "x": 0, "y": 1}
+		]}`
+		req, userID := withUniqueUserIdentityContext(httptest.NewRequest("PATCH", "/", strings.NewReader(body)))
+		mockDashboard := createPatchableDashboard(t, userID)
+		templateID := int64(mockDashboard.ID)
+		w := httptest.NewRecorder()
+		server.PatchWidgetLayoutWidgetsById(w, req, templateID, api.PatchWidgetLayoutWidgetsByIdParams{})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NotEmpty(t, w.Header().Get("ETag"))
+		var resp api.DashboardTemplate
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		xl := resp.TemplateConfig.Xl.Data()
+		require.Len(t, xl, 2)
+		assert.Equal(t, 1, *xl[0].Y)
+		assert.Equal(t, "widget2", xl[1].WidgetType)
+		assert.Len(t, resp.TemplateConfig.Sm.Data(), 1, "Other layouts should not change")
+	})
+
+	t.Run("should not return widgets the user is not permitted to see", func(t *testing.T) {
+		server := setupRouter()
+		mockDashboard, userIdentity := createHiddenWidgetDashboard(t)
+
+		body := `{"operations": [{"op": "resize", "breakpoint": "lg", "widgetType": "landing-./PublicWidget", "height": 3}]}`
+		req := withCustomIdentityContext(httptest.NewRequest("PATCH", "/", strings.NewReader(body)), userIdentity)
+		w := httptest.NewRecorder()
+		server.PatchWidgetLayoutWidgetsById(w, req, int64(mockDashboard.ID), api.PatchWidgetLayoutWidgetsByIdParams{})
+
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var resp api.DashboardTemplate
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		assertOnlyPublicWidget(t, resp.TemplateConfig)
+		assert.Equal(t, 3, resp.TemplateConfig.Lg.Data()[0].Height)
+	})
+
+	t.Run("should return 400 for an invalid operation", func(t *testing.T) {
+		server := setupRouter()
+
+		req, userID := withUniqueUserIdentityContext(httptest.NewRequest("PATCH", "/", strings.NewReader(`{"operations": [{"op": "remove", "widgetType": "missing"}]}`)))
+		mockDashboard := createPatchableDashboard(t, userID)
+
+		w := httptest.NewRecorder()
+		server.PatchWidgetLayoutWidgetsById(w, req, int64(mockDashboard.ID), api.PatchWidgetLayoutWidgetsByIdParams{})
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		var errorResponse api.ErrorResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&errorResponse))
+		require.NotEmpty(t, errorResponse.Errors)
+		assert.Contains(t, errorResponse.Errors[0].Message, "missing")
+	})
+
+	t.Run("should return 400 for an empty operation list", func(t *testing.T) {
+		server := setupRouter()
+
+		req, _ := withUniqueUserIdentityContext(httptest.NewRequest("PATCH", fmt.Sprintf("/%d/widgets", test_util.NoDBTestID), strings.NewReader(`{"operations": []}`)))
+		w := httptest.NewRecorder()
+		server.PatchWidgetLayoutWidgetsById(w, req, int64(test_util.NoDBTestID), api.PatchWidgetLayoutWidgetsByIdParams{})
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("should return 412 for a stale If-Match", func(t *testing.T) {
+		server := setupRouter()
+
+		req, userID := withUniqueUserIdentityContext(httptest.NewRequest("PATCH", "/", strings.NewReader(`{"operations": [{"op": "remove", "widgetType": "widget1"}]}`)))
+		mockDashboard := createPatchableDashboard(t, userID)
+		staleETag := `"0-0"`
+
+		w := httptest.NewRecorder()
+		server.PatchWidgetLayoutWidgetsById(w, req, int64(mockDashboard.ID), api.PatchWidgetLayoutWidgetsByIdParams{IfMatch: &staleETag})
+
+		assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	})
+
+	t.Run("should return 404 for non-existent template", func(t *testing.T) {
+		server := setupRouter()
+
+		req, _ := withUniqueUserIdentityContext(httptest.NewRequest("PATCH", "/", strings.NewReader(`{"operations": [{"op": "remove", "widgetType": "widget1"}]}`)))
+		w := httptest.NewRecorder()
+		server.PatchWidgetLayoutWidgetsById(w, req, int64(test_util.NonExistentID), api.PatchWidgetLayoutWidgetsByIdParams{})
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
@@ -0,0 +1,98 @@
+package server_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/RedHatInsights/widget-layout-backend/api"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/test_util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/subpop/xrhidgen"
+	"gorm.io/datatypes"
+)
+
+func TestPutOrgBaseWidgetDashboardTemplateByName(t *testing.T) {
+	t.Run("should store the base template of the organization", func(t *testing.T) {
+		server := setupRouter()
+		testRegistries.WidgetMappings.Replace(nil)
+		t.Cleanup(func() { testRegistries.WidgetMappings.Replace(nil) })
+		testRegistries.AddWidgetMapping(api.WidgetModuleFederationMetadata{
+			Scope:  "landing",
+			Module: "./RhelWidget",
+			Config: api.WidgetConfiguration{Title: "RHEL"},
+			Defaults: api.WidgetBaseDimensions{
+				Width:     test_util.IntPTR(1),
+				Height:    test_util.IntPTR(2),
+				MaxHeight: test_util.IntPTR(4),
+				MinHeight: test_util.IntPTR(1),
+			},
+		})
+
+		orgID := "org-" + test_util.GetUniqueUserID()
+		body, _ := json.Marshal(api.OrgBaseTemplateRequest{
+			DisplayName: "Org Landing",
+			TemplateConfig: api.DashboardTemplateConfig{
+				Sm: datatypes.NewJSONType([]api.WidgetItem{
+					{Width: 1, Height: 2, WidgetType: "landing-./RhelWidget", X: test_util.IntPTR(0), Y: test_util.IntPTR(0)},
+				}),
+			},
+		})
+
+		req, _ := http.NewRequest("PUT", "/org-base-templates/landing-landing", bytes.NewReader(body))
+		req = withCustomIdentityContext(req, test_util.GenerateIdentityStructFromTemplate(
+			xrhidgen.Identity{OrgID: stringPtr(orgID)},
+			xrhidgen.User{UserID: stringPtr(test_util.GetUniqueUserID()), IsOrgAdmin: test_util.BoolPTR(true)},
+			xrhidgen.Entitlements{},
+		))
+		w := httptest.NewRecorder()
+
+		server.PutOrgBaseWidgetDashboardTemplateByName(w, req, "landing-landing")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+		var resp api.OrgBaseTemplate
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		assert.Equal(t, orgID, resp.OrgId)
+		assert.Equal(t, "landing-landing", resp.Name)
+		assert.Equal(t, "Org Landing", resp.DisplayName)
+	})
+
+	t.Run("should return 400 for invalid request body", func(t *testing.T) {
+		server := setupRouter()
+
+		req, _ := http.NewRequest("PUT", "/org-base-templates/landing-landing", bytes.NewReader([]byte("invalid json")))
+		req = withIdentityContext(req)
+		w := httptest.NewRecorder()
+
+		server.PutOrgBaseWidgetDashboardTemplateByName(w, req, "landing-landing")
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		var errorResponse api.ErrorResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&errorResponse))
+		assert.Contains(t, errorResponse.Errors[0].Message, "Invalid request body")
+	})
+
+	t.Run("should return 403 for users who are not org admins", func(t *testing.T) {
+		server := setupRouter()
+
+		body, _ := json.Marshal(api.OrgBaseTemplateRequest{DisplayName: "Org Landing"})
+
+		req, _ := http.NewRequest("PUT", "/org-base-templates/landing-landing", bytes.NewReader(body))
+		req = withCustomIdentityContext(req, test_util.GenerateIdentityStructFromTemplate(
+			xrhidgen.Identity{OrgID: stringPtr("org-" + test_util.GetUniqueUserID())},
+			xrhidgen.User{UserID: stringPtr(test_util.GetUniqueUserID()), IsOrgAdmin: test_util.BoolPTR(false)},
+			xrhidgen.Entitlements{},
+		))
+		w := httptest.NewRecorder()
+
+		server.PutOrgBaseWidgetDashboardTemplateByName(w, req, "landing-landing")
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
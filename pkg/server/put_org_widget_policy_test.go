@@ -0,0 +1,78 @@
+package server_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/RedHatInsights/widget-layout-backend/api"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/test_util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/subpop/xrhidgen"
+)
+
+func TestPutOrgWidgetPolicy(t *testing.T) {
+	t.Run("should store the widget policy of the organization", func(t *testing.T) {
+		server := setupRouter()
+
+		orgID := "org-" + test_util.GetUniqueUserID()
+		adminID := test_util.GetUniqueUserID()
+		body, _ := json.Marshal(api.WidgetPolicy{ForbiddenWidgets: []string{"widget1"}})
+
+		req, _ := http.NewRequest("PUT", "/org-policy", bytes.NewReader(body))
+		req = withCustomIdentityContext(req, test_util.GenerateIdentityStructFromTemplate(
+			xrhidgen.Identity{OrgID: stringPtr(orgID)},
+			xrhidgen.User{UserID: stringPtr(adminID), IsOrgAdmin: test_util.BoolPTR(true)},
+			xrhidgen.Entitlements{},
+		))
+		w := httptest.NewRecorder()
+
+		server.PutOrgWidgetPolicy(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+		var resp api.OrgWidgetPolicy
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		assert.Equal(t, orgID, resp.OrgId)
+		assert.Equal(t, adminID, resp.UpdatedBy)
+		assert.Equal(t, []string{"widget1"}, resp.Policy.ForbiddenWidgets)
+	})
+
+	t.Run("should return 400 for invalid request body", func(t *testing.T) {
+		server := setupRouter()
+
+		req, _ := http.NewRequest("PUT", "/org-policy", bytes.NewReader([]byte("invalid json")))
+		req = withIdentityContext(req)
+		w := httptest.NewRecorder()
+
+		server.PutOrgWidgetPolicy(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		var errorResponse api.ErrorResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&errorResponse))
+		assert.Contains(t, errorResponse.Errors[0].Message, "Invalid request body")
+	})
+
+	t.Run("should return 403 for users who are not org admins", func(t *testing.T) {
+		server := setupRouter()
+
+		body, _ := json.Marshal(api.WidgetPolicy{ForbiddenWidgets: []string{"widget1"}})
+
+		req, _ := http.NewRequest("PUT", "/org-policy", bytes.NewReader(body))
+		req = withCustomIdentityContext(req, test_util.GenerateIdentityStructFromTemplate(
+			xrhidgen.Identity{OrgID: stringPtr("org-" + test_util.GetUniqueUserID())},
+			xrhidgen.User{UserID: stringPtr(test_util.GetUniqueUserID()), IsOrgAdmin: test_util.BoolPTR(false)},
+			xrhidgen.Entitlements{},
+		))
+		w := httptest.NewRecorder()
+
+		server.PutOrgWidgetPolicy(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
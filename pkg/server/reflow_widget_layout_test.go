@@ -0,0 +1,59 @@
+package server_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/RedHatInsights/widget-layout-backend/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReflowWidgetLayout(t *testing.T) {
+	t.Run("should return all breakpoints derived from the authored one", func(t *testing.T) {
+		server := setupRouter()
+
+		body := `{"templateConfig": {"xl": [
+			{"w": 2, "h": 2, "x": 0, "y": 0, "i": "widget1"},
+			{"w": 2, "h": 2, "x": 2, "y": 0, "i": "widget2"}
+		]}}`
+		req, _ := withUniqueUserIdentityContext(httptest.NewRequest("POST", "/layouts/reflow", strings.NewReader(body)))
+		w := httptest.NewRecorder()
+		server.ReflowWidgetLayout(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var tc api.DashboardTemplateConfig
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&tc))
+		assert.NoError(t, tc.IsValid())
+		md := tc.Md.Data()
+		require.Len(t, md, 2)
+		assert.Equal(t, 1, *md[1].X)
+		assert.Equal(t, 0, *md[1].Y)
+	})
+
+	t.Run("should return 400 without an authored breakpoint", func(t *testing.T) {
+		server := setupRouter()
+
+		req, _ := withUniqueUserIdentityContext(httptest.NewRequest("POST", "/layouts/reflow", strings.NewReader(`{"templateConfig": {}}`)))
+		w := httptest.NewRecorder()
+		server.ReflowWidgetLayout(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		var errorResponse api.ErrorResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&errorResponse))
+		require.NotEmpty(t, errorResponse.Errors)
+	})
+
+	t.Run("should return 400 for invalid JSON in request body", func(t *testing.T) {
+		server := setupRouter()
+
+		req, _ := withUniqueUserIdentityContext(httptest.NewRequest("POST", "/layouts/reflow", strings.NewReader(`{"templateConfig":`)))
+		w := httptest.NewRecorder()
+		server.ReflowWidgetLayout(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
@@ -38,7 +38,7 @@ func TestRenameWidgetLayoutById(t *testing.T) {
 		))
 		w := httptest.NewRecorder()
 
-		server.RenameWidgetLayoutById(w, req, templateID)
+		server.RenameWidgetLayoutById(w, req, templateID, api.RenameWidgetLayoutByIdParams{})
 
 		assert.Equal(t, http.StatusOK, w.Code)
 		assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
@@ -60,7 +60,7 @@ func TestRenameWidgetLayoutById(t *testing.T) {
 		req = withIdentityContext(req)
 		w := httptest.NewRecorder()
 
-		server.RenameWidgetLayoutById(w, req, int64(test_util.NoDBTestID))
+		server.RenameWidgetLayoutById(w, req, int64(test_util.NoDBTestID), api.RenameWidgetLayoutByIdParams{})
 
 		assert.Equal(t, http.StatusBadRequest, w.Code)
 
@@ -81,7 +81,7 @@ func TestRenameWidgetLayoutById(t *testing.T) {
 		req = withIdentityContext(req)
 		w := httptest.NewRecorder()
 
-		server.RenameWidgetLayoutById(w, req, int64(test_util.NoDBTestID))
+		server.RenameWidgetLayoutById(w, req, int64(test_util.NoDBTestID), api.RenameWidgetLayoutByIdParams{})
 
 		assert.Equal(t, http.StatusBadRequest, w.Code)
 
@@ -101,7 +101,7 @@ func TestRenameWidgetLayoutById(t *testing.T) {
 		req = withIdentityContext(req)
 		w := httptest.NewRecorder()
 
-		server.RenameWidgetLayoutById(w, req, int64(test_util.NoDBTestID))
+		server.RenameWidgetLayoutById(w, req, int64(test_util.NoDBTestID), api.RenameWidgetLayoutByIdParams{})
 
 		assert.Equal(t, http.StatusBadRequest, w.Code)
 
@@ -121,7 +121,7 @@ func TestRenameWidgetLayoutById(t *testing.T) {
 		req = withIdentityContext(req)
 		w := httptest.NewRecorder()
 
-		server.RenameWidgetLayoutById(w, req, int64(test_util.NonExistentID))
+		server.RenameWidgetLayoutById(w, req, int64(test_util.NonExistentID), api.RenameWidgetLayoutByIdParams{})
 
 		assert.Equal(t, http.StatusNotFound, w.Code)
 
@@ -152,7 +152,7 @@ func TestRenameWidgetLayoutById(t *testing.T) {
 		))
 		w := httptest.NewRecorder()
 
-		server.RenameWidgetLayoutById(w, req, templateID)
+		server.RenameWidgetLayoutById(w, req, templateID, api.RenameWidgetLayoutByIdParams{})
 
 		assert.Equal(t, http.StatusForbidden, w.Code)
 
@@ -173,7 +173,7 @@ func TestRenameWidgetLayoutById(t *testing.T) {
 		w := httptest.NewRecorder()
 
 		assert.Panics(t, func() {
-			server.RenameWidgetLayoutById(w, req, int64(test_util.NoDBTestID))
+			server.RenameWidgetLayoutById(w, req, int64(test_util.NoDBTestID), api.RenameWidgetLayoutByIdParams{})
 		}, "Should panic when identity is missing from context")
 	})
 }
@@ -9,7 +9,6 @@ import (
 
 	"github.com/RedHatInsights/widget-layout-backend/api"
 	"github.com/RedHatInsights/widget-layout-backend/pkg/database"
-	"github.com/RedHatInsights/widget-layout-backend/pkg/service"
 	"github.com/RedHatInsights/widget-layout-backend/pkg/test_util"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -22,7 +21,7 @@ func TestResetWidgetLayoutById(t *testing.T) {
 		server := setupRouter()
 
 		// Reset registry and add a base template for testing
-		service.BaseTemplateRegistry = api.BaseWidgetDashboardTemplateRegistry{}
+		testRegistries.BaseTemplates.Replace(nil)
 
 		baseWidget := api.WidgetItem{
 			Width:      2,
@@ -43,7 +42,7 @@ func TestResetWidgetLayoutById(t *testing.T) {
 				Xl: datatypes.NewJSONType([]api.WidgetItem{baseWidget}),
 			},
 		}
-		service.BaseTemplateRegistry.AddBase(baseTemplate)
+		testRegistries.AddBaseTemplate(baseTemplate)
 
 		// Create a dashboard template in the database that has been modified
 		testUserID := test_util.GetUniqueUserID()
@@ -85,7 +84,7 @@ func TestResetWidgetLayoutById(t *testing.T) {
 		))
 		w := httptest.NewRecorder()
 
-		server.ResetWidgetLayoutById(w, req, int64(templateID))
+		server.ResetWidgetLayoutById(w, req, int64(templateID), api.ResetWidgetLayoutByIdParams{})
 
 		assert.Equal(t, http.StatusOK, w.Code, "Expected status code 200 for successful reset")
 		assert.Equal(t, "application/json", w.Header().Get("Content-Type"), "Content-Type should be application/json")
@@ -114,7 +113,7 @@ func TestResetWidgetLayoutById(t *testing.T) {
 		req = withIdentityContext(req)
 		w := httptest.NewRecorder()
 
-		server.ResetWidgetLayoutById(w, req, int64(test_util.NonExistentID))
+		server.ResetWidgetLayoutById(w, req, int64(test_util.NonExistentID), api.ResetWidgetLayoutByIdParams{})
 
 		assert.Equal(t, http.StatusNotFound, w.Code, "Expected status code 404 for non-existent template")
 		assert.Equal(t, "application/json", w.Header().Get("Content-Type"), "Content-Type should be application/json")
@@ -161,7 +160,7 @@ func TestResetWidgetLayoutById(t *testing.T) {
 		))
 		w := httptest.NewRecorder()
 
-		server.ResetWidgetLayoutById(w, req, int64(templateID))
+		server.ResetWidgetLayoutById(w, req, int64(templateID), api.ResetWidgetLayoutByIdParams{})
 
 		assert.Equal(t, http.StatusForbidden, w.Code, "Expected status code 403 for unauthorized access")
 		assert.Equal(t, "application/json", w.Header().Get("Content-Type"), "Content-Type should be application/json")
@@ -178,7 +177,7 @@ func TestResetWidgetLayoutById(t *testing.T) {
 		server := setupRouter()
 
 		// Reset registry to ensure no base templates exist
-		service.BaseTemplateRegistry = api.BaseWidgetDashboardTemplateRegistry{}
+		testRegistries.BaseTemplates.Replace(nil)
 
 		// Create a dashboard template that references a non-existent base template
 		testUserID := test_util.GetUniqueUserID()
@@ -210,7 +209,7 @@ func TestResetWidgetLayoutById(t *testing.T) {
 		))
 		w := httptest.NewRecorder()
 
-		server.ResetWidgetLayoutById(w, req, int64(templateID))
+		server.ResetWidgetLayoutById(w, req, int64(templateID), api.ResetWidgetLayoutByIdParams{})
 
 		assert.Equal(t, http.StatusNotFound, w.Code, "Expected status code 404 when base template doesn't exist")
 		assert.Equal(t, "application/json", w.Header().Get("Content-Type"), "Content-Type should be application/json")
@@ -228,7 +227,7 @@ func TestResetWidgetLayoutById(t *testing.T) {
 		server := setupRouter()
 
 		// Reset registry and add a complex base template
-		service.BaseTemplateRegistry = api.BaseWidgetDashboardTemplateRegistry{}
+		testRegistries.BaseTemplates.Replace(nil)
 
 		baseWidgets := []api.WidgetItem{
 			{
@@ -261,7 +260,7 @@ func TestResetWidgetLayoutById(t *testing.T) {
 				Xl: datatypes.NewJSONType(baseWidgets),
 			},
 		}
-		service.BaseTemplateRegistry.AddBase(baseTemplate)
+		testRegistries.AddBaseTemplate(baseTemplate)
 
 		// Create a dashboard template with different configuration
 		testUserID := test_util.GetUniqueUserID()
@@ -306,7 +305,7 @@ func TestResetWidgetLayoutById(t *testing.T) {
 		))
 		w := httptest.NewRecorder()
 
-		server.ResetWidgetLayoutById(w, req, int64(templateID))
+		server.ResetWidgetLayoutById(w, req, int64(templateID), api.ResetWidgetLayoutByIdParams{})
 
 		assert.Equal(t, http.StatusOK, w.Code, "Expected status code 200 for successful reset")
 
@@ -333,7 +332,7 @@ func TestResetWidgetLayoutById(t *testing.T) {
 		server := setupRouter()
 
 		// Reset registry and add a base template
-		service.BaseTemplateRegistry = api.BaseWidgetDashboardTemplateRegistry{}
+		testRegistries.BaseTemplates.Replace(nil)
 
 		baseTemplate := api.BaseWidgetDashboardTemplate{
 			Name:        "metadata-test-base",
@@ -345,7 +344,7 @@ func TestResetWidgetLayoutById(t *testing.T) {
 				Xl: datatypes.NewJSONType([]api.WidgetItem{}),
 			},
 		}
-		service.BaseTemplateRegistry.AddBase(baseTemplate)
+		testRegistries.AddBaseTemplate(baseTemplate)
 
 		// Create a dashboard template with specific metadata
 		testUserID := test_util.GetUniqueUserID()
@@ -379,7 +378,7 @@ func TestResetWidgetLayoutById(t *testing.T) {
 		))
 		w := httptest.NewRecorder()
 
-		server.ResetWidgetLayoutById(w, req, int64(templateID))
+		server.ResetWidgetLayoutById(w, req, int64(templateID), api.ResetWidgetLayoutByIdParams{})
 
 		assert.Equal(t, http.StatusOK, w.Code, "Expected status code 200 for successful reset")
 
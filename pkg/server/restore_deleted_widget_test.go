@@ -0,0 +1,68 @@
+package server_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/RedHatInsights/widget-layout-backend/api"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/database"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/test_util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRestoreDeletedWidgetLayoutById(t *testing.T) {
+	t.Run("should restore a deleted template", func(t *testing.T) {
+		server := setupRouter()
+
+		req, userID := withUniqueUserIdentityContext(httptest.NewRequest("POST", "/", nil))
+		mockDashboard := test_util.MockDashboardTemplateWithSpecificUser(userID)
+		require.NoError(t, database.DB.Create(&mockDashboard).Error)
+		require.NoError(t, database.DB.Delete(&mockDashboard).Error)
+		templateID := int64(mockDashboard.ID)
+
+		w := httptest.NewRecorder()
+		server.RestoreDeletedWidgetLayoutById(w, req, templateID)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var resp api.DashboardTemplate
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		assert.Equal(t, mockDashboard.ID, resp.ID)
+
+		var dbTemplate api.DashboardTemplate
+		require.NoError(t, database.DB.First(&dbTemplate, templateID).Error, "Restored template should be visible again")
+	})
+
+	t.Run("should return 404 for a template which is not in the trash", func(t *testing.T) {
+		server := setupRouter()
+
+		req, userID := withUniqueUserIdentityContext(httptest.NewRequest("POST", "/", nil))
+		mockDashboard := test_util.MockDashboardTemplateWithSpecificUser(userID)
+		require.NoError(t, database.DB.Create(&mockDashboard).Error)
+
+		w := httptest.NewRecorder()
+		server.RestoreDeletedWidgetLayoutById(w, req, int64(mockDashboard.ID))
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		var errorResponse api.ErrorResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&errorResponse))
+		assert.NotEmpty(t, errorResponse.Errors)
+	})
+
+	t.Run("should return 403 for unauthorized user", func(t *testing.T) {
+		server := setupRouter()
+
+		mockDashboard := test_util.MockDashboardTemplateWithSpecificUser(test_util.GetUniqueUserID())
+		require.NoError(t, database.DB.Create(&mockDashboard).Error)
+		require.NoError(t, database.DB.Delete(&mockDashboard).Error)
+
+		req, _ := withUniqueUserIdentityContext(httptest.NewRequest("POST", fmt.Sprintf("/%d/restore", mockDashboard.ID), nil))
+		w := httptest.NewRecorder()
+		server.RestoreDeletedWidgetLayoutById(w, req, int64(mockDashboard.ID))
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
@@ -0,0 +1,165 @@
+package server_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/RedHatInsights/widget-layout-backend/api"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/database"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/service"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/test_util"
+	"github.com/redhatinsights/platform-go-middlewares/v2/identity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/subpop/xrhidgen"
+	"gorm.io/datatypes"
+)
+
+func TestRestoreWidgetLayoutRevisionById(t *testing.T) {
+	t.Run("should restore template config from a revision", func(t *testing.T) {
+		server := setupRouter()
+
+		testUserID := test_util.GetUniqueUserID()
+		testIdentity := test_util.GenerateIdentityStructFromTemplate(
+			xrhidgen.Identity{},
+			xrhidgen.User{UserID: stringPtr(testUserID)},
+			xrhidgen.Entitlements{},
+		)
+		mockDashboard := test_util.MockDashboardTemplateWithSpecificUser(testUserID)
+		stored := datatypes.NewJSONType([]api.WidgetItem{
+			{Width: 1, Height: 1, X: intPtr(0), Y: intPtr(0), WidgetType: "widget1"},
+		})
+		mockDashboard.TemplateConfig = api.DashboardTemplateConfig{Sm: stored, Md: stored, Lg: stored, Xl: stored}
+		require.NoError(t, database.DB.Create(&mockDashboard).Error)
+		templateID := int64(mockDashboard.ID)
+
+		changed := datatypes.NewJSONType([]api.WidgetItem{
+			{Width: 1, Height: 1, X: intPtr(0), Y: intPtr(0), WidgetType: "changed-widget"},
+		})
+		_, _, err := service.UpdateDashboardTemplate(context.Background(), testRegistries, templateID, api.DashboardTemplateConfig{
+			Sm: changed, Md: changed, Lg: changed, Xl: changed,
+		}, testIdentity, nil, false, nil)
+		require.NoError(t, err)
+
+		revisions, _, err := service.GetTemplateRevisions(templateID, testIdentity)
+		require.NoError(t, err)
+		require.Len(t, revisions, 1)
+		revisionID := int64(revisions[0].ID)
+
+		req, _ := http.NewRequest("POST", fmt.Sprintf("/%d/revisions/%d/restore", templateID, revisionID), nil)
+		req = withCustomIdentityContext(req, testIdentity)
+		w := httptest.NewRecorder()
+
+		server.RestoreWidgetLayoutRevisionById(w, req, templateID, revisionID, api.RestoreWidgetLayoutRevisionByIdParams{})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var resp api.DashboardTemplate
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		require.Len(t, resp.TemplateConfig.Xl.Data(), 1)
+		assert.Equal(t, "widget1", resp.TemplateConfig.Xl.Data()[0].WidgetType)
+
+		var dbTemplate api.DashboardTemplate
+		require.NoError(t, database.DB.First(&dbTemplate, templateID).Error)
+		assert.Equal(t, "widget1", dbTemplate.TemplateConfig.Xl.Data()[0].WidgetType)
+	})
+
+	t.Run("should return 404 for non-existent revision", func(t *testing.T) {
+		server := setupRouter()
+
+		req, userID := withUniqueUserIdentityContext(httptest.NewRequest("POST", "/", nil))
+		mockDashboard := test_util.MockDashboardTemplateWithSpecificUser(userID)
+		require.NoError(t, database.DB.Create(&mockDashboard).Error)
+
+		w := httptest.NewRecorder()
+		server.RestoreWidgetLayoutRevisionById(w, req, int64(mockDashboard.ID), int64(test_util.NonExistentID), api.RestoreWidgetLayoutRevisionByIdParams{})
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		var errorResponse api.ErrorResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&errorResponse))
+		assert.NotEmpty(t, errorResponse.Errors)
+	})
+
+	t.Run("should return 403 for unauthorized user", func(t *testing.T) {
+		server := setupRouter()
+
+		mockDashboard := test_util.MockDashboardTemplateWithSpecificUser(test_util.GetUniqueUserID())
+		require.NoError(t, database.DB.Create(&mockDashboard).Error)
+
+		req, _ := withUniqueUserIdentityContext(httptest.NewRequest("POST", "/", nil))
+		w := httptest.NewRecorder()
+
+		server.RestoreWidgetLayoutRevisionById(w, req, int64(mockDashboard.ID), int64(test_util.NonExistentID), api.RestoreWidgetLayoutRevisionByIdParams{})
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("should honour If-Match and return the new ETag", func(t *testing.T) {
+		server := setupRouter()
+		mockDashboard, revisionID, testIdentity := createRestorableDashboard(t)
+
+		stale := `"0"`
+		w := httptest.NewRecorder()
+		server.RestoreWidgetLayoutRevisionById(w, withCustomIdentityContext(httptest.NewRequest("POST", "/", nil), testIdentity), int64(mockDashboard.ID), revisionID, api.RestoreWidgetLayoutRevisionByIdParams{IfMatch: &stale})
+		assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+
+		var current api.DashboardTemplate
+		require.NoError(t, database.DB.First(&current, mockDashboard.ID).Error)
+		etag := current.ETag()
+		w = httptest.NewRecorder()
+		server.RestoreWidgetLayoutRevisionById(w, withCustomIdentityContext(httptest.NewRequest("POST", "/", nil), testIdentity), int64(mockDashboard.ID), revisionID, api.RestoreWidgetLayoutRevisionByIdParams{IfMatch: &etag})
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.NotEmpty(t, w.Header().Get("ETag"))
+		assert.NotEqual(t, etag, w.Header().Get("ETag"))
+	})
+
+	t.Run("should return 400 when the layout of the revision is invalid", func(t *testing.T) {
+		server := setupRouter()
+
+		testUserID := test_util.GetUniqueUserID()
+		testIdentity := test_util.GenerateIdentityStructFromTemplate(xrhidgen.Identity{}, xrhidgen.User{UserID: stringPtr(testUserID)}, xrhidgen.Entitlements{})
+		// the sm layout of the mock template is wider than the grid, it was stored before layouts were validated
+		mockDashboard := test_util.MockDashboardTemplateWithSpecificUser(testUserID)
+		require.NoError(t, database.DB.Create(&mockDashboard).Error)
+		changed := datatypes.NewJSONType([]api.WidgetItem{{Width: 1, Height: 1, X: intPtr(0), Y: intPtr(0), WidgetType: "changed-widget"}})
+		_, _, err := service.UpdateDashboardTemplate(context.Background(), testRegistries, int64(mockDashboard.ID), api.DashboardTemplateConfig{
+			Sm: changed, Md: changed, Lg: changed, Xl: changed,
+		}, testIdentity, nil, false, nil)
+		require.NoError(t, err)
+		revisions, _, err := service.GetTemplateRevisions(int64(mockDashboard.ID), testIdentity)
+		require.NoError(t, err)
+		require.Len(t, revisions, 1)
+
+		w := httptest.NewRecorder()
+		server.RestoreWidgetLayoutRevisionById(w, withCustomIdentityContext(httptest.NewRequest("POST", "/", nil), testIdentity), int64(mockDashboard.ID), int64(revisions[0].ID), api.RestoreWidgetLayoutRevisionByIdParams{})
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		var dbTemplate api.DashboardTemplate
+		require.NoError(t, database.DB.First(&dbTemplate, mockDashboard.ID).Error)
+		assert.Equal(t, "changed-widget", dbTemplate.TemplateConfig.Sm.Data()[0].WidgetType, "The template should not be changed")
+	})
+}
+
+// createRestorableDashboard stores a template of a new user with a valid layout, updates it once and returns the
+// template, the revision recorded by the update and the identity of the user
+func createRestorableDashboard(t *testing.T) (api.DashboardTemplate, int64, identity.XRHID) {
+	testUserID := test_util.GetUniqueUserID()
+	testIdentity := test_util.GenerateIdentityStructFromTemplate(xrhidgen.Identity{}, xrhidgen.User{UserID: stringPtr(testUserID)}, xrhidgen.Entitlements{})
+	mockDashboard := test_util.MockDashboardTemplateWithSpecificUser(testUserID)
+	stored := datatypes.NewJSONType([]api.WidgetItem{{Width: 1, Height: 1, X: intPtr(0), Y: intPtr(0), WidgetType: "widget1"}})
+	mockDashboard.TemplateConfig = api.DashboardTemplateConfig{Sm: stored, Md: stored, Lg: stored, Xl: stored}
+	require.NoError(t, database.DB.Create(&mockDashboard).Error)
+
+	changed := datatypes.NewJSONType([]api.WidgetItem{{Width: 1, Height: 1, X: intPtr(0), Y: intPtr(0), WidgetType: "changed-widget"}})
+	_, _, err := service.UpdateDashboardTemplate(context.Background(), testRegistries, int64(mockDashboard.ID), api.DashboardTemplateConfig{
+		Sm: changed, Md: changed, Lg: changed, Xl: changed,
+	}, testIdentity, nil, false, nil)
+	require.NoError(t, err)
+	revisions, _, err := service.GetTemplateRevisions(int64(mockDashboard.ID), testIdentity)
+	require.NoError(t, err)
+	require.Len(t, revisions, 1)
+	return mockDashboard, int64(revisions[0].ID), testIdentity
+}
@@ -0,0 +1,68 @@
+package server_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/RedHatInsights/widget-layout-backend/api"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/database"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/test_util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/subpop/xrhidgen"
+)
+
+func TestRevokeWidgetLayoutShareById(t *testing.T) {
+	t.Run("should revoke a share link", func(t *testing.T) {
+		server := setupRouter()
+
+		testUserID := test_util.GetUniqueUserID()
+		mockDashboard := test_util.MockDashboardTemplateWithSpecificUser(testUserID)
+		require.NoError(t, database.DB.Create(&mockDashboard).Error)
+		share := api.DashboardTemplateShare{DashboardTemplateId: mockDashboard.ID, UserId: testUserID, Token: "revoke-" + testUserID}
+		require.NoError(t, database.DB.Create(&share).Error)
+
+		templateID := int64(mockDashboard.ID)
+		req, _ := http.NewRequest("DELETE", fmt.Sprintf("/%d/shares/%d", templateID, share.ID), nil)
+		req = withCustomIdentityContext(req, test_util.GenerateIdentityStructFromTemplate(
+			xrhidgen.Identity{},
+			xrhidgen.User{UserID: stringPtr(testUserID)},
+			xrhidgen.Entitlements{},
+		))
+		w := httptest.NewRecorder()
+
+		server.RevokeWidgetLayoutShareById(w, req, templateID, int64(share.ID))
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		var count int64
+		require.NoError(t, database.DB.Model(&api.DashboardTemplateShare{}).Where("id = ?", share.ID).Count(&count).Error)
+		assert.Zero(t, count)
+	})
+
+	t.Run("should return 404 for a share link of another template", func(t *testing.T) {
+		server := setupRouter()
+
+		testUserID := test_util.GetUniqueUserID()
+		mockDashboard := test_util.MockDashboardTemplateWithSpecificUser(testUserID)
+		require.NoError(t, database.DB.Create(&mockDashboard).Error)
+		otherDashboard := test_util.MockDashboardTemplateWithSpecificUser(test_util.GetUniqueUserID())
+		require.NoError(t, database.DB.Create(&otherDashboard).Error)
+		share := api.DashboardTemplateShare{DashboardTemplateId: otherDashboard.ID, UserId: otherDashboard.UserId, Token: "revoke-other-" + testUserID}
+		require.NoError(t, database.DB.Create(&share).Error)
+
+		templateID := int64(mockDashboard.ID)
+		req, _ := http.NewRequest("DELETE", fmt.Sprintf("/%d/shares/%d", templateID, share.ID), nil)
+		req = withCustomIdentityContext(req, test_util.GenerateIdentityStructFromTemplate(
+			xrhidgen.Identity{},
+			xrhidgen.User{UserID: stringPtr(testUserID)},
+			xrhidgen.Entitlements{},
+		))
+		w := httptest.NewRecorder()
+
+		server.RevokeWidgetLayoutShareById(w, req, templateID, int64(share.ID))
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
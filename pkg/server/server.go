@@ -2,7 +2,13 @@ package server
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
 
 	"github.com/RedHatInsights/widget-layout-backend/api"
@@ -14,22 +20,57 @@ import (
 
 // optional code omitted
 
-type Server struct{}
+type Server struct {
+	registries *service.Registries
+}
+
+// errorPayloads converts a service error into response error payloads.
+// A layout validation error is reported with one payload per conflicting widget pair.
+func errorPayloads(status int, err error) []api.ErrorPayload {
+	var layoutErr *api.LayoutValidationError
+	if errors.As(err, &layoutErr) {
+		payloads := make([]api.ErrorPayload, 0, len(layoutErr.Issues))
+		for _, issue := range layoutErr.Issues {
+			payloads = append(payloads, api.ErrorPayload{
+				Code:       status,
+				Message:    issue.Message,
+				Kind:       issue.Kind,
+				Breakpoint: issue.Breakpoint,
+				Widgets:    issue.Widgets,
+			})
+		}
+		return payloads
+	}
+	var policyErr *api.PolicyViolationError
+	if errors.As(err, &policyErr) {
+		payloads := make([]api.ErrorPayload, 0, len(policyErr.Violations))
+		for _, violation := range policyErr.Violations {
+			payloads = append(payloads, api.ErrorPayload{Code: status, Message: violation.Message})
+		}
+		return payloads
+	}
+	return []api.ErrorPayload{{Code: status, Message: err.Error()}}
+}
 
-func NewServer(r chi.Router, middlewares ...func(next http.Handler) http.Handler) *Server {
+func NewServer(r chi.Router, registries *service.Registries, middlewares ...func(next http.Handler) http.Handler) *Server {
 	for _, mw := range middlewares {
 		r.Use(mw)
 	}
-	server := &Server{}
+	server := &Server{registries: registries}
 	return server
 }
 
 // (GET /)
-func (Server) GetWidgetLayout(w http.ResponseWriter, r *http.Request, params api.GetWidgetLayoutParams) {
+func (s Server) GetWidgetLayout(w http.ResponseWriter, r *http.Request, params api.GetWidgetLayoutParams) {
 	w.Header().Set("Content-Type", "application/json")
 	id := middlewares.GetUserIdentity(r.Context())
 
-	resp, status, err := service.GetUserTemplates(id, params)
+	if params.Fields != nil && *params.Fields != api.FieldsFull {
+		s.getWidgetLayoutSummaries(w, r, params)
+		return
+	}
+
+	resp, meta, status, err := service.GetUserTemplates(s.registries, id, params)
 	if err != nil {
 		logrus.Errorf("Failed to get dashboard templates: %v", err)
 		w.WriteHeader(status)
@@ -44,19 +85,73 @@ func (Server) GetWidgetLayout(w http.ResponseWriter, r *http.Request, params api
 
 	// Create the new list response format
 	listResponse := api.DashboardTemplateListResponse{
-		Data: resp,
-		Meta: api.ListResponseMeta{
-			Count: len(resp),
-		},
+		Data: service.FilterPermittedWidgets(r.Context(), s.registries, resp, id),
+		Meta: meta,
 	}
+	listResponse.Meta.Links = pageLinks(r.URL, meta)
 
+	w.Header().Set("ETag", api.DashboardTemplatesETag(resp))
 	// Use the status returned by the service (could be 200 or 404 when auto-creating)
 	w.WriteHeader(status)
 	_ = json.NewEncoder(w).Encode(listResponse)
 }
 
+// getWidgetLayoutSummaries responds to GET / with fields other than full, the layouts of the templates are not loaded.
+func (s Server) getWidgetLayoutSummaries(w http.ResponseWriter, r *http.Request, params api.GetWidgetLayoutParams) {
+	id := middlewares.GetUserIdentity(r.Context())
+	if *params.Fields != api.FieldsSummary {
+		err := fmt.Errorf("fields must be one of %s, %s", api.FieldsFull, api.FieldsSummary)
+		logrus.Errorf("Failed to get dashboard templates: %v", err)
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(api.ErrorResponse{Errors: errorPayloads(http.StatusBadRequest, err)})
+		return
+	}
+
+	resp, meta, status, err := service.GetUserTemplateSummaries(s.registries, id, params)
+	if err != nil {
+		logrus.Errorf("Failed to get dashboard template summaries: %v", err)
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(api.ErrorResponse{Errors: errorPayloads(status, err)})
+		return
+	}
+
+	listResponse := api.DashboardTemplateSummaryListResponse{
+		Data: resp,
+		Meta: meta,
+	}
+	listResponse.Meta.Links = pageLinks(r.URL, meta)
+
+	w.Header().Set("ETag", api.DashboardTemplateSummariesETag(resp))
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(listResponse)
+}
+
+// pageLinks returns the links to the pages before and after the page of a paginated list.
+// The links keep every other query parameter of the request.
+func pageLinks(u *url.URL, meta api.ListResponseMeta) *api.ListResponseLinks {
+	if meta.Total == nil || meta.Limit == nil || meta.Offset == nil {
+		return nil
+	}
+	limit, offset := *meta.Limit, *meta.Offset
+	link := func(offset int) *string {
+		query := u.Query()
+		query.Set("limit", strconv.Itoa(limit))
+		query.Set("offset", strconv.Itoa(offset))
+		href := u.Path + "?" + query.Encode()
+		return &href
+	}
+	links := &api.ListResponseLinks{}
+	if offset+meta.Count < *meta.Total {
+		links.Next = link(offset + meta.Count)
+	}
+	if offset > 0 {
+		links.Prev = link(max(0, offset-limit))
+	}
+	return links
+}
+
 // (GET /{dashboardTemplateId})
-func (Server) GetWidgetLayoutById(w http.ResponseWriter, r *http.Request, dashboardTemplateId int64) {
+func (s Server) GetWidgetLayoutById(w http.ResponseWriter, r *http.Request, dashboardTemplateId int64) {
 	w.Header().Set("Content-Type", "application/json")
 	id := middlewares.GetUserIdentity(r.Context())
 	resp, status, err := service.GetTemplateByID(dashboardTemplateId, id)
@@ -73,13 +168,14 @@ func (Server) GetWidgetLayoutById(w http.ResponseWriter, r *http.Request, dashbo
 		return
 	}
 
+	w.Header().Set("ETag", resp.ETag())
 	w.WriteHeader(http.StatusOK)
-	_ = json.NewEncoder(w).Encode(resp)
+	_ = json.NewEncoder(w).Encode(service.FilterPermittedTemplateWidgets(r.Context(), s.registries, resp, id))
 }
 
 // (PATCH /{dashboardTemplateId})
 
-func (Server) UpdateWidgetLayoutById(w http.ResponseWriter, r *http.Request, dashboardTemplateId int64) {
+func (s Server) UpdateWidgetLayoutById(w http.ResponseWriter, r *http.Request, dashboardTemplateId int64, params api.UpdateWidgetLayoutByIdParams) {
 	var template api.DashboardTemplate
 	if err := json.NewDecoder(r.Body).Decode(&template); err != nil {
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
@@ -87,26 +183,69 @@ func (Server) UpdateWidgetLayoutById(w http.ResponseWriter, r *http.Request, das
 	}
 	id := middlewares.GetUserIdentity(r.Context())
 	dr, status, err := service.UpdateDashboardTemplate(
+		r.Context(),
+		s.registries,
 		dashboardTemplateId,
 		template.TemplateConfig,
 		id,
+		params.ValidationMode,
+		params.Compact != nil && *params.Compact,
+		params.IfMatch,
 	)
 
 	if err != nil {
 		logrus.Errorf("Failed to update dashboard template: %v", err)
 		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(api.ErrorResponse{Errors: errorPayloads(status, err)})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", dr.ETag())
+	w.WriteHeader(status)
+	err = json.NewEncoder(w).Encode(service.FilterPermittedTemplateWidgets(r.Context(), s.registries, dr, id))
+	if err != nil {
+		logrus.Errorf("Failed to encode response: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+}
+
+// (PATCH /{dashboardTemplateId}/widgets)
+func (s Server) PatchWidgetLayoutWidgetsById(w http.ResponseWriter, r *http.Request, dashboardTemplateId int64, params api.PatchWidgetLayoutWidgetsByIdParams) {
+	w.Header().Set("Content-Type", "application/json")
+	var operationsRequest api.WidgetLayoutOperationsRequest
+	if err := json.NewDecoder(r.Body).Decode(&operationsRequest); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
 		_ = json.NewEncoder(w).Encode(api.ErrorResponse{Errors: []api.ErrorPayload{
 			{
-				Code:    status,
-				Message: err.Error(),
+				Code:    http.StatusBadRequest,
+				Message: "Invalid request body",
 			},
 		}})
 		return
 	}
-
-	w.Header().Set("Content-Type", "application/json")
+	if len(operationsRequest.Operations) == 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(api.ErrorResponse{Errors: []api.ErrorPayload{
+			{
+				Code:    http.StatusBadRequest,
+				Message: "operations are required and cannot be empty",
+			},
+		}})
+		return
+	}
+	id := middlewares.GetUserIdentity(r.Context())
+	resp, status, err := service.PatchDashboardTemplateWidgets(r.Context(), s.registries, dashboardTemplateId, operationsRequest.Operations, id, params.ValidationMode, params.IfMatch)
+	if err != nil {
+		logrus.Errorf("Failed to apply widget operations to dashboard template: %v", err)
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(api.ErrorResponse{Errors: errorPayloads(status, err)})
+		return
+	}
+	w.Header().Set("ETag", resp.ETag())
 	w.WriteHeader(status)
-	err = json.NewEncoder(w).Encode(dr)
+	err = json.NewEncoder(w).Encode(service.FilterPermittedTemplateWidgets(r.Context(), s.registries, resp, id))
 	if err != nil {
 		logrus.Errorf("Failed to encode response: %v", err)
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
@@ -137,7 +276,7 @@ func (Server) DeleteWidgetLayoutById(w http.ResponseWriter, r *http.Request, das
 	}
 }
 
-func (Server) RenameWidgetLayoutById(w http.ResponseWriter, r *http.Request, dashboardTemplateId int64) {
+func (s Server) RenameWidgetLayoutById(w http.ResponseWriter, r *http.Request, dashboardTemplateId int64, params api.RenameWidgetLayoutByIdParams) {
 	w.Header().Set("Content-Type", "application/json")
 	var renameRequest api.RenameWidgetDashboardTemplateRequest
 	if err := json.NewDecoder(r.Body).Decode(&renameRequest); err != nil {
@@ -161,7 +300,7 @@ func (Server) RenameWidgetLayoutById(w http.ResponseWriter, r *http.Request, das
 		return
 	}
 	id := middlewares.GetUserIdentity(r.Context())
-	resp, status, err := service.RenameDashboardTemplate(dashboardTemplateId, renameRequest.DashboardName, id)
+	resp, status, err := service.RenameDashboardTemplate(dashboardTemplateId, renameRequest.DashboardName, id, params.IfMatch)
 	if err != nil {
 		logrus.Errorf("Failed to rename dashboard template: %v", err)
 		w.WriteHeader(status)
@@ -173,8 +312,9 @@ func (Server) RenameWidgetLayoutById(w http.ResponseWriter, r *http.Request, das
 		}})
 		return
 	}
+	w.Header().Set("ETag", resp.ETag())
 	w.WriteHeader(status)
-	err = json.NewEncoder(w).Encode(resp)
+	err = json.NewEncoder(w).Encode(service.FilterPermittedTemplateWidgets(r.Context(), s.registries, resp, id))
 	if err != nil {
 		logrus.Errorf("Failed to encode response: %v", err)
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
@@ -182,7 +322,7 @@ func (Server) RenameWidgetLayoutById(w http.ResponseWriter, r *http.Request, das
 	}
 }
 
-func (Server) CopyWidgetLayoutById(w http.ResponseWriter, r *http.Request, dashboardTemplateId int64) {
+func (s Server) CopyWidgetLayoutById(w http.ResponseWriter, r *http.Request, dashboardTemplateId int64) {
 	id := middlewares.GetUserIdentity(r.Context())
 	w.Header().Set("Content-Type", "application/json")
 	var copyRequest api.CopyWidgetDashboardTemplateRequest
@@ -190,7 +330,7 @@ func (Server) CopyWidgetLayoutById(w http.ResponseWriter, r *http.Request, dashb
 		_ = json.NewDecoder(r.Body).Decode(&copyRequest)
 	}
 
-	resp, status, err := service.CopyDashboardTemplate(dashboardTemplateId, id, copyRequest.DashboardName)
+	resp, status, err := service.CopyDashboardTemplate(s.registries, dashboardTemplateId, id, copyRequest.DashboardName)
 	if err != nil {
 		logrus.Errorf("Failed to copy dashboard template: %v", err)
 		w.WriteHeader(status)
@@ -203,7 +343,7 @@ func (Server) CopyWidgetLayoutById(w http.ResponseWriter, r *http.Request, dashb
 		return
 	}
 	w.WriteHeader(status)
-	err = json.NewEncoder(w).Encode(resp)
+	err = json.NewEncoder(w).Encode(service.FilterPermittedTemplateWidgets(r.Context(), s.registries, resp, id))
 	if err != nil {
 		logrus.Errorf("Failed to encode response: %v", err)
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
@@ -211,10 +351,10 @@ func (Server) CopyWidgetLayoutById(w http.ResponseWriter, r *http.Request, dashb
 	}
 }
 
-func (Server) SetWidgetLayoutDefaultById(w http.ResponseWriter, r *http.Request, dashboardTemplateId int64) {
+func (s Server) SetWidgetLayoutDefaultById(w http.ResponseWriter, r *http.Request, dashboardTemplateId int64, params api.SetWidgetLayoutDefaultByIdParams) {
 	id := middlewares.GetUserIdentity(r.Context())
 	w.Header().Set("Content-Type", "application/json")
-	resp, status, err := service.ChangeDefaultTemplate(dashboardTemplateId, id)
+	resp, status, err := service.ChangeDefaultTemplate(dashboardTemplateId, id, params.IfMatch)
 	if err != nil {
 		logrus.Errorf("Failed to change default dashboard template: %v", err)
 		w.WriteHeader(status)
@@ -226,8 +366,9 @@ func (Server) SetWidgetLayoutDefaultById(w http.ResponseWriter, r *http.Request,
 		}})
 		return
 	}
+	w.Header().Set("ETag", resp.ETag())
 	w.WriteHeader(status)
-	err = json.NewEncoder(w).Encode(resp)
+	err = json.NewEncoder(w).Encode(service.FilterPermittedTemplateWidgets(r.Context(), s.registries, resp, id))
 	if err != nil {
 		logrus.Errorf("Failed to encode response: %v", err)
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
@@ -235,10 +376,10 @@ func (Server) SetWidgetLayoutDefaultById(w http.ResponseWriter, r *http.Request,
 	}
 }
 
-func (Server) ResetWidgetLayoutById(w http.ResponseWriter, r *http.Request, dashboardTemplateId int64) {
+func (s Server) ResetWidgetLayoutById(w http.ResponseWriter, r *http.Request, dashboardTemplateId int64, params api.ResetWidgetLayoutByIdParams) {
 	id := middlewares.GetUserIdentity(r.Context())
 	w.Header().Set("Content-Type", "application/json")
-	resp, status, err := service.ResetDashboardTemplate(dashboardTemplateId, id)
+	resp, status, err := service.ResetDashboardTemplate(s.registries, dashboardTemplateId, id, params.IfMatch)
 	if err != nil {
 		logrus.Errorf("Failed to reset dashboard template: %v", err)
 		w.WriteHeader(status)
@@ -250,8 +391,29 @@ func (Server) ResetWidgetLayoutById(w http.ResponseWriter, r *http.Request, dash
 		}})
 		return
 	}
+	w.Header().Set("ETag", resp.ETag())
 	w.WriteHeader(status)
-	err = json.NewEncoder(w).Encode(resp)
+	err = json.NewEncoder(w).Encode(service.FilterPermittedTemplateWidgets(r.Context(), s.registries, resp, id))
+	if err != nil {
+		logrus.Errorf("Failed to encode response: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (s Server) CompactWidgetLayoutById(w http.ResponseWriter, r *http.Request, dashboardTemplateId int64, params api.CompactWidgetLayoutByIdParams) {
+	id := middlewares.GetUserIdentity(r.Context())
+	w.Header().Set("Content-Type", "application/json")
+	resp, status, err := service.CompactDashboardTemplate(s.registries, dashboardTemplateId, id, params.IfMatch)
+	if err != nil {
+		logrus.Errorf("Failed to compact dashboard template: %v", err)
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(api.ErrorResponse{Errors: errorPayloads(status, err)})
+		return
+	}
+	w.Header().Set("ETag", resp.ETag())
+	w.WriteHeader(status)
+	err = json.NewEncoder(w).Encode(service.FilterPermittedTemplateWidgets(r.Context(), s.registries, resp, id))
 	if err != nil {
 		logrus.Errorf("Failed to encode response: %v", err)
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
@@ -259,7 +421,7 @@ func (Server) ResetWidgetLayoutById(w http.ResponseWriter, r *http.Request, dash
 	}
 }
 
-func (Server) ExportWidgetLayoutById(w http.ResponseWriter, r *http.Request, dashboardTemplateId int64) {
+func (s Server) ExportWidgetLayoutById(w http.ResponseWriter, r *http.Request, dashboardTemplateId int64) {
 	id := middlewares.GetUserIdentity((r.Context()))
 	w.Header().Set("Content-Type", "application/json")
 	resp, status, err := service.ExportWidgetDashboardTemplate(dashboardTemplateId, id)
@@ -275,7 +437,7 @@ func (Server) ExportWidgetLayoutById(w http.ResponseWriter, r *http.Request, das
 		return
 	}
 	w.WriteHeader(status)
-	err = json.NewEncoder(w).Encode(resp)
+	err = json.NewEncoder(w).Encode(service.FilterPermittedExportedTemplate(r.Context(), s.registries, resp, id))
 	if err != nil {
 		logrus.Errorf("Failed to encode response: %v", err)
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
@@ -283,72 +445,141 @@ func (Server) ExportWidgetLayoutById(w http.ResponseWriter, r *http.Request, das
 	}
 }
 
-func (Server) GetBaseWidgetDashboardTemplates(w http.ResponseWriter, r *http.Request) {
+// (GET /export)
+func (s Server) ExportDashboardArchive(w http.ResponseWriter, r *http.Request) {
+	id := middlewares.GetUserIdentity(r.Context())
 	w.Header().Set("Content-Type", "application/json")
-	templateMap := service.BaseTemplateRegistry.GetAllBases()
+	resp, status, err := service.ExportDashboardArchive(id)
+	if err != nil {
+		logrus.Errorf("Failed to export dashboard archive: %v", err)
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(api.ErrorResponse{Errors: errorPayloads(status, err)})
+		return
+	}
+	w.WriteHeader(status)
+	err = json.NewEncoder(w).Encode(service.FilterPermittedDashboardArchive(r.Context(), s.registries, resp, id))
+	if err != nil {
+		logrus.Errorf("Failed to encode response: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+}
 
-	// Convert map to array to match API spec
-	templates := make([]api.BaseWidgetDashboardTemplate, 0, len(templateMap))
-	for _, template := range templateMap {
-		templates = append(templates, template)
+func (s Server) GetWidgetLayoutRevisionsById(w http.ResponseWriter, r *http.Request, dashboardTemplateId int64) {
+	id := middlewares.GetUserIdentity(r.Context())
+	w.Header().Set("Content-Type", "application/json")
+	revisions, status, err := service.GetTemplateRevisions(dashboardTemplateId, id)
+	if err != nil {
+		logrus.Errorf("Failed to get dashboard template revisions: %v", err)
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(api.ErrorResponse{Errors: []api.ErrorPayload{
+			{
+				Code:    status,
+				Message: err.Error(),
+			},
+		}})
+		return
 	}
 
-	// Create the new list response format
-	listResponse := api.BaseWidgetDashboardTemplateListResponse{
-		Data: templates,
+	listResponse := api.DashboardTemplateRevisionListResponse{
+		Data: service.FilterPermittedTemplateRevisions(r.Context(), s.registries, revisions, id),
 		Meta: api.ListResponseMeta{
-			Count: len(templates),
+			Count: len(revisions),
 		},
 	}
 
-	w.WriteHeader(http.StatusOK)
-	err := json.NewEncoder(w).Encode(listResponse)
+	w.WriteHeader(status)
+	err = json.NewEncoder(w).Encode(listResponse)
 	if err != nil {
-		logrus.Errorf("Failed to encode base widget dashboard templates: %v", err)
+		logrus.Errorf("Failed to encode response: %v", err)
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 		return
 	}
 }
 
-func (Server) GetBaseWidgetDashboardTemplateByName(w http.ResponseWriter, r *http.Request, baseTemplateName string) {
+func (s Server) RestoreWidgetLayoutRevisionById(w http.ResponseWriter, r *http.Request, dashboardTemplateId int64, revisionId int64, params api.RestoreWidgetLayoutRevisionByIdParams) {
+	id := middlewares.GetUserIdentity(r.Context())
 	w.Header().Set("Content-Type", "application/json")
-	template, exists := service.BaseTemplateRegistry.GetBase(baseTemplateName)
-	if !exists {
-		w.WriteHeader(http.StatusNotFound)
-		_ = json.NewEncoder(w).Encode(api.ErrorResponse{Errors: []api.ErrorPayload{
-			{
-				Code:    http.StatusNotFound,
-				Message: "Base template not found",
-			},
-		}})
+	resp, status, err := service.RestoreTemplateRevision(s.registries, dashboardTemplateId, revisionId, id, params.IfMatch)
+	if err != nil {
+		logrus.Errorf("Failed to restore dashboard template revision: %v", err)
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(api.ErrorResponse{Errors: errorPayloads(status, err)})
 		return
 	}
-	w.WriteHeader(http.StatusOK)
-	err := json.NewEncoder(w).Encode(template)
+	w.Header().Set("ETag", resp.ETag())
+	w.WriteHeader(status)
+	err = json.NewEncoder(w).Encode(service.FilterPermittedTemplateWidgets(r.Context(), s.registries, resp, id))
 	if err != nil {
-		logrus.Errorf("Failed to encode base widget dashboard template: %v", err)
+		logrus.Errorf("Failed to encode response: %v", err)
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 		return
 	}
 }
 
-func (Server) ForkBaseWidgetDashboardTemplateByName(w http.ResponseWriter, r *http.Request, baseTemplateName string) {
+func (s Server) GetWidgetLayoutUpstreamChangesById(w http.ResponseWriter, r *http.Request, dashboardTemplateId int64) {
 	id := middlewares.GetUserIdentity(r.Context())
 	w.Header().Set("Content-Type", "application/json")
-	resp, status, err := service.ForkBaseTemplate(baseTemplateName, id)
+	resp, status, err := service.GetUpstreamChanges(s.registries, dashboardTemplateId, id)
 	if err != nil {
-		logrus.Errorf("Failed to fork base widget dashboard template: %v", err)
+		logrus.Errorf("Failed to retrieve upstream changes of dashboard template: %v", err)
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(api.ErrorResponse{Errors: errorPayloads(status, err)})
+		return
+	}
+	w.WriteHeader(status)
+	err = json.NewEncoder(w).Encode(service.FilterPermittedBaseTemplateChanges(r.Context(), s.registries, resp, id))
+	if err != nil {
+		logrus.Errorf("Failed to encode response: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (s Server) SyncWidgetLayoutBaseById(w http.ResponseWriter, r *http.Request, dashboardTemplateId int64, params api.SyncWidgetLayoutBaseByIdParams) {
+	id := middlewares.GetUserIdentity(r.Context())
+	w.Header().Set("Content-Type", "application/json")
+	resp, status, err := service.SyncDashboardTemplateBase(s.registries, dashboardTemplateId, id, params.IfMatch)
+	if err != nil {
+		logrus.Errorf("Failed to synchronize dashboard template with its base template: %v", err)
 		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(api.ErrorResponse{Errors: errorPayloads(status, err)})
+		return
+	}
+	w.Header().Set("ETag", resp.ETag())
+	w.WriteHeader(status)
+	err = json.NewEncoder(w).Encode(service.FilterPermittedTemplateWidgets(r.Context(), s.registries, resp, id))
+	if err != nil {
+		logrus.Errorf("Failed to encode response: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (s Server) SetWidgetLayoutVisibilityById(w http.ResponseWriter, r *http.Request, dashboardTemplateId int64, params api.SetWidgetLayoutVisibilityByIdParams) {
+	w.Header().Set("Content-Type", "application/json")
+	var visibilityRequest api.SetWidgetDashboardTemplateVisibilityRequest
+	if err := json.NewDecoder(r.Body).Decode(&visibilityRequest); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
 		_ = json.NewEncoder(w).Encode(api.ErrorResponse{Errors: []api.ErrorPayload{
 			{
-				Code:    status,
-				Message: err.Error(),
+				Code:    http.StatusBadRequest,
+				Message: "Invalid request body",
 			},
 		}})
 		return
 	}
+	id := middlewares.GetUserIdentity(r.Context())
+	resp, status, err := service.SetDashboardTemplateVisibility(dashboardTemplateId, visibilityRequest.Visibility, id, params.IfMatch)
+	if err != nil {
+		logrus.Errorf("Failed to change visibility of dashboard template: %v", err)
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(api.ErrorResponse{Errors: errorPayloads(status, err)})
+		return
+	}
+	w.Header().Set("ETag", resp.ETag())
 	w.WriteHeader(status)
-	err = json.NewEncoder(w).Encode(resp)
+	err = json.NewEncoder(w).Encode(service.FilterPermittedTemplateWidgets(r.Context(), s.registries, resp, id))
 	if err != nil {
 		logrus.Errorf("Failed to encode response: %v", err)
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
@@ -356,36 +587,125 @@ func (Server) ForkBaseWidgetDashboardTemplateByName(w http.ResponseWriter, r *ht
 	}
 }
 
-func (Server) GetWidgetMapping(w http.ResponseWriter, r *http.Request) {
+func (Server) GetWidgetLayoutSharesById(w http.ResponseWriter, r *http.Request, dashboardTemplateId int64) {
+	id := middlewares.GetUserIdentity(r.Context())
 	w.Header().Set("Content-Type", "application/json")
-	mappings := service.GetWidgetMappings()
-	resp := api.WidgetMappingResponse{
-		Data: mappings,
+	shares, status, err := service.GetDashboardTemplateShares(dashboardTemplateId, id)
+	if err != nil {
+		logrus.Errorf("Failed to get dashboard template share links: %v", err)
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(api.ErrorResponse{Errors: errorPayloads(status, err)})
+		return
 	}
-	w.WriteHeader(http.StatusOK)
-	err := json.NewEncoder(w).Encode(resp)
+	w.WriteHeader(status)
+	err = json.NewEncoder(w).Encode(api.DashboardTemplateShareListResponse{
+		Data: shares,
+		Meta: api.ListResponseMeta{
+			Count: len(shares),
+		},
+	})
 	if err != nil {
-		logrus.Errorf("Failed to encode widget mappings: %v", err)
+		logrus.Errorf("Failed to encode response: %v", err)
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 		return
 	}
 }
 
-func (Server) ImportWidgetLayout(w http.ResponseWriter, r *http.Request) {
+func (Server) CreateWidgetLayoutShareById(w http.ResponseWriter, r *http.Request, dashboardTemplateId int64) {
 	w.Header().Set("Content-Type", "application/json")
-	var template api.ImportWidgetDashboardTemplateRequest
-	if err := json.NewDecoder(r.Body).Decode(&template); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+	var shareRequest api.CreateWidgetDashboardTemplateShareRequest
+	if r.Body != nil {
+		// the request body is optional, share links without it never expire
+		if err := json.NewDecoder(r.Body).Decode(&shareRequest); err != nil && !errors.Is(err, io.EOF) {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(api.ErrorResponse{Errors: []api.ErrorPayload{
+				{
+					Code:    http.StatusBadRequest,
+					Message: "Invalid request body",
+				},
+			}})
+			return
+		}
+	}
+	id := middlewares.GetUserIdentity(r.Context())
+	resp, status, err := service.CreateDashboardTemplateShare(dashboardTemplateId, shareRequest.ExpiresAt, id)
+	if err != nil {
+		logrus.Errorf("Failed to create dashboard template share link: %v", err)
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(api.ErrorResponse{Errors: errorPayloads(status, err)})
+		return
+	}
+	w.WriteHeader(status)
+	err = json.NewEncoder(w).Encode(resp)
+	if err != nil {
+		logrus.Errorf("Failed to encode response: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 		return
 	}
+}
+
+func (Server) RevokeWidgetLayoutShareById(w http.ResponseWriter, r *http.Request, dashboardTemplateId int64, shareId int64) {
 	id := middlewares.GetUserIdentity(r.Context())
-	dr, status, err := service.ImportDashboardTemplate(
-		template,
-		id,
-	)
+	status, err := service.RevokeDashboardTemplateShare(dashboardTemplateId, shareId, id)
+	if err != nil {
+		logrus.Errorf("Failed to revoke dashboard template share link: %v", err)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(api.ErrorResponse{Errors: errorPayloads(status, err)})
+		return
+	}
+	w.WriteHeader(status)
+}
 
+func (s Server) GetSharedWidgetLayout(w http.ResponseWriter, r *http.Request, token string) {
+	id := middlewares.GetUserIdentity(r.Context())
+	w.Header().Set("Content-Type", "application/json")
+	resp, status, err := service.GetSharedDashboardTemplate(token)
 	if err != nil {
-		logrus.Errorf("Failed to import dashboard template: %v", err)
+		logrus.Errorf("Failed to get shared dashboard template: %v", err)
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(api.ErrorResponse{Errors: errorPayloads(status, err)})
+		return
+	}
+	w.WriteHeader(status)
+	err = json.NewEncoder(w).Encode(service.FilterPermittedSharedTemplateWidgets(r.Context(), s.registries, resp, id))
+	if err != nil {
+		logrus.Errorf("Failed to encode response: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (s Server) CopySharedWidgetLayout(w http.ResponseWriter, r *http.Request, token string) {
+	id := middlewares.GetUserIdentity(r.Context())
+	w.Header().Set("Content-Type", "application/json")
+	var copyRequest api.CopyWidgetDashboardTemplateRequest
+	if r.Body != nil {
+		_ = json.NewDecoder(r.Body).Decode(&copyRequest)
+	}
+
+	resp, status, err := service.CopySharedDashboardTemplate(s.registries, token, id, copyRequest.DashboardName)
+	if err != nil {
+		logrus.Errorf("Failed to copy shared dashboard template: %v", err)
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(api.ErrorResponse{Errors: errorPayloads(status, err)})
+		return
+	}
+	w.WriteHeader(status)
+	err = json.NewEncoder(w).Encode(service.FilterPermittedTemplateWidgets(r.Context(), s.registries, resp, id))
+	if err != nil {
+		logrus.Errorf("Failed to encode response: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (s Server) GetDeletedWidgetLayouts(w http.ResponseWriter, r *http.Request) {
+	id := middlewares.GetUserIdentity(r.Context())
+	w.Header().Set("Content-Type", "application/json")
+	templates, status, err := service.GetDeletedTemplates(id)
+	if err != nil {
+		logrus.Errorf("Failed to get deleted dashboard templates: %v", err)
 		w.WriteHeader(status)
 		_ = json.NewEncoder(w).Encode(api.ErrorResponse{Errors: []api.ErrorPayload{
 			{
@@ -396,8 +716,631 @@ func (Server) ImportWidgetLayout(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	w.WriteHeader(status)
-	err = json.NewEncoder(w).Encode(dr)
+	listResponse := api.DashboardTemplateListResponse{
+		Data: service.FilterPermittedWidgets(r.Context(), s.registries, templates, id),
+		Meta: api.ListResponseMeta{
+			Count: len(templates),
+		},
+	}
+
+	w.WriteHeader(status)
+	err = json.NewEncoder(w).Encode(listResponse)
+	if err != nil {
+		logrus.Errorf("Failed to encode response: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (s Server) RestoreDeletedWidgetLayoutById(w http.ResponseWriter, r *http.Request, dashboardTemplateId int64) {
+	id := middlewares.GetUserIdentity(r.Context())
+	w.Header().Set("Content-Type", "application/json")
+	resp, status, err := service.RestoreDeletedTemplate(dashboardTemplateId, id)
+	if err != nil {
+		logrus.Errorf("Failed to restore deleted dashboard template: %v", err)
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(api.ErrorResponse{Errors: []api.ErrorPayload{
+			{
+				Code:    status,
+				Message: err.Error(),
+			},
+		}})
+		return
+	}
+	w.WriteHeader(status)
+	err = json.NewEncoder(w).Encode(service.FilterPermittedTemplateWidgets(r.Context(), s.registries, resp, id))
+	if err != nil {
+		logrus.Errorf("Failed to encode response: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (s Server) GetBaseWidgetDashboardTemplates(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	templateMap := s.registries.BaseTemplates.All()
+
+	// Convert map to array to match API spec
+	templates := make([]api.BaseWidgetDashboardTemplate, 0, len(templateMap))
+	for _, template := range templateMap {
+		templates = append(templates, template)
+	}
+	if id, ok := middlewares.LookupUserIdentity(r.Context()); ok {
+		templates = service.FilterPermittedBaseTemplates(r.Context(), s.registries, templates, id)
+	}
+
+	// Create the new list response format
+	listResponse := api.BaseWidgetDashboardTemplateListResponse{
+		Data: templates,
+		Meta: api.ListResponseMeta{
+			Count: len(templates),
+		},
+	}
+
+	w.WriteHeader(http.StatusOK)
+	err := json.NewEncoder(w).Encode(listResponse)
+	if err != nil {
+		logrus.Errorf("Failed to encode base widget dashboard templates: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (s Server) GetBaseWidgetDashboardTemplateByName(w http.ResponseWriter, r *http.Request, baseTemplateName string) {
+	w.Header().Set("Content-Type", "application/json")
+	template, exists := s.registries.BaseTemplates.Get(baseTemplateName)
+	if !exists {
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(api.ErrorResponse{Errors: []api.ErrorPayload{
+			{
+				Code:    http.StatusNotFound,
+				Message: "Base template not found",
+			},
+		}})
+		return
+	}
+	if id, ok := middlewares.LookupUserIdentity(r.Context()); ok {
+		template = service.FilterPermittedBaseTemplates(r.Context(), s.registries, []api.BaseWidgetDashboardTemplate{template}, id)[0]
+	}
+	w.WriteHeader(http.StatusOK)
+	err := json.NewEncoder(w).Encode(template)
+	if err != nil {
+		logrus.Errorf("Failed to encode base widget dashboard template: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (s Server) ForkBaseWidgetDashboardTemplateByName(w http.ResponseWriter, r *http.Request, baseTemplateName string) {
+	id := middlewares.GetUserIdentity(r.Context())
+	w.Header().Set("Content-Type", "application/json")
+	resp, status, err := service.ForkBaseTemplate(s.registries, baseTemplateName, id)
+	if err != nil {
+		logrus.Errorf("Failed to fork base widget dashboard template: %v", err)
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(api.ErrorResponse{Errors: []api.ErrorPayload{
+			{
+				Code:    status,
+				Message: err.Error(),
+			},
+		}})
+		return
+	}
+	w.WriteHeader(status)
+	err = json.NewEncoder(w).Encode(service.FilterPermittedTemplateWidgets(r.Context(), s.registries, resp, id))
+	if err != nil {
+		logrus.Errorf("Failed to encode response: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (Server) GetOrgBaseWidgetDashboardTemplates(w http.ResponseWriter, r *http.Request) {
+	id := middlewares.GetUserIdentity(r.Context())
+	w.Header().Set("Content-Type", "application/json")
+	templates, status, err := service.GetOrgBaseTemplates(id)
+	if err != nil {
+		logrus.Errorf("Failed to get base templates of the organization: %v", err)
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(api.ErrorResponse{Errors: errorPayloads(status, err)})
+		return
+	}
+	w.WriteHeader(status)
+	err = json.NewEncoder(w).Encode(api.OrgBaseTemplateListResponse{
+		Data: templates,
+		Meta: api.ListResponseMeta{
+			Count: len(templates),
+		},
+	})
+	if err != nil {
+		logrus.Errorf("Failed to encode response: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (Server) GetOrgBaseWidgetDashboardTemplateByName(w http.ResponseWriter, r *http.Request, baseTemplateName string) {
+	id := middlewares.GetUserIdentity(r.Context())
+	w.Header().Set("Content-Type", "application/json")
+	resp, status, err := service.GetOrgBaseTemplate(baseTemplateName, id)
+	if err != nil {
+		logrus.Errorf("Failed to get base template of the organization: %v", err)
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(api.ErrorResponse{Errors: errorPayloads(status, err)})
+		return
+	}
+	w.WriteHeader(status)
+	err = json.NewEncoder(w).Encode(resp)
+	if err != nil {
+		logrus.Errorf("Failed to encode response: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (s Server) PutOrgBaseWidgetDashboardTemplateByName(w http.ResponseWriter, r *http.Request, baseTemplateName string) {
+	w.Header().Set("Content-Type", "application/json")
+	var templateRequest api.OrgBaseTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&templateRequest); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(api.ErrorResponse{Errors: []api.ErrorPayload{
+			{
+				Code:    http.StatusBadRequest,
+				Message: "Invalid request body",
+			},
+		}})
+		return
+	}
+	id := middlewares.GetUserIdentity(r.Context())
+	resp, status, err := service.PutOrgBaseTemplate(s.registries, baseTemplateName, templateRequest, id)
+	if err != nil {
+		logrus.Errorf("Failed to store base template of the organization: %v", err)
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(api.ErrorResponse{Errors: errorPayloads(status, err)})
+		return
+	}
+	w.WriteHeader(status)
+	err = json.NewEncoder(w).Encode(resp)
+	if err != nil {
+		logrus.Errorf("Failed to encode response: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (Server) DeleteOrgBaseWidgetDashboardTemplateByName(w http.ResponseWriter, r *http.Request, baseTemplateName string) {
+	id := middlewares.GetUserIdentity(r.Context())
+	status, err := service.DeleteOrgBaseTemplate(baseTemplateName, id)
+	if err != nil {
+		logrus.Errorf("Failed to delete base template of the organization: %v", err)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(api.ErrorResponse{Errors: errorPayloads(status, err)})
+		return
+	}
+	w.WriteHeader(status)
+}
+
+func (Server) GetOrgWidgetPolicy(w http.ResponseWriter, r *http.Request) {
+	id := middlewares.GetUserIdentity(r.Context())
+	w.Header().Set("Content-Type", "application/json")
+	resp, status, err := service.GetOrgWidgetPolicy(id)
+	if err != nil {
+		logrus.Errorf("Failed to get widget policy of the organization: %v", err)
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(api.ErrorResponse{Errors: errorPayloads(status, err)})
+		return
+	}
+	w.WriteHeader(status)
+	err = json.NewEncoder(w).Encode(resp)
+	if err != nil {
+		logrus.Errorf("Failed to encode response: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (Server) PutOrgWidgetPolicy(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	var policy api.WidgetPolicy
+	if err := json.NewDecoder(r.Body).Decode(&policy); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(api.ErrorResponse{Errors: []api.ErrorPayload{
+			{
+				Code:    http.StatusBadRequest,
+				Message: "Invalid request body",
+			},
+		}})
+		return
+	}
+	id := middlewares.GetUserIdentity(r.Context())
+	resp, status, err := service.PutOrgWidgetPolicy(policy, id)
+	if err != nil {
+		logrus.Errorf("Failed to store widget policy of the organization: %v", err)
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(api.ErrorResponse{Errors: errorPayloads(status, err)})
+		return
+	}
+	w.WriteHeader(status)
+	err = json.NewEncoder(w).Encode(resp)
+	if err != nil {
+		logrus.Errorf("Failed to encode response: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (Server) GetAuditEvents(w http.ResponseWriter, r *http.Request, params api.GetAuditEventsParams) {
+	id := middlewares.GetUserIdentity(r.Context())
+	w.Header().Set("Content-Type", "application/json")
+	resp, meta, status, err := service.GetAuditEvents(id, params)
+	if err != nil {
+		logrus.Errorf("Failed to get audit events of the organization: %v", err)
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(api.ErrorResponse{Errors: errorPayloads(status, err)})
+		return
+	}
+	listResponse := api.AuditEventListResponse{
+		Data: resp,
+		Meta: meta,
+	}
+	listResponse.Meta.Links = pageLinks(r.URL, meta)
+	w.WriteHeader(status)
+	err = json.NewEncoder(w).Encode(listResponse)
+	if err != nil {
+		logrus.Errorf("Failed to encode response: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (Server) GetWebhooks(w http.ResponseWriter, r *http.Request) {
+	id := middlewares.GetUserIdentity(r.Context())
+	w.Header().Set("Content-Type", "application/json")
+	webhooks, status, err := service.GetWebhooks(id)
+	if err != nil {
+		logrus.Errorf("Failed to get webhooks of the organization: %v", err)
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(api.ErrorResponse{Errors: errorPayloads(status, err)})
+		return
+	}
+	w.WriteHeader(status)
+	err = json.NewEncoder(w).Encode(api.WebhookListResponse{
+		Data: webhooks,
+		Meta: api.ListResponseMeta{
+			Count: len(webhooks),
+		},
+	})
+	if err != nil {
+		logrus.Errorf("Failed to encode response: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (Server) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	var webhookRequest api.WebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&webhookRequest); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(api.ErrorResponse{Errors: []api.ErrorPayload{
+			{
+				Code:    http.StatusBadRequest,
+				Message: "Invalid request body",
+			},
+		}})
+		return
+	}
+	id := middlewares.GetUserIdentity(r.Context())
+	resp, status, err := service.CreateWebhook(webhookRequest, id)
+	if err != nil {
+		logrus.Errorf("Failed to create webhook: %v", err)
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(api.ErrorResponse{Errors: errorPayloads(status, err)})
+		return
+	}
+	w.WriteHeader(status)
+	err = json.NewEncoder(w).Encode(resp)
+	if err != nil {
+		logrus.Errorf("Failed to encode response: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (Server) GetWebhookById(w http.ResponseWriter, r *http.Request, webhookId int64) {
+	id := middlewares.GetUserIdentity(r.Context())
+	w.Header().Set("Content-Type", "application/json")
+	resp, status, err := service.GetWebhookByID(webhookId, id)
+	if err != nil {
+		logrus.Errorf("Failed to get webhook: %v", err)
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(api.ErrorResponse{Errors: errorPayloads(status, err)})
+		return
+	}
+	w.WriteHeader(status)
+	err = json.NewEncoder(w).Encode(resp)
+	if err != nil {
+		logrus.Errorf("Failed to encode response: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (Server) DeleteWebhookById(w http.ResponseWriter, r *http.Request, webhookId int64) {
+	id := middlewares.GetUserIdentity(r.Context())
+	status, err := service.DeleteWebhook(webhookId, id)
+	if err != nil {
+		logrus.Errorf("Failed to delete webhook: %v", err)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(api.ErrorResponse{Errors: errorPayloads(status, err)})
+		return
+	}
+	w.WriteHeader(status)
+}
+
+func (Server) GetWebhookDeliveries(w http.ResponseWriter, r *http.Request, webhookId int64, params api.GetWebhookDeliveriesParams) {
+	id := middlewares.GetUserIdentity(r.Context())
+	w.Header().Set("Content-Type", "application/json")
+	resp, meta, status, err := service.GetWebhookDeliveries(webhookId, id, params)
+	if err != nil {
+		logrus.Errorf("Failed to get webhook deliveries: %v", err)
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(api.ErrorResponse{Errors: errorPayloads(status, err)})
+		return
+	}
+	listResponse := api.WebhookDeliveryListResponse{
+		Data: resp,
+		Meta: meta,
+	}
+	listResponse.Meta.Links = pageLinks(r.URL, meta)
+	w.WriteHeader(status)
+	err = json.NewEncoder(w).Encode(listResponse)
+	if err != nil {
+		logrus.Errorf("Failed to encode response: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (s Server) GetWidgetMapping(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	mappings := service.GetWidgetMappings(s.registries)
+	if id, ok := middlewares.LookupUserIdentity(r.Context()); ok {
+		mappings = service.FilterPermittedWidgetMappings(r.Context(), s.registries, mappings, id)
+	}
+	resp := api.WidgetMappingResponse{
+		Data: mappings,
+	}
+	w.WriteHeader(http.StatusOK)
+	err := json.NewEncoder(w).Encode(resp)
+	if err != nil {
+		logrus.Errorf("Failed to encode widget mappings: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (s Server) ReflowWidgetLayout(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	var request api.ReflowWidgetLayoutRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	tc, status, err := service.ReflowTemplateConfig(s.registries, request)
+	if err != nil {
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(api.ErrorResponse{Errors: errorPayloads(status, err)})
+		return
+	}
+
+	w.WriteHeader(status)
+	err = json.NewEncoder(w).Encode(tc)
+	if err != nil {
+		logrus.Errorf("Failed to encode response: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+}
+
+// decodeMigrated upgrades the exported JSON of the request body with migrate before decoding it into v. Payloads
+// which cannot be upgraded are answered with 400 and the reason, it returns false when the request was answered.
+func decodeMigrated(w http.ResponseWriter, r *http.Request, migrate func([]byte) ([]byte, error), v any) bool {
+	body, err := io.ReadAll(r.Body)
+	if err != nil || !json.Valid(body) {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return false
+	}
+	migrated, err := migrate(body)
+	if err != nil {
+		logrus.Warnf("Failed to upgrade imported payload: %v", err)
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(api.ErrorResponse{Errors: errorPayloads(http.StatusBadRequest, err)})
+		return false
+	}
+	if err := json.Unmarshal(migrated, v); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+func (s Server) ImportWidgetLayout(w http.ResponseWriter, r *http.Request, params api.ImportWidgetLayoutParams) {
+	w.Header().Set("Content-Type", "application/json")
+	var template api.ImportWidgetDashboardTemplateRequest
+	if !decodeMigrated(w, r, api.MigrateExportedTemplate, &template) {
+		return
+	}
+	id := middlewares.GetUserIdentity(r.Context())
+	dr, status, err := service.ImportDashboardTemplate(
+		s.registries,
+		template,
+		id,
+		params.ValidationMode,
+		params.Compact != nil && *params.Compact,
+	)
+
+	if err != nil {
+		logrus.Errorf("Failed to import dashboard template: %v", err)
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(api.ErrorResponse{Errors: errorPayloads(status, err)})
+		return
+	}
+
+	w.WriteHeader(status)
+	err = json.NewEncoder(w).Encode(service.FilterPermittedTemplateWidgets(r.Context(), s.registries, dr, id))
+	if err != nil {
+		logrus.Errorf("Failed to encode response: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+}
+
+// (POST /import/archive)
+func (s Server) ImportDashboardArchive(w http.ResponseWriter, r *http.Request, params api.ImportDashboardArchiveParams) {
+	w.Header().Set("Content-Type", "application/json")
+	var archive api.DashboardArchive
+	if !decodeMigrated(w, r, api.MigrateDashboardArchive, &archive) {
+		return
+	}
+	id := middlewares.GetUserIdentity(r.Context())
+	dryRun := params.DryRun != nil && *params.DryRun
+	items, status, err := service.ImportDashboardArchive(r.Context(), s.registries, archive, id, params.ValidationMode, params.Conflict, dryRun)
+	if err != nil {
+		logrus.Errorf("Failed to import dashboard archive: %v", err)
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(api.ErrorResponse{Errors: errorPayloads(status, err)})
+		return
+	}
+
+	resp := api.ArchiveImportResponse{
+		Data: make([]api.ArchiveImportResult, 0, len(items)),
+		Meta: api.ArchiveImportMeta{Count: len(items), DryRun: dryRun},
+	}
+	imported := make([]api.DashboardTemplate, 0, len(items))
+	for _, item := range items {
+		imported = append(imported, item.Template)
+	}
+	imported = service.FilterPermittedWidgets(r.Context(), s.registries, imported, id)
+	for i, item := range items {
+		result := api.ArchiveImportResult{Index: i, Action: item.Action, ConflictingTemplateId: item.ConflictingTemplateID}
+		if item.Err != nil {
+			errs := errorPayloads(item.Status, item.Err)
+			result.Errors = &errs
+		} else if item.Action != api.ArchiveImportSkipped {
+			result.DashboardTemplate = &imported[i]
+		}
+		switch item.Action {
+		case api.ArchiveImportCreated:
+			resp.Meta.Created++
+		case api.ArchiveImportRenamed:
+			resp.Meta.Renamed++
+		case api.ArchiveImportOverwritten:
+			resp.Meta.Overwritten++
+		case api.ArchiveImportSkipped:
+			resp.Meta.Skipped++
+		case api.ArchiveImportFailed:
+			resp.Meta.Failed++
+		}
+		resp.Data = append(resp.Data, result)
+	}
+
+	w.WriteHeader(status)
+	err = json.NewEncoder(w).Encode(resp)
+	if err != nil {
+		logrus.Errorf("Failed to encode response: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+}
+
+// decodeBulkImport reads the templates of a bulk import, sent either as a JSON array or as newline delimited JSON.
+func decodeBulkImport(r *http.Request) ([]json.RawMessage, error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "application/x-ndjson" {
+		var templates []json.RawMessage
+		err := json.NewDecoder(r.Body).Decode(&templates)
+		return templates, err
+	}
+	templates := []json.RawMessage{}
+	decoder := json.NewDecoder(r.Body)
+	// one template more than allowed is enough to reject the import
+	for len(templates) <= service.MaxBulkImportItems {
+		var template json.RawMessage
+		err := decoder.Decode(&template)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, template)
+	}
+	return templates, nil
+}
+
+// (POST /import/bulk)
+func (s Server) ImportWidgetLayoutBulk(w http.ResponseWriter, r *http.Request, params api.ImportWidgetLayoutBulkParams) {
+	w.Header().Set("Content-Type", "application/json")
+	payloads, err := decodeBulkImport(r)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	templates := make([]api.ImportWidgetDashboardTemplateRequest, len(payloads))
+	for i, payload := range payloads {
+		migrated, err := api.MigrateExportedTemplate(payload)
+		if err != nil {
+			logrus.Warnf("Failed to upgrade imported payload: %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(api.ErrorResponse{Errors: errorPayloads(http.StatusBadRequest, fmt.Errorf("template %d: %w", i, err))})
+			return
+		}
+		if err := json.Unmarshal(migrated, &templates[i]); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+	id := middlewares.GetUserIdentity(r.Context())
+	items, status, err := service.BulkImportDashboardTemplates(s.registries, templates, id, params.ValidationMode, params.Compact != nil && *params.Compact, params.Mode)
+	if err != nil {
+		logrus.Errorf("Failed to bulk import dashboard templates: %v", err)
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(api.ErrorResponse{Errors: errorPayloads(status, err)})
+		return
+	}
+
+	resp := api.BulkImportResponse{
+		Data: make([]api.BulkImportResult, 0, len(items)),
+		Meta: api.BulkImportMeta{Count: len(items)},
+	}
+	imported := make([]api.DashboardTemplate, 0, len(items))
+	for _, item := range items {
+		imported = append(imported, item.Template)
+	}
+	imported = service.FilterPermittedWidgets(r.Context(), s.registries, imported, id)
+	for i, item := range items {
+		result := api.BulkImportResult{Index: i}
+		switch {
+		case item.Imported:
+			result.Status = api.BulkImportItemImported
+			result.DashboardTemplate = &imported[i]
+			resp.Meta.Imported++
+		case item.Err != nil:
+			result.Status = api.BulkImportItemFailed
+			errs := errorPayloads(item.Status, item.Err)
+			result.Errors = &errs
+			resp.Meta.Failed++
+		default:
+			result.Status = api.BulkImportItemSkipped
+		}
+		resp.Data = append(resp.Data, result)
+	}
+
+	w.WriteHeader(status)
+	err = json.NewEncoder(w).Encode(resp)
 	if err != nil {
 		logrus.Errorf("Failed to encode response: %v", err)
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
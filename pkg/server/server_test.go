@@ -9,13 +9,18 @@ import (
 	"testing"
 	"time"
 
+	"github.com/RedHatInsights/widget-layout-backend/api"
 	"github.com/RedHatInsights/widget-layout-backend/pkg/config"
 	"github.com/RedHatInsights/widget-layout-backend/pkg/database"
 	"github.com/RedHatInsights/widget-layout-backend/pkg/models"
 	"github.com/RedHatInsights/widget-layout-backend/pkg/server"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/service"
 	"github.com/RedHatInsights/widget-layout-backend/pkg/test_util"
 	"github.com/go-chi/chi/v5"
+	"github.com/redhatinsights/platform-go-middlewares/v2/identity"
+	"github.com/stretchr/testify/require"
 	"github.com/subpop/xrhidgen"
+	"gorm.io/datatypes"
 )
 
 // Helper function to convert string to string pointer
@@ -33,6 +38,15 @@ func TestMain(m *testing.M) {
 	database.InitDb()
 	if err := database.DB.AutoMigrate(
 		&models.DashboardTemplate{},
+		&models.DashboardTemplateRevision{},
+		&models.BaseTemplateVersion{},
+		&models.DashboardTemplateShare{},
+		&models.OrgBaseTemplate{},
+		&models.OrgWidgetPolicy{},
+		&models.AuditEvent{},
+		&models.OutboxEvent{},
+		&models.Webhook{},
+		&models.WebhookDelivery{},
 	); err != nil {
 		log.Fatalf("Failed to migrate test database: %v", err)
 	}
@@ -59,9 +73,12 @@ func TestMain(m *testing.M) {
 	os.Exit(exitCode)
 }
 
+// testRegistries are the registries of every server created by setupRouter
+var testRegistries = service.NewRegistries()
+
 func setupRouter() *server.Server {
 	r := chi.NewRouter()
-	server := server.NewServer(r)
+	server := server.NewServer(r, testRegistries)
 	return server
 }
 
@@ -88,3 +105,50 @@ func withUniqueUserIdentityContext(req *http.Request) (*http.Request, string) {
 	ctx = context.WithValue(ctx, config.IdentityContextKey, identity)
 	return req.WithContext(ctx), userID
 }
+
+// registerPermissionTestMappings registers a widget only org admins are permitted to see and a widget everybody can see
+func registerPermissionTestMappings(t *testing.T) {
+	testRegistries.WidgetMappings.Replace(nil)
+	t.Cleanup(func() { testRegistries.WidgetMappings.Replace(nil) })
+	testRegistries.AddWidgetMapping(api.WidgetModuleFederationMetadata{
+		Scope:  "landing",
+		Module: "./AdminWidget",
+		Config: api.WidgetConfiguration{
+			Title:       "Admin",
+			Permissions: &[]api.Permission{{Method: "isOrgAdmin"}},
+		},
+	})
+	testRegistries.AddWidgetMapping(api.WidgetModuleFederationMetadata{
+		Scope:  "landing",
+		Module: "./PublicWidget",
+		Config: api.WidgetConfiguration{Title: "Public"},
+	})
+}
+
+// createHiddenWidgetDashboard registers the permission test mappings and stores a template with the admin widget above
+// the public widget in every layout. It returns the template and the identity of its owner, who is not an org admin.
+func createHiddenWidgetDashboard(t *testing.T) (api.DashboardTemplate, identity.XRHID) {
+	registerPermissionTestMappings(t)
+	testUserID := test_util.GetUniqueUserID()
+	items := datatypes.NewJSONType([]api.WidgetItem{
+		{Width: 1, Height: 2, X: intPtr(0), Y: intPtr(0), WidgetType: "landing-./AdminWidget"},
+		{Width: 1, Height: 2, X: intPtr(0), Y: intPtr(2), WidgetType: "landing-./PublicWidget"},
+	})
+	mockDashboard := test_util.MockDashboardTemplateWithSpecificUser(testUserID)
+	mockDashboard.TemplateConfig = api.DashboardTemplateConfig{Sm: items, Md: items, Lg: items, Xl: items}
+	require.NoError(t, database.DB.Create(&mockDashboard).Error)
+	isOrgAdmin := false
+	return mockDashboard, test_util.GenerateIdentityStructFromTemplate(
+		xrhidgen.Identity{},
+		xrhidgen.User{UserID: stringPtr(testUserID), IsOrgAdmin: &isOrgAdmin},
+		xrhidgen.Entitlements{},
+	)
+}
+
+// assertOnlyPublicWidget asserts that every layout of the configuration contains the public widget alone
+func assertOnlyPublicWidget(t *testing.T, tc api.DashboardTemplateConfig) {
+	for _, layout := range [][]api.WidgetItem{tc.Sm.Data(), tc.Md.Data(), tc.Lg.Data(), tc.Xl.Data()} {
+		require.Len(t, layout, 1)
+		require.Equal(t, "landing-./PublicWidget", layout[0].WidgetType)
+	}
+}
@@ -35,7 +35,7 @@ func TestSetWidgetLayoutDefaultById(t *testing.T) {
 		))
 		w := httptest.NewRecorder()
 
-		server.SetWidgetLayoutDefaultById(w, req, templateID)
+		server.SetWidgetLayoutDefaultById(w, req, templateID, api.SetWidgetLayoutDefaultByIdParams{})
 
 		assert.Equal(t, http.StatusOK, w.Code)
 		assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
@@ -77,7 +77,7 @@ func TestSetWidgetLayoutDefaultById(t *testing.T) {
 		))
 		w := httptest.NewRecorder()
 
-		server.SetWidgetLayoutDefaultById(w, req, templateID)
+		server.SetWidgetLayoutDefaultById(w, req, templateID, api.SetWidgetLayoutDefaultByIdParams{})
 
 		assert.Equal(t, http.StatusOK, w.Code)
 
@@ -101,7 +101,7 @@ func TestSetWidgetLayoutDefaultById(t *testing.T) {
 		req = withIdentityContext(req)
 		w := httptest.NewRecorder()
 
-		server.SetWidgetLayoutDefaultById(w, req, nonExistentID)
+		server.SetWidgetLayoutDefaultById(w, req, nonExistentID, api.SetWidgetLayoutDefaultByIdParams{})
 
 		assert.Equal(t, http.StatusNotFound, w.Code)
 
@@ -129,7 +129,7 @@ func TestSetWidgetLayoutDefaultById(t *testing.T) {
 		))
 		w := httptest.NewRecorder()
 
-		server.SetWidgetLayoutDefaultById(w, req, templateID)
+		server.SetWidgetLayoutDefaultById(w, req, templateID, api.SetWidgetLayoutDefaultByIdParams{})
 
 		assert.Equal(t, http.StatusForbidden, w.Code)
 
@@ -145,7 +145,7 @@ func TestSetWidgetLayoutDefaultById(t *testing.T) {
 		w := httptest.NewRecorder()
 
 		assert.Panics(t, func() {
-			server.SetWidgetLayoutDefaultById(w, req, int64(test_util.NoDBTestID))
+			server.SetWidgetLayoutDefaultById(w, req, int64(test_util.NoDBTestID), api.SetWidgetLayoutDefaultByIdParams{})
 		}, "Should panic when identity is missing from context")
 	})
 }
@@ -0,0 +1,140 @@
+package server_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/RedHatInsights/widget-layout-backend/api"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/database"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/test_util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/subpop/xrhidgen"
+)
+
+func TestSetWidgetLayoutVisibilityById(t *testing.T) {
+	t.Run("should share template with the organization", func(t *testing.T) {
+		server := setupRouter()
+
+		testUserID := test_util.GetUniqueUserID()
+		orgID := "org-" + testUserID
+		mockDashboard := test_util.MockDashboardTemplateWithSpecificUser(testUserID)
+		require.NoError(t, database.DB.Create(&mockDashboard).Error)
+
+		templateID := int64(mockDashboard.ID)
+		body, _ := json.Marshal(api.SetWidgetDashboardTemplateVisibilityRequest{
+			Visibility: api.VisibilityOrg,
+		})
+
+		req, _ := http.NewRequest("PATCH", fmt.Sprintf("/%d/visibility", templateID), bytes.NewReader(body))
+		req = withCustomIdentityContext(req, test_util.GenerateIdentityStructFromTemplate(
+			xrhidgen.Identity{OrgID: stringPtr(orgID)},
+			xrhidgen.User{UserID: stringPtr(testUserID)},
+			xrhidgen.Entitlements{},
+		))
+		w := httptest.NewRecorder()
+
+		server.SetWidgetLayoutVisibilityById(w, req, templateID, api.SetWidgetLayoutVisibilityByIdParams{IfMatch: stringPtr(mockDashboard.ETag())})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+		var resp api.DashboardTemplate
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		assert.Equal(t, api.VisibilityOrg, resp.Visibility)
+		assert.Equal(t, orgID, resp.OrgId)
+		assert.Equal(t, resp.ETag(), w.Header().Get("ETag"))
+
+		var dbTemplate api.DashboardTemplate
+		require.NoError(t, database.DB.First(&dbTemplate, templateID).Error)
+		assert.Equal(t, api.VisibilityOrg, dbTemplate.Visibility)
+	})
+
+	t.Run("should return 400 for invalid request body", func(t *testing.T) {
+		server := setupRouter()
+
+		req, _ := http.NewRequest("PATCH", "/123/visibility", bytes.NewReader([]byte("invalid json")))
+		req = withIdentityContext(req)
+		w := httptest.NewRecorder()
+
+		server.SetWidgetLayoutVisibilityById(w, req, int64(test_util.NoDBTestID), api.SetWidgetLayoutVisibilityByIdParams{})
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		var errorResponse api.ErrorResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&errorResponse))
+		assert.Contains(t, errorResponse.Errors[0].Message, "Invalid request body")
+	})
+
+	t.Run("should return 400 for unknown visibility", func(t *testing.T) {
+		server := setupRouter()
+
+		body, _ := json.Marshal(api.SetWidgetDashboardTemplateVisibilityRequest{
+			Visibility: "public",
+		})
+
+		req, _ := http.NewRequest("PATCH", "/123/visibility", bytes.NewReader(body))
+		req = withIdentityContext(req)
+		w := httptest.NewRecorder()
+
+		server.SetWidgetLayoutVisibilityById(w, req, int64(test_util.NoDBTestID), api.SetWidgetLayoutVisibilityByIdParams{})
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("should return 403 for org admins who do not own the template", func(t *testing.T) {
+		server := setupRouter()
+
+		orgID := "org-" + test_util.GetUniqueUserID()
+		mockDashboard := test_util.MockDashboardTemplateWithSpecificUser(test_util.GetUniqueUserID())
+		mockDashboard.OrgId = orgID
+		mockDashboard.Visibility = api.VisibilityOrg
+		require.NoError(t, database.DB.Create(&mockDashboard).Error)
+
+		templateID := int64(mockDashboard.ID)
+		body, _ := json.Marshal(api.SetWidgetDashboardTemplateVisibilityRequest{
+			Visibility: api.VisibilityPrivate,
+		})
+
+		req, _ := http.NewRequest("PATCH", fmt.Sprintf("/%d/visibility", templateID), bytes.NewReader(body))
+		req = withCustomIdentityContext(req, test_util.GenerateIdentityStructFromTemplate(
+			xrhidgen.Identity{OrgID: stringPtr(orgID)},
+			xrhidgen.User{UserID: stringPtr(test_util.GetUniqueUserID()), IsOrgAdmin: test_util.BoolPTR(true)},
+			xrhidgen.Entitlements{},
+		))
+		w := httptest.NewRecorder()
+
+		server.SetWidgetLayoutVisibilityById(w, req, templateID, api.SetWidgetLayoutVisibilityByIdParams{})
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("should return 412 for a stale ETag", func(t *testing.T) {
+		server := setupRouter()
+
+		testUserID := test_util.GetUniqueUserID()
+		mockDashboard := test_util.MockDashboardTemplateWithSpecificUser(testUserID)
+		require.NoError(t, database.DB.Create(&mockDashboard).Error)
+
+		templateID := int64(mockDashboard.ID)
+		body, _ := json.Marshal(api.SetWidgetDashboardTemplateVisibilityRequest{
+			Visibility: api.VisibilityOrg,
+		})
+
+		req, _ := http.NewRequest("PATCH", fmt.Sprintf("/%d/visibility", templateID), bytes.NewReader(body))
+		req = withCustomIdentityContext(req, test_util.GenerateIdentityStructFromTemplate(
+			xrhidgen.Identity{},
+			xrhidgen.User{UserID: stringPtr(testUserID)},
+			xrhidgen.Entitlements{},
+		))
+		w := httptest.NewRecorder()
+
+		server.SetWidgetLayoutVisibilityById(w, req, templateID, api.SetWidgetLayoutVisibilityByIdParams{IfMatch: stringPtr(`"0-0"`)})
+
+		assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	})
+}
@@ -0,0 +1,96 @@
+package server_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/RedHatInsights/widget-layout-backend/api"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/database"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/service"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/test_util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/subpop/xrhidgen"
+	"gorm.io/datatypes"
+)
+
+func syncBaseTemplate(name string, widgetTypes ...string) api.BaseWidgetDashboardTemplate {
+	items := make([]api.WidgetItem, 0, len(widgetTypes))
+	for i, widgetType := range widgetTypes {
+		items = append(items, api.WidgetItem{Width: 1, Height: 1, X: intPtr(0), Y: intPtr(i), WidgetType: widgetType})
+	}
+	layout := datatypes.NewJSONType(items)
+	return api.BaseWidgetDashboardTemplate{
+		Name:           name,
+		DisplayName:    name,
+		TemplateConfig: api.DashboardTemplateConfig{Sm: layout, Md: layout, Lg: layout, Xl: layout},
+	}
+}
+
+// forkOutdatedTemplate forks the base template and replaces it with a version retiring "old-widget" and shipping "new-widget"
+func forkOutdatedTemplate(t *testing.T, name string) (api.DashboardTemplate, interface{}) {
+	testUserID := test_util.GetUniqueUserID()
+	testIdentity := test_util.GenerateIdentityStructFromTemplate(
+		xrhidgen.Identity{},
+		xrhidgen.User{UserID: stringPtr(testUserID)},
+		xrhidgen.Entitlements{},
+	)
+	testRegistries.AddBaseTemplate(syncBaseTemplate(name, "kept-widget", "old-widget"))
+	fork, _, err := service.ForkBaseTemplate(testRegistries, name, testIdentity)
+	require.NoError(t, err)
+	testRegistries.AddBaseTemplate(syncBaseTemplate(name, "kept-widget", "new-widget"))
+	return fork, testIdentity
+}
+
+func TestSyncWidgetLayoutBaseById(t *testing.T) {
+	t.Run("should apply the changes of the base template", func(t *testing.T) {
+		server := setupRouter()
+		fork, testIdentity := forkOutdatedTemplate(t, "sync-server-base")
+
+		req := withCustomIdentityContext(httptest.NewRequest("POST", fmt.Sprintf("/%d/sync-base", fork.ID), nil), testIdentity)
+		w := httptest.NewRecorder()
+		server.SyncWidgetLayoutBaseById(w, req, int64(fork.ID), api.SyncWidgetLayoutBaseByIdParams{IfMatch: stringPtr(fork.ETag())})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var resp api.DashboardTemplate
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		assert.Equal(t, resp.ETag(), w.Header().Get("ETag"))
+		assert.NotEqual(t, fork.BaseVersion, resp.BaseVersion)
+		widgetTypes := []string{}
+		for _, wi := range resp.TemplateConfig.Xl.Data() {
+			widgetTypes = append(widgetTypes, wi.WidgetType)
+		}
+		assert.Equal(t, []string{"kept-widget", "new-widget"}, widgetTypes)
+
+		var dbTemplate api.DashboardTemplate
+		require.NoError(t, database.DB.First(&dbTemplate, fork.ID).Error)
+		assert.Equal(t, resp.BaseVersion, dbTemplate.BaseVersion)
+	})
+
+	t.Run("should return 412 when If-Match does not match", func(t *testing.T) {
+		server := setupRouter()
+		fork, testIdentity := forkOutdatedTemplate(t, "sync-server-stale-base")
+
+		req := withCustomIdentityContext(httptest.NewRequest("POST", fmt.Sprintf("/%d/sync-base", fork.ID), nil), testIdentity)
+		w := httptest.NewRecorder()
+		server.SyncWidgetLayoutBaseById(w, req, int64(fork.ID), api.SyncWidgetLayoutBaseByIdParams{IfMatch: stringPtr(`"0-0"`)})
+
+		assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	})
+
+	t.Run("should return 404 for non-existent template", func(t *testing.T) {
+		server := setupRouter()
+
+		req, _ := withUniqueUserIdentityContext(httptest.NewRequest("POST", fmt.Sprintf("/%d/sync-base", test_util.NonExistentID), nil))
+		w := httptest.NewRecorder()
+		server.SyncWidgetLayoutBaseById(w, req, int64(test_util.NonExistentID), api.SyncWidgetLayoutBaseByIdParams{})
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		var errorResponse api.ErrorResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&errorResponse))
+		require.NotEmpty(t, errorResponse.Errors)
+	})
+}
@@ -1,8 +1,11 @@
 package server_test
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -10,8 +13,11 @@ import (
 
 	"github.com/RedHatInsights/widget-layout-backend/api"
 	"github.com/RedHatInsights/widget-layout-backend/pkg/database"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/middlewares"
 	"github.com/RedHatInsights/widget-layout-backend/pkg/test_util"
+	"github.com/redhatinsights/platform-go-middlewares/v2/identity"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/subpop/xrhidgen"
 	"gorm.io/datatypes"
 )
@@ -28,7 +34,7 @@ func TestUpdateWidgetLayoutById(t *testing.T) {
 		req = withIdentityContext(req)
 		w := httptest.NewRecorder()
 
-		server.UpdateWidgetLayoutById(w, req, int64(test_util.NoDBTestID))
+		server.UpdateWidgetLayoutById(w, req, int64(test_util.NoDBTestID), api.UpdateWidgetLayoutByIdParams{})
 
 		assert.Equal(t, http.StatusBadRequest, w.Code, "Expected status code 400 for invalid JSON")
 		assert.Contains(t, w.Body.String(), "Invalid request body", "Expected error message for invalid JSON")
@@ -42,7 +48,7 @@ func TestUpdateWidgetLayoutById(t *testing.T) {
 		req = withIdentityContext(req)
 		w := httptest.NewRecorder()
 
-		server.UpdateWidgetLayoutById(w, req, int64(test_util.NoDBTestID))
+		server.UpdateWidgetLayoutById(w, req, int64(test_util.NoDBTestID), api.UpdateWidgetLayoutByIdParams{})
 
 		assert.Equal(t, http.StatusBadRequest, w.Code, "Expected status code 400 for empty body")
 	})
@@ -58,7 +64,7 @@ func TestUpdateWidgetLayoutById(t *testing.T) {
 		req = withIdentityContext(req)
 		w := httptest.NewRecorder()
 
-		server.UpdateWidgetLayoutById(w, req, int64(test_util.NoDBTestID))
+		server.UpdateWidgetLayoutById(w, req, int64(test_util.NoDBTestID), api.UpdateWidgetLayoutByIdParams{})
 
 		assert.Equal(t, http.StatusBadRequest, w.Code, "Expected status code 400 for malformed JSON")
 	})
@@ -73,10 +79,10 @@ func TestUpdateWidgetLayoutById(t *testing.T) {
 
 		templateID := int64(mockDashboard.ID)
 
-		// Valid structure but with complete widget data
+		// Valid structure but with complete widget data, narrow enough for the single column sm layout
 		testWidget := api.WidgetItem{
 			Height:     3,
-			Width:      2,
+			Width:      1,
 			X:          test_util.IntPTR(0),
 			WidgetType: "test-widget",
 			Y:          test_util.IntPTR(0),
@@ -106,7 +112,7 @@ func TestUpdateWidgetLayoutById(t *testing.T) {
 		))
 		w := httptest.NewRecorder()
 
-		server.UpdateWidgetLayoutById(w, req, templateID)
+		server.UpdateWidgetLayoutById(w, req, templateID, api.UpdateWidgetLayoutByIdParams{})
 
 		assert.NotEqual(t, http.StatusBadRequest, w.Code, "Should not return 400 for valid JSON structure")
 		assert.Equal(t, http.StatusOK, w.Code, "Expected status code 200 for valid JSON structure")
@@ -122,4 +128,227 @@ func TestUpdateWidgetLayoutById(t *testing.T) {
 			assert.Equal(t, validTemplate.TemplateConfig.Lg.Data()[0].Height, responseWidgets[0].Height)
 		}
 	})
+
+	t.Run("should honour If-Match and return the new ETag", func(t *testing.T) {
+		server := setupRouter()
+
+		req, userID := withUniqueUserIdentityContext(httptest.NewRequest("PATCH", "/", nil))
+		mockDashboard := test_util.MockDashboardTemplateWithSpecificUser(userID)
+		require.NoError(t, database.DB.Create(&mockDashboard).Error)
+		templateID := int64(mockDashboard.ID)
+		etag := mockDashboard.ETag()
+
+		items := datatypes.NewJSONType([]api.WidgetItem{
+			{Width: 1, Height: 2, X: intPtr(0), Y: intPtr(0), WidgetType: "widget1"},
+		})
+		requestBody, err := json.Marshal(api.DashboardTemplate{TemplateConfig: api.DashboardTemplateConfig{Sm: items, Md: items, Lg: items, Xl: items}})
+		require.NoError(t, err)
+
+		req.Body = io.NopCloser(bytes.NewReader(requestBody))
+		w := httptest.NewRecorder()
+		server.UpdateWidgetLayoutById(w, req, templateID, api.UpdateWidgetLayoutByIdParams{IfMatch: &etag})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		newETag := w.Header().Get("ETag")
+		assert.NotEmpty(t, newETag)
+		assert.NotEqual(t, etag, newETag, "ETag should change after the update")
+
+		// a second update based on the old version is rejected
+		req.Body = io.NopCloser(bytes.NewReader(requestBody))
+		w = httptest.NewRecorder()
+		server.UpdateWidgetLayoutById(w, req, templateID, api.UpdateWidgetLayoutByIdParams{IfMatch: &etag})
+
+		assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+		var errorResponse api.ErrorResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&errorResponse))
+		require.NotEmpty(t, errorResponse.Errors)
+		assert.Equal(t, http.StatusPreconditionFailed, errorResponse.Errors[0].Code)
+	})
+
+	t.Run("should return an error for every conflicting widget pair", func(t *testing.T) {
+		server := setupRouter()
+
+		items := datatypes.NewJSONType([]api.WidgetItem{
+			{Width: 1, Height: 2, X: intPtr(0), Y: intPtr(0), WidgetType: "widget1"},
+			{Width: 1, Height: 2, X: intPtr(0), Y: intPtr(1), WidgetType: "widget2"},
+		})
+		requestBody, err := json.Marshal(api.DashboardTemplate{TemplateConfig: api.DashboardTemplateConfig{Sm: items, Md: items, Lg: items, Xl: items}})
+		require.NoError(t, err)
+
+		req, userID := withUniqueUserIdentityContext(httptest.NewRequest("PATCH", "/", bytes.NewReader(requestBody)))
+		mockDashboard := test_util.MockDashboardTemplateWithSpecificUser(userID)
+		require.NoError(t, database.DB.Create(&mockDashboard).Error)
+
+		w := httptest.NewRecorder()
+		server.UpdateWidgetLayoutById(w, req, int64(mockDashboard.ID), api.UpdateWidgetLayoutByIdParams{})
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		var errorResponse api.ErrorResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&errorResponse))
+		require.Len(t, errorResponse.Errors, 4, "Expected one overlap error per layout")
+		breakpoints := []api.GridSizes{}
+		for _, payload := range errorResponse.Errors {
+			assert.Equal(t, http.StatusBadRequest, payload.Code)
+			assert.Contains(t, payload.Message, "overlap")
+			assert.Equal(t, api.LayoutIssueOverlap, payload.Kind)
+			assert.Equal(t, []string{"widget1", "widget2"}, payload.Widgets)
+			breakpoints = append(breakpoints, payload.Breakpoint)
+		}
+		assert.ElementsMatch(t, []api.GridSizes{api.Sm, api.Md, api.Lg, api.Xl}, breakpoints)
+	})
+
+	t.Run("should compact the layouts when requested", func(t *testing.T) {
+		server := setupRouter()
+
+		items := datatypes.NewJSONType([]api.WidgetItem{
+			{Width: 1, Height: 2, X: intPtr(0), Y: intPtr(0), WidgetType: "widget1"},
+			{Width: 1, Height: 2, X: intPtr(0), Y: intPtr(1), WidgetType: "widget2"},
+		})
+		requestBody, err := json.Marshal(api.DashboardTemplate{TemplateConfig: api.DashboardTemplateConfig{Sm: items, Md: items, Lg: items, Xl: items}})
+		require.NoError(t, err)
+
+		req, userID := withUniqueUserIdentityContext(httptest.NewRequest("PATCH", "/?compact=true", bytes.NewReader(requestBody)))
+		mockDashboard := test_util.MockDashboardTemplateWithSpecificUser(userID)
+		require.NoError(t, database.DB.Create(&mockDashboard).Error)
+
+		compact := true
+		w := httptest.NewRecorder()
+		server.UpdateWidgetLayoutById(w, req, int64(mockDashboard.ID), api.UpdateWidgetLayoutByIdParams{Compact: &compact})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var resp api.DashboardTemplate
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		md := resp.TemplateConfig.Md.Data()
+		require.Len(t, md, 2)
+		assert.Equal(t, 0, *md[0].Y)
+		assert.Equal(t, 2, *md[1].Y, "Overlapping widget should be pushed below the first one")
+	})
+
+	t.Run("should return 422 for every violated rule of the widget policy", func(t *testing.T) {
+		server := setupRouter()
+
+		testUserID := test_util.GetUniqueUserID()
+		orgID := "org-" + testUserID
+		require.NoError(t, database.DB.Create(&api.OrgWidgetPolicy{
+			OrgId:  orgID,
+			Policy: api.WidgetPolicy{ForbiddenWidgets: []string{"widget2"}},
+		}).Error)
+		mockDashboard := test_util.MockDashboardTemplateWithSpecificUser(testUserID)
+		require.NoError(t, database.DB.Create(&mockDashboard).Error)
+
+		items := datatypes.NewJSONType([]api.WidgetItem{
+			{Width: 1, Height: 2, X: intPtr(0), Y: intPtr(0), WidgetType: "widget1"},
+			{Width: 1, Height: 2, X: intPtr(0), Y: intPtr(2), WidgetType: "widget2"},
+		})
+		requestBody, err := json.Marshal(api.DashboardTemplate{TemplateConfig: api.DashboardTemplateConfig{Sm: items, Md: items, Lg: items, Xl: items}})
+		require.NoError(t, err)
+
+		req := withCustomIdentityContext(httptest.NewRequest("PATCH", "/", bytes.NewReader(requestBody)), test_util.GenerateIdentityStructFromTemplate(
+			xrhidgen.Identity{OrgID: stringPtr(orgID)},
+			xrhidgen.User{UserID: stringPtr(testUserID)},
+			xrhidgen.Entitlements{},
+		))
+		w := httptest.NewRecorder()
+		server.UpdateWidgetLayoutById(w, req, int64(mockDashboard.ID), api.UpdateWidgetLayoutByIdParams{})
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		var errorResponse api.ErrorResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&errorResponse))
+		assert.Len(t, errorResponse.Errors, 4, "Expected one violation per layout")
+		for _, payload := range errorResponse.Errors {
+			assert.Equal(t, http.StatusUnprocessableEntity, payload.Code)
+			assert.Contains(t, payload.Message, "forbidden rule: widget widget2")
+		}
+	})
+
+	t.Run("should keep widgets hidden from the user when saving a loaded layout", func(t *testing.T) {
+		server := setupRouter()
+		mockDashboard, userIdentity := createHiddenWidgetDashboard(t)
+
+		w := httptest.NewRecorder()
+		server.GetWidgetLayoutById(w, withCustomIdentityContext(httptest.NewRequest("GET", "/", nil), userIdentity), int64(mockDashboard.ID))
+		require.Equal(t, http.StatusOK, w.Code)
+		var loaded api.DashboardTemplate
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&loaded))
+		assertOnlyPublicWidget(t, loaded.TemplateConfig)
+
+		requestBody, err := json.Marshal(loaded)
+		require.NoError(t, err)
+		w = httptest.NewRecorder()
+		server.UpdateWidgetLayoutById(w, withCustomIdentityContext(httptest.NewRequest("PATCH", "/", bytes.NewReader(requestBody)), userIdentity), int64(mockDashboard.ID), api.UpdateWidgetLayoutByIdParams{})
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var updated api.DashboardTemplate
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&updated))
+		assertOnlyPublicWidget(t, updated.TemplateConfig)
+
+		var dbTemplate api.DashboardTemplate
+		require.NoError(t, database.DB.First(&dbTemplate, mockDashboard.ID).Error)
+		for _, layout := range [][]api.WidgetItem{dbTemplate.TemplateConfig.Sm.Data(), dbTemplate.TemplateConfig.Md.Data(), dbTemplate.TemplateConfig.Lg.Data(), dbTemplate.TemplateConfig.Xl.Data()} {
+			assert.ElementsMatch(t, mockDashboard.TemplateConfig.Lg.Data(), layout, "The stored layout should keep the hidden widget")
+		}
+	})
+
+	t.Run("should evaluate the feature flags of the caller once per request", func(t *testing.T) {
+		server := setupRouter()
+		testRegistries.WidgetMappings.Replace(nil)
+		previousProvider := testRegistries.FlagProvider
+		provider := &countingFlagProvider{flags: map[string]bool{"landing.preview-widget": false}}
+		testRegistries.FlagProvider = provider
+		t.Cleanup(func() {
+			testRegistries.WidgetMappings.Replace(nil)
+			testRegistries.FlagProvider = previousProvider
+		})
+		previewFlag := "landing.preview-widget"
+		testRegistries.AddWidgetMapping(api.WidgetModuleFederationMetadata{
+			Scope:       "landing",
+			Module:      "./PreviewWidget",
+			FeatureFlag: &previewFlag,
+			Config:      api.WidgetConfiguration{Title: "Preview"},
+		})
+		testRegistries.AddWidgetMapping(api.WidgetModuleFederationMetadata{
+			Scope:  "landing",
+			Module: "./PublicWidget",
+			Config: api.WidgetConfiguration{Title: "Public"},
+		})
+
+		testUserID := test_util.GetUniqueUserID()
+		mockDashboard := test_util.MockDashboardTemplateWithSpecificUser(testUserID)
+		stored := datatypes.NewJSONType([]api.WidgetItem{
+			{Width: 1, Height: 2, X: intPtr(0), Y: intPtr(0), WidgetType: "landing-./PreviewWidget"},
+			{Width: 1, Height: 2, X: intPtr(0), Y: intPtr(2), WidgetType: "landing-./PublicWidget"},
+		})
+		mockDashboard.TemplateConfig = api.DashboardTemplateConfig{Sm: stored, Md: stored, Lg: stored, Xl: stored}
+		require.NoError(t, database.DB.Create(&mockDashboard).Error)
+		userIdentity := test_util.GenerateIdentityStructFromTemplate(xrhidgen.Identity{}, xrhidgen.User{UserID: stringPtr(testUserID)}, xrhidgen.Entitlements{})
+
+		edited := datatypes.NewJSONType([]api.WidgetItem{{Width: 1, Height: 2, X: intPtr(0), Y: intPtr(0), WidgetType: "landing-./PublicWidget"}})
+		requestBody, err := json.Marshal(api.DashboardTemplate{TemplateConfig: api.DashboardTemplateConfig{Sm: edited, Md: edited, Lg: edited, Xl: edited}})
+		require.NoError(t, err)
+		handler := middlewares.InjectRequestFlags(testRegistries.FlagProvider)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			server.UpdateWidgetLayoutById(w, r, int64(mockDashboard.ID), api.UpdateWidgetLayoutByIdParams{})
+		}))
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, withCustomIdentityContext(httptest.NewRequest("PATCH", "/", bytes.NewReader(requestBody)), userIdentity))
+
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Equal(t, 1, provider.calls, "The flags should be evaluated once for the service and the response")
+		var updated api.DashboardTemplate
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&updated))
+		assertOnlyPublicWidget(t, updated.TemplateConfig)
+
+		var dbTemplate api.DashboardTemplate
+		require.NoError(t, database.DB.First(&dbTemplate, mockDashboard.ID).Error)
+		assert.Len(t, dbTemplate.TemplateConfig.Lg.Data(), 2, "The stored layout should keep the flagged widget")
+	})
+}
+
+// countingFlagProvider returns fixed flags and counts how often it is asked for them
+type countingFlagProvider struct {
+	calls int
+	flags map[string]bool
+}
+
+func (cp *countingFlagProvider) Flags(context.Context, identity.XRHID) (map[string]bool, error) {
+	cp.calls++
+	return cp.flags, nil
 }
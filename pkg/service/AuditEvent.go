@@ -0,0 +1,72 @@
+package service
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/RedHatInsights/widget-layout-backend/api"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/database"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/layout"
+	"github.com/redhatinsights/platform-go-middlewares/v2/identity"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// recordAuditEvent appends a change of a template made by the identity to the audit log. It is written in the
+// transaction of the change, so the log never misses a change nor records one that was rolled back.
+func recordAuditEvent(tx *gorm.DB, id identity.XRHID, action api.AuditAction, templateID uint, summary string) error {
+	return tx.Create(&api.AuditEvent{
+		OrgId:               id.Identity.OrgID,
+		UserId:              id.Identity.User.UserID,
+		Action:              action,
+		DashboardTemplateId: templateID,
+		Summary:             summary,
+	}).Error
+}
+
+// layoutSummary summarizes the changes of the layouts of a template for the audit log.
+func layoutSummary(before, after api.DashboardTemplateConfig) string {
+	diff := layout.DiffConfig(before, after)
+	if diff.IsEmpty() {
+		return "layouts unchanged"
+	}
+	return diff.String()
+}
+
+// GetAuditEvents returns a page of the audit events of the organization of the identity, newest first.
+func GetAuditEvents(id identity.XRHID, params api.GetAuditEventsParams) ([]api.AuditEvent, api.ListResponseMeta, int, error) {
+	if status, err := checkOrgAdmin(id); err != nil {
+		return nil, api.ListResponseMeta{}, status, err
+	}
+	limit, offset, err := pageOptions(params.Limit, params.Offset)
+	if err != nil {
+		return nil, api.ListResponseMeta{}, http.StatusBadRequest, err
+	}
+	if params.From != nil && params.To != nil && !params.From.Before(*params.To) {
+		return nil, api.ListResponseMeta{}, http.StatusBadRequest, errors.New("from must be before to")
+	}
+
+	query := database.DB.Model(&api.AuditEvent{}).Where("org_id = ?", id.Identity.OrgID)
+	if params.From != nil {
+		query = query.Where("created_at >= ?", *params.From)
+	}
+	if params.To != nil {
+		query = query.Where("created_at < ?", *params.To)
+	}
+	if params.UserId != nil && *params.UserId != "" {
+		query = query.Where("user_id = ?", *params.UserId)
+	}
+
+	var total int64
+	events := []api.AuditEvent{}
+	err = query.Session(&gorm.Session{}).Count(&total).Error
+	if err == nil {
+		err = query.Order("created_at DESC, id DESC").Limit(limit).Offset(offset).Find(&events).Error
+	}
+	if err != nil {
+		logrus.Errorf("Failed to retrieve audit events of organization %s: %v", id.Identity.OrgID, err)
+		return nil, api.ListResponseMeta{}, http.StatusInternalServerError, err
+	}
+	totalCount := int(total)
+	return events, api.ListResponseMeta{Count: len(events), Total: &totalCount, Limit: &limit, Offset: &offset}, http.StatusOK, nil
+}
@@ -0,0 +1,134 @@
+package service_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/RedHatInsights/widget-layout-backend/api"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/database"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/service"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/test_util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func templateAuditEvents(t *testing.T, templateID uint) []api.AuditEvent {
+	var events []api.AuditEvent
+	require.NoError(t, database.DB.Where("dashboard_template_id = ?", templateID).Order("id").Find(&events).Error)
+	return events
+}
+
+func TestAuditEvents(t *testing.T) {
+	t.Run("should record the changes of a template with their actor", func(t *testing.T) {
+		addPolicyBaseTemplate("audit-base", api.WidgetPolicy{}, "widget1", "widget2")
+		orgID := "org-" + test_util.GetUniqueUserID()
+		testUserID := test_util.GetUniqueUserID()
+		testIdentity := orgIdentity(testUserID, orgID, false)
+
+		template, _, err := service.ForkBaseTemplate(testRegistries, "audit-base", testIdentity)
+		require.NoError(t, err)
+		_, _, err = service.UpdateDashboardTemplate(context.Background(), testRegistries, int64(template.ID), test_util.MockTemplateConfig("widget1", "widget3"), testIdentity, nil, false, nil)
+		require.NoError(t, err)
+		_, _, err = service.RenameDashboardTemplate(int64(template.ID), "Renamed", testIdentity, nil)
+		require.NoError(t, err)
+		_, _, err = service.ChangeDefaultTemplate(int64(template.ID), testIdentity, nil)
+		require.NoError(t, err)
+		_, err = service.DeleteDashboardTemplate(int64(template.ID), testIdentity)
+		require.NoError(t, err)
+		_, _, err = service.RestoreDeletedTemplate(int64(template.ID), testIdentity)
+		require.NoError(t, err)
+
+		events := templateAuditEvents(t, template.ID)
+		require.Len(t, events, 6)
+		actions := make([]api.AuditAction, 0, len(events))
+		for _, event := range events {
+			actions = append(actions, event.Action)
+			assert.Equal(t, testUserID, event.UserId)
+			assert.Equal(t, orgID, event.OrgId)
+		}
+		assert.Equal(t, []api.AuditAction{
+			api.AuditActionFork,
+			api.AuditActionUpdate,
+			api.AuditActionRename,
+			api.AuditActionSetDefault,
+			api.AuditActionDelete,
+			api.AuditActionRestore,
+		}, actions)
+		assert.Equal(t, "forked from base template audit-base", events[0].Summary)
+		assert.Equal(t, "added widget3; removed widget2", events[1].Summary)
+		assert.Contains(t, events[2].Summary, `to "Renamed"`)
+	})
+
+	t.Run("should not record changes which were rolled back", func(t *testing.T) {
+		testUserID := test_util.GetUniqueUserID()
+		template := createTestTemplate(testUserID, "audit-base", "Audit Base")
+		require.NoError(t, database.DB.Create(&template).Error)
+
+		stale := "\"stale\""
+		_, status, err := service.RenameDashboardTemplate(int64(template.ID), "Renamed", orgIdentity(testUserID, "", false), &stale)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusPreconditionFailed, status)
+		assert.Empty(t, templateAuditEvents(t, template.ID))
+	})
+
+	t.Run("should record copies on the new template", func(t *testing.T) {
+		testUserID := test_util.GetUniqueUserID()
+		testIdentity := orgIdentity(testUserID, "", false)
+		template := createTestTemplate(testUserID, "audit-base", "Audit Base")
+		require.NoError(t, database.DB.Create(&template).Error)
+
+		copied, _, err := service.CopyDashboardTemplate(testRegistries, int64(template.ID), testIdentity, nil)
+		require.NoError(t, err)
+
+		assert.Empty(t, templateAuditEvents(t, template.ID))
+		events := templateAuditEvents(t, copied.ID)
+		require.Len(t, events, 1)
+		assert.Equal(t, api.AuditActionCopy, events[0].Action)
+	})
+
+	t.Run("should let org admins filter the audit log of their organization", func(t *testing.T) {
+		orgID := "org-" + test_util.GetUniqueUserID()
+		member := orgIdentity(test_util.GetUniqueUserID(), orgID, false)
+		otherMember := orgIdentity(test_util.GetUniqueUserID(), orgID, false)
+		admin := orgIdentity(test_util.GetUniqueUserID(), orgID, true)
+		now := time.Now()
+		for _, event := range []api.AuditEvent{
+			{OrgId: orgID, UserId: member.Identity.User.UserID, Action: api.AuditActionCreate, DashboardTemplateId: 1, CreatedAt: now.Add(-2 * time.Hour)},
+			{OrgId: orgID, UserId: member.Identity.User.UserID, Action: api.AuditActionUpdate, DashboardTemplateId: 1, CreatedAt: now.Add(-time.Hour)},
+			{OrgId: orgID, UserId: otherMember.Identity.User.UserID, Action: api.AuditActionDelete, DashboardTemplateId: 2, CreatedAt: now},
+			{OrgId: "other-" + orgID, UserId: member.Identity.User.UserID, Action: api.AuditActionDelete, DashboardTemplateId: 3, CreatedAt: now},
+		} {
+			require.NoError(t, database.DB.Create(&event).Error)
+		}
+
+		_, _, status, err := service.GetAuditEvents(member, api.GetAuditEventsParams{})
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusForbidden, status)
+
+		events, meta, status, err := service.GetAuditEvents(admin, api.GetAuditEventsParams{})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+		require.Len(t, events, 3)
+		assert.Equal(t, 3, *meta.Total)
+		assert.Equal(t, api.AuditActionDelete, events[0].Action, "The newest events should come first")
+		assert.Equal(t, api.AuditActionCreate, events[2].Action)
+
+		from := now.Add(-90 * time.Minute)
+		events, _, _, err = service.GetAuditEvents(admin, api.GetAuditEventsParams{From: &from, UserId: &member.Identity.User.UserID})
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		assert.Equal(t, api.AuditActionUpdate, events[0].Action)
+
+		to := now.Add(-90 * time.Minute)
+		events, _, _, err = service.GetAuditEvents(admin, api.GetAuditEventsParams{To: &to})
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		assert.Equal(t, api.AuditActionCreate, events[0].Action)
+
+		_, _, status, err = service.GetAuditEvents(admin, api.GetAuditEventsParams{From: &now, To: &to})
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusBadRequest, status)
+	})
+}
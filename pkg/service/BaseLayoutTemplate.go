@@ -2,34 +2,57 @@ package service
 
 import (
 	"encoding/json"
+	"fmt"
 
 	"github.com/RedHatInsights/widget-layout-backend/api"
-	"github.com/RedHatInsights/widget-layout-backend/pkg/config"
 	"github.com/sirupsen/logrus"
 )
 
-var BaseTemplateRegistry = api.BaseWidgetDashboardTemplateRegistry{}
-
-// LoadBaseTemplatesFromConfig loads base widget dashboard templates from config string.
-func LoadBaseTemplatesFromConfig(configString string) error {
+// LoadBaseTemplatesFromConfig replaces the base widget dashboard templates with the templates of the config string.
+// The registry is only changed when every template could be parsed, reflowed and has a valid layout matching the widget mappings.
+func (r *Registries) LoadBaseTemplatesFromConfig(configString string) error {
 	if configString == "" {
 		return nil
 	}
+	r.loadMu.Lock()
+	defer r.loadMu.Unlock()
+	return r.loadBaseTemplates(configString, false)
+}
+
+// loadBaseTemplates replaces the base widget dashboard templates with the templates of the config string. With
+// dropUnmapped a template which does not match the widget mappings is left out and logged instead of failing the load.
+func (r *Registries) loadBaseTemplates(configString string, dropUnmapped bool) error {
 	var baseTemplates []api.BaseWidgetDashboardTemplate
 	err := json.Unmarshal([]byte(configString), &baseTemplates)
 	if err != nil {
 		return err
 	}
-	for _, bt := range baseTemplates {
-		BaseTemplateRegistry.AddBase(bt)
+	templates := make(map[string]api.BaseWidgetDashboardTemplate, len(baseTemplates))
+	for idx, bt := range baseTemplates {
+		if bt.Name == "" {
+			return fmt.Errorf("base widget dashboard template[%d] requires a name", idx)
+		}
+		// Base templates may author a single breakpoint, derive the others before anything else
+		reflowed, err := reflowMissingBreakpoints(r, bt.TemplateConfig, nil)
+		if err != nil {
+			return fmt.Errorf("failed to reflow base widget dashboard template %s: %w", bt.Name, err)
+		}
+		bt.TemplateConfig = reflowed
+		if err := bt.TemplateConfig.IsValid(); err != nil {
+			return fmt.Errorf("base widget dashboard template %s has an invalid layout: %w", bt.Name, err)
+		}
+		if _, err := bt.TemplateConfig.CheckWidgetMappings(r.WidgetMappings, api.ValidationModeStrict); err != nil {
+			if dropUnmapped {
+				logrus.Errorf("Skipping base widget dashboard template %s, it does not match the widget mappings: %v", bt.Name, err)
+				continue
+			}
+			return fmt.Errorf("base widget dashboard template %s does not match the widget mappings: %w", bt.Name, err)
+		}
+		templates[bt.Name] = bt
 	}
-	logrus.Infof("Loaded %d base widget dashboard templates", len(BaseTemplateRegistry.BaseWidgetDashboardTemplates))
+	snapshot := r.BaseTemplates.Replace(templates)
+	r.baseTemplatesSource = configString
+	recordRegistryLoad(baseTemplatesRegistry)
+	logrus.Infof("Loaded %d base widget dashboard templates, version %s", snapshot.Len(), snapshot.Version())
 	return nil
 }
-
-func init() {
-	cfg := config.GetConfig()
-	if err := LoadBaseTemplatesFromConfig(cfg.BaseWidgetDashboardTemplates); err != nil {
-		logrus.Fatalln("Failed to parse base widget dashboard templates, shutting down the service", err)
-	}
-}
@@ -136,16 +136,16 @@ const invalidJSON = `[
 func TestLoadBaseTemplatesFromConfig(t *testing.T) {
 	t.Run("should load valid base templates from JSON config", func(t *testing.T) {
 		// Reset registry for clean test
-		service.BaseTemplateRegistry = api.BaseWidgetDashboardTemplateRegistry{}
+		testRegistries.BaseTemplates.Replace(nil)
 
-		err := service.LoadBaseTemplatesFromConfig(validBaseTemplateJSON)
+		err := testRegistries.LoadBaseTemplatesFromConfig(validBaseTemplateJSON)
 		require.NoError(t, err)
 
 		// Verify templates were loaded
-		assert.Len(t, service.BaseTemplateRegistry.BaseWidgetDashboardTemplates, 2)
+		assert.Len(t, testRegistries.BaseTemplates.All(), 2)
 
 		// Verify first template
-		template1, exists := service.BaseTemplateRegistry.GetBase("test-template-1")
+		template1, exists := testRegistries.BaseTemplates.Get("test-template-1")
 		assert.True(t, exists)
 		assert.Equal(t, "test-template-1", template1.Name)
 		assert.Equal(t, "Test Template 1", template1.DisplayName)
@@ -157,7 +157,7 @@ func TestLoadBaseTemplatesFromConfig(t *testing.T) {
 		assert.NotNil(t, template1.TemplateConfig.Xl)
 
 		// Verify second template
-		template2, exists := service.BaseTemplateRegistry.GetBase("test-template-2")
+		template2, exists := testRegistries.BaseTemplates.Get("test-template-2")
 		assert.True(t, exists)
 		assert.Equal(t, "test-template-2", template2.Name)
 		assert.Equal(t, "Test Template 2", template2.DisplayName)
@@ -165,53 +165,53 @@ func TestLoadBaseTemplatesFromConfig(t *testing.T) {
 
 	t.Run("should handle empty config string", func(t *testing.T) {
 		// Reset registry for clean test
-		service.BaseTemplateRegistry = api.BaseWidgetDashboardTemplateRegistry{}
+		testRegistries.BaseTemplates.Replace(nil)
 
-		err := service.LoadBaseTemplatesFromConfig("")
+		err := testRegistries.LoadBaseTemplatesFromConfig("")
 		require.NoError(t, err)
 
 		// Registry should remain empty
-		assert.Empty(t, service.BaseTemplateRegistry.BaseWidgetDashboardTemplates)
+		assert.Empty(t, testRegistries.BaseTemplates.All())
 	})
 
 	t.Run("should return error for invalid JSON", func(t *testing.T) {
 		// Reset registry for clean test
-		service.BaseTemplateRegistry = api.BaseWidgetDashboardTemplateRegistry{}
+		testRegistries.BaseTemplates.Replace(nil)
 
-		err := service.LoadBaseTemplatesFromConfig(invalidJSON)
+		err := testRegistries.LoadBaseTemplatesFromConfig(invalidJSON)
 		assert.Error(t, err)
 
 		// Registry should remain empty on error
-		assert.Empty(t, service.BaseTemplateRegistry.BaseWidgetDashboardTemplates)
+		assert.Empty(t, testRegistries.BaseTemplates.All())
 	})
 
 	t.Run("should handle malformed JSON structure", func(t *testing.T) {
 		// Reset registry for clean test
-		service.BaseTemplateRegistry = api.BaseWidgetDashboardTemplateRegistry{}
+		testRegistries.BaseTemplates.Replace(nil)
 
 		malformedJSON := `{"not": "an array"}`
-		err := service.LoadBaseTemplatesFromConfig(malformedJSON)
+		err := testRegistries.LoadBaseTemplatesFromConfig(malformedJSON)
 		assert.Error(t, err)
 
 		// Registry should remain empty on error
-		assert.Empty(t, service.BaseTemplateRegistry.BaseWidgetDashboardTemplates)
+		assert.Empty(t, testRegistries.BaseTemplates.All())
 	})
 
 	t.Run("should handle completely invalid JSON", func(t *testing.T) {
 		// Reset registry for clean test
-		service.BaseTemplateRegistry = api.BaseWidgetDashboardTemplateRegistry{}
+		testRegistries.BaseTemplates.Replace(nil)
 
 		invalidJSONString := `not json at all`
-		err := service.LoadBaseTemplatesFromConfig(invalidJSONString)
+		err := testRegistries.LoadBaseTemplatesFromConfig(invalidJSONString)
 		assert.Error(t, err)
 
 		// Registry should remain empty on error
-		assert.Empty(t, service.BaseTemplateRegistry.BaseWidgetDashboardTemplates)
+		assert.Empty(t, testRegistries.BaseTemplates.All())
 	})
 
 	t.Run("should handle partial template data", func(t *testing.T) {
 		// Reset registry for clean test
-		service.BaseTemplateRegistry = api.BaseWidgetDashboardTemplateRegistry{}
+		testRegistries.BaseTemplates.Replace(nil)
 
 		partialTemplateJSON := `[
 			{
@@ -226,22 +226,91 @@ func TestLoadBaseTemplatesFromConfig(t *testing.T) {
 			}
 		]`
 
-		err := service.LoadBaseTemplatesFromConfig(partialTemplateJSON)
+		err := testRegistries.LoadBaseTemplatesFromConfig(partialTemplateJSON)
 		require.NoError(t, err)
 
 		// Verify template was loaded
-		assert.Len(t, service.BaseTemplateRegistry.BaseWidgetDashboardTemplates, 1)
+		assert.Len(t, testRegistries.BaseTemplates.All(), 1)
 
-		template, exists := service.BaseTemplateRegistry.GetBase("partial-template")
+		template, exists := testRegistries.BaseTemplates.Get("partial-template")
 		assert.True(t, exists)
 		assert.Equal(t, "partial-template", template.Name)
 		assert.Equal(t, "Partial Template", template.DisplayName)
 	})
+
+	t.Run("should reject templates with conflicting widgets", func(t *testing.T) {
+		testRegistries.BaseTemplates.Replace(nil)
+
+		overlappingTemplateJSON := `[
+			{
+				"name": "overlapping-template",
+				"displayName": "Overlapping Template",
+				"templateConfig": {
+					"sm": [],
+					"md": [],
+					"lg": [
+						{"w": 1, "h": 4, "x": 0, "y": 0, "i": "widget1"},
+						{"w": 1, "h": 4, "x": 0, "y": 1, "i": "widget2"}
+					],
+					"xl": []
+				}
+			}
+		]`
+
+		err := testRegistries.LoadBaseTemplatesFromConfig(overlappingTemplateJSON)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "overlapping-template has an invalid layout")
+
+		_, exists := testRegistries.BaseTemplates.Get("overlapping-template")
+		assert.False(t, exists, "Base templates should be validated as authored, without compacting them")
+	})
+}
+
+func TestLoadBaseTemplatesReflow(t *testing.T) {
+	t.Run("should derive missing breakpoints of base templates", func(t *testing.T) {
+		testRegistries.BaseTemplates.Replace(nil)
+
+		singleBreakpointJSON := `[
+			{
+				"name": "single-breakpoint-template",
+				"displayName": "Single Breakpoint Template",
+				"templateConfig": {
+					"xl": [
+						{"w": 2, "h": 4, "x": 0, "y": 0, "i": "widget1"},
+						{"w": 2, "h": 4, "x": 2, "y": 0, "i": "widget2"}
+					]
+				}
+			}
+		]`
+
+		err := testRegistries.LoadBaseTemplatesFromConfig(singleBreakpointJSON)
+		require.NoError(t, err)
+
+		template, exists := testRegistries.BaseTemplates.Get("single-breakpoint-template")
+		require.True(t, exists)
+		assert.NoError(t, template.TemplateConfig.IsValid())
+		sm := template.TemplateConfig.Sm.Data()
+		require.Len(t, sm, 2)
+		assert.Equal(t, 0, *sm[0].Y)
+		assert.Equal(t, 4, *sm[1].Y)
+		assert.Len(t, template.TemplateConfig.Md.Data(), 2)
+		assert.Len(t, template.TemplateConfig.Lg.Data(), 2)
+	})
+
+	t.Run("should reject templates without any breakpoint", func(t *testing.T) {
+		testRegistries.BaseTemplates.Replace(nil)
+
+		err := testRegistries.LoadBaseTemplatesFromConfig(`[{"name": "empty-template", "displayName": "Empty Template", "templateConfig": {}}]`)
+		assert.ErrorContains(t, err, "empty-template")
+
+		_, exists := testRegistries.BaseTemplates.Get("empty-template")
+		assert.False(t, exists)
+	})
 }
 
 func TestBaseWidgetDashboardTemplateRegistry(t *testing.T) {
 	t.Run("should add and retrieve base templates", func(t *testing.T) {
-		registry := api.BaseWidgetDashboardTemplateRegistry{}
+		registry := service.NewRegistries()
 
 		template := api.BaseWidgetDashboardTemplate{
 			Name:        "test-template",
@@ -254,43 +323,43 @@ func TestBaseWidgetDashboardTemplateRegistry(t *testing.T) {
 			},
 		}
 
-		registry.AddBase(template)
+		registry.AddBaseTemplate(template)
 
-		retrievedTemplate, exists := registry.GetBase("test-template")
+		retrievedTemplate, exists := registry.BaseTemplates.Get("test-template")
 		assert.True(t, exists)
 		assert.Equal(t, "test-template", retrievedTemplate.Name)
 		assert.Equal(t, "Test Template", retrievedTemplate.DisplayName)
 	})
 
 	t.Run("should handle non-existent template", func(t *testing.T) {
-		registry := api.BaseWidgetDashboardTemplateRegistry{}
+		registry := service.NewRegistries()
 
-		template, exists := registry.GetBase("non-existent-template")
+		template, exists := registry.BaseTemplates.Get("non-existent-template")
 		assert.False(t, exists)
 		assert.Empty(t, template.Name)
 	})
 
 	t.Run("should overwrite existing template with same name", func(t *testing.T) {
-		registry := api.BaseWidgetDashboardTemplateRegistry{}
+		registry := service.NewRegistries()
 
 		// Add first template
 		template1 := api.BaseWidgetDashboardTemplate{
 			Name:        "same-name",
 			DisplayName: "First Template",
 		}
-		registry.AddBase(template1)
+		registry.AddBaseTemplate(template1)
 
 		// Add second template with same name
 		template2 := api.BaseWidgetDashboardTemplate{
 			Name:        "same-name",
 			DisplayName: "Second Template",
 		}
-		registry.AddBase(template2)
+		registry.AddBaseTemplate(template2)
 
 		// Should have only one template (the second one)
-		assert.Len(t, registry.BaseWidgetDashboardTemplates, 1)
+		assert.Len(t, registry.BaseTemplates.All(), 1)
 
-		retrievedTemplate, exists := registry.GetBase("same-name")
+		retrievedTemplate, exists := registry.BaseTemplates.Get("same-name")
 		assert.True(t, exists)
 		assert.Equal(t, "Second Template", retrievedTemplate.DisplayName)
 	})
@@ -331,7 +400,7 @@ func TestConfigurationIntegration(t *testing.T) {
 	t.Run("should work with environment variable format", func(t *testing.T) {
 		// This test simulates how the configuration would be used in practice
 		// Reset registry for clean test
-		service.BaseTemplateRegistry = api.BaseWidgetDashboardTemplateRegistry{}
+		testRegistries.BaseTemplates.Replace(nil)
 
 		// Set environment variable temporarily
 		originalEnv := os.Getenv("BASE_LAYOUTS")
@@ -346,18 +415,18 @@ func TestConfigurationIntegration(t *testing.T) {
 		require.NoError(t, os.Setenv("BASE_LAYOUTS", validBaseTemplateJSON))
 
 		// Load from config (simulating what happens in init)
-		err := service.LoadBaseTemplatesFromConfig(os.Getenv("BASE_LAYOUTS"))
+		err := testRegistries.LoadBaseTemplatesFromConfig(os.Getenv("BASE_LAYOUTS"))
 		require.NoError(t, err)
 
 		// Verify templates are loaded
-		assert.Len(t, service.BaseTemplateRegistry.BaseWidgetDashboardTemplates, 2)
+		assert.Len(t, testRegistries.BaseTemplates.All(), 2)
 
 		// Verify we can retrieve templates
-		template1, exists := service.BaseTemplateRegistry.GetBase("test-template-1")
+		template1, exists := testRegistries.BaseTemplates.Get("test-template-1")
 		assert.True(t, exists)
 		assert.Equal(t, "Test Template 1", template1.DisplayName)
 
-		template2, exists := service.BaseTemplateRegistry.GetBase("test-template-2")
+		template2, exists := testRegistries.BaseTemplates.Get("test-template-2")
 		assert.True(t, exists)
 		assert.Equal(t, "Test Template 2", template2.DisplayName)
 	})
@@ -0,0 +1,168 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/RedHatInsights/widget-layout-backend/api"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/database"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/layout"
+	"github.com/redhatinsights/platform-go-middlewares/v2/identity"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// recordBaseVersion stores a snapshot of the configuration of the base template, the common ancestor
+// of every template forked from or synchronized with this version of it.
+// It has to be called within the same transaction as the fork or synchronization itself.
+func recordBaseVersion(tx *gorm.DB, base api.BaseWidgetDashboardTemplate) error {
+	snapshot := api.BaseTemplateVersion{
+		Name:           base.Name,
+		Version:        base.ConfigVersion(),
+		TemplateConfig: base.TemplateConfig,
+	}
+	// the snapshot of a version never changes, concurrent forks of the same version only need one
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&snapshot).Error
+}
+
+// baseVersionConfig returns the configuration of a recorded version of a base template.
+// Templates forked before versions were recorded have no known ancestor. Their differences to the base template cannot
+// be told apart from upstream changes, so they are all kept as changes of the user: the current configuration of the
+// base template is used as the ancestor, nothing is added or removed and later synchronizations merge from there.
+func baseVersionConfig(name, version string, current api.DashboardTemplateConfig) (api.DashboardTemplateConfig, error) {
+	if version == "" {
+		return current, nil
+	}
+	var snapshot api.BaseTemplateVersion
+	err := database.DB.Where(api.BaseTemplateVersion{Name: name, Version: version}).First(&snapshot).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		logrus.Warnf("Version %s of base template %s is not recorded, keeping the layout of the user as it is", version, name)
+		return current, nil
+	}
+	return snapshot.TemplateConfig, err
+}
+
+// mergeBaseTemplate merges the current version of the base template into the template without saving it.
+// The base template is resolved for the identity, see resolveBaseTemplate.
+func mergeBaseTemplate(reg *Registries, template api.DashboardTemplate, id identity.XRHID) (api.BaseTemplateChanges, api.BaseWidgetDashboardTemplate, int, error) {
+	name := template.TemplateBase.Name
+	base, exists, err := resolveBaseTemplate(reg, name, id)
+	if err != nil {
+		logrus.Errorf("Failed to retrieve base template %s for dashboard template with ID %d: %v", name, template.ID, err)
+		return api.BaseTemplateChanges{}, base, http.StatusInternalServerError, err
+	}
+	if !exists {
+		logrus.Errorf("Base template %s not found for dashboard template with ID %d", name, template.ID)
+		return api.BaseTemplateChanges{}, base, http.StatusNotFound, fmt.Errorf("base template %s not found", name)
+	}
+	changes := api.BaseTemplateChanges{
+		BaseTemplate:   name,
+		FromVersion:    template.BaseVersion,
+		ToVersion:      base.ConfigVersion(),
+		Added:          []string{},
+		Removed:        []string{},
+		TemplateConfig: template.TemplateConfig,
+	}
+	changes.UpToDate = changes.FromVersion == changes.ToVersion
+	if changes.UpToDate {
+		return changes, base, http.StatusOK, nil
+	}
+
+	oldBase, err := baseVersionConfig(name, template.BaseVersion, base.TemplateConfig)
+	if err != nil {
+		logrus.Errorf("Failed to retrieve version %s of base template %s: %v", template.BaseVersion, name, err)
+		return api.BaseTemplateChanges{}, base, http.StatusInternalServerError, err
+	}
+	merged, err := layout.MergeBaseConfig(oldBase, base.TemplateConfig, template.TemplateConfig)
+	if err != nil {
+		logrus.Errorf("Failed to merge base template %s into dashboard template with ID %d: %v", name, template.ID, err)
+		return api.BaseTemplateChanges{}, base, http.StatusInternalServerError, err
+	}
+	changes.TemplateConfig = merged.Config
+	changes.Added = append(changes.Added, merged.Added...)
+	changes.Removed = append(changes.Removed, merged.Removed...)
+	return changes, base, http.StatusOK, nil
+}
+
+func GetUpstreamChanges(reg *Registries, templateID int64, id identity.XRHID) (api.BaseTemplateChanges, int, error) {
+	var template api.DashboardTemplate
+	err := database.DB.First(&template, templateID).Error
+	if ret, status, err := handleServiceError(
+		err,
+		fmt.Sprintf("Dashboard template with ID %d not found", templateID),
+		"Failed to retrieve dashboard template with ID %d: %v", http.StatusNotFound,
+		api.BaseTemplateChanges{}, api.BaseTemplateChanges{},
+	); err != nil {
+		return ret, status, err
+	}
+	if !canReadTemplate(template, id) {
+		return api.BaseTemplateChanges{}, http.StatusForbidden, errors.New("unauthorized")
+	}
+	changes, _, status, err := mergeBaseTemplate(reg, template, id)
+	return changes, status, err
+}
+
+func SyncDashboardTemplateBase(reg *Registries, templateID int64, id identity.XRHID, ifMatch *string) (api.DashboardTemplate, int, error) {
+	var template api.DashboardTemplate
+	err := database.DB.First(&template, templateID).Error
+	if ret, status, err := handleServiceError(
+		err,
+		fmt.Sprintf("Dashboard template with ID %d not found", templateID),
+		"Failed to retrieve dashboard template with ID %d: %v", http.StatusNotFound,
+		api.DashboardTemplate{}, api.DashboardTemplate{},
+	); err != nil {
+		return ret, status, err
+	}
+	if !canEditTemplate(template, id) {
+		return api.DashboardTemplate{}, http.StatusForbidden, errors.New("unauthorized")
+	}
+	if status, err := checkPrecondition(template, ifMatch); err != nil {
+		return api.DashboardTemplate{}, status, err
+	}
+
+	changes, base, status, err := mergeBaseTemplate(reg, template, id)
+	if err != nil {
+		return api.DashboardTemplate{}, status, err
+	}
+	if changes.UpToDate {
+		return template, http.StatusOK, nil
+	}
+	if err := changes.TemplateConfig.IsValid(); err != nil {
+		logrus.Errorf("Merging base template %s results in an invalid dashboard template with ID %d: %v", changes.BaseTemplate, templateID, err)
+		return api.DashboardTemplate{}, http.StatusBadRequest, err
+	}
+	merged := template
+	merged.TemplateConfig = changes.TemplateConfig
+	if status, err := checkWidgetPolicy(reg, merged, id); err != nil {
+		logrus.Errorf("Merging base template %s results in a dashboard template with ID %d violating a widget policy: %v", changes.BaseTemplate, templateID, err)
+		return api.DashboardTemplate{}, status, err
+	}
+
+	logrus.Infof("Synchronizing dashboard template with ID %d with version %s of base template %s, adding %v and removing %v", templateID, changes.ToVersion, changes.BaseTemplate, changes.Added, changes.Removed)
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := recordRevision(tx, template); err != nil {
+			return err
+		}
+		if err := recordBaseVersion(tx, base); err != nil {
+			return err
+		}
+		before := template.TemplateConfig
+		summary := fmt.Sprintf("synchronized with base template %s: %s", changes.BaseTemplate, layoutSummary(before, changes.TemplateConfig))
+		template.TemplateConfig = changes.TemplateConfig
+		template.BaseVersion = changes.ToVersion
+		if err := saveTemplate(tx, &template); err != nil {
+			return err
+		}
+		if err := recordAuditEvent(tx, id, api.AuditActionUpdate, template.ID, summary); err != nil {
+			return err
+		}
+		return enqueueTemplateEvent(tx, id, api.AuditActionUpdate, template, before)
+	})
+	if err != nil {
+		logrus.Errorf("Failed to synchronize dashboard template with ID %d: %v", templateID, err)
+		return api.DashboardTemplate{}, mutationErrorStatus(err), err
+	}
+	return template, http.StatusOK, nil
+}
@@ -0,0 +1,152 @@
+package service_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/RedHatInsights/widget-layout-backend/api"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/database"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/service"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/test_util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/subpop/xrhidgen"
+)
+
+func TestBaseTemplateSync(t *testing.T) {
+	t.Run("should merge a new version of the base template into a fork", func(t *testing.T) {
+		testUserID := test_util.GetUniqueUserID()
+		testIdentity := test_util.GenerateIdentityStructFromTemplate(
+			xrhidgen.Identity{},
+			xrhidgen.User{UserID: stringPtr(testUserID)},
+			xrhidgen.Entitlements{},
+		)
+
+		testRegistries.AddBaseTemplate(api.BaseWidgetDashboardTemplate{
+			Name:           "sync-base",
+			DisplayName:    "Sync Base",
+			TemplateConfig: test_util.MockTemplateConfig("kept", "retired"),
+		})
+		fork, _, err := service.ForkBaseTemplate(testRegistries, "sync-base", testIdentity)
+		require.NoError(t, err)
+		assert.NotEmpty(t, fork.BaseVersion, "Forks should record the version of the base template")
+
+		// the user moves a widget, the base template then retires one widget and ships a new one
+		customized := test_util.MockTemplateConfig("retired", "kept")
+		fork, _, err = service.UpdateDashboardTemplate(context.Background(), testRegistries, int64(fork.ID), customized, testIdentity, nil, false, nil)
+		require.NoError(t, err)
+		newBase := api.BaseWidgetDashboardTemplate{
+			Name:           "sync-base",
+			DisplayName:    "Sync Base",
+			TemplateConfig: test_util.MockTemplateConfig("kept", "shipped"),
+		}
+		testRegistries.AddBaseTemplate(newBase)
+
+		changes, status, err := service.GetUpstreamChanges(testRegistries, int64(fork.ID), testIdentity)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+		assert.False(t, changes.UpToDate)
+		assert.Equal(t, fork.BaseVersion, changes.FromVersion)
+		assert.Equal(t, newBase.ConfigVersion(), changes.ToVersion)
+		assert.Equal(t, []string{"shipped"}, changes.Added)
+		assert.Equal(t, []string{"retired"}, changes.Removed)
+
+		var stored api.DashboardTemplate
+		require.NoError(t, database.DB.First(&stored, fork.ID).Error)
+		assert.Equal(t, fork.Version, stored.Version, "The preview should not modify the template")
+
+		synced, status, err := service.SyncDashboardTemplateBase(testRegistries, int64(fork.ID), testIdentity, nil)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, newBase.ConfigVersion(), synced.BaseVersion)
+		xl := synced.TemplateConfig.Xl.Data()
+		require.Len(t, xl, 2)
+		assert.Equal(t, "kept", xl[0].WidgetType)
+		assert.Equal(t, 1, *xl[0].Y, "Widgets kept by the base template should keep the position chosen by the user")
+		assert.Equal(t, "shipped", xl[1].WidgetType)
+		assert.Equal(t, 0, *xl[1].Y, "New widgets should be placed in free space")
+
+		changes, _, err = service.GetUpstreamChanges(testRegistries, int64(fork.ID), testIdentity)
+		require.NoError(t, err)
+		assert.True(t, changes.UpToDate)
+		assert.Empty(t, changes.Added)
+
+		revisions, _, err := service.GetTemplateRevisions(int64(fork.ID), testIdentity)
+		require.NoError(t, err)
+		assert.Len(t, revisions, 2, "Synchronizing should record a revision")
+	})
+
+	t.Run("should keep the layout of templates without a recorded base version", func(t *testing.T) {
+		testUserID := test_util.GetUniqueUserID()
+		testIdentity := test_util.GenerateIdentityStructFromTemplate(
+			xrhidgen.Identity{},
+			xrhidgen.User{UserID: stringPtr(testUserID)},
+			xrhidgen.Entitlements{},
+		)
+		testRegistries.AddBaseTemplate(api.BaseWidgetDashboardTemplate{
+			Name:           "sync-legacy-base",
+			DisplayName:    "Sync Legacy Base",
+			TemplateConfig: test_util.MockTemplateConfig("kept", "removed-by-user"),
+		})
+		// the template was forked before versions were recorded, the user then removed a widget and added their own
+		template := createTestTemplate(testUserID, "sync-legacy-base", "Sync Legacy Base")
+		template.TemplateConfig = test_util.MockTemplateConfig("kept", "custom")
+		require.NoError(t, database.DB.Create(&template).Error)
+
+		changes, _, err := service.GetUpstreamChanges(testRegistries, int64(template.ID), testIdentity)
+		require.NoError(t, err)
+		assert.Empty(t, changes.FromVersion)
+		assert.False(t, changes.UpToDate)
+		assert.Empty(t, changes.Added, "Widgets the user may have removed should not be added again")
+		assert.Empty(t, changes.Removed, "Widgets the user may have added should not be removed")
+
+		synced, status, err := service.SyncDashboardTemplateBase(testRegistries, int64(template.ID), testIdentity, nil)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, template.TemplateConfig, synced.TemplateConfig)
+		assert.Equal(t, changes.ToVersion, synced.BaseVersion)
+
+		// later versions are merged from the version the template was synchronized with
+		testRegistries.AddBaseTemplate(api.BaseWidgetDashboardTemplate{
+			Name:        "sync-legacy-base",
+			DisplayName: "Sync Legacy Base",
+			TemplateConfig: test_util.MockTemplateConfig(
+				"kept", "removed-by-user", "shipped",
+			),
+		})
+		changes, _, err = service.GetUpstreamChanges(testRegistries, int64(template.ID), testIdentity)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"shipped"}, changes.Added)
+		assert.Empty(t, changes.Removed)
+	})
+
+	t.Run("should return 404 when the base template no longer exists", func(t *testing.T) {
+		testUserID := test_util.GetUniqueUserID()
+		testIdentity := test_util.GenerateIdentityStructFromTemplate(
+			xrhidgen.Identity{},
+			xrhidgen.User{UserID: stringPtr(testUserID)},
+			xrhidgen.Entitlements{},
+		)
+		template := createTestTemplate(testUserID, "sync-removed-base", "Sync Removed Base")
+		require.NoError(t, database.DB.Create(&template).Error)
+
+		_, status, err := service.SyncDashboardTemplateBase(testRegistries, int64(template.ID), testIdentity, nil)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusNotFound, status)
+	})
+
+	t.Run("should return 403 for a template of another user", func(t *testing.T) {
+		template := createTestTemplate(test_util.GetUniqueUserID(), "sync-base", "Sync Base")
+		require.NoError(t, database.DB.Create(&template).Error)
+		otherIdentity := test_util.GenerateIdentityStructFromTemplate(
+			xrhidgen.Identity{},
+			xrhidgen.User{UserID: stringPtr(test_util.GetUniqueUserID())},
+			xrhidgen.Entitlements{},
+		)
+
+		_, status, err := service.GetUpstreamChanges(testRegistries, int64(template.ID), otherIdentity)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusForbidden, status)
+	})
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/RedHatInsights/widget-layout-backend/api"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/database"
+	"github.com/redhatinsights/platform-go-middlewares/v2/identity"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// MaxBulkImportItems is the maximum number of templates imported by one bulk import
+const MaxBulkImportItems = 100
+
+// BulkImportItem is the outcome of the import of one template of a bulk import.
+type BulkImportItem struct {
+	// Template is the imported template, or the validated one if it was skipped
+	Template api.DashboardTemplate
+	// Status is the status the import of the template alone would have been answered with
+	Status   int
+	Err      error
+	Imported bool
+}
+
+// BulkImportDashboardTemplates validates all imported templates before storing the valid ones in a single
+// transaction. In atomic mode no template is stored when one of them is invalid, the request is then answered with
+// 422. With compact the layouts are compacted before they are validated. The outcomes are returned in the order of
+// the imported templates.
+func BulkImportDashboardTemplates(reg *Registries, importData []api.ImportWidgetDashboardTemplateRequest, id identity.XRHID, validationMode *api.ValidationMode, compact bool, mode *api.BulkImportMode) ([]BulkImportItem, int, error) {
+	if len(importData) == 0 {
+		return nil, http.StatusBadRequest, fmt.Errorf("no dashboard templates to import")
+	}
+	if len(importData) > MaxBulkImportItems {
+		return nil, http.StatusBadRequest, fmt.Errorf("at most %d dashboard templates can be imported at once", MaxBulkImportItems)
+	}
+	importMode := api.BulkImportAtomic
+	if mode != nil {
+		importMode = *mode
+	}
+	if importMode != api.BulkImportAtomic && importMode != api.BulkImportBestEffort {
+		return nil, http.StatusBadRequest, fmt.Errorf("invalid mode %s", importMode)
+	}
+
+	items := make([]BulkImportItem, len(importData))
+	failed := 0
+	for i, data := range importData {
+		template, status, err := prepareImportedTemplate(reg, data, id, validationMode, compact)
+		items[i] = BulkImportItem{Template: template, Status: status, Err: err}
+		if err != nil {
+			failed++
+		}
+	}
+	if failed > 0 && importMode == api.BulkImportAtomic {
+		logrus.Warnf("Rejected bulk import of user %s: %d of %d dashboard templates are invalid", id.Identity.User.UserID, failed, len(items))
+		return items, http.StatusUnprocessableEntity, nil
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		for i := range items {
+			if items[i].Err != nil {
+				continue
+			}
+			if err := storeImportedTemplate(tx, id, &items[i].Template); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		logrus.Errorf("Failed to bulk import dashboard templates: %v", err)
+		return nil, http.StatusInternalServerError, err
+	}
+	for i := range items {
+		items[i].Imported = items[i].Err == nil
+	}
+
+	logrus.Infof("Successfully imported %d of %d dashboard templates for user %s", len(items)-failed, len(items), id.Identity.User.UserID)
+	return items, http.StatusOK, nil
+}
@@ -0,0 +1,102 @@
+package service_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/RedHatInsights/widget-layout-backend/api"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/database"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/service"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/test_util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func bulkImportData(names ...string) []api.ImportWidgetDashboardTemplateRequest {
+	importData := make([]api.ImportWidgetDashboardTemplateRequest, 0, len(names))
+	for _, name := range names {
+		importData = append(importData, api.ImportWidgetDashboardTemplateRequest{
+			DashboardName:  "Imported " + name,
+			TemplateBase:   api.DashboardTemplateBase{Name: name, DisplayName: "Bulk Import"},
+			TemplateConfig: test_util.MockTemplateConfig("widget1"),
+		})
+	}
+	return importData
+}
+
+func countUserTemplates(t *testing.T, userID string) int64 {
+	var count int64
+	require.NoError(t, database.DB.Model(&api.DashboardTemplate{}).Where("user_id = ?", userID).Count(&count).Error)
+	return count
+}
+
+func TestBulkImportDashboardTemplates(t *testing.T) {
+	t.Run("should import all templates in the order of the request", func(t *testing.T) {
+		testUserID := test_util.GetUniqueUserID()
+		testIdentity := orgIdentity(testUserID, "", false)
+
+		items, status, err := service.BulkImportDashboardTemplates(testRegistries, bulkImportData("bulk-a", "bulk-b", "bulk-c"), testIdentity, nil, false, nil)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+		require.Len(t, items, 3)
+		for i, name := range []string{"bulk-a", "bulk-b", "bulk-c"} {
+			assert.True(t, items[i].Imported)
+			assert.NotZero(t, items[i].Template.ID)
+			assert.Equal(t, name, items[i].Template.TemplateBase.Name)
+			assert.Equal(t, testUserID, items[i].Template.UserId)
+			assert.False(t, items[i].Template.Default)
+		}
+		assert.Equal(t, int64(3), countUserTemplates(t, testUserID))
+	})
+
+	t.Run("should not import anything in atomic mode when a template is invalid", func(t *testing.T) {
+		testUserID := test_util.GetUniqueUserID()
+		importData := bulkImportData("bulk-a", "", "bulk-c")
+
+		items, status, err := service.BulkImportDashboardTemplates(testRegistries, importData, orgIdentity(testUserID, "", false), nil, false, nil)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, status)
+		require.Len(t, items, 3)
+		assert.False(t, items[0].Imported)
+		assert.NoError(t, items[0].Err)
+		assert.Error(t, items[1].Err)
+		assert.Equal(t, http.StatusBadRequest, items[1].Status)
+		assert.False(t, items[2].Imported)
+		assert.Zero(t, countUserTemplates(t, testUserID))
+	})
+
+	t.Run("should import the valid templates in best-effort mode", func(t *testing.T) {
+		testUserID := test_util.GetUniqueUserID()
+		bestEffort := api.BulkImportBestEffort
+
+		items, status, err := service.BulkImportDashboardTemplates(testRegistries, bulkImportData("bulk-a", "", "bulk-c"), orgIdentity(testUserID, "", false), nil, false, &bestEffort)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+		require.Len(t, items, 3)
+		assert.True(t, items[0].Imported)
+		assert.False(t, items[1].Imported)
+		assert.Error(t, items[1].Err)
+		assert.True(t, items[2].Imported)
+		assert.Equal(t, int64(2), countUserTemplates(t, testUserID))
+
+		var imports int64
+		require.NoError(t, database.DB.Model(&api.AuditEvent{}).Where("user_id = ? AND action = ?", testUserID, api.AuditActionImport).Count(&imports).Error)
+		assert.Equal(t, int64(2), imports, "Every imported template should be audited")
+	})
+
+	t.Run("should reject empty and oversized imports", func(t *testing.T) {
+		testIdentity := orgIdentity(test_util.GetUniqueUserID(), "", false)
+
+		_, status, err := service.BulkImportDashboardTemplates(testRegistries, nil, testIdentity, nil, false, nil)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusBadRequest, status)
+
+		names := make([]string, service.MaxBulkImportItems+1)
+		for i := range names {
+			names[i] = "bulk-a"
+		}
+		_, status, err = service.BulkImportDashboardTemplates(testRegistries, bulkImportData(names...), testIdentity, nil, false, nil)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusBadRequest, status)
+	})
+}
@@ -0,0 +1,231 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/RedHatInsights/widget-layout-backend/api"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/database"
+	"github.com/redhatinsights/platform-go-middlewares/v2/identity"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// MaxArchiveTemplates is the maximum number of templates restored from one archive
+const MaxArchiveTemplates = 500
+
+// ArchiveImportItem is the outcome of the restore of one template of an archive.
+type ArchiveImportItem struct {
+	Action api.ArchiveImportAction
+	// Template is the restored template, or the template as it would be restored when nothing was stored
+	Template api.DashboardTemplate
+	// ConflictingTemplateID is the template of the user with the same base template and dashboard name
+	ConflictingTemplateID *uint
+	// Status is the status the restore of the template alone would have been answered with
+	Status int
+	Err    error
+	// previous is the overwritten template before the restore
+	previous api.DashboardTemplate
+}
+
+// archiveKey identifies the templates of a user which conflict with each other
+type archiveKey struct {
+	baseName      string
+	dashboardName string
+}
+
+func templateArchiveKey(template api.DashboardTemplate) archiveKey {
+	return archiveKey{baseName: template.TemplateBase.Name, dashboardName: template.DashboardName}
+}
+
+// ExportDashboardArchive returns an archive of all templates of the user, trashed templates are not exported.
+func ExportDashboardArchive(id identity.XRHID) (api.DashboardArchive, int, error) {
+	var templates []api.DashboardTemplate
+	err := database.DB.Where("user_id = ?", id.Identity.User.UserID).Order("id").Find(&templates).Error
+	if err != nil {
+		logrus.Errorf("Failed to retrieve dashboard templates of user %s for export: %v", id.Identity.User.UserID, err)
+		return api.DashboardArchive{}, http.StatusInternalServerError, err
+	}
+	archive := api.DashboardArchive{
+		SchemaVersion: api.DashboardArchiveSchemaVersion,
+		ExportedAt:    time.Now().UTC(),
+		UserId:        id.Identity.User.UserID,
+		Templates:     make([]api.ArchivedDashboardTemplate, 0, len(templates)),
+	}
+	for _, template := range templates {
+		archive.Templates = append(archive.Templates, template.ToArchivedTemplate())
+	}
+	return archive, http.StatusOK, nil
+}
+
+// renamedDashboardName returns the first "<name> (n)" not used by another template of the user with the same base.
+func renamedDashboardName(template api.DashboardTemplate, taken map[archiveKey]bool) string {
+	name := template.DashboardName
+	if name == "" {
+		name = template.TemplateBase.DisplayName
+	}
+	for n := 2; ; n++ {
+		candidate := fmt.Sprintf("%s (%d)", name, n)
+		if !taken[archiveKey{baseName: template.TemplateBase.Name, dashboardName: candidate}] {
+			return candidate
+		}
+	}
+}
+
+// planArchiveImport validates the templates of the archive and decides how each of them is restored, without
+// storing anything. A template conflicting with a template of the user is skipped, renamed or overwrites it,
+// depending on the conflict policy. Templates conflicting with a template restored earlier from the same archive
+// are skipped with the skip policy and renamed otherwise, so a template of the user is overwritten at most once.
+// Archives only contain the widgets the user can see, an overwritten template keeps the widgets hidden from them.
+func planArchiveImport(ctx context.Context, reg *Registries, archive api.DashboardArchive, id identity.XRHID, validationMode *api.ValidationMode, conflict api.ArchiveConflictPolicy) ([]ArchiveImportItem, error) {
+	var existing []api.DashboardTemplate
+	if err := database.DB.Where("user_id = ?", id.Identity.User.UserID).Order("id").Find(&existing).Error; err != nil {
+		return nil, err
+	}
+	taken := map[archiveKey]bool{}
+	overwritable := map[archiveKey]api.DashboardTemplate{}
+	for _, template := range existing {
+		key := templateArchiveKey(template)
+		if !taken[key] {
+			overwritable[key] = template
+		}
+		taken[key] = true
+	}
+
+	wv := newWidgetVisibility(ctx, reg, id)
+	items := make([]ArchiveImportItem, len(archive.Templates))
+	for i, archived := range archive.Templates {
+		template, status, err := prepareImportedTemplate(reg, api.ImportWidgetDashboardTemplateRequest{
+			DashboardName:  archived.DashboardName,
+			TemplateBase:   archived.TemplateBase,
+			TemplateConfig: archived.TemplateConfig,
+		}, id, validationMode, false)
+		if err != nil {
+			items[i] = ArchiveImportItem{Action: api.ArchiveImportFailed, Status: status, Err: err}
+			continue
+		}
+		template.Default = archived.Default
+		template.BaseVersion = archived.BaseVersion
+		template.Visibility = api.VisibilityPrivate
+		// templates can only be shared with the organization of the restoring user
+		if archived.Visibility == api.VisibilityOrg && id.Identity.OrgID != "" {
+			template.Visibility = api.VisibilityOrg
+		}
+
+		item := ArchiveImportItem{Action: api.ArchiveImportCreated, Template: template, Status: http.StatusOK}
+		key := templateArchiveKey(template)
+		if conflicting, ok := overwritable[key]; ok {
+			item.ConflictingTemplateID = &conflicting.ID
+		}
+		switch {
+		case !taken[key]:
+		case conflict == api.ArchiveConflictSkip:
+			item.Action = api.ArchiveImportSkipped
+		case conflict == api.ArchiveConflictOverwrite && item.ConflictingTemplateID != nil:
+			item.Action = api.ArchiveImportOverwritten
+			item.previous = overwritable[key]
+			overwritten := item.previous
+			overwritten.TemplateBase.DisplayName = template.TemplateBase.DisplayName
+			overwritten.TemplateConfig = wv.restoreHidden(template.TemplateConfig, item.previous.TemplateConfig)
+			overwritten.Default = template.Default
+			overwritten.BaseVersion = template.BaseVersion
+			overwritten.Visibility = template.Visibility
+			overwritten.Warnings = template.Warnings
+			item.Template = overwritten
+			delete(overwritable, key)
+		default:
+			item.Action = api.ArchiveImportRenamed
+			item.Template.DashboardName = renamedDashboardName(template, taken)
+			key = templateArchiveKey(item.Template)
+		}
+		taken[key] = true
+		items[i] = item
+	}
+	return items, nil
+}
+
+// restoreArchivedTemplate stores a template of the archive as planned by planArchiveImport, the default status of
+// other templates is unset once all templates are stored.
+func restoreArchivedTemplate(tx *gorm.DB, id identity.XRHID, item *ArchiveImportItem) error {
+	template := &item.Template
+	switch item.Action {
+	case api.ArchiveImportCreated, api.ArchiveImportRenamed:
+		return storeImportedTemplate(tx, id, template)
+	case api.ArchiveImportOverwritten:
+		if err := recordRevision(tx, item.previous); err != nil {
+			return err
+		}
+		if err := saveTemplate(tx, template); err != nil {
+			return err
+		}
+		summary := "overwritten from an archive: " + layoutSummary(item.previous.TemplateConfig, template.TemplateConfig)
+		if err := recordAuditEvent(tx, id, api.AuditActionUpdate, template.ID, summary); err != nil {
+			return err
+		}
+		return enqueueTemplateEvent(tx, id, api.AuditActionUpdate, *template, item.previous.TemplateConfig)
+	}
+	return nil
+}
+
+// ImportDashboardArchive restores the templates of an archive in a single transaction. Nothing is restored when one
+// of the templates is invalid, the request is then answered with 422. In a dry run the outcomes are planned without
+// storing anything. The outcomes are returned in the order of the archived templates.
+func ImportDashboardArchive(ctx context.Context, reg *Registries, archive api.DashboardArchive, id identity.XRHID, validationMode *api.ValidationMode, conflict *api.ArchiveConflictPolicy, dryRun bool) ([]ArchiveImportItem, int, error) {
+	if err := archive.CheckSchemaVersion(); err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	if len(archive.Templates) > MaxArchiveTemplates {
+		return nil, http.StatusBadRequest, fmt.Errorf("at most %d dashboard templates can be restored at once", MaxArchiveTemplates)
+	}
+	conflictPolicy := api.ArchiveConflictSkip
+	if conflict != nil {
+		conflictPolicy = *conflict
+	}
+	if conflictPolicy != api.ArchiveConflictSkip && conflictPolicy != api.ArchiveConflictRename && conflictPolicy != api.ArchiveConflictOverwrite {
+		return nil, http.StatusBadRequest, fmt.Errorf("invalid conflict policy %s", conflictPolicy)
+	}
+
+	items, err := planArchiveImport(ctx, reg, archive, id, validationMode, conflictPolicy)
+	if err != nil {
+		logrus.Errorf("Failed to retrieve dashboard templates of user %s for archive import: %v", id.Identity.User.UserID, err)
+		return nil, http.StatusInternalServerError, err
+	}
+	for _, item := range items {
+		if item.Err != nil {
+			logrus.Warnf("Rejected archive import of user %s: dashboard templates are invalid", id.Identity.User.UserID)
+			return items, http.StatusUnprocessableEntity, nil
+		}
+	}
+	if dryRun {
+		return items, http.StatusOK, nil
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		defaults := map[string]uint{}
+		for i := range items {
+			if err := restoreArchivedTemplate(tx, id, &items[i]); err != nil {
+				return err
+			}
+			if items[i].Action != api.ArchiveImportSkipped && items[i].Template.Default {
+				defaults[items[i].Template.TemplateBase.Name] = items[i].Template.ID
+			}
+		}
+		// unsetting the default status bumps the version of the other templates, which would make the
+		// overwrite of later templates fail if it was done while restoring them
+		for baseName, templateID := range defaults {
+			if err := unsetDefaultTemplates(tx, baseName, id.Identity.User.UserID, templateID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		logrus.Errorf("Failed to import dashboard archive of user %s: %v", id.Identity.User.UserID, err)
+		return nil, mutationErrorStatus(err), err
+	}
+
+	logrus.Infof("Successfully restored an archive of %d dashboard templates for user %s", len(items), id.Identity.User.UserID)
+	return items, http.StatusOK, nil
+}
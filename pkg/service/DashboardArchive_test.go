@@ -0,0 +1,236 @@
+package service_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/RedHatInsights/widget-layout-backend/api"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/database"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/service"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/test_util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// storeArchiveTestTemplate stores a template of the user with a layout of the given widgets
+func storeArchiveTestTemplate(t *testing.T, userID, baseName, dashboardName string, isDefault bool, widgets ...string) api.DashboardTemplate {
+	template := api.DashboardTemplate{
+		UserId:         userID,
+		DashboardName:  dashboardName,
+		Default:        isDefault,
+		TemplateBase:   api.DashboardTemplateBase{Name: baseName, DisplayName: "Archive Base"},
+		TemplateConfig: test_util.MockTemplateConfig(widgets...),
+	}
+	require.NoError(t, database.DB.Create(&template).Error)
+	return template
+}
+
+func archivedTemplate(baseName, dashboardName string, isDefault bool, widgets ...string) api.ArchivedDashboardTemplate {
+	return api.ArchivedDashboardTemplate{
+		DashboardName:  dashboardName,
+		TemplateBase:   api.DashboardTemplateBase{Name: baseName, DisplayName: "Archive Base"},
+		TemplateConfig: test_util.MockTemplateConfig(widgets...),
+		Default:        isDefault,
+	}
+}
+
+func userTemplates(t *testing.T, userID string) []api.DashboardTemplate {
+	var templates []api.DashboardTemplate
+	require.NoError(t, database.DB.Where("user_id = ?", userID).Order("id").Find(&templates).Error)
+	return templates
+}
+
+func TestDashboardArchive(t *testing.T) {
+	t.Run("should restore an exported archive without losing names and defaults", func(t *testing.T) {
+		sourceUserID := test_util.GetUniqueUserID()
+		storeArchiveTestTemplate(t, sourceUserID, "archive-base", "Operations", false, "widget1")
+		storeArchiveTestTemplate(t, sourceUserID, "archive-base", "Security", true, "widget2")
+		forked := storeArchiveTestTemplate(t, sourceUserID, "other-base", "", false, "widget3")
+		require.NoError(t, database.DB.Model(&forked).Update("base_version", "v1").Error)
+		trashed := storeArchiveTestTemplate(t, sourceUserID, "archive-base", "Trashed", false, "widget1")
+		require.NoError(t, database.DB.Delete(&trashed).Error)
+
+		archive, status, err := service.ExportDashboardArchive(orgIdentity(sourceUserID, "", false))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, api.DashboardArchiveSchemaVersion, archive.SchemaVersion)
+		assert.Equal(t, sourceUserID, archive.UserId)
+		require.Len(t, archive.Templates, 3, "Trashed templates should not be exported")
+
+		targetUserID := test_util.GetUniqueUserID()
+		items, status, err := service.ImportDashboardArchive(context.Background(), testRegistries, archive, orgIdentity(targetUserID, "", false), nil, nil, false)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+		require.Len(t, items, 3)
+		for _, item := range items {
+			assert.Equal(t, api.ArchiveImportCreated, item.Action)
+			assert.NotZero(t, item.Template.ID)
+		}
+
+		restored := userTemplates(t, targetUserID)
+		require.Len(t, restored, 3)
+		for i, template := range restored {
+			assert.Equal(t, archive.Templates[i], template.ToArchivedTemplate())
+		}
+	})
+
+	t.Run("should skip, rename or overwrite conflicting templates", func(t *testing.T) {
+		for _, tc := range []struct {
+			policy   api.ArchiveConflictPolicy
+			action   api.ArchiveImportAction
+			expected []string
+		}{
+			{policy: api.ArchiveConflictSkip, action: api.ArchiveImportSkipped, expected: []string{"Operations"}},
+			{policy: api.ArchiveConflictRename, action: api.ArchiveImportRenamed, expected: []string{"Operations", "Operations (2)"}},
+			{policy: api.ArchiveConflictOverwrite, action: api.ArchiveImportOverwritten, expected: []string{"Operations"}},
+		} {
+			t.Run(string(tc.policy), func(t *testing.T) {
+				testUserID := test_util.GetUniqueUserID()
+				existing := storeArchiveTestTemplate(t, testUserID, "archive-base", "Operations", false, "widget1")
+				archive := api.DashboardArchive{
+					SchemaVersion: api.DashboardArchiveSchemaVersion,
+					Templates:     []api.ArchivedDashboardTemplate{archivedTemplate("archive-base", "Operations", false, "widget2")},
+				}
+
+				items, status, err := service.ImportDashboardArchive(context.Background(), testRegistries, archive, orgIdentity(testUserID, "", false), nil, &tc.policy, false)
+				require.NoError(t, err)
+				assert.Equal(t, http.StatusOK, status)
+				require.Len(t, items, 1)
+				assert.Equal(t, tc.action, items[0].Action)
+				require.NotNil(t, items[0].ConflictingTemplateID)
+				assert.Equal(t, existing.ID, *items[0].ConflictingTemplateID)
+
+				names := []string{}
+				for _, template := range userTemplates(t, testUserID) {
+					names = append(names, template.DashboardName)
+				}
+				assert.Equal(t, tc.expected, names)
+			})
+		}
+	})
+
+	t.Run("should keep a revision of overwritten templates and restore the default marker", func(t *testing.T) {
+		testUserID := test_util.GetUniqueUserID()
+		operations := storeArchiveTestTemplate(t, testUserID, "archive-base", "Operations", true, "widget1")
+		security := storeArchiveTestTemplate(t, testUserID, "archive-base", "Security", false, "widget1")
+		overwrite := api.ArchiveConflictOverwrite
+		archive := api.DashboardArchive{
+			SchemaVersion: api.DashboardArchiveSchemaVersion,
+			Templates: []api.ArchivedDashboardTemplate{
+				archivedTemplate("archive-base", "Security", true, "widget2"),
+				archivedTemplate("archive-base", "Operations", false, "widget3"),
+			},
+		}
+
+		_, status, err := service.ImportDashboardArchive(context.Background(), testRegistries, archive, orgIdentity(testUserID, "", false), nil, &overwrite, false)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+
+		restored := userTemplates(t, testUserID)
+		require.Len(t, restored, 2)
+		assert.Equal(t, operations.ID, restored[0].ID)
+		assert.False(t, restored[0].Default)
+		assert.Equal(t, "widget3", restored[0].TemplateConfig.Sm.Data()[0].WidgetType)
+		assert.Equal(t, security.ID, restored[1].ID)
+		assert.True(t, restored[1].Default)
+		assert.Greater(t, restored[1].Version, security.Version)
+
+		revisions, _, err := service.GetTemplateRevisions(int64(security.ID), orgIdentity(testUserID, "", false))
+		require.NoError(t, err)
+		require.NotEmpty(t, revisions)
+		assert.Equal(t, "widget1", revisions[0].TemplateConfig.Sm.Data()[0].WidgetType)
+	})
+
+	t.Run("should keep the widgets hidden from the user when overwriting with an exported archive", func(t *testing.T) {
+		registerPermissionTestMappings(t)
+		testRegistries.AddWidgetMapping(api.WidgetModuleFederationMetadata{
+			Scope:       "landing",
+			Module:      "./PreviewWidget",
+			FeatureFlag: stringPtr("landing.preview-widget"),
+			Config:      api.WidgetConfiguration{Title: "Preview"},
+		})
+		previous := testRegistries.FlagProvider
+		t.Cleanup(func() { testRegistries.FlagProvider = previous })
+		testRegistries.FlagProvider = staticFlags{"landing.preview-widget": false}
+
+		testUserID := test_util.GetUniqueUserID()
+		testIdentity := orgIdentity(testUserID, "", false)
+		stored := storeArchiveTestTemplate(t, testUserID, "archive-base", "Operations", false, "landing-./PreviewWidget", "landing-./PublicWidget")
+
+		exported, status, err := service.ExportDashboardArchive(testIdentity)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, status)
+		archive := service.FilterPermittedDashboardArchive(context.Background(), testRegistries, exported, testIdentity)
+		require.Len(t, archive.Templates, 1)
+		require.Len(t, archive.Templates[0].TemplateConfig.Sm.Data(), 1, "The archive should not contain the flagged widget")
+
+		overwrite := api.ArchiveConflictOverwrite
+		items, status, err := service.ImportDashboardArchive(context.Background(), testRegistries, archive, testIdentity, nil, &overwrite, false)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+		require.Len(t, items, 1)
+		assert.Equal(t, api.ArchiveImportOverwritten, items[0].Action)
+
+		restored := userTemplates(t, testUserID)
+		require.Len(t, restored, 1)
+		assert.Equal(t, stored.ID, restored[0].ID)
+		for _, layout := range [][]api.WidgetItem{restored[0].TemplateConfig.Sm.Data(), restored[0].TemplateConfig.Md.Data(), restored[0].TemplateConfig.Lg.Data(), restored[0].TemplateConfig.Xl.Data()} {
+			assert.ElementsMatch(t, stored.TemplateConfig.Sm.Data(), layout, "The flagged widget should survive the round trip")
+		}
+	})
+
+	t.Run("should not store anything in a dry run", func(t *testing.T) {
+		testUserID := test_util.GetUniqueUserID()
+		storeArchiveTestTemplate(t, testUserID, "archive-base", "Operations", false, "widget1")
+		rename := api.ArchiveConflictRename
+		archive := api.DashboardArchive{
+			SchemaVersion: api.DashboardArchiveSchemaVersion,
+			Templates: []api.ArchivedDashboardTemplate{
+				archivedTemplate("archive-base", "Operations", false, "widget1"),
+				archivedTemplate("archive-base", "Operations", false, "widget2"),
+			},
+		}
+
+		items, status, err := service.ImportDashboardArchive(context.Background(), testRegistries, archive, orgIdentity(testUserID, "", false), nil, &rename, true)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+		require.Len(t, items, 2)
+		assert.Equal(t, "Operations (2)", items[0].Template.DashboardName)
+		assert.Equal(t, "Operations (3)", items[1].Template.DashboardName, "Templates of the same archive should not conflict with each other")
+		assert.Len(t, userTemplates(t, testUserID), 1)
+	})
+
+	t.Run("should not restore anything when a template is invalid", func(t *testing.T) {
+		testUserID := test_util.GetUniqueUserID()
+		archive := api.DashboardArchive{
+			SchemaVersion: api.DashboardArchiveSchemaVersion,
+			Templates: []api.ArchivedDashboardTemplate{
+				archivedTemplate("archive-base", "Operations", false, "widget1"),
+				archivedTemplate("", "Invalid", false, "widget1"),
+			},
+		}
+
+		items, status, err := service.ImportDashboardArchive(context.Background(), testRegistries, archive, orgIdentity(testUserID, "", false), nil, nil, false)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, status)
+		require.Len(t, items, 2)
+		assert.Equal(t, api.ArchiveImportCreated, items[0].Action)
+		assert.Equal(t, api.ArchiveImportFailed, items[1].Action)
+		assert.Error(t, items[1].Err)
+		assert.Empty(t, userTemplates(t, testUserID))
+	})
+
+	t.Run("should reject unsupported archives", func(t *testing.T) {
+		testIdentity := orgIdentity(test_util.GetUniqueUserID(), "", false)
+		for _, archive := range []api.DashboardArchive{
+			{},
+			{SchemaVersion: api.DashboardArchiveSchemaVersion + 1},
+			{SchemaVersion: api.DashboardArchiveSchemaVersion, Templates: make([]api.ArchivedDashboardTemplate, service.MaxArchiveTemplates+1)},
+		} {
+			_, status, err := service.ImportDashboardArchive(context.Background(), testRegistries, archive, testIdentity, nil, nil, false)
+			assert.Error(t, err)
+			assert.Equal(t, http.StatusBadRequest, status)
+		}
+	})
+}
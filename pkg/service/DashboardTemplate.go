@@ -1,12 +1,15 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/RedHatInsights/widget-layout-backend/api"
 	"github.com/RedHatInsights/widget-layout-backend/pkg/database"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/layout"
 	"github.com/redhatinsights/platform-go-middlewares/v2/identity"
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
@@ -25,9 +28,45 @@ func handleServiceError[T any](err error, notFoundMsg, generalMsg string, notFou
 	return *new(T), 0, nil
 }
 
+// errVersionConflict is returned when a template was modified by another request between loading and saving it.
+var errVersionConflict = errors.New("dashboard template has been modified by another request")
+
+// checkPrecondition verifies the If-Match header of a request against the current version of the template.
+func checkPrecondition(template api.DashboardTemplate, ifMatch *string) (int, error) {
+	if ifMatch == nil || template.MatchesETag(*ifMatch) {
+		return 0, nil
+	}
+	logrus.Errorf("If-Match %s does not match version %s of dashboard template with ID %d", *ifMatch, template.ETag(), template.ID)
+	return http.StatusPreconditionFailed, fmt.Errorf("precondition failed: dashboard template with ID %d has been modified, current version is %s", template.ID, template.ETag())
+}
+
+// saveTemplate persists all fields of the template and increments its version.
+// The write only applies if the stored version is still the one the template was loaded with.
+func saveTemplate(tx *gorm.DB, template *api.DashboardTemplate) error {
+	version := template.Version
+	template.Version++
+	// Select is required, otherwise Save falls back to an insert when no row matches the version
+	res := tx.Select("*").Where("version = ?", version).Save(template)
+	if res.Error == nil && res.RowsAffected == 0 {
+		res.Error = errVersionConflict
+	}
+	if res.Error != nil {
+		template.Version = version
+	}
+	return res.Error
+}
+
+// mutationErrorStatus maps the error of a failed template write to a response status.
+func mutationErrorStatus(err error) int {
+	if errors.Is(err, errVersionConflict) {
+		return http.StatusPreconditionFailed
+	}
+	return http.StatusInternalServerError
+}
+
 func GetTemplateByID(templateID int64, id identity.XRHID) (api.DashboardTemplate, int, error) {
 	var template api.DashboardTemplate
-	err := database.DB.Where(api.DashboardTemplate{ID: uint(templateID), UserId: id.Identity.User.UserID}).First(&template).Error
+	err := database.DB.First(&template, templateID).Error
 	if ret, status, err := handleServiceError(
 		err,
 		// notFoundMsg
@@ -38,47 +77,169 @@ func GetTemplateByID(templateID int64, id identity.XRHID) (api.DashboardTemplate
 	); err != nil {
 		return ret, status, err
 	}
+	// templates the user cannot see do not exist for them
+	if !canReadTemplate(template, id) {
+		return api.DashboardTemplate{}, http.StatusNotFound, fmt.Errorf("Dashboard template with ID %d not found", templateID)
+	}
 	return template, http.StatusOK, nil
 }
 
-func GetUserTemplates(id identity.XRHID, params api.GetWidgetLayoutParams) ([]api.DashboardTemplate, int, error) {
-	var templates []api.DashboardTemplate
-	where := api.DashboardTemplate{UserId: id.Identity.User.UserID}
+// Page size of the template list when the request does not set a limit, and the largest page size a request can ask for.
+const (
+	DefaultTemplateListLimit = 50
+	MaxTemplateListLimit     = 100
+)
+
+// templateSortColumns maps the sortBy values of the template list to the columns they sort by.
+var templateSortColumns = map[api.GetWidgetLayoutParamsSortBy]string{
+	api.SortByCreatedAt:     "created_at",
+	api.SortByUpdatedAt:     "updated_at",
+	api.SortByDashboardName: "dashboard_name",
+}
+
+// likeEscaper escapes the wildcards of a LIKE pattern, the pattern has to use ESCAPE '\'.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// pageOptions validates the pagination parameters of a paginated list and applies their defaults.
+func pageOptions(limitParam, offsetParam *int) (limit, offset int, err error) {
+	limit, offset = DefaultTemplateListLimit, 0
+	if limitParam != nil {
+		limit = *limitParam
+	}
+	if limit < 1 || limit > MaxTemplateListLimit {
+		return 0, 0, fmt.Errorf("limit must be between 1 and %d", MaxTemplateListLimit)
+	}
+	if offsetParam != nil {
+		offset = *offsetParam
+	}
+	if offset < 0 {
+		return 0, 0, errors.New("offset must not be negative")
+	}
+	return limit, offset, nil
+}
+
+// templateListOptions validates the pagination and sorting parameters of the template list and applies their defaults.
+func templateListOptions(params api.GetWidgetLayoutParams) (limit, offset int, orderBy string, err error) {
+	limit, offset, err = pageOptions(params.Limit, params.Offset)
+	if err != nil {
+		return 0, 0, "", err
+	}
+
+	sortBy, order := api.SortByCreatedAt, api.OrderAsc
+	if params.SortBy != nil {
+		sortBy = *params.SortBy
+	}
+	column, ok := templateSortColumns[sortBy]
+	if !ok {
+		return 0, 0, "", fmt.Errorf("sortBy must be one of %s, %s, %s", api.SortByCreatedAt, api.SortByUpdatedAt, api.SortByDashboardName)
+	}
+	if params.Order != nil {
+		order = *params.Order
+	}
+	if order != api.OrderAsc && order != api.OrderDesc {
+		return 0, 0, "", fmt.Errorf("order must be one of %s, %s", api.OrderAsc, api.OrderDesc)
+	}
+	// the ID breaks ties, so pages never overlap or skip templates with equal sort values
+	return limit, offset, fmt.Sprintf("%s %s, id %s", column, order, order), nil
+}
+
+// templateSummaryColumns are the columns of a template needed for its summary, the layouts are never loaded.
+var templateSummaryColumns = []string{"id", "dashboard_name", "name", "display_name", "is_default", "visibility", "updated_at", "version"}
+
+// GetUserTemplates returns a page of the templates of the user, or of the templates shared with the organization
+// of the user when the scope is org, the meta describes the page.
+// When templates of a dashboard type of the user are requested and the user has none, the base template is forked instead.
+func GetUserTemplates(reg *Registries, id identity.XRHID, params api.GetWidgetLayoutParams) ([]api.DashboardTemplate, api.ListResponseMeta, int, error) {
+	return findUserTemplates(reg, id, params, nil, func(template api.DashboardTemplate) api.DashboardTemplate { return template })
+}
+
+// GetUserTemplateSummaries returns a page of the summaries of the templates of the user, see GetUserTemplates.
+func GetUserTemplateSummaries(reg *Registries, id identity.XRHID, params api.GetWidgetLayoutParams) ([]api.DashboardTemplateSummary, api.ListResponseMeta, int, error) {
+	return findUserTemplates(reg, id, params, templateSummaryColumns, api.DashboardTemplate.Summary)
+}
+
+// findUserTemplates loads a page of the templates of the user into T, selecting only the given columns if any.
+// A template forked because the user has none of the requested type is converted with fromTemplate.
+func findUserTemplates[T any](reg *Registries, id identity.XRHID, params api.GetWidgetLayoutParams, columns []string, fromTemplate func(api.DashboardTemplate) T) ([]T, api.ListResponseMeta, int, error) {
+	limit, offset, orderBy, err := templateListOptions(params)
+	if err != nil {
+		logrus.Errorf("Invalid dashboard template list parameters: %v", err)
+		return nil, api.ListResponseMeta{}, http.StatusBadRequest, err
+	}
+
+	scope := api.ScopeMine
+	if params.Scope != nil {
+		scope = *params.Scope
+	}
+	owned := database.DB.Model(&api.DashboardTemplate{})
+	switch scope {
+	case api.ScopeMine:
+		owned = owned.Where("user_id = ?", id.Identity.User.UserID)
+	case api.ScopeOrg:
+		if id.Identity.OrgID == "" {
+			logrus.Errorf("User %s has no organization to list shared dashboard templates of", id.Identity.User.UserID)
+			return nil, api.ListResponseMeta{}, http.StatusBadRequest, errors.New("the identity has no organization to list shared dashboard templates of")
+		}
+		owned = owned.Where("org_id = ? AND visibility = ?", id.Identity.OrgID, api.VisibilityOrg)
+	default:
+		logrus.Errorf("Invalid dashboard template list scope: %s", scope)
+		return nil, api.ListResponseMeta{}, http.StatusBadRequest, fmt.Errorf("scope must be one of %s, %s", api.ScopeMine, api.ScopeOrg)
+	}
 	if params.DashboardType != nil {
-		where.TemplateBase.Name = *params.DashboardType
+		owned = owned.Where("name = ?", *params.DashboardType)
 	}
-	query := database.DB.Where(where)
-	res := query.Find(&templates)
-	err := res.Error
-	if err == nil && res.RowsAffected == 0 && params.DashboardType != nil {
+	var typeTotal int64
+	err = owned.Session(&gorm.Session{}).Count(&typeTotal).Error
+	// only the templates of the user are forked, an organization without shared templates simply has none
+	if err == nil && typeTotal == 0 && params.DashboardType != nil && scope == api.ScopeMine {
 		logrus.Infof("No dashboard templates found for user %s with type %s", id.Identity.User.UserID, *params.DashboardType)
-		newTemplate, status, err := ForkBaseTemplate(*params.DashboardType, id)
+		newTemplate, status, err := forkBaseTemplate(reg, *params.DashboardType, id, api.AuditActionCreate)
 		if err != nil {
 			logrus.Errorf("Failed to create new dashboard template for user %s with type %s: %v", id.Identity.User.UserID, *params.DashboardType, err)
-			return nil, status, err
+			return nil, api.ListResponseMeta{}, status, err
 		}
 
-		newTemplate, status, err = ChangeDefaultTemplate(int64(newTemplate.ID), id)
+		newTemplate, status, err = ChangeDefaultTemplate(int64(newTemplate.ID), id, nil)
 		if err != nil {
 			logrus.Errorf("Failed to set new dashboard template as default for user %s with type %s: %v", id.Identity.User.UserID, *params.DashboardType, err)
-			return nil, status, err
+			return nil, api.ListResponseMeta{}, status, err
 		}
 
-		return []api.DashboardTemplate{newTemplate}, http.StatusNotFound, nil
+		total := 1
+		return []T{fromTemplate(newTemplate)}, api.ListResponseMeta{Count: 1, Total: &total, Limit: &limit, Offset: &offset}, http.StatusNotFound, nil
 	}
 
+	var templates []T
+	var total int64
+	if err == nil {
+		query := owned.Session(&gorm.Session{})
+		if params.Default != nil {
+			query = query.Where("is_default = ?", *params.Default)
+		}
+		if params.DashboardName != nil && *params.DashboardName != "" {
+			query = query.Where(`LOWER(dashboard_name) LIKE ? ESCAPE '\'`, "%"+likeEscaper.Replace(strings.ToLower(*params.DashboardName))+"%")
+		}
+		err = query.Count(&total).Error
+		if err == nil {
+			if len(columns) > 0 {
+				query = query.Select(columns)
+			}
+			err = query.Order(orderBy).Limit(limit).Offset(offset).Find(&templates).Error
+		}
+	}
 	if _, status, err := handleServiceError(
 		err,
 		fmt.Sprintf("No dashboard templates found for user %s", id.Identity.User.UserID),
 		"Failed to retrieve dashboard templates for user %s: %v", http.StatusNotFound,
-		nil, []api.DashboardTemplate{},
+		nil, []T{},
 	); err != nil {
-		return nil, status, err
+		return nil, api.ListResponseMeta{}, status, err
 	}
-	return templates, http.StatusOK, nil
+	totalCount := int(total)
+	return templates, api.ListResponseMeta{Count: len(templates), Total: &totalCount, Limit: &limit, Offset: &offset}, http.StatusOK, nil
 }
 
-func UpdateDashboardTemplate(templateID int64, newConfig api.DashboardTemplateConfig, id identity.XRHID) (api.DashboardTemplate, int, error) {
+func UpdateDashboardTemplate(ctx context.Context, reg *Registries, templateID int64, newConfig api.DashboardTemplateConfig, id identity.XRHID, mode *api.ValidationMode, compact bool, ifMatch *string) (api.DashboardTemplate, int, error) {
 	var originalTemplate api.DashboardTemplate
 	err := database.DB.First(&originalTemplate, templateID).Error
 	if ret, status, err := handleServiceError(
@@ -89,17 +250,162 @@ func UpdateDashboardTemplate(templateID int64, newConfig api.DashboardTemplateCo
 	); err != nil {
 		return ret, status, err
 	}
-	if !originalTemplate.IsAuthorized(id.Identity.User.UserID) {
+	if !canEditTemplate(originalTemplate, id) {
 		return api.DashboardTemplate{}, http.StatusForbidden, errors.New("unauthorized")
 	}
+	if status, err := checkPrecondition(originalTemplate, ifMatch); err != nil {
+		return api.DashboardTemplate{}, status, err
+	}
+	updated := originalTemplate
+	// the caller saves the layouts they were shown, widgets hidden from them are kept as stored
+	updated.TemplateConfig = newWidgetVisibility(ctx, reg, id).restoreHidden(newConfig, originalTemplate.TemplateConfig)
+	if err := checkWidgetMappings(reg, &updated, mode); err != nil {
+		logrus.Errorf("Failed to update dashboard template with ID %d: %v", templateID, err)
+		return api.DashboardTemplate{}, http.StatusBadRequest, err
+	}
+	// compacted after the hidden widgets are restored, so they are moved up with the rest of the layout
+	if compact {
+		updated.TemplateConfig = layout.CompactConfig(updated.TemplateConfig)
+	}
+	if err := updated.TemplateConfig.IsValid(); err != nil {
+		logrus.Errorf("Failed to update dashboard template with ID %d: %v", templateID, err)
+		return api.DashboardTemplate{}, http.StatusBadRequest, err
+	}
+	if status, err := checkWidgetPolicy(reg, updated, id); err != nil {
+		logrus.Errorf("Failed to update dashboard template with ID %d: %v", templateID, err)
+		return api.DashboardTemplate{}, status, err
+	}
 	logrus.Infof("Updating dashboard template with ID: %d", templateID)
-	originalTemplate.TemplateConfig = newConfig
-	err = database.DB.Save(&originalTemplate).Error
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := recordRevision(tx, originalTemplate); err != nil {
+			return err
+		}
+		if err := saveTemplate(tx, &updated); err != nil {
+			return err
+		}
+		if err := recordAuditEvent(tx, id, api.AuditActionUpdate, updated.ID, layoutSummary(originalTemplate.TemplateConfig, updated.TemplateConfig)); err != nil {
+			return err
+		}
+		return enqueueTemplateEvent(tx, id, api.AuditActionUpdate, updated, originalTemplate.TemplateConfig)
+	})
 	if err != nil {
 		logrus.Errorf("Failed to update dashboard template with ID %d: %v", templateID, err)
-		return api.DashboardTemplate{}, http.StatusInternalServerError, err
+		return api.DashboardTemplate{}, mutationErrorStatus(err), err
+	}
+	return updated, http.StatusOK, nil
+}
+
+func PatchDashboardTemplateWidgets(ctx context.Context, reg *Registries, templateID int64, operations []api.WidgetLayoutOperation, id identity.XRHID, mode *api.ValidationMode, ifMatch *string) (api.DashboardTemplate, int, error) {
+	var template api.DashboardTemplate
+	err := database.DB.First(&template, templateID).Error
+	if ret, status, err := handleServiceError(
+		err,
+		fmt.Sprintf("Dashboard template with ID %d not found", templateID),
+		"Failed to retrieve dashboard template with ID %d: %v", http.StatusNotFound,
+		api.DashboardTemplate{}, api.DashboardTemplate{},
+	); err != nil {
+		return ret, status, err
+	}
+	if !canEditTemplate(template, id) {
+		return api.DashboardTemplate{}, http.StatusForbidden, errors.New("unauthorized")
+	}
+	if status, err := checkPrecondition(template, ifMatch); err != nil {
+		return api.DashboardTemplate{}, status, err
+	}
+
+	// the operations address the layouts the caller was shown, widgets hidden from them are kept as stored
+	wv := newWidgetVisibility(ctx, reg, id)
+	updated := template
+	updated.TemplateConfig = wv.filterConfig(template.TemplateConfig)
+	if err := updated.TemplateConfig.ApplyOperations(operations); err != nil {
+		logrus.Errorf("Failed to apply widget operations to dashboard template with ID %d: %v", templateID, err)
+		return api.DashboardTemplate{}, http.StatusBadRequest, err
+	}
+	updated.TemplateConfig = wv.restoreHidden(updated.TemplateConfig, template.TemplateConfig)
+	if err := checkWidgetMappings(reg, &updated, mode); err != nil {
+		logrus.Errorf("Widget operations result in an invalid dashboard template with ID %d: %v", templateID, err)
+		return api.DashboardTemplate{}, http.StatusBadRequest, err
+	}
+	if err := updated.TemplateConfig.IsValid(); err != nil {
+		logrus.Errorf("Widget operations result in an invalid dashboard template with ID %d: %v", templateID, err)
+		return api.DashboardTemplate{}, http.StatusBadRequest, err
+	}
+	if status, err := checkWidgetPolicy(reg, updated, id); err != nil {
+		logrus.Errorf("Widget operations result in a dashboard template with ID %d violating a widget policy: %v", templateID, err)
+		return api.DashboardTemplate{}, status, err
+	}
+
+	logrus.Infof("Applying %d widget operations to dashboard template with ID: %d", len(operations), templateID)
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := recordRevision(tx, template); err != nil {
+			return err
+		}
+		if err := saveTemplate(tx, &updated); err != nil {
+			return err
+		}
+		if err := recordAuditEvent(tx, id, api.AuditActionUpdate, updated.ID, layoutSummary(template.TemplateConfig, updated.TemplateConfig)); err != nil {
+			return err
+		}
+		return enqueueTemplateEvent(tx, id, api.AuditActionUpdate, updated, template.TemplateConfig)
+	})
+	if err != nil {
+		logrus.Errorf("Failed to apply widget operations to dashboard template with ID %d: %v", templateID, err)
+		return api.DashboardTemplate{}, mutationErrorStatus(err), err
+	}
+	return updated, http.StatusOK, nil
+}
+
+func CompactDashboardTemplate(reg *Registries, templateID int64, id identity.XRHID, ifMatch *string) (api.DashboardTemplate, int, error) {
+	var template api.DashboardTemplate
+	err := database.DB.First(&template, templateID).Error
+	if ret, status, err := handleServiceError(
+		err,
+		fmt.Sprintf("Dashboard template with ID %d not found", templateID),
+		"Failed to retrieve dashboard template with ID %d: %v", http.StatusNotFound,
+		api.DashboardTemplate{}, api.DashboardTemplate{},
+	); err != nil {
+		return ret, status, err
+	}
+	if !canEditTemplate(template, id) {
+		return api.DashboardTemplate{}, http.StatusForbidden, errors.New("unauthorized")
+	}
+	if status, err := checkPrecondition(template, ifMatch); err != nil {
+		return api.DashboardTemplate{}, status, err
 	}
-	return originalTemplate, http.StatusOK, nil
+
+	newConfig := layout.CompactConfig(template.TemplateConfig)
+	if err := newConfig.IsValid(); err != nil {
+		logrus.Errorf("Compacted dashboard template with ID %d is invalid: %v", templateID, err)
+		return api.DashboardTemplate{}, http.StatusBadRequest, err
+	}
+	compacted := template
+	compacted.TemplateConfig = newConfig
+	if status, err := checkWidgetPolicy(reg, compacted, id); err != nil {
+		logrus.Errorf("Compacted dashboard template with ID %d violates a widget policy: %v", templateID, err)
+		return api.DashboardTemplate{}, status, err
+	}
+
+	logrus.Infof("Compacting dashboard template with ID: %d", templateID)
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := recordRevision(tx, template); err != nil {
+			return err
+		}
+		before := template.TemplateConfig
+		summary := "compacted: " + layoutSummary(before, newConfig)
+		template.TemplateConfig = newConfig
+		if err := saveTemplate(tx, &template); err != nil {
+			return err
+		}
+		if err := recordAuditEvent(tx, id, api.AuditActionUpdate, template.ID, summary); err != nil {
+			return err
+		}
+		return enqueueTemplateEvent(tx, id, api.AuditActionUpdate, template, before)
+	})
+	if err != nil {
+		logrus.Errorf("Failed to compact dashboard template with ID %d: %v", templateID, err)
+		return api.DashboardTemplate{}, mutationErrorStatus(err), err
+	}
+	return template, http.StatusOK, nil
 }
 
 func DeleteDashboardTemplate(templateID int64, id identity.XRHID) (int, error) {
@@ -117,8 +423,16 @@ func DeleteDashboardTemplate(templateID int64, id identity.XRHID) (int, error) {
 		return http.StatusForbidden, errors.New("unauthorized")
 	}
 	logrus.Infof("Deleting dashboard template with ID: %d", templateID)
-	// delete permanently, there is no restore feature implemented
-	err = database.DB.Unscoped().Delete(&template).Error
+	// soft delete, the template stays in the trash until it is restored or purged
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&template).Error; err != nil {
+			return err
+		}
+		if err := recordAuditEvent(tx, id, api.AuditActionDelete, template.ID, "moved to the trash"); err != nil {
+			return err
+		}
+		return enqueueTemplateEvent(tx, id, api.AuditActionDelete, template, template.TemplateConfig)
+	})
 	if err != nil {
 		logrus.Errorf("Failed to delete dashboard template with ID %d: %v", templateID, err)
 		return http.StatusInternalServerError, err
@@ -126,7 +440,7 @@ func DeleteDashboardTemplate(templateID int64, id identity.XRHID) (int, error) {
 	return http.StatusNoContent, nil
 }
 
-func CopyDashboardTemplate(templateID int64, id identity.XRHID, dashboardName *string) (api.DashboardTemplate, int, error) {
+func CopyDashboardTemplate(reg *Registries, templateID int64, id identity.XRHID, dashboardName *string) (api.DashboardTemplate, int, error) {
 	var dashboardTemplate api.DashboardTemplate
 	err := database.DB.First(&dashboardTemplate, templateID).Error
 	if ret, status, err := handleServiceError(
@@ -137,17 +451,39 @@ func CopyDashboardTemplate(templateID int64, id identity.XRHID, dashboardName *s
 	); err != nil {
 		return ret, status, err
 	}
-	// For copying, we don't check authorization - any user can copy any template
-	// The new template will belong to the copying user
+	// templates of other users can only be copied when they are shared with the user, see CopySharedDashboardTemplate
+	if !canReadTemplate(dashboardTemplate, id) {
+		return api.DashboardTemplate{}, http.StatusForbidden, errors.New("unauthorized")
+	}
+	return copyTemplate(reg, dashboardTemplate, id, dashboardName)
+}
+
+// copyTemplate stores a private copy of the template owned by the user. The copy has to follow the widget
+// policies of the organization of the user, which may differ from the ones of the source template.
+func copyTemplate(reg *Registries, dashboardTemplate api.DashboardTemplate, id identity.XRHID, dashboardName *string) (api.DashboardTemplate, int, error) {
 	newTemplate := api.DashboardTemplate{
 		TemplateBase:   dashboardTemplate.TemplateBase,
 		UserId:         id.Identity.User.UserID,
+		OrgId:          id.Identity.OrgID,
 		TemplateConfig: dashboardTemplate.TemplateConfig,
+		BaseVersion:    dashboardTemplate.BaseVersion,
 	}
 	if dashboardName != nil && *dashboardName != "" {
 		newTemplate.DashboardName = *dashboardName
 	}
-	err = database.DB.Create(&newTemplate).Error
+	if status, err := checkWidgetPolicy(reg, newTemplate, id); err != nil {
+		logrus.Errorf("Failed to copy dashboard template with ID %d: %v", dashboardTemplate.ID, err)
+		return api.DashboardTemplate{}, status, err
+	}
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&newTemplate).Error; err != nil {
+			return err
+		}
+		if err := recordAuditEvent(tx, id, api.AuditActionCopy, newTemplate.ID, fmt.Sprintf("copied from dashboard template %d", dashboardTemplate.ID)); err != nil {
+			return err
+		}
+		return enqueueTemplateEvent(tx, id, api.AuditActionCopy, newTemplate, api.DashboardTemplateConfig{})
+	})
 	if err != nil {
 		logrus.Errorf("Failed to create dashboard template: %v", err)
 		return api.DashboardTemplate{}, http.StatusInternalServerError, err
@@ -155,7 +491,17 @@ func CopyDashboardTemplate(templateID int64, id identity.XRHID, dashboardName *s
 	return newTemplate, http.StatusOK, nil
 }
 
-func ChangeDefaultTemplate(templateID int64, id identity.XRHID) (api.DashboardTemplate, int, error) {
+// unsetDefaultTemplates unsets the default status of the other templates of the user with the same base.
+func unsetDefaultTemplates(tx *gorm.DB, baseName, userID string, templateID uint) error {
+	// Use map update because GORM skips zero-value fields in struct updates,
+	// and false is the zero value for bool.
+	// Use "is_default" column name (not "default") to avoid the SQL reserved keyword
+	// which causes silent 0-row updates in PostgreSQL.
+	// The version of every template losing its default status is bumped as well.
+	return tx.Model(&api.DashboardTemplate{}).Where("name = ? AND user_id = ? AND id <> ? AND is_default = ?", baseName, userID, templateID, true).Updates(map[string]interface{}{"is_default": false, "version": gorm.Expr("version + 1")}).Error
+}
+
+func ChangeDefaultTemplate(templateID int64, id identity.XRHID, ifMatch *string) (api.DashboardTemplate, int, error) {
 	var template api.DashboardTemplate
 	err := database.DB.First(&template, templateID).Error
 	if ret, status, err := handleServiceError(
@@ -170,13 +516,11 @@ func ChangeDefaultTemplate(templateID int64, id identity.XRHID) (api.DashboardTe
 		logrus.Errorf("User %s is not authorized to change default template with ID %d", id.Identity.User.UserID, templateID)
 		return api.DashboardTemplate{}, http.StatusForbidden, errors.New("unauthorized")
 	}
+	if status, err := checkPrecondition(template, ifMatch); err != nil {
+		return api.DashboardTemplate{}, status, err
+	}
 	tx := database.DB.Begin()
-	// Update all other templates with the same base to unset their default status.
-	// Use map update because GORM skips zero-value fields in struct updates,
-	// and false is the zero value for bool.
-	// Use "is_default" column name (not "default") to avoid the SQL reserved keyword
-	// which causes silent 0-row updates in PostgreSQL.
-	err = tx.Model(&api.DashboardTemplate{}).Where("name = ? AND user_id = ? AND id <> ?", template.TemplateBase.Name, id.Identity.User.UserID, template.ID).Updates(map[string]interface{}{"is_default": false}).Error
+	err = unsetDefaultTemplates(tx, template.TemplateBase.Name, id.Identity.User.UserID, template.ID)
 	if err != nil {
 		logrus.Errorf("Failed to unset default dashboard template with ID %d: %v", templateID, err)
 		tx.Rollback()
@@ -184,10 +528,22 @@ func ChangeDefaultTemplate(templateID int64, id identity.XRHID) (api.DashboardTe
 	}
 	// Set the specified template as the default
 	template.Default = true
-	err = tx.Save(&template).Error
+	err = saveTemplate(tx, &template)
 	if err != nil {
 		logrus.Errorf("Failed to change default dashboard template with ID %d: %v", templateID, err)
 		tx.Rollback()
+		return api.DashboardTemplate{}, mutationErrorStatus(err), err
+	}
+	err = recordAuditEvent(tx, id, api.AuditActionSetDefault, template.ID, fmt.Sprintf("set as default %s dashboard", template.TemplateBase.Name))
+	if err != nil {
+		logrus.Errorf("Failed to record default dashboard template with ID %d in the audit log: %v", templateID, err)
+		tx.Rollback()
+		return api.DashboardTemplate{}, http.StatusInternalServerError, err
+	}
+	err = enqueueTemplateEvent(tx, id, api.AuditActionSetDefault, template, template.TemplateConfig)
+	if err != nil {
+		logrus.Errorf("Failed to queue the change event of default dashboard template with ID %d: %v", templateID, err)
+		tx.Rollback()
 		return api.DashboardTemplate{}, http.StatusInternalServerError, err
 	}
 	err = tx.Commit().Error
@@ -198,7 +554,7 @@ func ChangeDefaultTemplate(templateID int64, id identity.XRHID) (api.DashboardTe
 	return template, http.StatusOK, nil
 }
 
-func ResetDashboardTemplate(templateID int64, id identity.XRHID) (api.DashboardTemplate, int, error) {
+func ResetDashboardTemplate(reg *Registries, templateID int64, id identity.XRHID, ifMatch *string) (api.DashboardTemplate, int, error) {
 	var template api.DashboardTemplate
 	err := database.DB.First(&template, templateID).Error
 	if ret, status, err := handleServiceError(
@@ -209,21 +565,45 @@ func ResetDashboardTemplate(templateID int64, id identity.XRHID) (api.DashboardT
 	); err != nil {
 		return ret, status, err
 	}
-	if !template.IsAuthorized(id.Identity.User.UserID) {
+	if !canEditTemplate(template, id) {
 		return api.DashboardTemplate{}, http.StatusForbidden, errors.New("unauthorized")
 	}
+	if status, err := checkPrecondition(template, ifMatch); err != nil {
+		return api.DashboardTemplate{}, status, err
+	}
 	templateName := template.TemplateBase.Name
-	baseTC, exists := BaseTemplateRegistry.GetBase(templateName)
+	baseTC, exists, err := resolveBaseTemplate(reg, templateName, id)
+	if err != nil {
+		logrus.Errorf("Failed to retrieve base template %s for resetting dashboard template with ID %d: %v", templateName, templateID, err)
+		return api.DashboardTemplate{}, http.StatusInternalServerError, err
+	}
 	if !exists {
 		logrus.Errorf("Base template %s not found for resetting dashboard template with ID %d", templateName, templateID)
 		return template, http.StatusNotFound, fmt.Errorf("base template %s not found", templateName)
 	}
 
-	template.TemplateConfig = baseTC.TemplateConfig
-	err = database.DB.Save(&template).Error
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := recordRevision(tx, template); err != nil {
+			return err
+		}
+		if err := recordBaseVersion(tx, baseTC); err != nil {
+			return err
+		}
+		before := template.TemplateConfig
+		summary := fmt.Sprintf("reset to base template %s: %s", templateName, layoutSummary(before, baseTC.TemplateConfig))
+		template.TemplateConfig = baseTC.TemplateConfig
+		template.BaseVersion = baseTC.ConfigVersion()
+		if err := saveTemplate(tx, &template); err != nil {
+			return err
+		}
+		if err := recordAuditEvent(tx, id, api.AuditActionReset, template.ID, summary); err != nil {
+			return err
+		}
+		return enqueueTemplateEvent(tx, id, api.AuditActionReset, template, before)
+	})
 	if err != nil {
 		logrus.Errorf("Failed to reset dashboard template with ID %d: %v", templateID, err)
-		return api.DashboardTemplate{}, http.StatusInternalServerError, err
+		return api.DashboardTemplate{}, mutationErrorStatus(err), err
 	}
 	logrus.Infof("Dashboard template with ID %d reset to base template %s", templateID, templateName)
 	return template, http.StatusOK, nil
@@ -237,23 +617,46 @@ func ExportWidgetDashboardTemplate(templateID int64, id identity.XRHID) (api.Exp
 
 	// Convert to export format
 	return api.ExportWidgetDashboardTemplateResponse{
+		SchemaVersion:  api.ExportSchemaVersion,
 		TemplateConfig: template.TemplateConfig,
 		TemplateBase:   template.TemplateBase,
 	}, http.StatusOK, nil
 }
 
-func ForkBaseTemplate(baseTemplateName string, id identity.XRHID) (api.DashboardTemplate, int, error) {
-	baseTemplate, exists := BaseTemplateRegistry.GetBase(baseTemplateName)
+func ForkBaseTemplate(reg *Registries, baseTemplateName string, id identity.XRHID) (api.DashboardTemplate, int, error) {
+	return forkBaseTemplate(reg, baseTemplateName, id, api.AuditActionFork)
+}
+
+// forkBaseTemplate stores a copy of the base template owned by the user, the audit log tells forks requested by the
+// user apart from templates created on their behalf.
+func forkBaseTemplate(reg *Registries, baseTemplateName string, id identity.XRHID, action api.AuditAction) (api.DashboardTemplate, int, error) {
+	baseTemplate, exists, err := resolveBaseTemplate(reg, baseTemplateName, id)
+	if err != nil {
+		logrus.Errorf("Failed to retrieve base template %s for forking: %v", baseTemplateName, err)
+		return api.DashboardTemplate{}, http.StatusInternalServerError, err
+	}
 	if !exists {
 		logrus.Errorf("Base template %s not found for forking", baseTemplateName)
 		return api.DashboardTemplate{}, http.StatusNotFound, fmt.Errorf("base template %s not found", baseTemplateName)
 	}
 	// Create a new dashboard template using the base template's ToDashboardTemplate method
 	newTemplate := baseTemplate.ToDashboardTemplate()
-	// Set the user ID for the forked template
+	// Set the user ID and organization for the forked template
 	newTemplate.UserId = id.Identity.User.UserID
+	newTemplate.OrgId = id.Identity.OrgID
 
-	err := database.DB.Create(&newTemplate).Error
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := recordBaseVersion(tx, baseTemplate); err != nil {
+			return err
+		}
+		if err := tx.Create(&newTemplate).Error; err != nil {
+			return err
+		}
+		if err := recordAuditEvent(tx, id, action, newTemplate.ID, fmt.Sprintf("forked from base template %s", baseTemplateName)); err != nil {
+			return err
+		}
+		return enqueueTemplateEvent(tx, id, action, newTemplate, api.DashboardTemplateConfig{})
+	})
 	if err != nil {
 		logrus.Errorf("Failed to create forked dashboard template from base %s: %v", baseTemplateName, err)
 		return api.DashboardTemplate{}, http.StatusInternalServerError, err
@@ -263,7 +666,7 @@ func ForkBaseTemplate(baseTemplateName string, id identity.XRHID) (api.Dashboard
 	return newTemplate, http.StatusOK, nil
 }
 
-func RenameDashboardTemplate(templateID int64, newName string, id identity.XRHID) (api.DashboardTemplate, int, error) {
+func RenameDashboardTemplate(templateID int64, newName string, id identity.XRHID, ifMatch *string) (api.DashboardTemplate, int, error) {
 	var template api.DashboardTemplate
 	err := database.DB.First(&template, templateID).Error
 	if ret, status, err := handleServiceError(
@@ -274,34 +677,88 @@ func RenameDashboardTemplate(templateID int64, newName string, id identity.XRHID
 	); err != nil {
 		return ret, status, err
 	}
-	if !template.IsAuthorized(id.Identity.User.UserID) {
+	if !canEditTemplate(template, id) {
 		return api.DashboardTemplate{}, http.StatusForbidden, errors.New("unauthorized")
 	}
+	if status, err := checkPrecondition(template, ifMatch); err != nil {
+		return api.DashboardTemplate{}, status, err
+	}
 	logrus.Infof("Renaming dashboard template with ID: %d", templateID)
-	template.DashboardName = newName
-	err = database.DB.Model(&template).Update("dashboard_name", newName).Error
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := recordRevision(tx, template); err != nil {
+			return err
+		}
+		summary := fmt.Sprintf("renamed from %q to %q", template.DashboardName, newName)
+		template.DashboardName = newName
+		if err := saveTemplate(tx, &template); err != nil {
+			return err
+		}
+		if err := recordAuditEvent(tx, id, api.AuditActionRename, template.ID, summary); err != nil {
+			return err
+		}
+		return enqueueTemplateEvent(tx, id, api.AuditActionRename, template, template.TemplateConfig)
+	})
 	if err != nil {
 		logrus.Errorf("Failed to rename dashboard template with ID %d: %v", templateID, err)
-		return api.DashboardTemplate{}, http.StatusInternalServerError, err
+		return api.DashboardTemplate{}, mutationErrorStatus(err), err
 	}
 	return template, http.StatusOK, nil
 }
 
-func ImportDashboardTemplate(importData api.ImportWidgetLayoutJSONRequestBody, id identity.XRHID) (api.DashboardTemplate, int, error) {
+// prepareImportedTemplate builds the template of the importing user from the imported data and validates it,
+// without storing it. With compact the layouts are compacted before they are validated.
+func prepareImportedTemplate(reg *Registries, importData api.ImportWidgetDashboardTemplateRequest, id identity.XRHID, mode *api.ValidationMode, compact bool) (api.DashboardTemplate, int, error) {
 	newTemplate := api.DashboardTemplate{
 		TemplateConfig: importData.TemplateConfig,
 		TemplateBase:   importData.TemplateBase,
 		DashboardName:  importData.DashboardName,
 		Default:        false,
 		UserId:         id.Identity.User.UserID,
+		OrgId:          id.Identity.OrgID,
+	}
+
+	templateConfig, err := reflowMissingBreakpoints(reg, newTemplate.TemplateConfig, nil)
+	if err != nil {
+		return api.DashboardTemplate{}, http.StatusBadRequest, err
 	}
+	newTemplate.TemplateConfig = templateConfig
 
+	if err := checkWidgetMappings(reg, &newTemplate, mode); err != nil {
+		return api.DashboardTemplate{}, http.StatusBadRequest, err
+	}
+	if compact {
+		newTemplate.TemplateConfig = layout.CompactConfig(newTemplate.TemplateConfig)
+	}
 	if err := newTemplate.IsValid(); err != nil {
-		logrus.Errorf("Failed to import dashboard template: %v", err)
 		return api.DashboardTemplate{}, http.StatusBadRequest, err
 	}
+	if status, err := checkWidgetPolicy(reg, newTemplate, id); err != nil {
+		return api.DashboardTemplate{}, status, err
+	}
+	return newTemplate, http.StatusOK, nil
+}
+
+// storeImportedTemplate creates a prepared template in the transaction of the import.
+func storeImportedTemplate(tx *gorm.DB, id identity.XRHID, template *api.DashboardTemplate) error {
+	if err := tx.Create(template).Error; err != nil {
+		return err
+	}
+	if err := recordAuditEvent(tx, id, api.AuditActionImport, template.ID, layoutSummary(api.DashboardTemplateConfig{}, template.TemplateConfig)); err != nil {
+		return err
+	}
+	return enqueueTemplateEvent(tx, id, api.AuditActionImport, *template, api.DashboardTemplateConfig{})
+}
+
+func ImportDashboardTemplate(reg *Registries, importData api.ImportWidgetLayoutJSONRequestBody, id identity.XRHID, mode *api.ValidationMode, compact bool) (api.DashboardTemplate, int, error) {
+	newTemplate, status, err := prepareImportedTemplate(reg, importData, id, mode, compact)
+	if err != nil {
+		logrus.Errorf("Failed to import dashboard template: %v", err)
+		return api.DashboardTemplate{}, status, err
+	}
 
-	err := database.DB.Create(&newTemplate).Error
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		return storeImportedTemplate(tx, id, &newTemplate)
+	})
 	if err != nil {
 		logrus.Errorf("Failed to import dashboard template: %v", err)
 		return api.DashboardTemplate{}, http.StatusInternalServerError, err
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/RedHatInsights/widget-layout-backend/api"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/config"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/database"
+	"github.com/redhatinsights/platform-go-middlewares/v2/identity"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// recordRevision stores a snapshot of the template as it is before being overwritten.
+// It has to be called within the same transaction as the mutation itself.
+func recordRevision(tx *gorm.DB, template api.DashboardTemplate) error {
+	revision := api.DashboardTemplateRevision{
+		DashboardTemplateId: template.ID,
+		UserId:              template.UserId,
+		DashboardName:       template.DashboardName,
+		TemplateConfig:      template.TemplateConfig,
+	}
+	if err := tx.Create(&revision).Error; err != nil {
+		return err
+	}
+	return pruneRevisions(tx, template.ID)
+}
+
+// pruneRevisions removes the oldest revisions of a template exceeding the configured retention count.
+func pruneRevisions(tx *gorm.DB, templateID uint) error {
+	retention := config.GetConfig().TemplateRevisionRetention
+	var revisionIDs []uint
+	err := tx.Model(&api.DashboardTemplateRevision{}).
+		Where("dashboard_template_id = ?", templateID).
+		Order("id DESC").
+		Pluck("id", &revisionIDs).Error
+	if err != nil {
+		return err
+	}
+	if len(revisionIDs) <= retention {
+		return nil
+	}
+	return tx.Delete(&api.DashboardTemplateRevision{}, revisionIDs[retention:]).Error
+}
+
+// deleteRevisions removes the whole revision history of a template.
+func deleteRevisions(tx *gorm.DB, templateID uint) error {
+	return tx.Where("dashboard_template_id = ?", templateID).Delete(&api.DashboardTemplateRevision{}).Error
+}
+
+func GetTemplateRevisions(templateID int64, id identity.XRHID) ([]api.DashboardTemplateRevision, int, error) {
+	var template api.DashboardTemplate
+	err := database.DB.First(&template, templateID).Error
+	if _, status, err := handleServiceError(
+		err,
+		fmt.Sprintf("Dashboard template with ID %d not found", templateID),
+		"Failed to retrieve dashboard template with ID %d: %v", http.StatusNotFound,
+		nil, []api.DashboardTemplateRevision{},
+	); err != nil {
+		return nil, status, err
+	}
+	if !canReadTemplate(template, id) {
+		return nil, http.StatusForbidden, errors.New("unauthorized")
+	}
+
+	revisions := []api.DashboardTemplateRevision{}
+	err = database.DB.Where("dashboard_template_id = ?", template.ID).Order("id DESC").Find(&revisions).Error
+	if err != nil {
+		logrus.Errorf("Failed to retrieve revisions of dashboard template with ID %d: %v", templateID, err)
+		return nil, http.StatusInternalServerError, err
+	}
+	return revisions, http.StatusOK, nil
+}
+
+func RestoreTemplateRevision(reg *Registries, templateID int64, revisionID int64, id identity.XRHID, ifMatch *string) (api.DashboardTemplate, int, error) {
+	var template api.DashboardTemplate
+	err := database.DB.First(&template, templateID).Error
+	if ret, status, err := handleServiceError(
+		err,
+		fmt.Sprintf("Dashboard template with ID %d not found", templateID),
+		"Failed to retrieve dashboard template with ID %d: %v", http.StatusNotFound,
+		api.DashboardTemplate{}, api.DashboardTemplate{},
+	); err != nil {
+		return ret, status, err
+	}
+	if !canEditTemplate(template, id) {
+		return api.DashboardTemplate{}, http.StatusForbidden, errors.New("unauthorized")
+	}
+	if status, err := checkPrecondition(template, ifMatch); err != nil {
+		return api.DashboardTemplate{}, status, err
+	}
+
+	var revision api.DashboardTemplateRevision
+	err = database.DB.Where("id = ? AND dashboard_template_id = ?", revisionID, template.ID).First(&revision).Error
+	if ret, status, err := handleServiceError(
+		err,
+		fmt.Sprintf("Revision %d of dashboard template with ID %d not found", revisionID, templateID),
+		"Failed to retrieve revision of dashboard template with ID %d: %v", http.StatusNotFound,
+		api.DashboardTemplate{}, api.DashboardTemplate{},
+	); err != nil {
+		return ret, status, err
+	}
+	restored := template
+	restored.TemplateConfig = revision.TemplateConfig
+	// the widget mappings may have changed since the revision was recorded
+	if err := checkWidgetMappings(reg, &restored, nil); err != nil {
+		logrus.Errorf("Revision %d of dashboard template with ID %d does not match the widget mappings: %v", revisionID, templateID, err)
+		return api.DashboardTemplate{}, http.StatusBadRequest, err
+	}
+	if err := restored.TemplateConfig.IsValid(); err != nil {
+		logrus.Errorf("Revision %d of dashboard template with ID %d has an invalid layout: %v", revisionID, templateID, err)
+		return api.DashboardTemplate{}, http.StatusBadRequest, err
+	}
+	if status, err := checkWidgetPolicy(reg, restored, id); err != nil {
+		logrus.Errorf("Revision %d of dashboard template with ID %d violates a widget policy: %v", revisionID, templateID, err)
+		return api.DashboardTemplate{}, status, err
+	}
+
+	logrus.Infof("Restoring dashboard template with ID %d to revision %d", templateID, revisionID)
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		// the restore itself is recorded so it can be undone as well
+		if err := recordRevision(tx, template); err != nil {
+			return err
+		}
+		before := template.TemplateConfig
+		summary := fmt.Sprintf("restored revision %d: %s", revision.ID, layoutSummary(before, revision.TemplateConfig))
+		template.DashboardName = revision.DashboardName
+		template.TemplateConfig = revision.TemplateConfig
+		if err := saveTemplate(tx, &template); err != nil {
+			return err
+		}
+		if err := recordAuditEvent(tx, id, api.AuditActionUpdate, template.ID, summary); err != nil {
+			return err
+		}
+		return enqueueTemplateEvent(tx, id, api.AuditActionUpdate, template, before)
+	})
+	if err != nil {
+		logrus.Errorf("Failed to restore dashboard template with ID %d to revision %d: %v", templateID, revisionID, err)
+		return api.DashboardTemplate{}, mutationErrorStatus(err), err
+	}
+	return template, http.StatusOK, nil
+}
@@ -0,0 +1,216 @@
+package service_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/RedHatInsights/widget-layout-backend/api"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/config"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/database"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/service"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/test_util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/subpop/xrhidgen"
+)
+
+func TestDashboardTemplateRevisions(t *testing.T) {
+	t.Run("should record a revision on update, rename and reset", func(t *testing.T) {
+		testUserID := test_util.GetUniqueUserID()
+		testIdentity := test_util.GenerateIdentityStructFromTemplate(
+			xrhidgen.Identity{},
+			xrhidgen.User{UserID: stringPtr(testUserID)},
+			xrhidgen.Entitlements{},
+		)
+
+		testRegistries.BaseTemplates.Replace(nil)
+		testRegistries.AddBaseTemplate(api.BaseWidgetDashboardTemplate{
+			Name:           "revision-base",
+			DisplayName:    "Revision Base",
+			TemplateConfig: test_util.MockTemplateConfig("base-widget"),
+		})
+
+		template := createTestTemplate(testUserID, "revision-base", "Revision Base")
+		template.DashboardName = "Original"
+		require.NoError(t, database.DB.Create(&template).Error)
+
+		_, _, err := service.UpdateDashboardTemplate(context.Background(), testRegistries, int64(template.ID), test_util.MockTemplateConfig("updated-widget"), testIdentity, nil, false, nil)
+		require.NoError(t, err)
+		_, _, err = service.RenameDashboardTemplate(int64(template.ID), "Renamed", testIdentity, nil)
+		require.NoError(t, err)
+		_, _, err = service.ResetDashboardTemplate(testRegistries, int64(template.ID), testIdentity, nil)
+		require.NoError(t, err)
+
+		revisions, status, err := service.GetTemplateRevisions(int64(template.ID), testIdentity)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+		require.Len(t, revisions, 3)
+
+		// newest first, each revision holds the state before the mutation
+		assert.Equal(t, "Renamed", revisions[0].DashboardName)
+		assert.Equal(t, "updated-widget", revisions[0].TemplateConfig.Sm.Data()[0].WidgetType)
+		assert.Equal(t, "Original", revisions[1].DashboardName)
+		assert.Equal(t, "updated-widget", revisions[1].TemplateConfig.Sm.Data()[0].WidgetType)
+		assert.Equal(t, "Original", revisions[2].DashboardName)
+		assert.Equal(t, "widget1", revisions[2].TemplateConfig.Sm.Data()[0].WidgetType)
+	})
+
+	t.Run("should prune revisions beyond the retention count", func(t *testing.T) {
+		cfg := config.GetConfig()
+		originalRetention := cfg.TemplateRevisionRetention
+		cfg.TemplateRevisionRetention = 2
+		defer func() { cfg.TemplateRevisionRetention = originalRetention }()
+
+		testUserID := test_util.GetUniqueUserID()
+		testIdentity := test_util.GenerateIdentityStructFromTemplate(
+			xrhidgen.Identity{},
+			xrhidgen.User{UserID: stringPtr(testUserID)},
+			xrhidgen.Entitlements{},
+		)
+
+		template := test_util.MockDashboardTemplateWithSpecificUser(testUserID)
+		require.NoError(t, database.DB.Create(&template).Error)
+
+		for _, name := range []string{"First", "Second", "Third", "Fourth"} {
+			_, _, err := service.RenameDashboardTemplate(int64(template.ID), name, testIdentity, nil)
+			require.NoError(t, err)
+		}
+
+		revisions, _, err := service.GetTemplateRevisions(int64(template.ID), testIdentity)
+		require.NoError(t, err)
+		require.Len(t, revisions, 2)
+		assert.Equal(t, "Third", revisions[0].DashboardName)
+		assert.Equal(t, "Second", revisions[1].DashboardName)
+	})
+
+	t.Run("should return 403 when listing revisions of another user's template", func(t *testing.T) {
+		ownerID := test_util.GetUniqueUserID()
+		otherIdentity := test_util.GenerateIdentityStructFromTemplate(
+			xrhidgen.Identity{},
+			xrhidgen.User{UserID: stringPtr(test_util.GetUniqueUserID())},
+			xrhidgen.Entitlements{},
+		)
+
+		template := test_util.MockDashboardTemplateWithSpecificUser(ownerID)
+		require.NoError(t, database.DB.Create(&template).Error)
+
+		_, status, err := service.GetTemplateRevisions(int64(template.ID), otherIdentity)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusForbidden, status)
+	})
+
+	t.Run("should restore a revision and record the overwritten state", func(t *testing.T) {
+		testUserID := test_util.GetUniqueUserID()
+		testIdentity := test_util.GenerateIdentityStructFromTemplate(
+			xrhidgen.Identity{},
+			xrhidgen.User{UserID: stringPtr(testUserID)},
+			xrhidgen.Entitlements{},
+		)
+
+		template := test_util.MockDashboardTemplateWithSpecificUser(testUserID)
+		template.DashboardName = "Before"
+		template.TemplateConfig = test_util.MockTemplateConfig("widget1")
+		require.NoError(t, database.DB.Create(&template).Error)
+
+		_, _, err := service.UpdateDashboardTemplate(context.Background(), testRegistries, int64(template.ID), test_util.MockTemplateConfig("broken-layout"), testIdentity, nil, false, nil)
+		require.NoError(t, err)
+
+		revisions, _, err := service.GetTemplateRevisions(int64(template.ID), testIdentity)
+		require.NoError(t, err)
+		require.Len(t, revisions, 1)
+
+		restored, status, err := service.RestoreTemplateRevision(testRegistries, int64(template.ID), int64(revisions[0].ID), testIdentity, nil)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, "widget1", restored.TemplateConfig.Sm.Data()[0].WidgetType)
+
+		var dbTemplate api.DashboardTemplate
+		require.NoError(t, database.DB.First(&dbTemplate, template.ID).Error)
+		assert.Equal(t, "widget1", dbTemplate.TemplateConfig.Sm.Data()[0].WidgetType)
+
+		revisions, _, err = service.GetTemplateRevisions(int64(template.ID), testIdentity)
+		require.NoError(t, err)
+		require.Len(t, revisions, 2)
+		assert.Equal(t, "broken-layout", revisions[0].TemplateConfig.Sm.Data()[0].WidgetType)
+	})
+
+	t.Run("should return 404 when restoring a revision of a different template", func(t *testing.T) {
+		testUserID := test_util.GetUniqueUserID()
+		testIdentity := test_util.GenerateIdentityStructFromTemplate(
+			xrhidgen.Identity{},
+			xrhidgen.User{UserID: stringPtr(testUserID)},
+			xrhidgen.Entitlements{},
+		)
+
+		template1 := test_util.MockDashboardTemplateWithSpecificUser(testUserID)
+		template2 := test_util.MockDashboardTemplateWithSpecificUser(testUserID)
+		require.NoError(t, database.DB.Create(&template1).Error)
+		require.NoError(t, database.DB.Create(&template2).Error)
+
+		_, _, err := service.RenameDashboardTemplate(int64(template1.ID), "Renamed", testIdentity, nil)
+		require.NoError(t, err)
+		revisions, _, err := service.GetTemplateRevisions(int64(template1.ID), testIdentity)
+		require.NoError(t, err)
+		require.Len(t, revisions, 1)
+
+		_, status, err := service.RestoreTemplateRevision(testRegistries, int64(template2.ID), int64(revisions[0].ID), testIdentity, nil)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusNotFound, status)
+	})
+
+	t.Run("should not restore a revision which no longer matches the widget mappings", func(t *testing.T) {
+		registerPermissionTestMappings(t)
+		testUserID := test_util.GetUniqueUserID()
+		testIdentity := test_util.GenerateIdentityStructFromTemplate(
+			xrhidgen.Identity{},
+			xrhidgen.User{UserID: stringPtr(testUserID)},
+			xrhidgen.Entitlements{},
+		)
+
+		template := test_util.MockDashboardTemplateWithSpecificUser(testUserID)
+		template.TemplateConfig = test_util.MockTemplateConfig("retired-widget")
+		require.NoError(t, database.DB.Create(&template).Error)
+		_, _, err := service.UpdateDashboardTemplate(context.Background(), testRegistries, int64(template.ID), test_util.MockTemplateConfig("landing-./PublicWidget"), testIdentity, nil, false, nil)
+		require.NoError(t, err)
+		revisions, _, err := service.GetTemplateRevisions(int64(template.ID), testIdentity)
+		require.NoError(t, err)
+		require.Len(t, revisions, 1)
+
+		_, status, err := service.RestoreTemplateRevision(testRegistries, int64(template.ID), int64(revisions[0].ID), testIdentity, nil)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusBadRequest, status)
+
+		var dbTemplate api.DashboardTemplate
+		require.NoError(t, database.DB.First(&dbTemplate, template.ID).Error)
+		assert.Equal(t, "landing-./PublicWidget", dbTemplate.TemplateConfig.Sm.Data()[0].WidgetType)
+	})
+
+	t.Run("should keep revisions of deleted templates until they are purged", func(t *testing.T) {
+		testUserID := test_util.GetUniqueUserID()
+		testIdentity := test_util.GenerateIdentityStructFromTemplate(
+			xrhidgen.Identity{},
+			xrhidgen.User{UserID: stringPtr(testUserID)},
+			xrhidgen.Entitlements{},
+		)
+
+		template := test_util.MockDashboardTemplateWithSpecificUser(testUserID)
+		require.NoError(t, database.DB.Create(&template).Error)
+		_, _, err := service.RenameDashboardTemplate(int64(template.ID), "Renamed", testIdentity, nil)
+		require.NoError(t, err)
+
+		_, err = service.DeleteDashboardTemplate(int64(template.ID), testIdentity)
+		require.NoError(t, err)
+
+		var count int64
+		require.NoError(t, database.DB.Model(&api.DashboardTemplateRevision{}).Where("dashboard_template_id = ?", template.ID).Count(&count).Error)
+		assert.Equal(t, int64(1), count, "Revisions should be kept while the template is in the trash")
+
+		_, err = service.PurgeDeletedTemplates(time.Now().Add(time.Minute))
+		require.NoError(t, err)
+
+		require.NoError(t, database.DB.Model(&api.DashboardTemplateRevision{}).Where("dashboard_template_id = ?", template.ID).Count(&count).Error)
+		assert.Zero(t, count)
+	})
+}
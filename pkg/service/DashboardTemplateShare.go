@@ -0,0 +1,159 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/RedHatInsights/widget-layout-backend/api"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/database"
+	"github.com/redhatinsights/platform-go-middlewares/v2/identity"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// shareTokenBytes is the amount of random bytes of a share token, it is encoded into 32 characters.
+const shareTokenBytes = 24
+
+var errShareNotFound = errors.New("share link not found")
+
+func generateShareToken() (string, error) {
+	b := make([]byte, shareTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// getOwnedTemplate loads a template whose share links are managed, only the owner can manage them.
+func getOwnedTemplate(templateID int64, id identity.XRHID) (api.DashboardTemplate, int, error) {
+	var template api.DashboardTemplate
+	err := database.DB.First(&template, templateID).Error
+	if ret, status, err := handleServiceError(
+		err,
+		fmt.Sprintf("Dashboard template with ID %d not found", templateID),
+		"Failed to retrieve dashboard template with ID %d: %v", http.StatusNotFound,
+		api.DashboardTemplate{}, api.DashboardTemplate{},
+	); err != nil {
+		return ret, status, err
+	}
+	if !template.IsAuthorized(id.Identity.User.UserID) {
+		return api.DashboardTemplate{}, http.StatusForbidden, errors.New("unauthorized")
+	}
+	return template, http.StatusOK, nil
+}
+
+func CreateDashboardTemplateShare(templateID int64, expiresAt *time.Time, id identity.XRHID) (api.DashboardTemplateShare, int, error) {
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return api.DashboardTemplateShare{}, http.StatusBadRequest, errors.New("expiresAt must be in the future")
+	}
+	template, status, err := getOwnedTemplate(templateID, id)
+	if err != nil {
+		return api.DashboardTemplateShare{}, status, err
+	}
+	token, err := generateShareToken()
+	if err != nil {
+		logrus.Errorf("Failed to generate share token for dashboard template with ID %d: %v", templateID, err)
+		return api.DashboardTemplateShare{}, http.StatusInternalServerError, err
+	}
+
+	share := api.DashboardTemplateShare{
+		DashboardTemplateId: template.ID,
+		UserId:              id.Identity.User.UserID,
+		Token:               token,
+		ExpiresAt:           expiresAt,
+	}
+	if err := database.DB.Create(&share).Error; err != nil {
+		logrus.Errorf("Failed to create share link for dashboard template with ID %d: %v", templateID, err)
+		return api.DashboardTemplateShare{}, http.StatusInternalServerError, err
+	}
+	logrus.Infof("Created share link %d for dashboard template with ID %d", share.ID, templateID)
+	return share, http.StatusOK, nil
+}
+
+func GetDashboardTemplateShares(templateID int64, id identity.XRHID) ([]api.DashboardTemplateShare, int, error) {
+	template, status, err := getOwnedTemplate(templateID, id)
+	if err != nil {
+		return nil, status, err
+	}
+	shares := []api.DashboardTemplateShare{}
+	err = database.DB.Where("dashboard_template_id = ?", template.ID).Order("id DESC").Find(&shares).Error
+	if err != nil {
+		logrus.Errorf("Failed to retrieve share links of dashboard template with ID %d: %v", templateID, err)
+		return nil, http.StatusInternalServerError, err
+	}
+	return shares, http.StatusOK, nil
+}
+
+func RevokeDashboardTemplateShare(templateID int64, shareID int64, id identity.XRHID) (int, error) {
+	template, status, err := getOwnedTemplate(templateID, id)
+	if err != nil {
+		return status, err
+	}
+	res := database.DB.Where("id = ? AND dashboard_template_id = ?", shareID, template.ID).Delete(&api.DashboardTemplateShare{})
+	if res.Error != nil {
+		logrus.Errorf("Failed to revoke share link %d of dashboard template with ID %d: %v", shareID, templateID, res.Error)
+		return http.StatusInternalServerError, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return http.StatusNotFound, fmt.Errorf("Share link %d of dashboard template with ID %d not found", shareID, templateID)
+	}
+	logrus.Infof("Revoked share link %d of dashboard template with ID %d", shareID, templateID)
+	return http.StatusNoContent, nil
+}
+
+// deleteShares revokes all share links of a template.
+func deleteShares(tx *gorm.DB, templateID uint) error {
+	return tx.Where("dashboard_template_id = ?", templateID).Delete(&api.DashboardTemplateShare{}).Error
+}
+
+// findSharedTemplate resolves a share token to the template it shares. Expired links and links of deleted
+// templates are not found, just like revoked ones.
+func findSharedTemplate(token string) (api.DashboardTemplateShare, api.DashboardTemplate, int, error) {
+	var share api.DashboardTemplateShare
+	err := database.DB.Where("token = ?", token).First(&share).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && share.ExpiresAt != nil && !share.ExpiresAt.After(time.Now())) {
+		return api.DashboardTemplateShare{}, api.DashboardTemplate{}, http.StatusNotFound, errShareNotFound
+	}
+	if err != nil {
+		logrus.Errorf("Failed to retrieve share link: %v", err)
+		return api.DashboardTemplateShare{}, api.DashboardTemplate{}, http.StatusInternalServerError, err
+	}
+
+	var template api.DashboardTemplate
+	err = database.DB.First(&template, share.DashboardTemplateId).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return api.DashboardTemplateShare{}, api.DashboardTemplate{}, http.StatusNotFound, errShareNotFound
+	}
+	if err != nil {
+		logrus.Errorf("Failed to retrieve dashboard template of share link %d: %v", share.ID, err)
+		return api.DashboardTemplateShare{}, api.DashboardTemplate{}, http.StatusInternalServerError, err
+	}
+	return share, template, http.StatusOK, nil
+}
+
+func GetSharedDashboardTemplate(token string) (api.SharedDashboardTemplate, int, error) {
+	share, template, status, err := findSharedTemplate(token)
+	if err != nil {
+		return api.SharedDashboardTemplate{}, status, err
+	}
+	return api.SharedDashboardTemplate{
+		DashboardName:  template.DashboardName,
+		TemplateBase:   template.TemplateBase,
+		TemplateConfig: template.TemplateConfig,
+		UpdatedAt:      template.UpdatedAt,
+		ExpiresAt:      share.ExpiresAt,
+	}, http.StatusOK, nil
+}
+
+func CopySharedDashboardTemplate(reg *Registries, token string, id identity.XRHID, dashboardName *string) (api.DashboardTemplate, int, error) {
+	share, template, status, err := findSharedTemplate(token)
+	if err != nil {
+		return api.DashboardTemplate{}, status, err
+	}
+	logrus.Infof("Copying dashboard template with ID %d shared by link %d for user %s", template.ID, share.ID, id.Identity.User.UserID)
+	return copyTemplate(reg, template, id, dashboardName)
+}
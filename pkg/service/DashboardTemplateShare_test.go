@@ -0,0 +1,152 @@
+package service_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/RedHatInsights/widget-layout-backend/api"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/database"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/service"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/test_util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/subpop/xrhidgen"
+)
+
+func TestDashboardTemplateShare(t *testing.T) {
+	t.Run("should share a read-only view and copies with everyone holding the token", func(t *testing.T) {
+		ownerID := test_util.GetUniqueUserID()
+		owner := test_util.GenerateIdentityStructFromTemplate(
+			xrhidgen.Identity{},
+			xrhidgen.User{UserID: stringPtr(ownerID)},
+			xrhidgen.Entitlements{},
+		)
+		template := createTestTemplate(ownerID, "share-base", "Share Base")
+		template.DashboardName = "Shared Dashboard"
+		require.NoError(t, database.DB.Create(&template).Error)
+
+		share, status, err := service.CreateDashboardTemplateShare(int64(template.ID), nil, owner)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Len(t, share.Token, 32)
+		assert.Equal(t, template.ID, share.DashboardTemplateId)
+
+		shared, status, err := service.GetSharedDashboardTemplate(share.Token)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, "Shared Dashboard", shared.DashboardName)
+		assert.Equal(t, template.TemplateConfig, shared.TemplateConfig)
+
+		copierID := test_util.GetUniqueUserID()
+		copier := test_util.GenerateIdentityStructFromTemplate(
+			xrhidgen.Identity{},
+			xrhidgen.User{UserID: stringPtr(copierID)},
+			xrhidgen.Entitlements{},
+		)
+		copied, status, err := service.CopySharedDashboardTemplate(testRegistries, share.Token, copier, nil)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+		assert.NotEqual(t, template.ID, copied.ID)
+		assert.Equal(t, copierID, copied.UserId)
+		assert.Equal(t, api.VisibilityPrivate, copied.Visibility, "Copies should be private")
+	})
+
+	t.Run("should not resolve revoked share links", func(t *testing.T) {
+		ownerID := test_util.GetUniqueUserID()
+		owner := test_util.GenerateIdentityStructFromTemplate(
+			xrhidgen.Identity{},
+			xrhidgen.User{UserID: stringPtr(ownerID)},
+			xrhidgen.Entitlements{},
+		)
+		template := createTestTemplate(ownerID, "share-base", "Share Base")
+		require.NoError(t, database.DB.Create(&template).Error)
+		share, _, err := service.CreateDashboardTemplateShare(int64(template.ID), nil, owner)
+		require.NoError(t, err)
+
+		shares, _, err := service.GetDashboardTemplateShares(int64(template.ID), owner)
+		require.NoError(t, err)
+		require.Len(t, shares, 1)
+
+		status, err := service.RevokeDashboardTemplateShare(int64(template.ID), int64(share.ID), owner)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNoContent, status)
+
+		_, status, err = service.GetSharedDashboardTemplate(share.Token)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusNotFound, status)
+
+		status, err = service.RevokeDashboardTemplateShare(int64(template.ID), int64(share.ID), owner)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusNotFound, status)
+	})
+
+	t.Run("should not resolve expired share links", func(t *testing.T) {
+		ownerID := test_util.GetUniqueUserID()
+		template := createTestTemplate(ownerID, "share-base", "Share Base")
+		require.NoError(t, database.DB.Create(&template).Error)
+		expired := time.Now().Add(-time.Minute)
+		share := api.DashboardTemplateShare{DashboardTemplateId: template.ID, UserId: ownerID, Token: "expired-" + ownerID, ExpiresAt: &expired}
+		require.NoError(t, database.DB.Create(&share).Error)
+
+		_, status, err := service.GetSharedDashboardTemplate(share.Token)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusNotFound, status)
+	})
+
+	t.Run("should not resolve share links of deleted templates", func(t *testing.T) {
+		ownerID := test_util.GetUniqueUserID()
+		owner := test_util.GenerateIdentityStructFromTemplate(
+			xrhidgen.Identity{},
+			xrhidgen.User{UserID: stringPtr(ownerID)},
+			xrhidgen.Entitlements{},
+		)
+		template := createTestTemplate(ownerID, "share-base", "Share Base")
+		require.NoError(t, database.DB.Create(&template).Error)
+		share, _, err := service.CreateDashboardTemplateShare(int64(template.ID), nil, owner)
+		require.NoError(t, err)
+		_, err = service.DeleteDashboardTemplate(int64(template.ID), owner)
+		require.NoError(t, err)
+
+		_, status, err := service.CopySharedDashboardTemplate(testRegistries, share.Token, owner, nil)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusNotFound, status)
+	})
+
+	t.Run("should reject expirations in the past", func(t *testing.T) {
+		ownerID := test_util.GetUniqueUserID()
+		owner := test_util.GenerateIdentityStructFromTemplate(
+			xrhidgen.Identity{},
+			xrhidgen.User{UserID: stringPtr(ownerID)},
+			xrhidgen.Entitlements{},
+		)
+		template := createTestTemplate(ownerID, "share-base", "Share Base")
+		require.NoError(t, database.DB.Create(&template).Error)
+		past := time.Now().Add(-time.Hour)
+
+		_, status, err := service.CreateDashboardTemplateShare(int64(template.ID), &past, owner)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusBadRequest, status)
+	})
+
+	t.Run("should only let the owner manage share links", func(t *testing.T) {
+		orgID := "org-" + test_util.GetUniqueUserID()
+		template := createTestTemplate(test_util.GetUniqueUserID(), "share-base", "Share Base")
+		template.OrgId = orgID
+		template.Visibility = api.VisibilityOrg
+		require.NoError(t, database.DB.Create(&template).Error)
+		admin := test_util.GenerateIdentityStructFromTemplate(
+			xrhidgen.Identity{OrgID: stringPtr(orgID)},
+			xrhidgen.User{UserID: stringPtr(test_util.GetUniqueUserID()), IsOrgAdmin: test_util.BoolPTR(true)},
+			xrhidgen.Entitlements{},
+		)
+
+		_, status, err := service.CreateDashboardTemplateShare(int64(template.ID), nil, admin)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusForbidden, status)
+
+		_, status, err = service.GetDashboardTemplateShares(int64(template.ID), admin)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusForbidden, status)
+	})
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/RedHatInsights/widget-layout-backend/api"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/database"
+	"github.com/redhatinsights/platform-go-middlewares/v2/identity"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// canReadTemplate reports whether the identity can see the template, see api.DashboardTemplate.IsReadableBy.
+func canReadTemplate(template api.DashboardTemplate, id identity.XRHID) bool {
+	if template.IsReadableBy(id.Identity.User.UserID, id.Identity.OrgID) {
+		return true
+	}
+	logrus.Errorf("User %s is not authorized to access template with ID %d", id.Identity.User.UserID, template.ID)
+	return false
+}
+
+// canEditTemplate reports whether the identity can modify the template, see api.DashboardTemplate.IsEditableBy.
+func canEditTemplate(template api.DashboardTemplate, id identity.XRHID) bool {
+	if template.IsEditableBy(id.Identity.User.UserID, id.Identity.OrgID, id.Identity.User.OrgAdmin) {
+		return true
+	}
+	logrus.Errorf("User %s is not authorized to modify template with ID %d", id.Identity.User.UserID, template.ID)
+	return false
+}
+
+func SetDashboardTemplateVisibility(templateID int64, visibility api.TemplateVisibility, id identity.XRHID, ifMatch *string) (api.DashboardTemplate, int, error) {
+	if err := visibility.IsValid(); err != nil {
+		return api.DashboardTemplate{}, http.StatusBadRequest, err
+	}
+	var template api.DashboardTemplate
+	err := database.DB.First(&template, templateID).Error
+	if ret, status, err := handleServiceError(
+		err,
+		fmt.Sprintf("Dashboard template with ID %d not found", templateID),
+		"Failed to retrieve dashboard template with ID %d: %v", http.StatusNotFound,
+		api.DashboardTemplate{}, api.DashboardTemplate{},
+	); err != nil {
+		return ret, status, err
+	}
+	// sharing is decided by the owner alone, org admins can only edit shared templates
+	if !template.IsAuthorized(id.Identity.User.UserID) {
+		return api.DashboardTemplate{}, http.StatusForbidden, errors.New("unauthorized")
+	}
+	if status, err := checkPrecondition(template, ifMatch); err != nil {
+		return api.DashboardTemplate{}, status, err
+	}
+	if visibility == api.VisibilityOrg && id.Identity.OrgID == "" {
+		logrus.Errorf("User %s has no organization to share dashboard template with ID %d with", id.Identity.User.UserID, templateID)
+		return api.DashboardTemplate{}, http.StatusBadRequest, errors.New("the identity has no organization to share the dashboard template with")
+	}
+
+	logrus.Infof("Changing visibility of dashboard template with ID %d to %s", templateID, visibility)
+	summary := fmt.Sprintf("visibility changed from %s to %s", template.Visibility, visibility)
+	template.Visibility = visibility
+	// templates created before organizations were recorded belong to the organization of their owner
+	if template.OrgId == "" {
+		template.OrgId = id.Identity.OrgID
+	}
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := saveTemplate(tx, &template); err != nil {
+			return err
+		}
+		if err := recordAuditEvent(tx, id, api.AuditActionUpdate, template.ID, summary); err != nil {
+			return err
+		}
+		return enqueueTemplateEvent(tx, id, api.AuditActionUpdate, template, template.TemplateConfig)
+	})
+	if err != nil {
+		logrus.Errorf("Failed to change visibility of dashboard template with ID %d: %v", templateID, err)
+		return api.DashboardTemplate{}, mutationErrorStatus(err), err
+	}
+	return template, http.StatusOK, nil
+}
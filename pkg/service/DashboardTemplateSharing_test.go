@@ -0,0 +1,159 @@
+package service_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/RedHatInsights/widget-layout-backend/api"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/database"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/service"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/test_util"
+	"github.com/redhatinsights/platform-go-middlewares/v2/identity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/subpop/xrhidgen"
+)
+
+func orgIdentity(userID, orgID string, orgAdmin bool) identity.XRHID {
+	return test_util.GenerateIdentityStructFromTemplate(
+		xrhidgen.Identity{OrgID: stringPtr(orgID)},
+		xrhidgen.User{UserID: stringPtr(userID), IsOrgAdmin: test_util.BoolPTR(orgAdmin)},
+		xrhidgen.Entitlements{},
+	)
+}
+
+// createSharedTemplate stores a template of the owner shared with the organization
+func createSharedTemplate(t *testing.T, ownerID, orgID string) api.DashboardTemplate {
+	template := createTestTemplate(ownerID, "sharing-base", "Sharing Base")
+	template.OrgId = orgID
+	template.Visibility = api.VisibilityOrg
+	require.NoError(t, database.DB.Create(&template).Error)
+	return template
+}
+
+func TestDashboardTemplateSharing(t *testing.T) {
+	t.Run("should let org members read but not modify shared templates", func(t *testing.T) {
+		orgID := "org-" + test_util.GetUniqueUserID()
+		template := createSharedTemplate(t, test_util.GetUniqueUserID(), orgID)
+		member := orgIdentity(test_util.GetUniqueUserID(), orgID, false)
+
+		found, status, err := service.GetTemplateByID(int64(template.ID), member)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, template.ID, found.ID)
+
+		_, status, err = service.RenameDashboardTemplate(int64(template.ID), "Taken Over", member, nil)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusForbidden, status)
+	})
+
+	t.Run("should let org admins modify shared templates", func(t *testing.T) {
+		orgID := "org-" + test_util.GetUniqueUserID()
+		template := createSharedTemplate(t, test_util.GetUniqueUserID(), orgID)
+		admin := orgIdentity(test_util.GetUniqueUserID(), orgID, true)
+
+		renamed, status, err := service.RenameDashboardTemplate(int64(template.ID), "Team Dashboard", admin, nil)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, "Team Dashboard", renamed.DashboardName)
+		assert.Equal(t, template.UserId, renamed.UserId, "Editing should not change the owner")
+	})
+
+	t.Run("should hide shared templates from other organizations", func(t *testing.T) {
+		template := createSharedTemplate(t, test_util.GetUniqueUserID(), "org-"+test_util.GetUniqueUserID())
+		outsider := orgIdentity(test_util.GetUniqueUserID(), "org-"+test_util.GetUniqueUserID(), true)
+
+		_, status, err := service.GetTemplateByID(int64(template.ID), outsider)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusNotFound, status)
+
+		_, status, err = service.RenameDashboardTemplate(int64(template.ID), "Taken Over", outsider, nil)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusForbidden, status)
+	})
+
+	t.Run("should hide private templates from org admins", func(t *testing.T) {
+		orgID := "org-" + test_util.GetUniqueUserID()
+		template := createTestTemplate(test_util.GetUniqueUserID(), "sharing-base", "Sharing Base")
+		template.OrgId = orgID
+		require.NoError(t, database.DB.Create(&template).Error)
+		assert.Equal(t, api.VisibilityPrivate, template.Visibility, "Templates should be private by default")
+		admin := orgIdentity(test_util.GetUniqueUserID(), orgID, true)
+
+		_, status, err := service.GetTemplateByID(int64(template.ID), admin)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusNotFound, status)
+	})
+
+	t.Run("should only let the owner change the visibility", func(t *testing.T) {
+		orgID := "org-" + test_util.GetUniqueUserID()
+		ownerID := test_util.GetUniqueUserID()
+		owner := orgIdentity(ownerID, orgID, false)
+		template := createTestTemplate(ownerID, "sharing-base", "Sharing Base")
+		require.NoError(t, database.DB.Create(&template).Error)
+
+		shared, status, err := service.SetDashboardTemplateVisibility(int64(template.ID), api.VisibilityOrg, owner, nil)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, api.VisibilityOrg, shared.Visibility)
+		assert.Equal(t, orgID, shared.OrgId, "Templates without an organization should be assigned the organization of the owner")
+		assert.Equal(t, template.Version+1, shared.Version)
+
+		admin := orgIdentity(test_util.GetUniqueUserID(), orgID, true)
+		_, status, err = service.SetDashboardTemplateVisibility(int64(template.ID), api.VisibilityPrivate, admin, nil)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusForbidden, status)
+	})
+
+	t.Run("should reject invalid visibilities", func(t *testing.T) {
+		ownerID := test_util.GetUniqueUserID()
+		template := createTestTemplate(ownerID, "sharing-base", "Sharing Base")
+		require.NoError(t, database.DB.Create(&template).Error)
+
+		_, status, err := service.SetDashboardTemplateVisibility(int64(template.ID), "public", orgIdentity(ownerID, "org-"+ownerID, false), nil)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusBadRequest, status)
+	})
+
+	t.Run("should list the templates shared with the organization", func(t *testing.T) {
+		orgID := "org-" + test_util.GetUniqueUserID()
+		shared := createSharedTemplate(t, test_util.GetUniqueUserID(), orgID)
+		private := createTestTemplate(test_util.GetUniqueUserID(), "sharing-base", "Sharing Base")
+		private.OrgId = orgID
+		require.NoError(t, database.DB.Create(&private).Error)
+		createSharedTemplate(t, test_util.GetUniqueUserID(), "org-"+test_util.GetUniqueUserID())
+
+		memberID := test_util.GetUniqueUserID()
+		scope := api.ScopeOrg
+		templates, meta, status, err := service.GetUserTemplates(testRegistries, orgIdentity(memberID, orgID, false), api.GetWidgetLayoutParams{Scope: &scope})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+		require.Len(t, templates, 1)
+		assert.Equal(t, shared.ID, templates[0].ID)
+		assert.Equal(t, 1, *meta.Total)
+
+		dashboardType := "sharing-base"
+		templates, _, status, err = service.GetUserTemplates(testRegistries, orgIdentity(memberID, orgID, false), api.GetWidgetLayoutParams{Scope: &scope, DashboardType: &dashboardType})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Len(t, templates, 1)
+
+		var owned int64
+		require.NoError(t, database.DB.Model(&api.DashboardTemplate{}).Where("user_id = ?", memberID).Count(&owned).Error)
+		assert.Zero(t, owned, "Listing shared templates should never fork a base template")
+	})
+
+	t.Run("should reject the org scope for identities without an organization", func(t *testing.T) {
+		scope := api.ScopeOrg
+		_, _, status, err := service.GetUserTemplates(testRegistries, orgIdentity(test_util.GetUniqueUserID(), "", false), api.GetWidgetLayoutParams{Scope: &scope})
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusBadRequest, status)
+	})
+
+	t.Run("should reject unknown scopes", func(t *testing.T) {
+		scope := api.GetWidgetLayoutParamsScope("everyone")
+		_, _, status, err := service.GetUserTemplates(testRegistries, orgIdentity(test_util.GetUniqueUserID(), "org-1", false), api.GetWidgetLayoutParams{Scope: &scope})
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusBadRequest, status)
+	})
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/RedHatInsights/widget-layout-backend/api"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/database"
+	"github.com/redhatinsights/platform-go-middlewares/v2/identity"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+func GetDeletedTemplates(id identity.XRHID) ([]api.DashboardTemplate, int, error) {
+	templates := []api.DashboardTemplate{}
+	err := database.DB.Unscoped().
+		Where("user_id = ? AND deleted_at IS NOT NULL", id.Identity.User.UserID).
+		Order("deleted_at DESC").
+		Find(&templates).Error
+	if err != nil {
+		logrus.Errorf("Failed to retrieve deleted dashboard templates for user %s: %v", id.Identity.User.UserID, err)
+		return nil, http.StatusInternalServerError, err
+	}
+	return templates, http.StatusOK, nil
+}
+
+func RestoreDeletedTemplate(templateID int64, id identity.XRHID) (api.DashboardTemplate, int, error) {
+	var template api.DashboardTemplate
+	err := database.DB.Unscoped().Where("deleted_at IS NOT NULL").First(&template, templateID).Error
+	if ret, status, err := handleServiceError(
+		err,
+		fmt.Sprintf("Deleted dashboard template with ID %d not found", templateID),
+		"Failed to retrieve deleted dashboard template with ID %d: %v", http.StatusNotFound,
+		api.DashboardTemplate{}, api.DashboardTemplate{},
+	); err != nil {
+		return ret, status, err
+	}
+	if !template.IsAuthorized(id.Identity.User.UserID) {
+		return api.DashboardTemplate{}, http.StatusForbidden, errors.New("unauthorized")
+	}
+
+	logrus.Infof("Restoring deleted dashboard template with ID: %d", templateID)
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if template.Default {
+			// another template may have become the default while this one was in the trash
+			var defaults int64
+			err := tx.Model(&api.DashboardTemplate{}).
+				Where("name = ? AND user_id = ? AND is_default = ?", template.TemplateBase.Name, template.UserId, true).
+				Count(&defaults).Error
+			if err != nil {
+				return err
+			}
+			if defaults > 0 {
+				template.Default = false
+			}
+		}
+		template.DeletedAt = gorm.DeletedAt{}
+		if err := saveTemplate(tx.Unscoped(), &template); err != nil {
+			return err
+		}
+		if err := recordAuditEvent(tx, id, api.AuditActionRestore, template.ID, "restored from the trash"); err != nil {
+			return err
+		}
+		return enqueueTemplateEvent(tx, id, api.AuditActionRestore, template, api.DashboardTemplateConfig{})
+	})
+	if err != nil {
+		logrus.Errorf("Failed to restore deleted dashboard template with ID %d: %v", templateID, err)
+		return api.DashboardTemplate{}, mutationErrorStatus(err), err
+	}
+	return template, http.StatusOK, nil
+}
+
+// PurgeDeletedTemplates permanently removes templates which were deleted before the cutoff, including their revisions and share links.
+func PurgeDeletedTemplates(cutoff time.Time) (int64, error) {
+	var purged int64
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var templateIDs []uint
+		err := tx.Unscoped().Model(&api.DashboardTemplate{}).
+			Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).
+			Pluck("id", &templateIDs).Error
+		if err != nil || len(templateIDs) == 0 {
+			return err
+		}
+		for _, templateID := range templateIDs {
+			if err := deleteRevisions(tx, templateID); err != nil {
+				return err
+			}
+			if err := deleteShares(tx, templateID); err != nil {
+				return err
+			}
+		}
+		res := tx.Unscoped().Delete(&api.DashboardTemplate{}, templateIDs)
+		purged = res.RowsAffected
+		return res.Error
+	})
+	return purged, err
+}
+
+// StartTrashPurgeJob periodically purges templates which have been in the trash for longer than maxAge.
+// The job stops when the context is cancelled.
+func StartTrashPurgeJob(ctx context.Context, interval time.Duration, maxAge time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				purged, err := PurgeDeletedTemplates(time.Now().Add(-maxAge))
+				if err != nil {
+					logrus.Errorf("Failed to purge deleted dashboard templates: %v", err)
+					continue
+				}
+				if purged > 0 {
+					logrus.Infof("Purged %d deleted dashboard templates", purged)
+				}
+			}
+		}
+	}()
+}
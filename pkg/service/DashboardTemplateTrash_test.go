@@ -0,0 +1,150 @@
+package service_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/RedHatInsights/widget-layout-backend/api"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/database"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/service"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/test_util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/subpop/xrhidgen"
+	"gorm.io/gorm"
+)
+
+func TestDashboardTemplateTrash(t *testing.T) {
+	t.Run("should list only deleted templates of the user", func(t *testing.T) {
+		testUserID := test_util.GetUniqueUserID()
+		testIdentity := test_util.GenerateIdentityStructFromTemplate(
+			xrhidgen.Identity{},
+			xrhidgen.User{UserID: stringPtr(testUserID)},
+			xrhidgen.Entitlements{},
+		)
+
+		deleted := test_util.MockDashboardTemplateWithSpecificUser(testUserID)
+		require.NoError(t, database.DB.Create(&deleted).Error)
+		kept := test_util.MockDashboardTemplateWithSpecificUser(testUserID)
+		require.NoError(t, database.DB.Create(&kept).Error)
+		otherUser := test_util.MockDashboardTemplateWithSpecificUser(test_util.GetUniqueUserID())
+		require.NoError(t, database.DB.Create(&otherUser).Error)
+		require.NoError(t, database.DB.Delete(&otherUser).Error)
+
+		_, err := service.DeleteDashboardTemplate(int64(deleted.ID), testIdentity)
+		require.NoError(t, err)
+
+		templates, status, err := service.GetDeletedTemplates(testIdentity)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+		require.Len(t, templates, 1)
+		assert.Equal(t, deleted.ID, templates[0].ID)
+		assert.True(t, templates[0].DeletedAt.Valid)
+	})
+
+	t.Run("should restore a deleted template", func(t *testing.T) {
+		testUserID := test_util.GetUniqueUserID()
+		testIdentity := test_util.GenerateIdentityStructFromTemplate(
+			xrhidgen.Identity{},
+			xrhidgen.User{UserID: stringPtr(testUserID)},
+			xrhidgen.Entitlements{},
+		)
+
+		template := test_util.MockDashboardTemplateWithSpecificUser(testUserID)
+		require.NoError(t, database.DB.Create(&template).Error)
+		_, err := service.DeleteDashboardTemplate(int64(template.ID), testIdentity)
+		require.NoError(t, err)
+
+		restored, status, err := service.RestoreDeletedTemplate(int64(template.ID), testIdentity)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+		assert.False(t, restored.DeletedAt.Valid)
+
+		var dbTemplate api.DashboardTemplate
+		require.NoError(t, database.DB.First(&dbTemplate, template.ID).Error)
+		assert.Equal(t, template.DashboardName, dbTemplate.DashboardName)
+	})
+
+	t.Run("should not restore the default flag if another default exists", func(t *testing.T) {
+		testUserID := test_util.GetUniqueUserID()
+		testIdentity := test_util.GenerateIdentityStructFromTemplate(
+			xrhidgen.Identity{},
+			xrhidgen.User{UserID: stringPtr(testUserID)},
+			xrhidgen.Entitlements{},
+		)
+
+		template := test_util.MockDashboardTemplateWithSpecificUser(testUserID)
+		template.Default = true
+		require.NoError(t, database.DB.Create(&template).Error)
+		_, err := service.DeleteDashboardTemplate(int64(template.ID), testIdentity)
+		require.NoError(t, err)
+
+		replacement := test_util.MockDashboardTemplateWithSpecificUser(testUserID)
+		replacement.Default = true
+		require.NoError(t, database.DB.Create(&replacement).Error)
+
+		restored, _, err := service.RestoreDeletedTemplate(int64(template.ID), testIdentity)
+		require.NoError(t, err)
+		assert.False(t, restored.Default)
+
+		var dbReplacement api.DashboardTemplate
+		require.NoError(t, database.DB.First(&dbReplacement, replacement.ID).Error)
+		assert.True(t, dbReplacement.Default)
+	})
+
+	t.Run("should return 404 when restoring a template which is not deleted", func(t *testing.T) {
+		testUserID := test_util.GetUniqueUserID()
+		testIdentity := test_util.GenerateIdentityStructFromTemplate(
+			xrhidgen.Identity{},
+			xrhidgen.User{UserID: stringPtr(testUserID)},
+			xrhidgen.Entitlements{},
+		)
+
+		template := test_util.MockDashboardTemplateWithSpecificUser(testUserID)
+		require.NoError(t, database.DB.Create(&template).Error)
+
+		_, status, err := service.RestoreDeletedTemplate(int64(template.ID), testIdentity)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusNotFound, status)
+	})
+
+	t.Run("should return 403 when restoring a template of another user", func(t *testing.T) {
+		template := test_util.MockDashboardTemplateWithSpecificUser(test_util.GetUniqueUserID())
+		require.NoError(t, database.DB.Create(&template).Error)
+		require.NoError(t, database.DB.Delete(&template).Error)
+
+		testIdentity := test_util.GenerateIdentityStructFromTemplate(
+			xrhidgen.Identity{},
+			xrhidgen.User{UserID: stringPtr(test_util.GetUniqueUserID())},
+			xrhidgen.Entitlements{},
+		)
+		_, status, err := service.RestoreDeletedTemplate(int64(template.ID), testIdentity)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusForbidden, status)
+	})
+
+	t.Run("should purge only templates deleted before the cutoff", func(t *testing.T) {
+		testUserID := test_util.GetUniqueUserID()
+
+		expired := test_util.MockDashboardTemplateWithSpecificUser(testUserID)
+		require.NoError(t, database.DB.Create(&expired).Error)
+		require.NoError(t, database.DB.Delete(&expired).Error)
+		require.NoError(t, database.DB.Unscoped().Model(&expired).Update("deleted_at", time.Now().Add(-48*time.Hour)).Error)
+		share := api.DashboardTemplateShare{DashboardTemplateId: expired.ID, UserId: testUserID, Token: "purged-" + testUserID}
+		require.NoError(t, database.DB.Create(&share).Error)
+
+		recent := test_util.MockDashboardTemplateWithSpecificUser(testUserID)
+		require.NoError(t, database.DB.Create(&recent).Error)
+		require.NoError(t, database.DB.Delete(&recent).Error)
+
+		_, err := service.PurgeDeletedTemplates(time.Now().Add(-24 * time.Hour))
+		require.NoError(t, err)
+
+		var dbTemplate api.DashboardTemplate
+		err = database.DB.Unscoped().First(&dbTemplate, expired.ID).Error
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound, "Expired template should be purged")
+		assert.ErrorIs(t, database.DB.First(&api.DashboardTemplateShare{}, share.ID).Error, gorm.ErrRecordNotFound, "Share links of purged templates should be removed")
+		require.NoError(t, database.DB.Unscoped().First(&dbTemplate, recent.ID).Error, "Recently deleted template should be kept")
+	})
+}
@@ -1,6 +1,7 @@
 package service_test
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/http"
@@ -21,6 +22,9 @@ import (
 	"gorm.io/gorm"
 )
 
+// testRegistries are the registries passed to the services under test
+var testRegistries = service.NewRegistries()
+
 func TestMain(m *testing.M) {
 	cfg := config.GetConfig()
 	now := time.Now().UnixNano()
@@ -31,6 +35,15 @@ func TestMain(m *testing.M) {
 	database.InitDb()
 	if err := database.DB.AutoMigrate(
 		&models.DashboardTemplate{},
+		&models.DashboardTemplateRevision{},
+		&models.BaseTemplateVersion{},
+		&models.DashboardTemplateShare{},
+		&models.OrgBaseTemplate{},
+		&models.OrgWidgetPolicy{},
+		&models.AuditEvent{},
+		&models.OutboxEvent{},
+		&models.Webhook{},
+		&models.WebhookDelivery{},
 	); err != nil {
 		log.Fatalf("Failed to migrate test database: %v", err)
 	}
@@ -56,7 +69,7 @@ func TestMain(m *testing.M) {
 func TestForkBaseTemplate(t *testing.T) {
 	t.Run("should successfully fork existing base template", func(t *testing.T) {
 		// Reset registry and add a base template
-		service.BaseTemplateRegistry = api.BaseWidgetDashboardTemplateRegistry{}
+		testRegistries.BaseTemplates.Replace(nil)
 
 		baseTemplate := api.BaseWidgetDashboardTemplate{
 			Name:        "fork-service-test",
@@ -79,7 +92,7 @@ func TestForkBaseTemplate(t *testing.T) {
 			},
 		}
 
-		service.BaseTemplateRegistry.AddBase(baseTemplate)
+		testRegistries.AddBaseTemplate(baseTemplate)
 
 		testUserID := test_util.GetUniqueUserID()
 		testIdentity := test_util.GenerateIdentityStructFromTemplate(
@@ -89,7 +102,7 @@ func TestForkBaseTemplate(t *testing.T) {
 		)
 
 		// Fork the base template
-		forkedTemplate, status, err := service.ForkBaseTemplate("fork-service-test", testIdentity)
+		forkedTemplate, status, err := service.ForkBaseTemplate(testRegistries, "fork-service-test", testIdentity)
 
 		// Verify success
 		assert.NoError(t, err, "ForkBaseTemplate should not return an error")
@@ -115,7 +128,7 @@ func TestForkBaseTemplate(t *testing.T) {
 
 	t.Run("should return 404 for non-existent base template", func(t *testing.T) {
 		// Reset registry to ensure no templates exist
-		service.BaseTemplateRegistry = api.BaseWidgetDashboardTemplateRegistry{}
+		testRegistries.BaseTemplates.Replace(nil)
 
 		testUserID := test_util.GetUniqueUserID()
 		testIdentity := test_util.GenerateIdentityStructFromTemplate(
@@ -125,7 +138,7 @@ func TestForkBaseTemplate(t *testing.T) {
 		)
 
 		// Try to fork non-existent template
-		forkedTemplate, status, err := service.ForkBaseTemplate("non-existent-template", testIdentity)
+		forkedTemplate, status, err := service.ForkBaseTemplate(testRegistries, "non-existent-template", testIdentity)
 
 		// Verify error response
 		assert.Error(t, err, "ForkBaseTemplate should return an error for non-existent template")
@@ -137,7 +150,7 @@ func TestForkBaseTemplate(t *testing.T) {
 
 	t.Run("should create separate templates for different users", func(t *testing.T) {
 		// Reset registry and add a base template
-		service.BaseTemplateRegistry = api.BaseWidgetDashboardTemplateRegistry{}
+		testRegistries.BaseTemplates.Replace(nil)
 
 		baseTemplate := api.BaseWidgetDashboardTemplate{
 			Name:        "shared-fork-test",
@@ -150,7 +163,7 @@ func TestForkBaseTemplate(t *testing.T) {
 			},
 		}
 
-		service.BaseTemplateRegistry.AddBase(baseTemplate)
+		testRegistries.AddBaseTemplate(baseTemplate)
 
 		user1ID := test_util.GetUniqueUserID()
 		user2ID := test_util.GetUniqueUserID()
@@ -166,12 +179,12 @@ func TestForkBaseTemplate(t *testing.T) {
 		)
 
 		// Fork template as first user
-		template1, status1, err1 := service.ForkBaseTemplate("shared-fork-test", identity1)
+		template1, status1, err1 := service.ForkBaseTemplate(testRegistries, "shared-fork-test", identity1)
 		assert.NoError(t, err1, "First fork should succeed")
 		assert.Equal(t, http.StatusOK, status1, "First fork status should be 200")
 
 		// Fork same template as second user
-		template2, status2, err2 := service.ForkBaseTemplate("shared-fork-test", identity2)
+		template2, status2, err2 := service.ForkBaseTemplate(testRegistries, "shared-fork-test", identity2)
 		assert.NoError(t, err2, "Second fork should succeed")
 		assert.Equal(t, http.StatusOK, status2, "Second fork status should be 200")
 
@@ -184,7 +197,7 @@ func TestForkBaseTemplate(t *testing.T) {
 
 	t.Run("should preserve complex template configuration", func(t *testing.T) {
 		// Reset registry and add a complex base template
-		service.BaseTemplateRegistry = api.BaseWidgetDashboardTemplateRegistry{}
+		testRegistries.BaseTemplates.Replace(nil)
 
 		complexWidgets := []api.WidgetItem{
 			{
@@ -220,7 +233,7 @@ func TestForkBaseTemplate(t *testing.T) {
 			},
 		}
 
-		service.BaseTemplateRegistry.AddBase(baseTemplate)
+		testRegistries.AddBaseTemplate(baseTemplate)
 
 		testUserID := test_util.GetUniqueUserID()
 		testIdentity := test_util.GenerateIdentityStructFromTemplate(
@@ -230,7 +243,7 @@ func TestForkBaseTemplate(t *testing.T) {
 		)
 
 		// Fork the complex template
-		forkedTemplate, status, err := service.ForkBaseTemplate("complex-fork-test", testIdentity)
+		forkedTemplate, status, err := service.ForkBaseTemplate(testRegistries, "complex-fork-test", testIdentity)
 
 		assert.NoError(t, err, "Complex fork should succeed")
 		assert.Equal(t, http.StatusOK, status, "Status should be 200")
@@ -279,7 +292,7 @@ func TestGetUserTemplates(t *testing.T) {
 
 		// Test filtering by dashboard-type-1
 		params := api.GetWidgetLayoutParams{DashboardType: stringPtr("dashboard-type-1")}
-		templates, status, err := service.GetUserTemplates(testIdentity, params)
+		templates, _, status, err := service.GetUserTemplates(testRegistries, testIdentity, params)
 
 		assert.NoError(t, err)
 		assert.Equal(t, http.StatusOK, status)
@@ -301,7 +314,7 @@ func TestGetUserTemplates(t *testing.T) {
 		)
 
 		// Reset and add a base template to the registry
-		service.BaseTemplateRegistry = api.BaseWidgetDashboardTemplateRegistry{}
+		testRegistries.BaseTemplates.Replace(nil)
 		baseTemplate := api.BaseWidgetDashboardTemplate{
 			Name:        "auto-create-test",
 			DisplayName: "Auto Create Test",
@@ -312,11 +325,11 @@ func TestGetUserTemplates(t *testing.T) {
 				Xl: datatypes.NewJSONType([]api.WidgetItem{}),
 			},
 		}
-		service.BaseTemplateRegistry.AddBase(baseTemplate)
+		testRegistries.AddBaseTemplate(baseTemplate)
 
 		// Test filtering by a dashboard type that exists as base template but user has no templates
 		params := api.GetWidgetLayoutParams{DashboardType: stringPtr("auto-create-test")}
-		templates, status, err := service.GetUserTemplates(testIdentity, params)
+		templates, _, status, err := service.GetUserTemplates(testRegistries, testIdentity, params)
 
 		assert.NoError(t, err, "Should not return error when auto-creating from base template")
 		assert.Equal(t, http.StatusNotFound, status, "Should return 404 status (but with created template)")
@@ -345,7 +358,7 @@ func TestGetUserTemplates(t *testing.T) {
 
 		// Test filtering by non-existent dashboard type (no base template exists)
 		params := api.GetWidgetLayoutParams{DashboardType: stringPtr("non-existent-dashboard")}
-		templates, status, err := service.GetUserTemplates(testIdentity, params)
+		templates, _, status, err := service.GetUserTemplates(testRegistries, testIdentity, params)
 
 		assert.Error(t, err, "Should return error when base template doesn't exist")
 		assert.Equal(t, http.StatusNotFound, status, "Should return 404 when base template not found")
@@ -375,7 +388,7 @@ func TestGetUserTemplates(t *testing.T) {
 
 		// Test without filtering (DashboardType is nil)
 		params := api.GetWidgetLayoutParams{DashboardType: nil}
-		result, status, err := service.GetUserTemplates(testIdentity, params)
+		result, _, status, err := service.GetUserTemplates(testRegistries, testIdentity, params)
 
 		assert.NoError(t, err)
 		assert.Equal(t, http.StatusOK, status)
@@ -413,7 +426,7 @@ func TestGetUserTemplates(t *testing.T) {
 
 		// Test filtering as user1 - should only get user1's template
 		params := api.GetWidgetLayoutParams{DashboardType: stringPtr("shared-dashboard-type")}
-		templates, status, err := service.GetUserTemplates(user1Identity, params)
+		templates, _, status, err := service.GetUserTemplates(testRegistries, user1Identity, params)
 
 		assert.NoError(t, err)
 		assert.Equal(t, http.StatusOK, status)
@@ -422,6 +435,135 @@ func TestGetUserTemplates(t *testing.T) {
 		assert.Equal(t, user1ID, templates[0].UserId)
 		assert.Equal(t, "User 1 Dashboard", templates[0].TemplateBase.DisplayName)
 	})
+
+	t.Run("should paginate and sort templates", func(t *testing.T) {
+		testUserID := test_util.GetUniqueUserID()
+		testIdentity := test_util.GenerateIdentityStructFromTemplate(
+			xrhidgen.Identity{},
+			xrhidgen.User{UserID: stringPtr(testUserID)},
+			xrhidgen.Entitlements{},
+		)
+		for _, name := range []string{"Charlie", "alpha", "Bravo", "delta"} {
+			template := createTestTemplate(testUserID, "paginated-dashboard", "Paginated Dashboard")
+			template.DashboardName = name
+			require.NoError(t, database.DB.Create(&template).Error)
+		}
+
+		sortBy, order := api.SortByDashboardName, api.OrderDesc
+		params := api.GetWidgetLayoutParams{SortBy: &sortBy, Order: &order, Limit: test_util.IntPTR(2), Offset: test_util.IntPTR(1)}
+		templates, meta, status, err := service.GetUserTemplates(testRegistries, testIdentity, params)
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+		require.Len(t, templates, 2)
+		assert.Equal(t, "alpha", templates[0].DashboardName)
+		assert.Equal(t, "Charlie", templates[1].DashboardName)
+		assert.Equal(t, 2, meta.Count)
+		assert.Equal(t, 4, *meta.Total)
+		assert.Equal(t, 2, *meta.Limit)
+		assert.Equal(t, 1, *meta.Offset)
+
+		templates, meta, _, err = service.GetUserTemplates(testRegistries, testIdentity, api.GetWidgetLayoutParams{})
+		require.NoError(t, err)
+		assert.Len(t, templates, 4)
+		assert.Equal(t, "Charlie", templates[0].DashboardName, "Templates should be sorted by creation by default")
+		assert.Equal(t, service.DefaultTemplateListLimit, *meta.Limit)
+	})
+
+	t.Run("should filter templates by default status and dashboard name", func(t *testing.T) {
+		testUserID := test_util.GetUniqueUserID()
+		testIdentity := test_util.GenerateIdentityStructFromTemplate(
+			xrhidgen.Identity{},
+			xrhidgen.User{UserID: stringPtr(testUserID)},
+			xrhidgen.Entitlements{},
+		)
+		for i, name := range []string{"Team Overview", "My overview", "100% Coverage"} {
+			template := createTestTemplate(testUserID, "filtered-dashboard", "Filtered Dashboard")
+			template.DashboardName = name
+			template.Default = i == 0
+			require.NoError(t, database.DB.Create(&template).Error)
+		}
+
+		templates, meta, _, err := service.GetUserTemplates(testRegistries, testIdentity, api.GetWidgetLayoutParams{DashboardName: stringPtr("OVERVIEW")})
+		require.NoError(t, err)
+		assert.Len(t, templates, 2)
+		assert.Equal(t, 2, *meta.Total)
+
+		templates, _, _, err = service.GetUserTemplates(testRegistries, testIdentity, api.GetWidgetLayoutParams{DashboardName: stringPtr("overview"), Default: test_util.BoolPTR(false)})
+		require.NoError(t, err)
+		require.Len(t, templates, 1)
+		assert.Equal(t, "My overview", templates[0].DashboardName)
+
+		templates, _, _, err = service.GetUserTemplates(testRegistries, testIdentity, api.GetWidgetLayoutParams{DashboardName: stringPtr("0%")})
+		require.NoError(t, err)
+		require.Len(t, templates, 1, "Wildcards in the dashboard name should be matched literally")
+		assert.Equal(t, "100% Coverage", templates[0].DashboardName)
+	})
+
+	t.Run("should not fork the base template when the page is empty", func(t *testing.T) {
+		testUserID := test_util.GetUniqueUserID()
+		testIdentity := test_util.GenerateIdentityStructFromTemplate(
+			xrhidgen.Identity{},
+			xrhidgen.User{UserID: stringPtr(testUserID)},
+			xrhidgen.Entitlements{},
+		)
+		template := createTestTemplate(testUserID, "paged-out-dashboard", "Paged Out Dashboard")
+		require.NoError(t, database.DB.Create(&template).Error)
+
+		params := api.GetWidgetLayoutParams{DashboardType: stringPtr("paged-out-dashboard"), Offset: test_util.IntPTR(5)}
+		templates, meta, status, err := service.GetUserTemplates(testRegistries, testIdentity, params)
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Empty(t, templates)
+		assert.Equal(t, 1, *meta.Total)
+	})
+
+	t.Run("should return summaries of the templates", func(t *testing.T) {
+		testUserID := test_util.GetUniqueUserID()
+		testIdentity := test_util.GenerateIdentityStructFromTemplate(
+			xrhidgen.Identity{},
+			xrhidgen.User{UserID: stringPtr(testUserID)},
+			xrhidgen.Entitlements{},
+		)
+		template := createTestTemplate(testUserID, "summary-dashboard", "Summary Dashboard")
+		template.DashboardName = "Summarized"
+		template.Default = true
+		require.NoError(t, database.DB.Create(&template).Error)
+
+		summaries, meta, status, err := service.GetUserTemplateSummaries(testRegistries, testIdentity, api.GetWidgetLayoutParams{DashboardType: stringPtr("summary-dashboard")})
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, 1, *meta.Total)
+		require.Len(t, summaries, 1)
+		assert.Equal(t, template.ID, summaries[0].ID)
+		assert.Equal(t, "Summarized", summaries[0].DashboardName)
+		assert.Equal(t, template.TemplateBase, summaries[0].TemplateBase)
+		assert.True(t, summaries[0].Default)
+		assert.Equal(t, template.Version, summaries[0].Version)
+		assert.False(t, summaries[0].UpdatedAt.IsZero())
+	})
+
+	t.Run("should return 400 for invalid pagination and sorting parameters", func(t *testing.T) {
+		testIdentity := test_util.GenerateIdentityStructFromTemplate(
+			xrhidgen.Identity{},
+			xrhidgen.User{UserID: stringPtr(test_util.GetUniqueUserID())},
+			xrhidgen.Entitlements{},
+		)
+		sortBy, order := api.GetWidgetLayoutParamsSortBy("userId"), api.GetWidgetLayoutParamsOrder("up")
+		for _, params := range []api.GetWidgetLayoutParams{
+			{Limit: test_util.IntPTR(0)},
+			{Limit: test_util.IntPTR(service.MaxTemplateListLimit + 1)},
+			{Offset: test_util.IntPTR(-1)},
+			{SortBy: &sortBy},
+			{Order: &order},
+		} {
+			_, _, status, err := service.GetUserTemplates(testRegistries, testIdentity, params)
+			assert.Error(t, err)
+			assert.Equal(t, http.StatusBadRequest, status)
+		}
+	})
 }
 
 func TestChangeDefaultTemplate(t *testing.T) {
@@ -443,7 +585,7 @@ func TestChangeDefaultTemplate(t *testing.T) {
 		require.NoError(t, database.DB.Create(&templateB).Error)
 
 		// Set template B as default
-		result, status, err := service.ChangeDefaultTemplate(int64(templateB.ID), testIdentity)
+		result, status, err := service.ChangeDefaultTemplate(int64(templateB.ID), testIdentity, nil)
 
 		assert.NoError(t, err)
 		assert.Equal(t, http.StatusOK, status)
@@ -479,7 +621,7 @@ func TestChangeDefaultTemplate(t *testing.T) {
 		require.NoError(t, database.DB.Create(&targetTemplate).Error)
 
 		// Set target as default
-		_, status, err := service.ChangeDefaultTemplate(int64(targetTemplate.ID), testIdentity)
+		_, status, err := service.ChangeDefaultTemplate(int64(targetTemplate.ID), testIdentity, nil)
 
 		assert.NoError(t, err)
 		assert.Equal(t, http.StatusOK, status)
@@ -511,7 +653,7 @@ func TestChangeDefaultTemplate(t *testing.T) {
 		require.NoError(t, database.DB.Create(&user1Template).Error)
 
 		// user1 changes default on same base name
-		_, status, err := service.ChangeDefaultTemplate(int64(user1Template.ID), user1Identity)
+		_, status, err := service.ChangeDefaultTemplate(int64(user1Template.ID), user1Identity, nil)
 		assert.NoError(t, err)
 		assert.Equal(t, http.StatusOK, status)
 
@@ -529,7 +671,7 @@ func TestChangeDefaultTemplate(t *testing.T) {
 			xrhidgen.Entitlements{},
 		)
 
-		_, status, err := service.ChangeDefaultTemplate(int64(test_util.NonExistentID), testIdentity)
+		_, status, err := service.ChangeDefaultTemplate(int64(test_util.NonExistentID), testIdentity, nil)
 
 		assert.Error(t, err)
 		assert.Equal(t, http.StatusNotFound, status)
@@ -547,7 +689,7 @@ func TestChangeDefaultTemplate(t *testing.T) {
 		template := createTestTemplate(ownerUserID, "auth-test", "Auth Test")
 		require.NoError(t, database.DB.Create(&template).Error)
 
-		_, status, err := service.ChangeDefaultTemplate(int64(template.ID), otherIdentity)
+		_, status, err := service.ChangeDefaultTemplate(int64(template.ID), otherIdentity, nil)
 
 		assert.Error(t, err)
 		assert.Equal(t, http.StatusForbidden, status)
@@ -628,7 +770,7 @@ func TestUpdateDashboardTemplate(t *testing.T) {
 			Xl: datatypes.NewJSONType([]api.WidgetItem{}),
 		}
 
-		result, status, err := service.UpdateDashboardTemplate(int64(template.ID), newConfig, testIdentity)
+		result, status, err := service.UpdateDashboardTemplate(context.Background(), testRegistries, int64(template.ID), newConfig, testIdentity, nil, false, nil)
 
 		assert.NoError(t, err)
 		assert.Equal(t, http.StatusOK, status)
@@ -654,7 +796,7 @@ func TestUpdateDashboardTemplate(t *testing.T) {
 			Xl: datatypes.NewJSONType([]api.WidgetItem{}),
 		}
 
-		_, status, err := service.UpdateDashboardTemplate(int64(test_util.NonExistentID), newConfig, testIdentity)
+		_, status, err := service.UpdateDashboardTemplate(context.Background(), testRegistries, int64(test_util.NonExistentID), newConfig, testIdentity, nil, false, nil)
 
 		assert.Error(t, err)
 		assert.Equal(t, http.StatusNotFound, status)
@@ -679,7 +821,7 @@ func TestUpdateDashboardTemplate(t *testing.T) {
 			Xl: datatypes.NewJSONType([]api.WidgetItem{}),
 		}
 
-		_, status, err := service.UpdateDashboardTemplate(int64(template.ID), newConfig, otherIdentity)
+		_, status, err := service.UpdateDashboardTemplate(context.Background(), testRegistries, int64(template.ID), newConfig, otherIdentity, nil, false, nil)
 
 		assert.Error(t, err)
 		assert.Equal(t, http.StatusForbidden, status)
@@ -705,7 +847,7 @@ func TestUpdateDashboardTemplate(t *testing.T) {
 			Xl: datatypes.NewJSONType([]api.WidgetItem{}),
 		}
 
-		_, _, err := service.UpdateDashboardTemplate(int64(template.ID), newConfig, testIdentity)
+		_, _, err := service.UpdateDashboardTemplate(context.Background(), testRegistries, int64(template.ID), newConfig, testIdentity, nil, false, nil)
 		require.NoError(t, err)
 
 		var dbTemplate api.DashboardTemplate
@@ -714,6 +856,123 @@ func TestUpdateDashboardTemplate(t *testing.T) {
 		require.Len(t, smWidgets, 1)
 		assert.Equal(t, "persisted-widget", smWidgets[0].WidgetType)
 	})
+
+	t.Run("should validate widgets against the widget mapping registry", func(t *testing.T) {
+		testRegistries.WidgetMappings.Replace(nil)
+		t.Cleanup(func() { testRegistries.WidgetMappings.Replace(nil) })
+		testRegistries.AddWidgetMapping(api.WidgetModuleFederationMetadata{
+			Scope:  "landing",
+			Module: "./RhelWidget",
+			Config: api.WidgetConfiguration{Title: "RHEL"},
+			Defaults: api.WidgetBaseDimensions{
+				Width:     test_util.IntPTR(1),
+				Height:    test_util.IntPTR(2),
+				MaxHeight: test_util.IntPTR(4),
+				MinHeight: test_util.IntPTR(1),
+			},
+		})
+
+		testUserID := test_util.GetUniqueUserID()
+		testIdentity := test_util.GenerateIdentityStructFromTemplate(
+			xrhidgen.Identity{},
+			xrhidgen.User{UserID: stringPtr(testUserID)},
+			xrhidgen.Entitlements{},
+		)
+
+		template := test_util.MockDashboardTemplateWithSpecificUser(testUserID)
+		require.NoError(t, database.DB.Create(&template).Error)
+
+		items := datatypes.NewJSONType([]api.WidgetItem{
+			{Width: 1, Height: 2, WidgetType: "landing-./RhelWidget", X: test_util.IntPTR(0), Y: test_util.IntPTR(0)},
+			{Width: 1, Height: 2, WidgetType: "landing-./RemovedWidget", X: test_util.IntPTR(0), Y: test_util.IntPTR(2)},
+		})
+		newConfig := api.DashboardTemplateConfig{Sm: items, Md: items, Lg: items, Xl: items}
+
+		_, status, err := service.UpdateDashboardTemplate(context.Background(), testRegistries, int64(template.ID), newConfig, testIdentity, nil, false, nil)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusBadRequest, status, "Strict mode should be the default")
+
+		lenient := api.ValidationModeLenient
+		result, status, err := service.UpdateDashboardTemplate(context.Background(), testRegistries, int64(template.ID), newConfig, testIdentity, &lenient, false, nil)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Len(t, result.Warnings, 4)
+		assert.Len(t, result.TemplateConfig.Xl.Data(), 1)
+
+		var dbTemplate api.DashboardTemplate
+		require.NoError(t, database.DB.First(&dbTemplate, template.ID).Error)
+		assert.Len(t, dbTemplate.TemplateConfig.Sm.Data(), 1, "Unknown widgets should not be persisted")
+		assert.Empty(t, dbTemplate.Warnings)
+	})
+
+	t.Run("should keep widgets whose feature flag is disabled", func(t *testing.T) {
+		registerPermissionTestMappings(t)
+		testRegistries.AddWidgetMapping(api.WidgetModuleFederationMetadata{
+			Scope:       "landing",
+			Module:      "./PreviewWidget",
+			FeatureFlag: stringPtr("landing.preview-widget"),
+			Config:      api.WidgetConfiguration{Title: "Preview"},
+		})
+		previous := testRegistries.FlagProvider
+		t.Cleanup(func() { testRegistries.FlagProvider = previous })
+		testRegistries.FlagProvider = staticFlags{"landing.preview-widget": false}
+
+		testUserID := test_util.GetUniqueUserID()
+		testIdentity := test_util.GenerateIdentityStructFromTemplate(
+			xrhidgen.Identity{},
+			xrhidgen.User{UserID: stringPtr(testUserID)},
+			xrhidgen.Entitlements{},
+		)
+		items := datatypes.NewJSONType([]api.WidgetItem{
+			{Width: 1, Height: 2, WidgetType: "landing-./PreviewWidget", X: test_util.IntPTR(0), Y: test_util.IntPTR(0)},
+			{Width: 1, Height: 2, WidgetType: "landing-./PublicWidget", X: test_util.IntPTR(0), Y: test_util.IntPTR(2)},
+		})
+		template := test_util.MockDashboardTemplateWithSpecificUser(testUserID)
+		template.TemplateConfig = api.DashboardTemplateConfig{Sm: items, Md: items, Lg: items, Xl: items}
+		require.NoError(t, database.DB.Create(&template).Error)
+
+		loaded := service.FilterPermittedTemplateWidgets(context.Background(), testRegistries, template, testIdentity)
+		require.Len(t, loaded.TemplateConfig.Sm.Data(), 1)
+		_, status, err := service.UpdateDashboardTemplate(context.Background(), testRegistries, int64(template.ID), loaded.TemplateConfig, testIdentity, nil, false, nil)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+
+		testRegistries.FlagProvider = staticFlags{"landing.preview-widget": true}
+		var dbTemplate api.DashboardTemplate
+		require.NoError(t, database.DB.First(&dbTemplate, template.ID).Error)
+		shown := service.FilterPermittedTemplateWidgets(context.Background(), testRegistries, dbTemplate, testIdentity)
+		for _, layout := range [][]api.WidgetItem{shown.TemplateConfig.Sm.Data(), shown.TemplateConfig.Md.Data(), shown.TemplateConfig.Lg.Data(), shown.TemplateConfig.Xl.Data()} {
+			assert.ElementsMatch(t, items.Data(), layout, "The widget should be shown again once its flag is enabled")
+		}
+	})
+
+	t.Run("should compact the layouts after restoring the widgets hidden from the user", func(t *testing.T) {
+		registerPermissionTestMappings(t)
+		testUserID := test_util.GetUniqueUserID()
+		isOrgAdmin := false
+		testIdentity := test_util.GenerateIdentityStructFromTemplate(
+			xrhidgen.Identity{},
+			xrhidgen.User{UserID: stringPtr(testUserID), IsOrgAdmin: &isOrgAdmin},
+			xrhidgen.Entitlements{},
+		)
+		items := datatypes.NewJSONType([]api.WidgetItem{
+			{Width: 1, Height: 2, WidgetType: "landing-./AdminWidget", X: test_util.IntPTR(0), Y: test_util.IntPTR(0)},
+			{Width: 1, Height: 2, WidgetType: "landing-./PublicWidget", X: test_util.IntPTR(0), Y: test_util.IntPTR(2)},
+		})
+		template := test_util.MockDashboardTemplateWithSpecificUser(testUserID)
+		template.TemplateConfig = api.DashboardTemplateConfig{Sm: items, Md: items, Lg: items, Xl: items}
+		require.NoError(t, database.DB.Create(&template).Error)
+
+		edited := datatypes.NewJSONType([]api.WidgetItem{
+			{Width: 1, Height: 2, WidgetType: "landing-./PublicWidget", X: test_util.IntPTR(0), Y: test_util.IntPTR(6)},
+		})
+		result, status, err := service.UpdateDashboardTemplate(context.Background(), testRegistries, int64(template.ID), api.DashboardTemplateConfig{Sm: edited, Md: edited, Lg: edited, Xl: edited}, testIdentity, nil, true, nil)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+		for _, layout := range [][]api.WidgetItem{result.TemplateConfig.Sm.Data(), result.TemplateConfig.Md.Data(), result.TemplateConfig.Lg.Data(), result.TemplateConfig.Xl.Data()} {
+			assert.ElementsMatch(t, items.Data(), layout, "The hidden widget should keep its position and the edited widget move up below it")
+		}
+	})
 }
 
 func TestDeleteDashboardTemplate(t *testing.T) {
@@ -733,10 +992,14 @@ func TestDeleteDashboardTemplate(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Equal(t, http.StatusNoContent, status)
 
-		// Verify template is permanently deleted
+		// Verify template is soft deleted
 		var dbTemplate api.DashboardTemplate
 		err = database.DB.First(&dbTemplate, template.ID).Error
-		assert.ErrorIs(t, err, gorm.ErrRecordNotFound, "Template should be permanently deleted")
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound, "Template should not be found after deletion")
+
+		err = database.DB.Unscoped().First(&dbTemplate, template.ID).Error
+		require.NoError(t, err, "Template should be kept in the trash")
+		assert.True(t, dbTemplate.DeletedAt.Valid, "Template should have a deletion time")
 	})
 
 	t.Run("should return 404 for non-existent template", func(t *testing.T) {
@@ -781,16 +1044,19 @@ func TestCopyDashboardTemplate(t *testing.T) {
 	t.Run("should copy template for requesting user", func(t *testing.T) {
 		ownerID := test_util.GetUniqueUserID()
 		copierID := test_util.GetUniqueUserID()
+		orgID := "org-" + ownerID
 		copierIdentity := test_util.GenerateIdentityStructFromTemplate(
-			xrhidgen.Identity{},
+			xrhidgen.Identity{OrgID: stringPtr(orgID)},
 			xrhidgen.User{UserID: stringPtr(copierID)},
 			xrhidgen.Entitlements{},
 		)
 
 		template := test_util.MockDashboardTemplateWithSpecificUser(ownerID)
+		template.OrgId = orgID
+		template.Visibility = api.VisibilityOrg
 		require.NoError(t, database.DB.Create(&template).Error)
 
-		result, status, err := service.CopyDashboardTemplate(int64(template.ID), copierIdentity, nil)
+		result, status, err := service.CopyDashboardTemplate(testRegistries, int64(template.ID), copierIdentity, nil)
 
 		assert.NoError(t, err)
 		assert.Equal(t, http.StatusOK, status)
@@ -799,6 +1065,21 @@ func TestCopyDashboardTemplate(t *testing.T) {
 		assert.Equal(t, template.TemplateBase.Name, result.TemplateBase.Name)
 	})
 
+	t.Run("should return 403 for templates not shared with the user", func(t *testing.T) {
+		template := test_util.MockDashboardTemplateWithSpecificUser(test_util.GetUniqueUserID())
+		require.NoError(t, database.DB.Create(&template).Error)
+		copierIdentity := test_util.GenerateIdentityStructFromTemplate(
+			xrhidgen.Identity{},
+			xrhidgen.User{UserID: stringPtr(test_util.GetUniqueUserID())},
+			xrhidgen.Entitlements{},
+		)
+
+		_, status, err := service.CopyDashboardTemplate(testRegistries, int64(template.ID), copierIdentity, nil)
+
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusForbidden, status)
+	})
+
 	t.Run("should use custom dashboard name when provided", func(t *testing.T) {
 		testUserID := test_util.GetUniqueUserID()
 		testIdentity := test_util.GenerateIdentityStructFromTemplate(
@@ -811,7 +1092,7 @@ func TestCopyDashboardTemplate(t *testing.T) {
 		require.NoError(t, database.DB.Create(&template).Error)
 
 		customName := "My Custom Copy"
-		result, status, err := service.CopyDashboardTemplate(int64(template.ID), testIdentity, &customName)
+		result, status, err := service.CopyDashboardTemplate(testRegistries, int64(template.ID), testIdentity, &customName)
 
 		assert.NoError(t, err)
 		assert.Equal(t, http.StatusOK, status)
@@ -826,7 +1107,7 @@ func TestCopyDashboardTemplate(t *testing.T) {
 			xrhidgen.Entitlements{},
 		)
 
-		_, status, err := service.CopyDashboardTemplate(int64(test_util.NonExistentID), testIdentity, nil)
+		_, status, err := service.CopyDashboardTemplate(testRegistries, int64(test_util.NonExistentID), testIdentity, nil)
 
 		assert.Error(t, err)
 		assert.Equal(t, http.StatusNotFound, status)
@@ -845,7 +1126,7 @@ func TestCopyDashboardTemplate(t *testing.T) {
 		require.NoError(t, database.DB.Create(&template).Error)
 
 		emptyName := ""
-		result, status, err := service.CopyDashboardTemplate(int64(template.ID), testIdentity, &emptyName)
+		result, status, err := service.CopyDashboardTemplate(testRegistries, int64(template.ID), testIdentity, &emptyName)
 
 		assert.NoError(t, err)
 		assert.Equal(t, http.StatusOK, status)
@@ -856,13 +1137,13 @@ func TestCopyDashboardTemplate(t *testing.T) {
 }
 
 func TestResetDashboardTemplate(t *testing.T) {
-	oldRegistry := service.BaseTemplateRegistry
+	oldBases := testRegistries.BaseTemplates.All()
 	t.Cleanup(func() {
-		service.BaseTemplateRegistry = oldRegistry
+		testRegistries.BaseTemplates.Replace(oldBases)
 	})
 
 	t.Run("should reset template to base config", func(t *testing.T) {
-		service.BaseTemplateRegistry = api.BaseWidgetDashboardTemplateRegistry{}
+		testRegistries.BaseTemplates.Replace(nil)
 		baseConfig := api.DashboardTemplateConfig{
 			Sm: datatypes.NewJSONType([]api.WidgetItem{
 				{Width: 1, Height: 2, WidgetType: "base-widget", X: test_util.IntPTR(0), Y: test_util.IntPTR(0), MaxHeight: test_util.IntPTR(4), MinHeight: test_util.IntPTR(1)},
@@ -871,7 +1152,7 @@ func TestResetDashboardTemplate(t *testing.T) {
 			Lg: datatypes.NewJSONType([]api.WidgetItem{}),
 			Xl: datatypes.NewJSONType([]api.WidgetItem{}),
 		}
-		service.BaseTemplateRegistry.AddBase(api.BaseWidgetDashboardTemplate{
+		testRegistries.AddBaseTemplate(api.BaseWidgetDashboardTemplate{
 			Name:           "reset-test-base",
 			DisplayName:    "Reset Test Base",
 			TemplateConfig: baseConfig,
@@ -888,7 +1169,7 @@ func TestResetDashboardTemplate(t *testing.T) {
 		template.TemplateBase.Name = "reset-test-base"
 		require.NoError(t, database.DB.Create(&template).Error)
 
-		result, status, err := service.ResetDashboardTemplate(int64(template.ID), testIdentity)
+		result, status, err := service.ResetDashboardTemplate(testRegistries, int64(template.ID), testIdentity, nil)
 
 		assert.NoError(t, err)
 		assert.Equal(t, http.StatusOK, status)
@@ -906,7 +1187,7 @@ func TestResetDashboardTemplate(t *testing.T) {
 			xrhidgen.Entitlements{},
 		)
 
-		_, status, err := service.ResetDashboardTemplate(int64(test_util.NonExistentID), testIdentity)
+		_, status, err := service.ResetDashboardTemplate(testRegistries, int64(test_util.NonExistentID), testIdentity, nil)
 
 		assert.Error(t, err)
 		assert.Equal(t, http.StatusNotFound, status)
@@ -924,14 +1205,14 @@ func TestResetDashboardTemplate(t *testing.T) {
 		template := test_util.MockDashboardTemplateWithSpecificUser(ownerID)
 		require.NoError(t, database.DB.Create(&template).Error)
 
-		_, status, err := service.ResetDashboardTemplate(int64(template.ID), otherIdentity)
+		_, status, err := service.ResetDashboardTemplate(testRegistries, int64(template.ID), otherIdentity, nil)
 
 		assert.Error(t, err)
 		assert.Equal(t, http.StatusForbidden, status)
 	})
 
 	t.Run("should return 404 when base template not found in registry", func(t *testing.T) {
-		service.BaseTemplateRegistry = api.BaseWidgetDashboardTemplateRegistry{}
+		testRegistries.BaseTemplates.Replace(nil)
 
 		testUserID := test_util.GetUniqueUserID()
 		testIdentity := test_util.GenerateIdentityStructFromTemplate(
@@ -944,7 +1225,7 @@ func TestResetDashboardTemplate(t *testing.T) {
 		template.TemplateBase.Name = "non-existent-base"
 		require.NoError(t, database.DB.Create(&template).Error)
 
-		_, status, err := service.ResetDashboardTemplate(int64(template.ID), testIdentity)
+		_, status, err := service.ResetDashboardTemplate(testRegistries, int64(template.ID), testIdentity, nil)
 
 		assert.Error(t, err)
 		assert.Equal(t, http.StatusNotFound, status)
@@ -970,6 +1251,7 @@ func TestExportWidgetDashboardTemplate(t *testing.T) {
 
 		assert.NoError(t, err)
 		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, api.ExportSchemaVersion, result.SchemaVersion)
 		assert.Equal(t, "export-test", result.TemplateBase.Name)
 		assert.Equal(t, "Export Test", result.TemplateBase.DisplayName)
 		assert.NotNil(t, result.TemplateConfig.Sm.Data(), "Export should include config")
@@ -1021,7 +1303,7 @@ func TestRenameDashboardTemplate(t *testing.T) {
 		template.DashboardName = "Old Name"
 		require.NoError(t, database.DB.Create(&template).Error)
 
-		result, status, err := service.RenameDashboardTemplate(int64(template.ID), "New Name", testIdentity)
+		result, status, err := service.RenameDashboardTemplate(int64(template.ID), "New Name", testIdentity, nil)
 
 		assert.NoError(t, err)
 		assert.Equal(t, http.StatusOK, status)
@@ -1041,7 +1323,7 @@ func TestRenameDashboardTemplate(t *testing.T) {
 			xrhidgen.Entitlements{},
 		)
 
-		_, status, err := service.RenameDashboardTemplate(int64(test_util.NonExistentID), "New Name", testIdentity)
+		_, status, err := service.RenameDashboardTemplate(int64(test_util.NonExistentID), "New Name", testIdentity, nil)
 
 		assert.Error(t, err)
 		assert.Equal(t, http.StatusNotFound, status)
@@ -1059,7 +1341,7 @@ func TestRenameDashboardTemplate(t *testing.T) {
 		template := test_util.MockDashboardTemplateWithSpecificUser(ownerID)
 		require.NoError(t, database.DB.Create(&template).Error)
 
-		_, status, err := service.RenameDashboardTemplate(int64(template.ID), "Unauthorized Name", otherIdentity)
+		_, status, err := service.RenameDashboardTemplate(int64(template.ID), "Unauthorized Name", otherIdentity, nil)
 
 		assert.Error(t, err)
 		assert.Equal(t, http.StatusForbidden, status)
@@ -1097,7 +1379,7 @@ func TestImportDashboardTemplate(t *testing.T) {
 			},
 		}
 
-		result, status, err := service.ImportDashboardTemplate(importData, testIdentity)
+		result, status, err := service.ImportDashboardTemplate(testRegistries, importData, testIdentity, nil, false)
 
 		assert.NoError(t, err)
 		assert.Equal(t, http.StatusOK, status)
@@ -1134,7 +1416,7 @@ func TestImportDashboardTemplate(t *testing.T) {
 			},
 		}
 
-		_, status, err := service.ImportDashboardTemplate(importData, testIdentity)
+		_, status, err := service.ImportDashboardTemplate(testRegistries, importData, testIdentity, nil, false)
 
 		assert.Error(t, err)
 		assert.Equal(t, http.StatusBadRequest, status)
@@ -1163,15 +1445,331 @@ func TestImportDashboardTemplate(t *testing.T) {
 			},
 		}
 
-		_, status, err := service.ImportDashboardTemplate(importData, testIdentity)
+		_, status, err := service.ImportDashboardTemplate(testRegistries, importData, testIdentity, nil, false)
 
 		assert.Error(t, err)
 		assert.Equal(t, http.StatusBadRequest, status)
 		assert.Contains(t, err.Error(), "displayName is required")
 	})
+
+	t.Run("should derive missing breakpoints of the imported template", func(t *testing.T) {
+		testUserID := test_util.GetUniqueUserID()
+		testIdentity := test_util.GenerateIdentityStructFromTemplate(
+			xrhidgen.Identity{},
+			xrhidgen.User{UserID: stringPtr(testUserID)},
+			xrhidgen.Entitlements{},
+		)
+
+		importData := api.ImportWidgetLayoutJSONRequestBody{
+			DashboardName: "Single Breakpoint Import",
+			TemplateBase: api.DashboardTemplateBase{
+				Name:        "import-reflow-test",
+				DisplayName: "Import Reflow Test",
+			},
+			TemplateConfig: api.DashboardTemplateConfig{
+				Xl: datatypes.NewJSONType([]api.WidgetItem{
+					{Width: 2, Height: 2, WidgetType: "widget1", X: test_util.IntPTR(0), Y: test_util.IntPTR(0)},
+					{Width: 2, Height: 2, WidgetType: "widget2", X: test_util.IntPTR(2), Y: test_util.IntPTR(0)},
+				}),
+			},
+		}
+
+		result, status, err := service.ImportDashboardTemplate(testRegistries, importData, testIdentity, nil, false)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Len(t, result.TemplateConfig.Sm.Data(), 2)
+		assert.Len(t, result.TemplateConfig.Md.Data(), 2)
+		assert.Len(t, result.TemplateConfig.Lg.Data(), 2)
+
+		var dbTemplate api.DashboardTemplate
+		require.NoError(t, database.DB.First(&dbTemplate, result.ID).Error)
+		assert.Equal(t, 2, *dbTemplate.TemplateConfig.Sm.Data()[1].Y)
+	})
+
+	t.Run("should return 400 when no breakpoint is authored", func(t *testing.T) {
+		testUserID := test_util.GetUniqueUserID()
+		testIdentity := test_util.GenerateIdentityStructFromTemplate(
+			xrhidgen.Identity{},
+			xrhidgen.User{UserID: stringPtr(testUserID)},
+			xrhidgen.Entitlements{},
+		)
+
+		importData := api.ImportWidgetLayoutJSONRequestBody{
+			DashboardName: "Empty Import",
+			TemplateBase: api.DashboardTemplateBase{
+				Name:        "import-empty-test",
+				DisplayName: "Import Empty Test",
+			},
+		}
+
+		_, status, err := service.ImportDashboardTemplate(testRegistries, importData, testIdentity, nil, false)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusBadRequest, status)
+	})
 }
 
 // Helper function for creating string pointers
 func stringPtr(s string) *string {
 	return &s
 }
+
+func TestDashboardTemplateVersioning(t *testing.T) {
+	t.Run("should start at version 1 and increment on every modification", func(t *testing.T) {
+		testUserID := test_util.GetUniqueUserID()
+		testIdentity := test_util.GenerateIdentityStructFromTemplate(
+			xrhidgen.Identity{},
+			xrhidgen.User{UserID: stringPtr(testUserID)},
+			xrhidgen.Entitlements{},
+		)
+
+		template := test_util.MockDashboardTemplateWithSpecificUser(testUserID)
+		require.NoError(t, database.DB.Create(&template).Error)
+		assert.Equal(t, uint(1), template.Version)
+
+		renamed, _, err := service.RenameDashboardTemplate(int64(template.ID), "Renamed", testIdentity, nil)
+		require.NoError(t, err)
+		assert.Equal(t, uint(2), renamed.Version)
+
+		var dbTemplate api.DashboardTemplate
+		require.NoError(t, database.DB.First(&dbTemplate, template.ID).Error)
+		assert.Equal(t, uint(2), dbTemplate.Version)
+	})
+
+	t.Run("should apply the change when If-Match matches the current version", func(t *testing.T) {
+		testUserID := test_util.GetUniqueUserID()
+		testIdentity := test_util.GenerateIdentityStructFromTemplate(
+			xrhidgen.Identity{},
+			xrhidgen.User{UserID: stringPtr(testUserID)},
+			xrhidgen.Entitlements{},
+		)
+
+		template := test_util.MockDashboardTemplateWithSpecificUser(testUserID)
+		require.NoError(t, database.DB.Create(&template).Error)
+
+		etag := template.ETag()
+		result, status, err := service.RenameDashboardTemplate(int64(template.ID), "Renamed", testIdentity, &etag)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, "Renamed", result.DashboardName)
+	})
+
+	t.Run("should return 412 when If-Match is stale", func(t *testing.T) {
+		testUserID := test_util.GetUniqueUserID()
+		testIdentity := test_util.GenerateIdentityStructFromTemplate(
+			xrhidgen.Identity{},
+			xrhidgen.User{UserID: stringPtr(testUserID)},
+			xrhidgen.Entitlements{},
+		)
+
+		template := test_util.MockDashboardTemplateWithSpecificUser(testUserID)
+		require.NoError(t, database.DB.Create(&template).Error)
+		staleETag := template.ETag()
+
+		_, _, err := service.RenameDashboardTemplate(int64(template.ID), "First Tab", testIdentity, &staleETag)
+		require.NoError(t, err)
+
+		_, status, err := service.RenameDashboardTemplate(int64(template.ID), "Second Tab", testIdentity, &staleETag)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusPreconditionFailed, status)
+		_, status, err = service.UpdateDashboardTemplate(context.Background(), testRegistries, int64(template.ID), template.TemplateConfig, testIdentity, nil, false, &staleETag)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusPreconditionFailed, status)
+		_, status, err = service.ResetDashboardTemplate(testRegistries, int64(template.ID), testIdentity, &staleETag)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusPreconditionFailed, status)
+		_, status, err = service.ChangeDefaultTemplate(int64(template.ID), testIdentity, &staleETag)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusPreconditionFailed, status)
+
+		var dbTemplate api.DashboardTemplate
+		require.NoError(t, database.DB.First(&dbTemplate, template.ID).Error)
+		assert.Equal(t, "First Tab", dbTemplate.DashboardName, "Stale write should not be applied")
+	})
+
+	t.Run("should bump the version of the template losing its default status", func(t *testing.T) {
+		testUserID := test_util.GetUniqueUserID()
+		testIdentity := test_util.GenerateIdentityStructFromTemplate(
+			xrhidgen.Identity{},
+			xrhidgen.User{UserID: stringPtr(testUserID)},
+			xrhidgen.Entitlements{},
+		)
+
+		previousDefault := test_util.MockDashboardTemplateWithSpecificUser(testUserID)
+		previousDefault.Default = true
+		require.NoError(t, database.DB.Create(&previousDefault).Error)
+		template := test_util.MockDashboardTemplateWithSpecificUser(testUserID)
+		require.NoError(t, database.DB.Create(&template).Error)
+
+		_, _, err := service.ChangeDefaultTemplate(int64(template.ID), testIdentity, nil)
+		require.NoError(t, err)
+
+		var dbTemplate api.DashboardTemplate
+		require.NoError(t, database.DB.First(&dbTemplate, previousDefault.ID).Error)
+		assert.False(t, dbTemplate.Default)
+		assert.Equal(t, previousDefault.Version+1, dbTemplate.Version)
+	})
+}
+
+func TestPatchDashboardTemplateWidgets(t *testing.T) {
+	t.Run("should apply widget operations and record a revision", func(t *testing.T) {
+		testUserID := test_util.GetUniqueUserID()
+		testIdentity := test_util.GenerateIdentityStructFromTemplate(
+			xrhidgen.Identity{},
+			xrhidgen.User{UserID: stringPtr(testUserID)},
+			xrhidgen.Entitlements{},
+		)
+
+		template := test_util.MockDashboardTemplateWithSpecificUser(testUserID)
+		template.TemplateConfig = test_util.MockTemplateConfig("widget1")
+		require.NoError(t, database.DB.Create(&template).Error)
+
+		lg := api.Lg
+		result, status, err := service.PatchDashboardTemplateWidgets(context.Background(), testRegistries, int64(template.ID), []api.WidgetLayoutOperation{
+			{Op: api.WidgetOperationResize, Breakpoint: &lg, WidgetType: stringPtr("widget1"), Width: test_util.IntPTR(3)},
+		}, testIdentity, nil, nil)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, 3, result.TemplateConfig.Lg.Data()[0].Width)
+		assert.Equal(t, 1, result.TemplateConfig.Xl.Data()[0].Width)
+
+		var dbTemplate api.DashboardTemplate
+		require.NoError(t, database.DB.First(&dbTemplate, template.ID).Error)
+		assert.Equal(t, 3, dbTemplate.TemplateConfig.Lg.Data()[0].Width)
+
+		revisions, _, err := service.GetTemplateRevisions(int64(template.ID), testIdentity)
+		require.NoError(t, err)
+		assert.Len(t, revisions, 1)
+	})
+
+	t.Run("should return 400 when the result is not a valid layout", func(t *testing.T) {
+		testUserID := test_util.GetUniqueUserID()
+		testIdentity := test_util.GenerateIdentityStructFromTemplate(
+			xrhidgen.Identity{},
+			xrhidgen.User{UserID: stringPtr(testUserID)},
+			xrhidgen.Entitlements{},
+		)
+
+		template := test_util.MockDashboardTemplateWithSpecificUser(testUserID)
+		template.TemplateConfig = test_util.MockTemplateConfig("widget1")
+		require.NoError(t, database.DB.Create(&template).Error)
+
+		sm := api.Sm
+		_, status, err := service.PatchDashboardTemplateWidgets(context.Background(), testRegistries, int64(template.ID), []api.WidgetLayoutOperation{
+			{Op: api.WidgetOperationResize, Breakpoint: &sm, WidgetType: stringPtr("widget1"), Width: test_util.IntPTR(4)},
+		}, testIdentity, nil, nil)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusBadRequest, status)
+
+		var dbTemplate api.DashboardTemplate
+		require.NoError(t, database.DB.First(&dbTemplate, template.ID).Error)
+		assert.Equal(t, 1, dbTemplate.TemplateConfig.Sm.Data()[0].Width, "Template should not be modified")
+	})
+
+	t.Run("should return 403 for unauthorized user", func(t *testing.T) {
+		template := test_util.MockDashboardTemplateWithSpecificUser(test_util.GetUniqueUserID())
+		template.TemplateConfig = test_util.MockTemplateConfig("widget1")
+		require.NoError(t, database.DB.Create(&template).Error)
+
+		otherIdentity := test_util.GenerateIdentityStructFromTemplate(
+			xrhidgen.Identity{},
+			xrhidgen.User{UserID: stringPtr(test_util.GetUniqueUserID())},
+			xrhidgen.Entitlements{},
+		)
+		_, status, err := service.PatchDashboardTemplateWidgets(context.Background(), testRegistries, int64(template.ID), []api.WidgetLayoutOperation{
+			{Op: api.WidgetOperationRemove, WidgetType: stringPtr("widget1")},
+		}, otherIdentity, nil, nil)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusForbidden, status)
+	})
+
+	t.Run("should keep widgets hidden from the user", func(t *testing.T) {
+		registerPermissionTestMappings(t)
+		testUserID := test_util.GetUniqueUserID()
+		isOrgAdmin := false
+		testIdentity := test_util.GenerateIdentityStructFromTemplate(
+			xrhidgen.Identity{},
+			xrhidgen.User{UserID: stringPtr(testUserID), IsOrgAdmin: &isOrgAdmin},
+			xrhidgen.Entitlements{},
+		)
+
+		items := datatypes.NewJSONType([]api.WidgetItem{
+			{Width: 1, Height: 2, WidgetType: "landing-./AdminWidget", X: test_util.IntPTR(0), Y: test_util.IntPTR(0)},
+			{Width: 1, Height: 2, WidgetType: "landing-./PublicWidget", X: test_util.IntPTR(0), Y: test_util.IntPTR(2)},
+		})
+		template := test_util.MockDashboardTemplateWithSpecificUser(testUserID)
+		template.TemplateConfig = api.DashboardTemplateConfig{Sm: items, Md: items, Lg: items, Xl: items}
+		require.NoError(t, database.DB.Create(&template).Error)
+
+		_, status, err := service.PatchDashboardTemplateWidgets(context.Background(), testRegistries, int64(template.ID), []api.WidgetLayoutOperation{
+			{Op: api.WidgetOperationRemove, WidgetType: stringPtr("landing-./AdminWidget")},
+		}, testIdentity, nil, nil)
+		assert.Error(t, err, "Hidden widgets should not be addressable")
+		assert.Equal(t, http.StatusBadRequest, status)
+
+		result, status, err := service.PatchDashboardTemplateWidgets(context.Background(), testRegistries, int64(template.ID), []api.WidgetLayoutOperation{
+			{Op: api.WidgetOperationRemove, WidgetType: stringPtr("landing-./PublicWidget")},
+		}, testIdentity, nil, nil)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+		for _, layout := range [][]api.WidgetItem{result.TemplateConfig.Sm.Data(), result.TemplateConfig.Md.Data(), result.TemplateConfig.Lg.Data(), result.TemplateConfig.Xl.Data()} {
+			require.Len(t, layout, 1)
+			assert.Equal(t, "landing-./AdminWidget", layout[0].WidgetType)
+		}
+	})
+}
+
+func TestCompactDashboardTemplate(t *testing.T) {
+	t.Run("should compact all layouts and record a revision", func(t *testing.T) {
+		testUserID := test_util.GetUniqueUserID()
+		testIdentity := test_util.GenerateIdentityStructFromTemplate(
+			xrhidgen.Identity{},
+			xrhidgen.User{UserID: stringPtr(testUserID)},
+			xrhidgen.Entitlements{},
+		)
+
+		items := datatypes.NewJSONType([]api.WidgetItem{
+			{Width: 1, Height: 2, WidgetType: "widget1", X: test_util.IntPTR(0), Y: test_util.IntPTR(5)},
+		})
+		template := test_util.MockDashboardTemplateWithSpecificUser(testUserID)
+		template.TemplateConfig = api.DashboardTemplateConfig{Sm: items, Md: items, Lg: items, Xl: items}
+		require.NoError(t, database.DB.Create(&template).Error)
+
+		result, status, err := service.CompactDashboardTemplate(testRegistries, int64(template.ID), testIdentity, nil)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, 0, *result.TemplateConfig.Sm.Data()[0].Y)
+		assert.Equal(t, 0, *result.TemplateConfig.Xl.Data()[0].Y)
+
+		revisions, _, err := service.GetTemplateRevisions(int64(template.ID), testIdentity)
+		require.NoError(t, err)
+		require.Len(t, revisions, 1)
+		assert.Equal(t, 5, *revisions[0].TemplateConfig.Sm.Data()[0].Y)
+	})
+
+	t.Run("should return 400 when a widget is used twice", func(t *testing.T) {
+		testUserID := test_util.GetUniqueUserID()
+		testIdentity := test_util.GenerateIdentityStructFromTemplate(
+			xrhidgen.Identity{},
+			xrhidgen.User{UserID: stringPtr(testUserID)},
+			xrhidgen.Entitlements{},
+		)
+
+		items := datatypes.NewJSONType([]api.WidgetItem{
+			{Width: 1, Height: 2, WidgetType: "widget1", X: test_util.IntPTR(0), Y: test_util.IntPTR(0), Static: true},
+			{Width: 1, Height: 2, WidgetType: "widget1", X: test_util.IntPTR(0), Y: test_util.IntPTR(4), Static: true},
+		})
+		template := test_util.MockDashboardTemplateWithSpecificUser(testUserID)
+		template.TemplateConfig = api.DashboardTemplateConfig{Sm: items, Md: items, Lg: items, Xl: items}
+		require.NoError(t, database.DB.Create(&template).Error)
+
+		_, status, err := service.CompactDashboardTemplate(testRegistries, int64(template.ID), testIdentity, nil)
+		assert.Equal(t, http.StatusBadRequest, status)
+		var layoutErr *api.LayoutValidationError
+		require.ErrorAs(t, err, &layoutErr)
+		require.Len(t, layoutErr.Issues, 4, "Expected one duplicate per layout")
+		for _, issue := range layoutErr.Issues {
+			assert.Equal(t, api.LayoutIssueDuplicate, issue.Kind)
+			assert.Equal(t, []string{"widget1", "widget1"}, issue.Widgets)
+		}
+	})
+}
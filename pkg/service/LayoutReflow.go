@@ -0,0 +1,28 @@
+package service
+
+import (
+	"net/http"
+
+	"github.com/RedHatInsights/widget-layout-backend/api"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/layout"
+	"github.com/sirupsen/logrus"
+)
+
+// reflowMissingBreakpoints derives the missing layouts of the configuration using the defaults of the registered widget mappings.
+func reflowMissingBreakpoints(reg *Registries, tc api.DashboardTemplateConfig, source *api.GridSizes) (api.DashboardTemplateConfig, error) {
+	return layout.ReflowConfig(tc, source, reg.WidgetMappings.All())
+}
+
+// ReflowTemplateConfig derives the missing layouts of the configuration and validates the result.
+func ReflowTemplateConfig(reg *Registries, request api.ReflowWidgetLayoutRequest) (api.DashboardTemplateConfig, int, error) {
+	tc, err := reflowMissingBreakpoints(reg, request.TemplateConfig, request.Breakpoint)
+	if err != nil {
+		logrus.Errorf("Failed to reflow template configuration: %v", err)
+		return api.DashboardTemplateConfig{}, http.StatusBadRequest, err
+	}
+	if err := tc.IsValid(); err != nil {
+		logrus.Errorf("Reflowed template configuration is invalid: %v", err)
+		return api.DashboardTemplateConfig{}, http.StatusBadRequest, err
+	}
+	return tc, http.StatusOK, nil
+}
@@ -0,0 +1,68 @@
+package service_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/RedHatInsights/widget-layout-backend/api"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/service"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/test_util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+)
+
+func TestReflowTemplateConfig(t *testing.T) {
+	t.Run("should use the defaults of the widget mappings", func(t *testing.T) {
+		testRegistries.WidgetMappings.Replace(nil)
+		defer func() { testRegistries.WidgetMappings.Replace(nil) }()
+		testRegistries.AddWidgetMapping(api.WidgetModuleFederationMetadata{
+			Scope:  "landing",
+			Module: "./RhelWidget",
+			Config: api.WidgetConfiguration{Title: "RHEL"},
+			Defaults: api.WidgetBaseDimensions{
+				Width:     test_util.IntPTR(2),
+				Height:    test_util.IntPTR(3),
+				MaxHeight: test_util.IntPTR(6),
+				MinHeight: test_util.IntPTR(2),
+			},
+		})
+
+		source := api.Sm
+		tc, status, err := service.ReflowTemplateConfig(testRegistries, api.ReflowWidgetLayoutRequest{
+			Breakpoint: &source,
+			TemplateConfig: api.DashboardTemplateConfig{
+				Sm: datatypes.NewJSONType([]api.WidgetItem{
+					{Width: 1, Height: 10, WidgetType: "landing-./RhelWidget", X: test_util.IntPTR(0), Y: test_util.IntPTR(0)},
+				}),
+			},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+
+		xl := tc.Xl.Data()
+		require.Len(t, xl, 1)
+		assert.Equal(t, 2, xl[0].Width)
+		assert.Equal(t, 6, xl[0].Height)
+		assert.Equal(t, 1, tc.Sm.Data()[0].Width, "The authored breakpoint should not change")
+	})
+
+	t.Run("should return 400 when the authored breakpoint is invalid", func(t *testing.T) {
+		_, status, err := service.ReflowTemplateConfig(testRegistries, api.ReflowWidgetLayoutRequest{
+			TemplateConfig: api.DashboardTemplateConfig{
+				Xl: datatypes.NewJSONType([]api.WidgetItem{
+					{Width: 1, Height: 2, WidgetType: "widget1", X: test_util.IntPTR(0), Y: test_util.IntPTR(0)},
+					{Width: 1, Height: 2, WidgetType: "widget2", X: test_util.IntPTR(0), Y: test_util.IntPTR(1)},
+				}),
+			},
+		})
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusBadRequest, status)
+	})
+
+	t.Run("should return 400 without an authored breakpoint", func(t *testing.T) {
+		_, status, err := service.ReflowTemplateConfig(testRegistries, api.ReflowWidgetLayoutRequest{})
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusBadRequest, status)
+	})
+}
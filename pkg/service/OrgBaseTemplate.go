@@ -0,0 +1,150 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/RedHatInsights/widget-layout-backend/api"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/database"
+	"github.com/redhatinsights/platform-go-middlewares/v2/identity"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// resolveBaseTemplate returns the base template a dashboard template of the identity is forked from, reset to or
+// synchronized with. The base template of the organization of the identity takes precedence over the global one.
+func resolveBaseTemplate(reg *Registries, name string, id identity.XRHID) (api.BaseWidgetDashboardTemplate, bool, error) {
+	if id.Identity.OrgID != "" {
+		var orgTemplate api.OrgBaseTemplate
+		err := database.DB.Where("org_id = ? AND name = ?", id.Identity.OrgID, name).First(&orgTemplate).Error
+		if err == nil {
+			return orgTemplate.ToBaseTemplate(), true, nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return api.BaseWidgetDashboardTemplate{}, false, err
+		}
+	}
+	base, exists := reg.BaseTemplates.Get(name)
+	return base, exists, nil
+}
+
+// checkOrgAdmin rejects identities which cannot manage the base templates and the widget policy of their organization.
+func checkOrgAdmin(id identity.XRHID) (int, error) {
+	if id.Identity.OrgID == "" || !id.Identity.User.OrgAdmin {
+		logrus.Errorf("User %s is not an org admin of organization %s", id.Identity.User.UserID, id.Identity.OrgID)
+		return http.StatusForbidden, errors.New("unauthorized")
+	}
+	return http.StatusOK, nil
+}
+
+func GetOrgBaseTemplates(id identity.XRHID) ([]api.OrgBaseTemplate, int, error) {
+	if status, err := checkOrgAdmin(id); err != nil {
+		return nil, status, err
+	}
+	templates := []api.OrgBaseTemplate{}
+	if err := database.DB.Where("org_id = ?", id.Identity.OrgID).Order("name").Find(&templates).Error; err != nil {
+		logrus.Errorf("Failed to retrieve base templates of organization %s: %v", id.Identity.OrgID, err)
+		return nil, http.StatusInternalServerError, err
+	}
+	return templates, http.StatusOK, nil
+}
+
+func GetOrgBaseTemplate(name string, id identity.XRHID) (api.OrgBaseTemplate, int, error) {
+	if status, err := checkOrgAdmin(id); err != nil {
+		return api.OrgBaseTemplate{}, status, err
+	}
+	var template api.OrgBaseTemplate
+	err := database.DB.Where("org_id = ? AND name = ?", id.Identity.OrgID, name).First(&template).Error
+	if ret, status, err := handleServiceError(
+		err,
+		fmt.Sprintf("Base template %s of organization %s not found", name, id.Identity.OrgID),
+		"Failed to retrieve base template of organization: %v", http.StatusNotFound,
+		api.OrgBaseTemplate{}, api.OrgBaseTemplate{},
+	); err != nil {
+		return ret, status, err
+	}
+	return template, http.StatusOK, nil
+}
+
+// PutOrgBaseTemplate creates or replaces a base template of the organization of the identity. Its layouts are
+// validated like the layouts of a dashboard template, layouts missing from the request are reflowed.
+func PutOrgBaseTemplate(reg *Registries, name string, request api.OrgBaseTemplateRequest, id identity.XRHID) (api.OrgBaseTemplate, int, error) {
+	if status, err := checkOrgAdmin(id); err != nil {
+		return api.OrgBaseTemplate{}, status, err
+	}
+	if name == "" || request.DisplayName == "" {
+		return api.OrgBaseTemplate{}, http.StatusBadRequest, errors.New("name and displayName are required")
+	}
+	templateConfig, err := reflowMissingBreakpoints(reg, request.TemplateConfig, nil)
+	if err != nil {
+		logrus.Errorf("Invalid base template %s of organization %s: %v", name, id.Identity.OrgID, err)
+		return api.OrgBaseTemplate{}, http.StatusBadRequest, err
+	}
+	// validate the layouts the same way a dashboard template forked from the base template is validated
+	candidate := api.DashboardTemplate{
+		TemplateBase:   api.DashboardTemplateBase{Name: name, DisplayName: request.DisplayName},
+		TemplateConfig: templateConfig,
+	}
+	if err := checkWidgetMappings(reg, &candidate, nil); err != nil {
+		logrus.Errorf("Invalid base template %s of organization %s: %v", name, id.Identity.OrgID, err)
+		return api.OrgBaseTemplate{}, http.StatusBadRequest, err
+	}
+	if err := candidate.TemplateConfig.IsValid(); err != nil {
+		logrus.Errorf("Invalid base template %s of organization %s: %v", name, id.Identity.OrgID, err)
+		return api.OrgBaseTemplate{}, http.StatusBadRequest, err
+	}
+	if request.Policy != nil {
+		// dashboard templates forked from the base template have to follow its policy right away
+		err := request.Policy.IsValid()
+		if err == nil {
+			err = request.Policy.Check(templateConfig, &templateConfig)
+		}
+		if err != nil {
+			logrus.Errorf("Invalid widget policy of base template %s of organization %s: %v", name, id.Identity.OrgID, err)
+			return api.OrgBaseTemplate{}, http.StatusBadRequest, err
+		}
+	}
+
+	template := api.OrgBaseTemplate{
+		OrgId:          id.Identity.OrgID,
+		Name:           name,
+		DisplayName:    request.DisplayName,
+		TemplateConfig: templateConfig,
+		Policy:         request.Policy,
+		UpdatedBy:      id.Identity.User.UserID,
+	}
+	err = database.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "org_id"}, {Name: "name"}},
+		DoUpdates: clause.AssignmentColumns([]string{"display_name", "sm", "md", "lg", "xl", "policy", "updated_by", "updated_at"}),
+	}).Create(&template).Error
+	if err != nil {
+		logrus.Errorf("Failed to store base template %s of organization %s: %v", name, id.Identity.OrgID, err)
+		return api.OrgBaseTemplate{}, http.StatusInternalServerError, err
+	}
+	// the upsert does not report the stored row on every database, it is read back instead
+	var stored api.OrgBaseTemplate
+	if err := database.DB.Where("org_id = ? AND name = ?", template.OrgId, template.Name).First(&stored).Error; err != nil {
+		logrus.Errorf("Failed to retrieve base template %s of organization %s: %v", name, id.Identity.OrgID, err)
+		return api.OrgBaseTemplate{}, http.StatusInternalServerError, err
+	}
+	logrus.Infof("User %s stored base template %s of organization %s", id.Identity.User.UserID, name, id.Identity.OrgID)
+	return stored, http.StatusOK, nil
+}
+
+func DeleteOrgBaseTemplate(name string, id identity.XRHID) (int, error) {
+	if status, err := checkOrgAdmin(id); err != nil {
+		return status, err
+	}
+	res := database.DB.Where("org_id = ? AND name = ?", id.Identity.OrgID, name).Delete(&api.OrgBaseTemplate{})
+	if res.Error != nil {
+		logrus.Errorf("Failed to delete base template %s of organization %s: %v", name, id.Identity.OrgID, res.Error)
+		return http.StatusInternalServerError, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return http.StatusNotFound, fmt.Errorf("Base template %s of organization %s not found", name, id.Identity.OrgID)
+	}
+	logrus.Infof("User %s deleted base template %s of organization %s", id.Identity.User.UserID, name, id.Identity.OrgID)
+	return http.StatusNoContent, nil
+}
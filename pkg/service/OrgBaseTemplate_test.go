@@ -0,0 +1,183 @@
+package service_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/RedHatInsights/widget-layout-backend/api"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/service"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/test_util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+)
+
+// setupOrgBaseTemplates registers a global base template and the widget the org base templates of the tests use
+func setupOrgBaseTemplates(t *testing.T, name string) {
+	testRegistries.WidgetMappings.Replace(nil)
+	t.Cleanup(func() { testRegistries.WidgetMappings.Replace(nil) })
+	testRegistries.AddWidgetMapping(api.WidgetModuleFederationMetadata{
+		Scope:  "landing",
+		Module: "./RhelWidget",
+		Config: api.WidgetConfiguration{Title: "RHEL"},
+		Defaults: api.WidgetBaseDimensions{
+			Width:     test_util.IntPTR(1),
+			Height:    test_util.IntPTR(2),
+			MaxHeight: test_util.IntPTR(4),
+			MinHeight: test_util.IntPTR(1),
+		},
+	})
+	testRegistries.AddBaseTemplate(api.BaseWidgetDashboardTemplate{
+		Name:        name,
+		DisplayName: "Global Base",
+		TemplateConfig: api.DashboardTemplateConfig{
+			Sm: datatypes.NewJSONType([]api.WidgetItem{
+				{Width: 1, Height: 2, WidgetType: "global-widget", X: test_util.IntPTR(0), Y: test_util.IntPTR(0)},
+			}),
+			Md: datatypes.NewJSONType([]api.WidgetItem{}),
+			Lg: datatypes.NewJSONType([]api.WidgetItem{}),
+			Xl: datatypes.NewJSONType([]api.WidgetItem{}),
+		},
+	})
+}
+
+func orgBaseTemplateRequest() api.OrgBaseTemplateRequest {
+	return api.OrgBaseTemplateRequest{
+		DisplayName: "Org Base",
+		TemplateConfig: api.DashboardTemplateConfig{
+			Sm: datatypes.NewJSONType([]api.WidgetItem{
+				{Width: 1, Height: 2, WidgetType: "landing-./RhelWidget", X: test_util.IntPTR(0), Y: test_util.IntPTR(0)},
+			}),
+		},
+	}
+}
+
+func TestOrgBaseTemplate(t *testing.T) {
+	t.Run("should let org admins manage the base templates of their organization", func(t *testing.T) {
+		setupOrgBaseTemplates(t, "org-managed-base")
+		orgID := "org-" + test_util.GetUniqueUserID()
+		adminID := test_util.GetUniqueUserID()
+		admin := orgIdentity(adminID, orgID, true)
+
+		stored, status, err := service.PutOrgBaseTemplate(testRegistries, "org-managed-base", orgBaseTemplateRequest(), admin)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+		assert.NotZero(t, stored.ID)
+		assert.Equal(t, orgID, stored.OrgId)
+		assert.Equal(t, adminID, stored.UpdatedBy)
+		assert.Len(t, stored.TemplateConfig.Xl.Data(), 1, "Missing layouts should be reflowed")
+
+		request := orgBaseTemplateRequest()
+		request.DisplayName = "Org Base Renamed"
+		replaced, status, err := service.PutOrgBaseTemplate(testRegistries, "org-managed-base", request, admin)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, stored.ID, replaced.ID, "Storing a base template again should replace it")
+		assert.Equal(t, "Org Base Renamed", replaced.DisplayName)
+
+		found, status, err := service.GetOrgBaseTemplate("org-managed-base", admin)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, "Org Base Renamed", found.DisplayName)
+
+		templates, status, err := service.GetOrgBaseTemplates(admin)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+		require.Len(t, templates, 1)
+
+		status, err = service.DeleteOrgBaseTemplate("org-managed-base", admin)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNoContent, status)
+
+		_, status, err = service.GetOrgBaseTemplate("org-managed-base", admin)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusNotFound, status)
+
+		status, err = service.DeleteOrgBaseTemplate("org-managed-base", admin)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusNotFound, status)
+	})
+
+	t.Run("should only let org admins manage base templates", func(t *testing.T) {
+		setupOrgBaseTemplates(t, "org-managed-base")
+		member := orgIdentity(test_util.GetUniqueUserID(), "org-"+test_util.GetUniqueUserID(), false)
+
+		_, status, err := service.PutOrgBaseTemplate(testRegistries, "org-managed-base", orgBaseTemplateRequest(), member)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusForbidden, status)
+
+		_, status, err = service.GetOrgBaseTemplates(member)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusForbidden, status)
+
+		_, status, err = service.GetOrgBaseTemplates(orgIdentity(test_util.GetUniqueUserID(), "", true))
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusForbidden, status)
+	})
+
+	t.Run("should reject base templates with unknown widgets", func(t *testing.T) {
+		setupOrgBaseTemplates(t, "org-managed-base")
+		admin := orgIdentity(test_util.GetUniqueUserID(), "org-"+test_util.GetUniqueUserID(), true)
+		request := orgBaseTemplateRequest()
+		request.TemplateConfig.Sm = datatypes.NewJSONType([]api.WidgetItem{
+			{Width: 1, Height: 2, WidgetType: "unknown-widget", X: test_util.IntPTR(0), Y: test_util.IntPTR(0)},
+		})
+
+		_, status, err := service.PutOrgBaseTemplate(testRegistries, "org-managed-base", request, admin)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusBadRequest, status)
+	})
+
+	t.Run("should reject widget policies the base template does not follow", func(t *testing.T) {
+		setupOrgBaseTemplates(t, "org-managed-base")
+		admin := orgIdentity(test_util.GetUniqueUserID(), "org-"+test_util.GetUniqueUserID(), true)
+		request := orgBaseTemplateRequest()
+		request.Policy = &api.WidgetPolicy{MandatoryWidgets: []string{"landing-./MissingWidget"}}
+
+		_, status, err := service.PutOrgBaseTemplate(testRegistries, "org-managed-base", request, admin)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusBadRequest, status)
+
+		request.Policy = &api.WidgetPolicy{LockedWidgets: []string{"landing-./RhelWidget"}}
+		stored, status, err := service.PutOrgBaseTemplate(testRegistries, "org-managed-base", request, admin)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+		require.NotNil(t, stored.Policy)
+		assert.Equal(t, []string{"landing-./RhelWidget"}, stored.Policy.LockedWidgets)
+	})
+
+	t.Run("should fork, reset and auto-create from the base template of the organization", func(t *testing.T) {
+		setupOrgBaseTemplates(t, "org-override-base")
+		orgID := "org-" + test_util.GetUniqueUserID()
+		_, _, err := service.PutOrgBaseTemplate(testRegistries, "org-override-base", orgBaseTemplateRequest(), orgIdentity(test_util.GetUniqueUserID(), orgID, true))
+		require.NoError(t, err)
+
+		member := orgIdentity(test_util.GetUniqueUserID(), orgID, false)
+		fork, status, err := service.ForkBaseTemplate(testRegistries, "org-override-base", member)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, "Org Base", fork.TemplateBase.DisplayName)
+		assert.Equal(t, "landing-./RhelWidget", fork.TemplateConfig.Sm.Data()[0].WidgetType)
+
+		dashboardType := "org-override-base"
+		templates, _, status, err := service.GetUserTemplates(testRegistries, orgIdentity(test_util.GetUniqueUserID(), orgID, false), api.GetWidgetLayoutParams{DashboardType: &dashboardType})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, status, "Auto-created templates are reported with 404")
+		require.Len(t, templates, 1)
+		assert.Equal(t, "landing-./RhelWidget", templates[0].TemplateConfig.Sm.Data()[0].WidgetType)
+
+		// the global base template is used by other organizations
+		other, _, err := service.ForkBaseTemplate(testRegistries, "org-override-base", orgIdentity(test_util.GetUniqueUserID(), "org-"+test_util.GetUniqueUserID(), false))
+		require.NoError(t, err)
+		assert.Equal(t, "global-widget", other.TemplateConfig.Sm.Data()[0].WidgetType)
+
+		// and again once the organization drops its own
+		status, err = service.DeleteOrgBaseTemplate("org-override-base", orgIdentity(test_util.GetUniqueUserID(), orgID, true))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNoContent, status)
+		reset, status, err := service.ResetDashboardTemplate(testRegistries, int64(fork.ID), member, nil)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, "global-widget", reset.TemplateConfig.Sm.Data()[0].WidgetType)
+	})
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"sync"
+
+	"github.com/RedHatInsights/widget-layout-backend/api"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/config"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/featureflags"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/registry"
+)
+
+// Registries holds the base templates, widget mappings and feature flags the services read from. It is created
+// once on startup and passed to the services, tests create their own instead of sharing one.
+type Registries struct {
+	BaseTemplates  registry.Registry[string, api.BaseWidgetDashboardTemplate]
+	WidgetMappings registry.Registry[string, api.WidgetModuleFederationMetadata]
+	// FlagProvider evaluates the feature flags of the widget mappings, widgets are not filtered by flags when it is nil
+	FlagProvider featureflags.FlagProvider
+
+	// loadMu serializes loads, a widget mapping load also loads the base templates again
+	loadMu sync.Mutex
+	// baseTemplatesSource holds the last loaded base templates, they are loaded again whenever the widget mappings change
+	baseTemplatesSource string
+}
+
+// NewRegistries creates empty registries.
+func NewRegistries() *Registries {
+	return &Registries{
+		BaseTemplates:  registry.New[string, api.BaseWidgetDashboardTemplate](),
+		WidgetMappings: registry.New[string, api.WidgetModuleFederationMetadata](),
+	}
+}
+
+// LoadRegistries creates the registries from the configured files, or from the environment variables when no files are configured.
+func LoadRegistries(cfg *config.WidgetLayoutConfig) (*Registries, error) {
+	widgetMappings, err := readRegistrySource(cfg.WidgetMappingFile, cfg.WidgetMappingConfig)
+	if err != nil {
+		return nil, err
+	}
+	baseTemplates, err := readRegistrySource(cfg.BaseWidgetDashboardFile, cfg.BaseWidgetDashboardTemplates)
+	if err != nil {
+		return nil, err
+	}
+	r := NewRegistries()
+	// base templates are reflowed with the defaults of the widget mappings, so the mappings have to be loaded first
+	if err := r.LoadWidgetMappingsFromConfig(widgetMappings); err != nil {
+		return nil, err
+	}
+	// the mappings and the templates are deployed separately, a template not matching the mappings must not keep the
+	// service from starting, it is left out until a reload of either file makes them match
+	if baseTemplates != "" {
+		if err := r.loadBaseTemplates(baseTemplates, true); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// AddBaseTemplate adds the base template to the registry, a template with the same name is replaced.
+func (r *Registries) AddBaseTemplate(bt api.BaseWidgetDashboardTemplate) {
+	r.BaseTemplates.Put(bt.Name, bt)
+}
+
+// AddWidgetMapping adds the widget mapping to the registry under its widget key, a mapping with the same key is replaced.
+func (r *Registries) AddWidgetMapping(wm api.WidgetModuleFederationMetadata) {
+	r.WidgetMappings.Put(wm.GetWidgetKey(), wm)
+}
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	baseTemplatesRegistry  = "base_templates"
+	widgetMappingsRegistry = "widget_mappings"
+)
+
+var (
+	registryLoadGeneration = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "widget_layout_registry_load_generation",
+		Help: "Generation of the registry, incremented every time the registry is loaded successfully",
+	}, []string{"registry"})
+	registryLastReload = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "widget_layout_registry_last_reload_timestamp_seconds",
+		Help: "Unix timestamp of the last successful load of the registry",
+	}, []string{"registry"})
+	registryReloadFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "widget_layout_registry_reload_failures_total",
+		Help: "Number of reloads of the registry which failed and kept the previous version",
+	}, []string{"registry"})
+)
+
+func recordRegistryLoad(registry string) {
+	registryLoadGeneration.WithLabelValues(registry).Inc()
+	registryLastReload.WithLabelValues(registry).SetToCurrentTime()
+}
+
+// readRegistrySource returns the content of the file when it is configured and the fallback otherwise.
+// A missing file is treated as empty, ConfigMap keys are optional and the file may appear later.
+func readRegistrySource(file string, fallback string) (string, error) {
+	if file == "" {
+		return fallback, nil
+	}
+	content, err := os.ReadFile(file)
+	if errors.Is(err, fs.ErrNotExist) {
+		logrus.Warnf("Registry file %s does not exist, it is loaded once it is created", file)
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
+
+type registryFile struct {
+	registry string
+	path     string
+	load     func(string) error
+	loaded   string
+}
+
+// reload loads the file again when its content changed since the last load.
+// The registry keeps its previous version when the new content is invalid.
+func (rf *registryFile) reload() {
+	content, err := os.ReadFile(rf.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		// mounted ConfigMaps briefly miss their files while the symlinks are swapped
+		return
+	}
+	if err == nil && string(content) == rf.loaded {
+		return
+	}
+	if err == nil && len(content) == 0 {
+		err = errors.New("file is empty")
+	}
+	if err == nil {
+		err = rf.load(string(content))
+	}
+	if err != nil {
+		logrus.Errorf("Failed to reload %s from %s, keeping the previous version: %v", rf.registry, rf.path, err)
+		registryReloadFailures.WithLabelValues(rf.registry).Inc()
+		return
+	}
+	rf.loaded = string(content)
+	logrus.Infof("Reloaded %s from %s", rf.registry, rf.path)
+}
+
+// WatchFiles reloads the widget mappings and base templates whenever their files change, until the context is done.
+// The directories of the files are watched instead of the files, Kubernetes updates mounted ConfigMaps by swapping symlinks.
+// Nothing is watched when neither file is configured.
+func (r *Registries) WatchFiles(ctx context.Context, baseTemplatesFile string, widgetMappingFile string) error {
+	var files []*registryFile
+	// mappings first, base templates depend on them
+	if widgetMappingFile != "" {
+		files = append(files, &registryFile{registry: widgetMappingsRegistry, path: filepath.Clean(widgetMappingFile), load: r.LoadWidgetMappingsFromConfig})
+	}
+	if baseTemplatesFile != "" {
+		files = append(files, &registryFile{registry: baseTemplatesRegistry, path: filepath.Clean(baseTemplatesFile), load: r.LoadBaseTemplatesFromConfig})
+	}
+	if len(files) == 0 {
+		return nil
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	watched := make(map[string]bool)
+	for _, rf := range files {
+		// the files were loaded on start up, only later changes are reloaded
+		if content, err := os.ReadFile(rf.path); err == nil {
+			rf.loaded = string(content)
+		}
+		dir := filepath.Dir(rf.path)
+		if watched[dir] {
+			continue
+		}
+		if err := watcher.Add(dir); err != nil {
+			_ = watcher.Close()
+			return fmt.Errorf("failed to watch %s: %w", dir, err)
+		}
+		watched[dir] = true
+	}
+
+	go func() {
+		defer watcher.Close()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				for _, rf := range files {
+					if filepath.Dir(event.Name) == filepath.Dir(rf.path) {
+						rf.reload()
+					}
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				logrus.Errorf("Registry file watcher failed: %v", err)
+			}
+		}
+	}()
+	logrus.Infof("Watching %d registry files for changes", len(files))
+	return nil
+}
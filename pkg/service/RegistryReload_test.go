@@ -0,0 +1,246 @@
+package service_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/RedHatInsights/widget-layout-backend/api"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/config"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/service"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const reloadMappingJSON = `[{"scope": "landing", "module": "./FirstWidget", "config": {"title": "First"}, "defaults": {"w": 1, "h": 1}}]`
+
+// reloadBaseTemplateJSON returns a base template config with a single widget of the reloadMappingJSON mapping
+func reloadBaseTemplateJSON(name string) string {
+	return fmt.Sprintf(`[{"name": %q, "displayName": %q, "templateConfig": {
+		"sm": [{"w": 1, "h": 1, "x": 0, "y": 0, "i": "landing-./FirstWidget"}]
+	}}]`, name, name)
+}
+
+// registryMetric returns the value of the registry metric from the default prometheus registry
+func registryMetric(t *testing.T, name string, registry string) float64 {
+	families, err := prometheus.DefaultGatherer.Gather()
+	require.NoError(t, err)
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "registry" && label.GetValue() == registry {
+					if metric.GetCounter() != nil {
+						return metric.GetCounter().GetValue()
+					}
+					return metric.GetGauge().GetValue()
+				}
+			}
+		}
+	}
+	return 0
+}
+
+// writeFileAtomically replaces the file with a rename, the way editors and config management tools do
+func writeFileAtomically(t *testing.T, path string, content string) {
+	tmp := path + ".tmp"
+	require.NoError(t, os.WriteFile(tmp, []byte(content), 0o600))
+	require.NoError(t, os.Rename(tmp, path))
+}
+
+func TestLoadWidgetMappingsFromConfig(t *testing.T) {
+	t.Run("should replace the widget mappings", func(t *testing.T) {
+		registries := service.NewRegistries()
+		registries.AddWidgetMapping(api.WidgetModuleFederationMetadata{Scope: "old", Module: "./OldWidget"})
+		generation := registryMetric(t, "widget_layout_registry_load_generation", "widget_mappings")
+
+		require.NoError(t, registries.LoadWidgetMappingsFromConfig(reloadMappingJSON))
+
+		mappings := service.GetWidgetMappings(registries)
+		assert.Len(t, mappings, 1)
+		assert.Contains(t, mappings, "landing-./FirstWidget")
+		assert.Equal(t, generation+1, registryMetric(t, "widget_layout_registry_load_generation", "widget_mappings"))
+		assert.NotZero(t, registryMetric(t, "widget_layout_registry_last_reload_timestamp_seconds", "widget_mappings"))
+	})
+
+	t.Run("should keep the widget mappings when the config is invalid", func(t *testing.T) {
+		registries := service.NewRegistries()
+		require.NoError(t, registries.LoadWidgetMappingsFromConfig(reloadMappingJSON))
+
+		assert.Error(t, registries.LoadWidgetMappingsFromConfig(`not json`))
+		assert.Error(t, registries.LoadWidgetMappingsFromConfig(`[{"scope": "landing", "config": {"title": "No Module"}}]`))
+
+		mappings := service.GetWidgetMappings(registries)
+		assert.Len(t, mappings, 1)
+		assert.Contains(t, mappings, "landing-./FirstWidget")
+	})
+
+	t.Run("should load the base templates again with the new widget mappings", func(t *testing.T) {
+		registries := service.NewRegistries()
+		require.NoError(t, registries.LoadWidgetMappingsFromConfig(reloadMappingJSON))
+		require.NoError(t, registries.LoadBaseTemplatesFromConfig(`[{"name": "landing", "displayName": "Landing", "templateConfig": {
+			"sm": [{"w": 1, "h": 1, "x": 0, "y": 0, "i": "landing-./FirstWidget"}]
+		}}]`))
+		base, _ := registries.BaseTemplates.Get("landing")
+		require.Len(t, base.TemplateConfig.Xl.Data(), 1)
+		assert.Equal(t, 1, base.TemplateConfig.Xl.Data()[0].Width)
+
+		require.NoError(t, registries.LoadWidgetMappingsFromConfig(`[{"scope": "landing", "module": "./FirstWidget", "config": {"title": "First"}, "defaults": {"w": 2, "h": 1}}]`))
+
+		base, _ = registries.BaseTemplates.Get("landing")
+		require.Len(t, base.TemplateConfig.Xl.Data(), 1)
+		assert.Equal(t, 2, base.TemplateConfig.Xl.Data()[0].Width, "Derived breakpoints should use the new defaults")
+	})
+}
+
+func TestLoadRegistries(t *testing.T) {
+	unmappedTemplateJSON := `[
+		{"name": "first", "displayName": "First", "templateConfig": {
+			"sm": [{"w": 1, "h": 1, "x": 0, "y": 0, "i": "landing-./FirstWidget"}]
+		}},
+		{"name": "unmapped", "displayName": "Unmapped", "templateConfig": {
+			"sm": [{"w": 1, "h": 1, "x": 0, "y": 0, "i": "landing-./UnknownWidget"}]
+		}}
+	]`
+
+	t.Run("should start without the base templates which do not match the widget mappings", func(t *testing.T) {
+		registries, err := service.LoadRegistries(&config.WidgetLayoutConfig{
+			WidgetMappingConfig:          reloadMappingJSON,
+			BaseWidgetDashboardTemplates: unmappedTemplateJSON,
+		})
+		require.NoError(t, err)
+
+		_, exists := registries.BaseTemplates.Get("first")
+		assert.True(t, exists)
+		_, exists = registries.BaseTemplates.Get("unmapped")
+		assert.False(t, exists, "The template not matching the widget mappings should be left out")
+
+		err = registries.LoadBaseTemplatesFromConfig(unmappedTemplateJSON)
+		assert.Error(t, err, "Reloads should keep rejecting templates which do not match the widget mappings")
+		assert.Equal(t, 1, registries.BaseTemplates.Len())
+	})
+
+	t.Run("should not start with an invalid base template config", func(t *testing.T) {
+		_, err := service.LoadRegistries(&config.WidgetLayoutConfig{
+			WidgetMappingConfig:          reloadMappingJSON,
+			BaseWidgetDashboardTemplates: `[{"displayName": "Nameless"}]`,
+		})
+		assert.Error(t, err)
+	})
+}
+
+func TestWatchRegistryFiles(t *testing.T) {
+	registries := service.NewRegistries()
+	dir := t.TempDir()
+	mappingFile := filepath.Join(dir, "widget-registry.json")
+	baseFile := filepath.Join(dir, "base-widget-dashboard-templates.json")
+	require.NoError(t, os.WriteFile(mappingFile, []byte(reloadMappingJSON), 0o600))
+	require.NoError(t, os.WriteFile(baseFile, []byte(reloadBaseTemplateJSON("first")), 0o600))
+	require.NoError(t, registries.LoadWidgetMappingsFromConfig(reloadMappingJSON))
+	require.NoError(t, registries.LoadBaseTemplatesFromConfig(reloadBaseTemplateJSON("first")))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	require.NoError(t, registries.WatchFiles(ctx, baseFile, mappingFile))
+
+	t.Run("should reload changed files", func(t *testing.T) {
+		generation := registryMetric(t, "widget_layout_registry_load_generation", "base_templates")
+
+		writeFileAtomically(t, baseFile, reloadBaseTemplateJSON("second"))
+
+		assert.Eventually(t, func() bool {
+			_, exists := registries.BaseTemplates.Get("second")
+			return exists
+		}, 5*time.Second, 10*time.Millisecond)
+		_, exists := registries.BaseTemplates.Get("first")
+		assert.False(t, exists, "The previous templates should be replaced")
+		assert.GreaterOrEqual(t, registryMetric(t, "widget_layout_registry_load_generation", "base_templates"), generation+1)
+	})
+
+	t.Run("should keep the previous version when a file is invalid", func(t *testing.T) {
+		failures := registryMetric(t, "widget_layout_registry_reload_failures_total", "widget_mappings")
+
+		writeFileAtomically(t, mappingFile, `[{"scope": "landing"`)
+
+		assert.Eventually(t, func() bool {
+			return registryMetric(t, "widget_layout_registry_reload_failures_total", "widget_mappings") > failures
+		}, 5*time.Second, 10*time.Millisecond)
+		assert.Contains(t, service.GetWidgetMappings(registries), "landing-./FirstWidget")
+	})
+
+	t.Run("should keep the previous base templates when a layout is invalid", func(t *testing.T) {
+		_, exists := registries.BaseTemplates.Get("second")
+		require.True(t, exists)
+		registries.AddWidgetMapping(api.WidgetModuleFederationMetadata{Scope: "landing", Module: "./SecondWidget", Config: api.WidgetConfiguration{Title: "Second"}})
+		tests := []struct {
+			name    string
+			content string
+		}{
+			{
+				// base templates are validated as authored, overlapping widgets are not compacted apart
+				name: "overlapping",
+				content: `[{"name": "overlapping", "displayName": "Overlapping", "templateConfig": {"sm": [
+					{"w": 1, "h": 2, "x": 0, "y": 0, "i": "landing-./FirstWidget"},
+					{"w": 1, "h": 2, "x": 0, "y": 1, "i": "landing-./SecondWidget"}
+				]}}]`,
+			},
+			{
+				name: "unmapped",
+				content: `[{"name": "unmapped", "displayName": "Unmapped", "templateConfig": {"sm": [
+					{"w": 1, "h": 1, "x": 0, "y": 0, "i": "landing-./UnknownWidget"}
+				]}}]`,
+			},
+		}
+		for _, tt := range tests {
+			generation := registryMetric(t, "widget_layout_registry_load_generation", "base_templates")
+			failures := registryMetric(t, "widget_layout_registry_reload_failures_total", "base_templates")
+
+			writeFileAtomically(t, baseFile, tt.content)
+
+			assert.Eventually(t, func() bool {
+				return registryMetric(t, "widget_layout_registry_reload_failures_total", "base_templates") > failures
+			}, 5*time.Second, 10*time.Millisecond, tt.name)
+			assert.Equal(t, generation, registryMetric(t, "widget_layout_registry_load_generation", "base_templates"), tt.name)
+			_, exists := registries.BaseTemplates.Get(tt.name)
+			assert.False(t, exists, tt.name)
+			assert.Equal(t, 1, registries.BaseTemplates.Len(), tt.name)
+			_, exists = registries.BaseTemplates.Get("second")
+			assert.True(t, exists, "The previous templates should be kept")
+		}
+	})
+}
+
+func TestWatchRegistryFilesConfigMapMount(t *testing.T) {
+	registries := service.NewRegistries()
+
+	// kubelet mounts ConfigMap keys as symlinks to a ..data symlink, which is swapped to a new directory on update
+	dir := t.TempDir()
+	writeVersion := func(version string, content string) {
+		require.NoError(t, os.Mkdir(filepath.Join(dir, version), 0o700))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, version, "widget-registry.json"), []byte(content), 0o600))
+		require.NoError(t, os.Symlink(version, filepath.Join(dir, "..data_tmp")))
+		require.NoError(t, os.Rename(filepath.Join(dir, "..data_tmp"), filepath.Join(dir, "..data")))
+	}
+	writeVersion("..v1", reloadMappingJSON)
+	mappingFile := filepath.Join(dir, "widget-registry.json")
+	require.NoError(t, os.Symlink(filepath.Join("..data", "widget-registry.json"), mappingFile))
+	require.NoError(t, registries.LoadWidgetMappingsFromConfig(reloadMappingJSON))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	require.NoError(t, registries.WatchFiles(ctx, "", mappingFile))
+
+	writeVersion("..v2", `[{"scope": "landing", "module": "./SecondWidget", "config": {"title": "Second"}}]`)
+
+	assert.Eventually(t, func() bool {
+		_, exists := registries.WidgetMappings.Get("landing-./SecondWidget")
+		return exists
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, 1, registries.WidgetMappings.Len())
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/RedHatInsights/widget-layout-backend/api"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/database"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/events"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/layout"
+	"github.com/redhatinsights/platform-go-middlewares/v2/identity"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// OutboxRelayBatchSize is the maximum number of events published at once by the outbox relay
+const OutboxRelayBatchSize = 100
+
+// templateEventType returns the type of the change event of a template recorded with the audit action.
+func templateEventType(action api.AuditAction) string {
+	switch action {
+	case api.AuditActionCreate, api.AuditActionCopy, api.AuditActionImport, api.AuditActionFork, api.AuditActionRestore:
+		return events.TypeTemplateCreated
+	case api.AuditActionDelete:
+		return events.TypeTemplateDeleted
+	case api.AuditActionSetDefault:
+		return events.TypeTemplateDefaultChanged
+	default:
+		return events.TypeTemplateUpdated
+	}
+}
+
+// enqueueTemplateEvent writes the change event of the template to the outbox and queues its delivery to the webhooks
+// of the organization, both in the transaction of the change. The outbox relay publishes the event once the change
+// is committed. before is the configuration of the template before the change, the widgets which differ from the
+// current configuration are part of the event.
+func enqueueTemplateEvent(tx *gorm.DB, id identity.XRHID, action api.AuditAction, template api.DashboardTemplate, before api.DashboardTemplateConfig) error {
+	diff := layout.DiffConfig(before, template.TemplateConfig)
+	// templates created before organizations were recorded belong to the organization of their owner
+	orgID := template.OrgId
+	if orgID == "" {
+		orgID = id.Identity.OrgID
+	}
+	event, err := events.NewCloudEvent(templateEventType(action), strconv.FormatUint(uint64(template.ID), 10), orgID, events.TemplateEventData{
+		DashboardTemplateID: template.ID,
+		DashboardType:       template.TemplateBase.Name,
+		UserID:              template.UserId,
+		OrgID:               orgID,
+		ActorID:             id.Identity.User.UserID,
+		Action:              string(action),
+		Default:             template.Default,
+		AddedWidgets:        diff.Added,
+		RemovedWidgets:      diff.Removed,
+		ChangedWidgets:      diff.Changed,
+	})
+	if err != nil {
+		return err
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	if err := tx.Create(&api.OutboxEvent{Payload: payload}).Error; err != nil {
+		return err
+	}
+	return enqueueWebhookDeliveries(tx, orgID, event, payload)
+}
+
+// RelayOutboxEvents publishes the oldest events of the outbox and removes them once they are published.
+// Events are delivered at least once: when removing them fails after publishing, they are published again.
+// Concurrent relays skip the events locked by each other.
+func RelayOutboxEvents(ctx context.Context, publisher events.Publisher, limit int) (int, error) {
+	var relayed int
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var outbox []api.OutboxEvent
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Order("id").Limit(limit).Find(&outbox).Error
+		if err != nil || len(outbox) == 0 {
+			return err
+		}
+		pending := make([]events.CloudEvent, 0, len(outbox))
+		ids := make([]uint, 0, len(outbox))
+		for _, entry := range outbox {
+			var event events.CloudEvent
+			if err := json.Unmarshal(entry.Payload, &event); err != nil {
+				// an event which cannot be decoded would block the outbox forever
+				logrus.Errorf("Dropping undecodable outbox event with ID %d: %v", entry.ID, err)
+			} else {
+				pending = append(pending, event)
+			}
+			ids = append(ids, entry.ID)
+		}
+		if err := publisher.Publish(ctx, pending...); err != nil {
+			return err
+		}
+		relayed = len(ids)
+		return tx.Delete(&api.OutboxEvent{}, ids).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+	return relayed, nil
+}
+
+// StartOutboxRelay periodically publishes the events of the outbox until it is empty.
+// The relay stops when the context is cancelled.
+func StartOutboxRelay(ctx context.Context, publisher events.Publisher, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				for {
+					relayed, err := RelayOutboxEvents(ctx, publisher, OutboxRelayBatchSize)
+					if err != nil {
+						logrus.Errorf("Failed to publish dashboard template events: %v", err)
+						break
+					}
+					if relayed < OutboxRelayBatchSize {
+						break
+					}
+				}
+			}
+		}
+	}()
+}
@@ -0,0 +1,124 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/RedHatInsights/widget-layout-backend/api"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/database"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/events"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/service"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/test_util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// drainOutbox publishes the events queued by other tests, so only the events of the test are relayed afterwards
+func drainOutbox(t *testing.T) {
+	for {
+		relayed, err := service.RelayOutboxEvents(context.Background(), events.NoopPublisher{}, service.OutboxRelayBatchSize)
+		require.NoError(t, err)
+		if relayed == 0 {
+			return
+		}
+	}
+}
+
+func templateEventData(t *testing.T, event events.CloudEvent) events.TemplateEventData {
+	var data events.TemplateEventData
+	require.NoError(t, json.Unmarshal(event.Data, &data))
+	return data
+}
+
+func TestTemplateEvents(t *testing.T) {
+	t.Run("should publish the changes of a template in order", func(t *testing.T) {
+		addPolicyBaseTemplate("events-base", api.WidgetPolicy{}, "widget1", "widget2")
+		drainOutbox(t)
+		orgID := "org-" + test_util.GetUniqueUserID()
+		testUserID := test_util.GetUniqueUserID()
+		testIdentity := orgIdentity(testUserID, orgID, false)
+
+		template, _, err := service.ForkBaseTemplate(testRegistries, "events-base", testIdentity)
+		require.NoError(t, err)
+		_, _, err = service.UpdateDashboardTemplate(context.Background(), testRegistries, int64(template.ID), test_util.MockTemplateConfig("widget1", "widget3"), testIdentity, nil, false, nil)
+		require.NoError(t, err)
+		_, _, err = service.ChangeDefaultTemplate(int64(template.ID), testIdentity, nil)
+		require.NoError(t, err)
+		_, err = service.DeleteDashboardTemplate(int64(template.ID), testIdentity)
+		require.NoError(t, err)
+
+		publisher := &events.MemoryPublisher{}
+		relayed, err := service.RelayOutboxEvents(context.Background(), publisher, service.OutboxRelayBatchSize)
+		require.NoError(t, err)
+		assert.Equal(t, 4, relayed)
+
+		published := publisher.Events()
+		require.Len(t, published, 4)
+		types := make([]string, 0, len(published))
+		for _, event := range published {
+			types = append(types, event.Type)
+			assert.Equal(t, strconv.FormatUint(uint64(template.ID), 10), event.Subject)
+			assert.Equal(t, orgID, event.RedHatOrgID)
+		}
+		assert.Equal(t, []string{
+			events.TypeTemplateCreated,
+			events.TypeTemplateUpdated,
+			events.TypeTemplateDefaultChanged,
+			events.TypeTemplateDeleted,
+		}, types)
+
+		created := templateEventData(t, published[0])
+		assert.Equal(t, "events-base", created.DashboardType)
+		assert.Equal(t, testUserID, created.UserID)
+		assert.Equal(t, []string{"widget1", "widget2"}, created.AddedWidgets)
+
+		updated := templateEventData(t, published[1])
+		assert.Equal(t, []string{"widget3"}, updated.AddedWidgets)
+		assert.Equal(t, []string{"widget2"}, updated.RemovedWidgets)
+		assert.True(t, templateEventData(t, published[2]).Default)
+
+		relayed, err = service.RelayOutboxEvents(context.Background(), publisher, service.OutboxRelayBatchSize)
+		require.NoError(t, err)
+		assert.Equal(t, 0, relayed, "Published events should be removed from the outbox")
+	})
+
+	t.Run("should keep events in the outbox when publishing fails", func(t *testing.T) {
+		drainOutbox(t)
+		testUserID := test_util.GetUniqueUserID()
+		template := createTestTemplate(testUserID, "events-base", "Events Base")
+		require.NoError(t, database.DB.Create(&template).Error)
+		_, _, err := service.RenameDashboardTemplate(int64(template.ID), "Renamed", orgIdentity(testUserID, "", false), nil)
+		require.NoError(t, err)
+
+		publisher := &events.MemoryPublisher{Err: errors.New("unavailable")}
+		_, err = service.RelayOutboxEvents(context.Background(), publisher, service.OutboxRelayBatchSize)
+		assert.Error(t, err)
+
+		publisher.Err = nil
+		relayed, err := service.RelayOutboxEvents(context.Background(), publisher, service.OutboxRelayBatchSize)
+		require.NoError(t, err)
+		assert.Equal(t, 1, relayed)
+		require.Len(t, publisher.Events(), 1)
+		assert.Equal(t, events.TypeTemplateUpdated, publisher.Events()[0].Type)
+	})
+
+	t.Run("should not queue events of changes which were rolled back", func(t *testing.T) {
+		drainOutbox(t)
+		testUserID := test_util.GetUniqueUserID()
+		template := createTestTemplate(testUserID, "events-base", "Events Base")
+		require.NoError(t, database.DB.Create(&template).Error)
+
+		stale := "\"stale\""
+		_, status, err := service.RenameDashboardTemplate(int64(template.ID), "Renamed", orgIdentity(testUserID, "", false), &stale)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusPreconditionFailed, status)
+
+		var queued int64
+		require.NoError(t, database.DB.Model(&api.OutboxEvent{}).Count(&queued).Error)
+		assert.Zero(t, queued)
+	})
+}
@@ -0,0 +1,185 @@
+package service
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/RedHatInsights/widget-layout-backend/api"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/database"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/events"
+	"github.com/redhatinsights/platform-go-middlewares/v2/identity"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// minWebhookSecretLength is the minimal length of the secret the payloads of a webhook are signed with
+const minWebhookSecretLength = 16
+
+var webhookEventTypes = map[api.WebhookEventType]bool{
+	api.WebhookEventTemplateCreated:        true,
+	api.WebhookEventTemplateUpdated:        true,
+	api.WebhookEventTemplateDeleted:        true,
+	api.WebhookEventTemplateDefaultChanged: true,
+}
+
+func validateWebhookRequest(req api.WebhookRequest) error {
+	u, err := url.Parse(req.Url)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("url must be an absolute http or https URL")
+	}
+	if len(req.Secret) < minWebhookSecretLength {
+		return fmt.Errorf("secret must be at least %d characters long", minWebhookSecretLength)
+	}
+	if req.Events != nil {
+		for _, eventType := range *req.Events {
+			if !webhookEventTypes[eventType] {
+				return fmt.Errorf("unknown event type %s", eventType)
+			}
+		}
+	}
+	return nil
+}
+
+// findWebhook returns the webhook of the organization of the identity, webhooks of other organizations are not found.
+func findWebhook(webhookID int64, id identity.XRHID) (api.Webhook, int, error) {
+	if status, err := checkOrgAdmin(id); err != nil {
+		return api.Webhook{}, status, err
+	}
+	var webhook api.Webhook
+	err := database.DB.Where("id = ? AND org_id = ?", webhookID, id.Identity.OrgID).First(&webhook).Error
+	if ret, status, err := handleServiceError(
+		err,
+		fmt.Sprintf("Webhook with ID %d not found", webhookID),
+		"Failed to retrieve webhook with ID %d: %v", http.StatusNotFound,
+		api.Webhook{}, api.Webhook{},
+	); err != nil {
+		return ret, status, err
+	}
+	return webhook, http.StatusOK, nil
+}
+
+func GetWebhooks(id identity.XRHID) ([]api.Webhook, int, error) {
+	if status, err := checkOrgAdmin(id); err != nil {
+		return nil, status, err
+	}
+	webhooks := []api.Webhook{}
+	err := database.DB.Where("org_id = ?", id.Identity.OrgID).Order("id").Find(&webhooks).Error
+	if err != nil {
+		logrus.Errorf("Failed to retrieve webhooks of organization %s: %v", id.Identity.OrgID, err)
+		return nil, http.StatusInternalServerError, err
+	}
+	return webhooks, http.StatusOK, nil
+}
+
+func GetWebhookByID(webhookID int64, id identity.XRHID) (api.Webhook, int, error) {
+	return findWebhook(webhookID, id)
+}
+
+// CreateWebhook registers a webhook for the organization of the identity, it receives the events of changes
+// committed after its registration.
+func CreateWebhook(req api.WebhookRequest, id identity.XRHID) (api.Webhook, int, error) {
+	if status, err := checkOrgAdmin(id); err != nil {
+		return api.Webhook{}, status, err
+	}
+	if err := validateWebhookRequest(req); err != nil {
+		return api.Webhook{}, http.StatusBadRequest, err
+	}
+	webhook := api.Webhook{
+		OrgId:     id.Identity.OrgID,
+		Url:       req.Url,
+		Events:    []api.WebhookEventType{},
+		Secret:    req.Secret,
+		CreatedBy: id.Identity.User.UserID,
+	}
+	if req.Events != nil {
+		webhook.Events = *req.Events
+	}
+	if err := database.DB.Create(&webhook).Error; err != nil {
+		logrus.Errorf("Failed to create webhook of organization %s: %v", id.Identity.OrgID, err)
+		return api.Webhook{}, http.StatusInternalServerError, err
+	}
+	logrus.Infof("User %s registered webhook %d of organization %s", id.Identity.User.UserID, webhook.ID, id.Identity.OrgID)
+	return webhook, http.StatusOK, nil
+}
+
+// DeleteWebhook removes the webhook together with its deliveries, pending deliveries are discarded.
+func DeleteWebhook(webhookID int64, id identity.XRHID) (int, error) {
+	webhook, status, err := findWebhook(webhookID, id)
+	if err != nil {
+		return status, err
+	}
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("webhook_id = ?", webhook.ID).Delete(&api.WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&webhook).Error
+	})
+	if err != nil {
+		logrus.Errorf("Failed to delete webhook with ID %d: %v", webhookID, err)
+		return http.StatusInternalServerError, err
+	}
+	logrus.Infof("User %s deleted webhook %d of organization %s", id.Identity.User.UserID, webhook.ID, id.Identity.OrgID)
+	return http.StatusNoContent, nil
+}
+
+// GetWebhookDeliveries returns a page of the deliveries of the webhook, newest first.
+func GetWebhookDeliveries(webhookID int64, id identity.XRHID, params api.GetWebhookDeliveriesParams) ([]api.WebhookDelivery, api.ListResponseMeta, int, error) {
+	webhook, status, err := findWebhook(webhookID, id)
+	if err != nil {
+		return nil, api.ListResponseMeta{}, status, err
+	}
+	limit, offset, err := pageOptions(params.Limit, params.Offset)
+	if err != nil {
+		return nil, api.ListResponseMeta{}, http.StatusBadRequest, err
+	}
+
+	query := database.DB.Model(&api.WebhookDelivery{}).Where("webhook_id = ?", webhook.ID)
+	if params.Status != nil {
+		query = query.Where("status = ?", *params.Status)
+	}
+	var total int64
+	deliveries := []api.WebhookDelivery{}
+	err = query.Session(&gorm.Session{}).Count(&total).Error
+	if err == nil {
+		err = query.Order("id DESC").Limit(limit).Offset(offset).Find(&deliveries).Error
+	}
+	if err != nil {
+		logrus.Errorf("Failed to retrieve deliveries of webhook with ID %d: %v", webhookID, err)
+		return nil, api.ListResponseMeta{}, http.StatusInternalServerError, err
+	}
+	totalCount := int(total)
+	return deliveries, api.ListResponseMeta{Count: len(deliveries), Total: &totalCount, Limit: &limit, Offset: &offset}, http.StatusOK, nil
+}
+
+// enqueueWebhookDeliveries queues the delivery of the event to the webhooks of the organization subscribed to its
+// type, in the transaction of the change the event describes.
+func enqueueWebhookDeliveries(tx *gorm.DB, orgID string, event events.CloudEvent, payload []byte) error {
+	if orgID == "" {
+		return nil
+	}
+	var webhooks []api.Webhook
+	if err := tx.Where("org_id = ?", orgID).Find(&webhooks).Error; err != nil {
+		return err
+	}
+	eventType := api.WebhookEventType(event.Type)
+	now := time.Now()
+	for _, webhook := range webhooks {
+		if !webhook.Subscribes(eventType) {
+			continue
+		}
+		err := tx.Create(&api.WebhookDelivery{
+			WebhookId:     webhook.ID,
+			EventId:       event.ID,
+			EventType:     eventType,
+			Payload:       payload,
+			Status:        api.WebhookDeliveryPending,
+			NextAttemptAt: now,
+		}).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
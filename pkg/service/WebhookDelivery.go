@@ -0,0 +1,200 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/RedHatInsights/widget-layout-backend/api"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/database"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// WebhookDeliveryBatchSize is the maximum number of deliveries attempted at once by the delivery worker
+	WebhookDeliveryBatchSize = 20
+	webhookTimeout           = 10 * time.Second
+	// maxWebhookRetryDelay caps the exponential backoff between the attempts of a delivery
+	maxWebhookRetryDelay = 6 * time.Hour
+)
+
+// WebhookDeliveryOptions configures the delivery of events to webhooks.
+type WebhookDeliveryOptions struct {
+	Client *http.Client
+	// MaxAttempts is the number of failed attempts after which a delivery is moved to the dead letter state
+	MaxAttempts int
+	// RetryDelay is the delay after the first failed attempt, it doubles with every further failed attempt
+	RetryDelay time.Duration
+}
+
+// retryDelay returns the delay before the next attempt of a delivery which failed the given number of times.
+func (o WebhookDeliveryOptions) retryDelay(attempts int) time.Duration {
+	delay := o.RetryDelay
+	for i := 1; i < attempts && delay < maxWebhookRetryDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, maxWebhookRetryDelay)
+}
+
+// NewWebhookClient creates the client events are delivered with. Redirects are not followed and, unless private
+// networks are allowed, connections to loopback, private and link-local addresses are refused, so webhooks cannot
+// be used to reach internal services.
+func NewWebhookClient(allowPrivateNetworks bool) *http.Client {
+	dialer := &net.Dialer{Timeout: webhookTimeout}
+	if !allowPrivateNetworks {
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip := net.ParseIP(host)
+			if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsUnspecified() {
+				return fmt.Errorf("webhooks cannot be delivered to %s", host)
+			}
+			return nil
+		}
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	transport.Proxy = nil
+	return &http.Client{
+		Timeout:   webhookTimeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// claimWebhookDeliveries returns the pending deliveries which are due and postpones their next attempt, so
+// concurrent workers do not attempt them as well. Deliveries of a worker stopping before they are attempted are
+// attempted again once the postponed attempt is due.
+func claimWebhookDeliveries(limit int, lease time.Duration) ([]api.WebhookDelivery, error) {
+	var deliveries []api.WebhookDelivery
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", api.WebhookDeliveryPending, now).
+			Order("next_attempt_at, id").Limit(limit).Find(&deliveries).Error
+		if err != nil || len(deliveries) == 0 {
+			return err
+		}
+		ids := make([]uint, 0, len(deliveries))
+		for _, delivery := range deliveries {
+			ids = append(ids, delivery.ID)
+		}
+		return tx.Model(&api.WebhookDelivery{}).Where("id IN ?", ids).Update("next_attempt_at", now.Add(lease)).Error
+	})
+	return deliveries, err
+}
+
+// postWebhookDelivery sends the payload of the delivery to the webhook, it returns the status code of the
+// response, 0 if the webhook did not respond.
+func postWebhookDelivery(ctx context.Context, client *http.Client, webhook api.Webhook, delivery api.WebhookDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.Url, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/cloudevents+json; charset=UTF-8")
+	req.Header.Set("User-Agent", "widget-layout-backend")
+	req.Header.Set("X-Webhook-Id", strconv.FormatUint(uint64(webhook.ID), 10))
+	req.Header.Set("X-Webhook-Delivery", strconv.FormatUint(uint64(delivery.ID), 10))
+	req.Header.Set("X-Webhook-Signature-256", webhook.Sign(delivery.Payload))
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	// drain a bit of the body, so the connection can be reused
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// attemptWebhookDelivery delivers the event and records the outcome of the attempt.
+func attemptWebhookDelivery(ctx context.Context, opts WebhookDeliveryOptions, delivery api.WebhookDelivery) error {
+	var webhook api.Webhook
+	err := database.DB.First(&webhook, delivery.WebhookId).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// the webhook was deleted together with its deliveries after they were claimed
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	statusCode, err := postWebhookDelivery(ctx, opts.Client, webhook, delivery)
+	now := time.Now()
+	delivery.Attempts++
+	delivery.LastStatusCode = nil
+	if statusCode != 0 {
+		delivery.LastStatusCode = &statusCode
+	}
+	switch {
+	case err == nil:
+		delivery.Status = api.WebhookDeliveryDelivered
+		delivery.DeliveredAt = &now
+		delivery.LastError = ""
+	case delivery.Attempts >= opts.MaxAttempts:
+		logrus.Warnf("Moving delivery %d of webhook %d to the dead letter state after %d attempts: %v", delivery.ID, webhook.ID, delivery.Attempts, err)
+		delivery.Status = api.WebhookDeliveryDeadLetter
+		delivery.LastError = err.Error()
+	default:
+		delivery.NextAttemptAt = now.Add(opts.retryDelay(delivery.Attempts))
+		delivery.LastError = err.Error()
+	}
+	return database.DB.Model(&delivery).Select("status", "attempts", "last_status_code", "last_error", "next_attempt_at", "delivered_at").Updates(&delivery).Error
+}
+
+// DeliverWebhooks attempts the pending deliveries which are due, it returns the number of attempted deliveries.
+// Events are delivered at least once, receivers deduplicate them by the id of the CloudEvent.
+func DeliverWebhooks(ctx context.Context, opts WebhookDeliveryOptions, limit int) (int, error) {
+	// every delivery of the batch may take until the timeout of the client
+	deliveries, err := claimWebhookDeliveries(limit, time.Duration(limit+1)*webhookTimeout)
+	if err != nil {
+		return 0, err
+	}
+	for _, delivery := range deliveries {
+		if err := attemptWebhookDelivery(ctx, opts, delivery); err != nil {
+			logrus.Errorf("Failed to record the attempt of delivery %d of webhook %d: %v", delivery.ID, delivery.WebhookId, err)
+		}
+	}
+	return len(deliveries), nil
+}
+
+// StartWebhookDelivery periodically delivers the pending deliveries which are due.
+// The worker stops when the context is cancelled.
+func StartWebhookDelivery(ctx context.Context, opts WebhookDeliveryOptions, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				for {
+					attempted, err := DeliverWebhooks(ctx, opts, WebhookDeliveryBatchSize)
+					if err != nil {
+						logrus.Errorf("Failed to deliver webhook events: %v", err)
+						break
+					}
+					if attempted < WebhookDeliveryBatchSize {
+						break
+					}
+				}
+			}
+		}
+	}()
+}
@@ -0,0 +1,246 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/RedHatInsights/widget-layout-backend/api"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/database"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/events"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/service"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/test_util"
+	"github.com/redhatinsights/platform-go-middlewares/v2/identity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testWebhookSecret = "0123456789abcdef"
+
+// webhookReceiver records the requests of the deliveries and answers them with the next status code
+type webhookReceiver struct {
+	mu       sync.Mutex
+	statuses []int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (wr *webhookReceiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	wr.mu.Lock()
+	defer wr.mu.Unlock()
+	body, _ := io.ReadAll(r.Body)
+	wr.requests = append(wr.requests, r)
+	wr.bodies = append(wr.bodies, body)
+	status := http.StatusOK
+	if len(wr.statuses) > 0 {
+		status, wr.statuses = wr.statuses[0], wr.statuses[1:]
+	}
+	w.WriteHeader(status)
+}
+
+// setupWebhook registers a webhook of a new organization for a test receiver, the webhook is deleted when the test ends
+func setupWebhook(t *testing.T, receiver *webhookReceiver, eventTypes ...api.WebhookEventType) (*httptest.Server, api.Webhook, identity.XRHID) {
+	server := httptest.NewServer(receiver)
+	t.Cleanup(server.Close)
+	admin := orgIdentity(test_util.GetUniqueUserID(), "org-"+test_util.GetUniqueUserID(), true)
+	webhook, _, err := service.CreateWebhook(api.WebhookRequest{Url: server.URL, Secret: testWebhookSecret, Events: &eventTypes}, admin)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, _ = service.DeleteWebhook(int64(webhook.ID), admin)
+	})
+	return server, webhook, admin
+}
+
+func webhookDeliveries(t *testing.T, webhookID uint) []api.WebhookDelivery {
+	var deliveries []api.WebhookDelivery
+	require.NoError(t, database.DB.Where("webhook_id = ?", webhookID).Order("id").Find(&deliveries).Error)
+	return deliveries
+}
+
+// makeDeliveriesDue lets the pending deliveries of the webhook be attempted again without waiting for their backoff
+func makeDeliveriesDue(t *testing.T, webhookID uint) {
+	require.NoError(t, database.DB.Model(&api.WebhookDelivery{}).Where("webhook_id = ?", webhookID).
+		Update("next_attempt_at", time.Now().Add(-time.Second)).Error)
+}
+
+func TestWebhooks(t *testing.T) {
+	t.Run("should only let org admins manage the webhooks of their organization", func(t *testing.T) {
+		orgID := "org-" + test_util.GetUniqueUserID()
+		admin := orgIdentity(test_util.GetUniqueUserID(), orgID, true)
+		member := orgIdentity(test_util.GetUniqueUserID(), orgID, false)
+		otherAdmin := orgIdentity(test_util.GetUniqueUserID(), "org-"+test_util.GetUniqueUserID(), true)
+		request := api.WebhookRequest{Url: "https://example.com/hook", Secret: testWebhookSecret}
+
+		_, status, err := service.CreateWebhook(request, member)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusForbidden, status)
+
+		webhook, status, err := service.CreateWebhook(request, admin)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, orgID, webhook.OrgId)
+		assert.Empty(t, webhook.Events)
+
+		webhooks, _, err := service.GetWebhooks(admin)
+		require.NoError(t, err)
+		require.Len(t, webhooks, 1)
+		assert.Equal(t, webhook.ID, webhooks[0].ID)
+
+		_, status, err = service.GetWebhookByID(int64(webhook.ID), otherAdmin)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusNotFound, status, "Webhooks of other organizations should not be found")
+		status, err = service.DeleteWebhook(int64(webhook.ID), otherAdmin)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusNotFound, status)
+
+		status, err = service.DeleteWebhook(int64(webhook.ID), admin)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNoContent, status)
+		_, status, err = service.GetWebhookByID(int64(webhook.ID), admin)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusNotFound, status)
+	})
+
+	t.Run("should reject invalid webhooks", func(t *testing.T) {
+		admin := orgIdentity(test_util.GetUniqueUserID(), "org-"+test_util.GetUniqueUserID(), true)
+		unknown := []api.WebhookEventType{"dashboard.template.viewed"}
+		for _, request := range []api.WebhookRequest{
+			{Url: "ftp://example.com/hook", Secret: testWebhookSecret},
+			{Url: "/hook", Secret: testWebhookSecret},
+			{Url: "https://example.com/hook", Secret: "short"},
+			{Url: "https://example.com/hook", Secret: testWebhookSecret, Events: &unknown},
+		} {
+			_, status, err := service.CreateWebhook(request, admin)
+			assert.Error(t, err)
+			assert.Equal(t, http.StatusBadRequest, status)
+		}
+	})
+
+	t.Run("should deliver signed events of the organization matching the filters", func(t *testing.T) {
+		receiver := &webhookReceiver{}
+		_, webhook, admin := setupWebhook(t, receiver, api.WebhookEventTemplateCreated, api.WebhookEventTemplateDeleted)
+		testUserID := test_util.GetUniqueUserID()
+		member := orgIdentity(testUserID, admin.Identity.OrgID, false)
+		addPolicyBaseTemplate("webhook-base", api.WidgetPolicy{}, "widget1")
+
+		template, _, err := service.ForkBaseTemplate(testRegistries, "webhook-base", member)
+		require.NoError(t, err)
+		_, _, err = service.RenameDashboardTemplate(int64(template.ID), "Renamed", member, nil)
+		require.NoError(t, err)
+		// templates of other organizations are not delivered
+		_, _, err = service.ForkBaseTemplate(testRegistries, "webhook-base", orgIdentity(test_util.GetUniqueUserID(), "org-"+test_util.GetUniqueUserID(), false))
+		require.NoError(t, err)
+
+		deliveries := webhookDeliveries(t, webhook.ID)
+		require.Len(t, deliveries, 1, "Only the created event should match the filters")
+		assert.Equal(t, api.WebhookDeliveryPending, deliveries[0].Status)
+
+		attempted, err := service.DeliverWebhooks(context.Background(), service.WebhookDeliveryOptions{
+			Client:      service.NewWebhookClient(true),
+			MaxAttempts: 3,
+			RetryDelay:  time.Minute,
+		}, service.WebhookDeliveryBatchSize)
+		require.NoError(t, err)
+		assert.Equal(t, 1, attempted)
+
+		require.Len(t, receiver.requests, 1)
+		req, body := receiver.requests[0], receiver.bodies[0]
+		assert.Equal(t, http.MethodPost, req.Method)
+		assert.Contains(t, req.Header.Get("Content-Type"), "application/cloudevents+json")
+		assert.Equal(t, api.Webhook{Secret: testWebhookSecret}.Sign(body), req.Header.Get("X-Webhook-Signature-256"))
+		var event events.CloudEvent
+		require.NoError(t, json.Unmarshal(body, &event))
+		assert.Equal(t, events.TypeTemplateCreated, event.Type)
+		assert.Equal(t, deliveries[0].EventId, event.ID)
+		assert.Equal(t, testUserID, templateEventData(t, event).UserID)
+
+		deliveries = webhookDeliveries(t, webhook.ID)
+		assert.Equal(t, api.WebhookDeliveryDelivered, deliveries[0].Status)
+		assert.Equal(t, 1, deliveries[0].Attempts)
+		assert.NotNil(t, deliveries[0].DeliveredAt)
+		require.NotNil(t, deliveries[0].LastStatusCode)
+		assert.Equal(t, http.StatusOK, *deliveries[0].LastStatusCode)
+	})
+
+	t.Run("should retry failed deliveries with a backoff until they are dead letters", func(t *testing.T) {
+		receiver := &webhookReceiver{statuses: []int{http.StatusInternalServerError, http.StatusServiceUnavailable, http.StatusBadGateway}}
+		_, webhook, admin := setupWebhook(t, receiver)
+		testUserID := test_util.GetUniqueUserID()
+		template := createTestTemplate(testUserID, "webhook-base", "Webhook Base")
+		template.OrgId = admin.Identity.OrgID
+		require.NoError(t, database.DB.Create(&template).Error)
+		_, err := service.DeleteDashboardTemplate(int64(template.ID), orgIdentity(testUserID, admin.Identity.OrgID, false))
+		require.NoError(t, err)
+
+		opts := service.WebhookDeliveryOptions{Client: service.NewWebhookClient(true), MaxAttempts: 3, RetryDelay: time.Minute}
+		_, err = service.DeliverWebhooks(context.Background(), opts, service.WebhookDeliveryBatchSize)
+		require.NoError(t, err)
+		delivery := webhookDeliveries(t, webhook.ID)[0]
+		assert.Equal(t, api.WebhookDeliveryPending, delivery.Status)
+		assert.Equal(t, 1, delivery.Attempts)
+		assert.Equal(t, http.StatusInternalServerError, *delivery.LastStatusCode)
+		assert.Contains(t, delivery.LastError, "500")
+		assert.WithinDuration(t, time.Now().Add(time.Minute), delivery.NextAttemptAt, 10*time.Second)
+
+		// the delivery is not attempted before its backoff ends
+		attempted, err := service.DeliverWebhooks(context.Background(), opts, service.WebhookDeliveryBatchSize)
+		require.NoError(t, err)
+		assert.Zero(t, attempted)
+
+		makeDeliveriesDue(t, webhook.ID)
+		_, err = service.DeliverWebhooks(context.Background(), opts, service.WebhookDeliveryBatchSize)
+		require.NoError(t, err)
+		delivery = webhookDeliveries(t, webhook.ID)[0]
+		assert.Equal(t, 2, delivery.Attempts)
+		assert.WithinDuration(t, time.Now().Add(2*time.Minute), delivery.NextAttemptAt, 10*time.Second, "The delay should double with every attempt")
+
+		makeDeliveriesDue(t, webhook.ID)
+		_, err = service.DeliverWebhooks(context.Background(), opts, service.WebhookDeliveryBatchSize)
+		require.NoError(t, err)
+		delivery = webhookDeliveries(t, webhook.ID)[0]
+		assert.Equal(t, api.WebhookDeliveryDeadLetter, delivery.Status)
+		assert.Equal(t, 3, delivery.Attempts)
+		assert.Len(t, receiver.requests, 3)
+
+		makeDeliveriesDue(t, webhook.ID)
+		attempted, err = service.DeliverWebhooks(context.Background(), opts, service.WebhookDeliveryBatchSize)
+		require.NoError(t, err)
+		assert.Zero(t, attempted, "Dead letters should not be retried")
+
+		deadLetter := api.WebhookDeliveryDeadLetter
+		page, meta, status, err := service.GetWebhookDeliveries(int64(webhook.ID), admin, api.GetWebhookDeliveriesParams{Status: &deadLetter})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+		require.Len(t, page, 1)
+		assert.Equal(t, 1, *meta.Total)
+	})
+
+	t.Run("should not deliver to private networks unless they are allowed", func(t *testing.T) {
+		receiver := &webhookReceiver{}
+		_, webhook, admin := setupWebhook(t, receiver)
+		testUserID := test_util.GetUniqueUserID()
+		template := createTestTemplate(testUserID, "webhook-base", "Webhook Base")
+		template.OrgId = admin.Identity.OrgID
+		require.NoError(t, database.DB.Create(&template).Error)
+		_, err := service.DeleteDashboardTemplate(int64(template.ID), orgIdentity(testUserID, admin.Identity.OrgID, false))
+		require.NoError(t, err)
+
+		_, err = service.DeliverWebhooks(context.Background(), service.WebhookDeliveryOptions{
+			Client:      service.NewWebhookClient(false),
+			MaxAttempts: 3,
+			RetryDelay:  time.Minute,
+		}, service.WebhookDeliveryBatchSize)
+		require.NoError(t, err)
+
+		assert.Empty(t, receiver.requests)
+		delivery := webhookDeliveries(t, webhook.ID)[0]
+		assert.Equal(t, api.WebhookDeliveryPending, delivery.Status)
+		assert.Nil(t, delivery.LastStatusCode)
+		assert.Contains(t, delivery.LastError, "cannot be delivered")
+	})
+}
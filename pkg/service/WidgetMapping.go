@@ -2,42 +2,64 @@ package service
 
 import (
 	"encoding/json"
+	"fmt"
 
 	"github.com/RedHatInsights/widget-layout-backend/api"
-	"github.com/RedHatInsights/widget-layout-backend/pkg/config"
 	"github.com/sirupsen/logrus"
 )
 
-var WidgetMappingRegistry = api.WidgetMappingRegistry{
-	WidgetMappings: make(map[string]api.WidgetModuleFederationMetadata),
-}
-
-func LoadWidgetMappingsFromConfig(configString string) error {
+// LoadWidgetMappingsFromConfig replaces the widget mappings with the mappings of the config string.
+// The registry is only changed when every mapping is valid. Base templates depend on the defaults of
+// the mappings, so the last loaded base templates are loaded again with the new mappings.
+func (r *Registries) LoadWidgetMappingsFromConfig(configString string) error {
 	if configString == "" {
 		return nil
 	}
+	r.loadMu.Lock()
+	defer r.loadMu.Unlock()
+
 	var widgetMappings []api.WidgetModuleFederationMetadata
 	err := json.Unmarshal([]byte(configString), &widgetMappings)
 	if err != nil {
 		return err
 	}
-	for _, wm := range widgetMappings {
-		WidgetMappingRegistry.AddWidgetMapping(wm)
+	mappings := make(map[string]api.WidgetModuleFederationMetadata, len(widgetMappings))
+	for idx, wm := range widgetMappings {
+		if wm.Scope == "" || wm.Module == "" {
+			return fmt.Errorf("widget mapping[%d] requires a scope and a module", idx)
+		}
+		mappings[wm.GetWidgetKey()] = wm
 	}
-	logrus.Infof("Loaded %d widget mappings", len(WidgetMappingRegistry.WidgetMappings))
-	return nil
-}
+	snapshot := r.WidgetMappings.Replace(mappings)
+	recordRegistryLoad(widgetMappingsRegistry)
+	logrus.Infof("Loaded %d widget mappings, version %s", snapshot.Len(), snapshot.Version())
 
-func init() {
-	cfg := config.GetConfig()
-	if err := LoadWidgetMappingsFromConfig(cfg.WidgetMappingConfig); err != nil {
-		logrus.Fatalln("Failed to parse widget mappings, shutting down the service", err)
+	if r.baseTemplatesSource != "" {
+		if err := r.loadBaseTemplates(r.baseTemplatesSource, false); err != nil {
+			logrus.Errorf("Failed to load base widget dashboard templates with the new widget mappings, keeping the previous templates: %v", err)
+		}
 	}
+	return nil
 }
 
 // GetWidgetMappings returns all widget mappings from the registry
-func GetWidgetMappings() map[string]api.WidgetModuleFederationMetadata {
-	mappings := WidgetMappingRegistry.GetAllWidgetMappings()
+func GetWidgetMappings(reg *Registries) map[string]api.WidgetModuleFederationMetadata {
+	mappings := reg.WidgetMappings.All()
 	logrus.Debugf("Retrieved %d widget mappings", len(mappings))
 	return mappings
 }
+
+// checkWidgetMappings validates the widgets of the template against the widget mapping registry.
+// Changes made in lenient mode are reported in the warnings of the template.
+func checkWidgetMappings(reg *Registries, template *api.DashboardTemplate, mode *api.ValidationMode) error {
+	validationMode := api.ValidationModeStrict
+	if mode != nil {
+		validationMode = *mode
+	}
+	warnings, err := template.TemplateConfig.CheckWidgetMappings(reg.WidgetMappings, validationMode)
+	if err != nil {
+		return err
+	}
+	template.Warnings = append(template.Warnings, warnings...)
+	return nil
+}
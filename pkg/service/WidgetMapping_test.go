@@ -11,11 +11,14 @@ import (
 )
 
 func TestGetWidgetMappings(t *testing.T) {
+	// other tests validate layouts against the registry, leave it empty for them
+	t.Cleanup(func() { testRegistries.WidgetMappings.Replace(nil) })
+
 	t.Run("should return empty map when no widget mappings exist", func(t *testing.T) {
 		// Reset registry to ensure clean state
-		service.WidgetMappingRegistry = api.WidgetMappingRegistry{}
+		testRegistries.WidgetMappings.Replace(nil)
 
-		mappings := service.GetWidgetMappings()
+		mappings := service.GetWidgetMappings(testRegistries)
 
 		assert.NotNil(t, mappings, "Should return a non-nil map")
 		assert.Empty(t, mappings, "Should return empty map when no mappings exist")
@@ -23,7 +26,7 @@ func TestGetWidgetMappings(t *testing.T) {
 
 	t.Run("should return all widget mappings from registry", func(t *testing.T) {
 		// Reset registry and add test widget mappings
-		service.WidgetMappingRegistry = api.WidgetMappingRegistry{}
+		testRegistries.WidgetMappings.Replace(nil)
 
 		// Create test widget mappings
 		widget1 := api.WidgetModuleFederationMetadata{
@@ -57,10 +60,10 @@ func TestGetWidgetMappings(t *testing.T) {
 		}
 
 		// Add widgets to registry
-		service.WidgetMappingRegistry.AddWidgetMapping(widget1)
-		service.WidgetMappingRegistry.AddWidgetMapping(widget2)
+		testRegistries.AddWidgetMapping(widget1)
+		testRegistries.AddWidgetMapping(widget2)
 
-		mappings := service.GetWidgetMappings()
+		mappings := service.GetWidgetMappings(testRegistries)
 
 		assert.NotNil(t, mappings, "Should return a non-nil map")
 		assert.Len(t, mappings, 2, "Should return both widget mappings")
@@ -84,7 +87,7 @@ func TestGetWidgetMappings(t *testing.T) {
 
 	t.Run("should handle widget mapping with import name", func(t *testing.T) {
 		// Reset registry
-		service.WidgetMappingRegistry = api.WidgetMappingRegistry{}
+		testRegistries.WidgetMappings.Replace(nil)
 
 		importName := "CustomImport"
 		widget := api.WidgetModuleFederationMetadata{
@@ -103,9 +106,9 @@ func TestGetWidgetMappings(t *testing.T) {
 			},
 		}
 
-		service.WidgetMappingRegistry.AddWidgetMapping(widget)
+		testRegistries.AddWidgetMapping(widget)
 
-		mappings := service.GetWidgetMappings()
+		mappings := service.GetWidgetMappings(testRegistries)
 
 		assert.Len(t, mappings, 1, "Should contain one widget mapping")
 
@@ -120,7 +123,7 @@ func TestGetWidgetMappings(t *testing.T) {
 
 	t.Run("should handle widget mapping with feature flag", func(t *testing.T) {
 		// Reset registry
-		service.WidgetMappingRegistry = api.WidgetMappingRegistry{}
+		testRegistries.WidgetMappings.Replace(nil)
 
 		featureFlag := "enable-test-feature"
 		widget := api.WidgetModuleFederationMetadata{
@@ -139,9 +142,9 @@ func TestGetWidgetMappings(t *testing.T) {
 			},
 		}
 
-		service.WidgetMappingRegistry.AddWidgetMapping(widget)
+		testRegistries.AddWidgetMapping(widget)
 
-		mappings := service.GetWidgetMappings()
+		mappings := service.GetWidgetMappings(testRegistries)
 
 		assert.Len(t, mappings, 1, "Should contain one widget mapping")
 
@@ -152,7 +155,7 @@ func TestGetWidgetMappings(t *testing.T) {
 
 	t.Run("should handle widget mapping with header link", func(t *testing.T) {
 		// Reset registry
-		service.WidgetMappingRegistry = api.WidgetMappingRegistry{}
+		testRegistries.WidgetMappings.Replace(nil)
 
 		widget := api.WidgetModuleFederationMetadata{
 			Scope:  "test-scope",
@@ -173,9 +176,9 @@ func TestGetWidgetMappings(t *testing.T) {
 			},
 		}
 
-		service.WidgetMappingRegistry.AddWidgetMapping(widget)
+		testRegistries.AddWidgetMapping(widget)
 
-		mappings := service.GetWidgetMappings()
+		mappings := service.GetWidgetMappings(testRegistries)
 
 		assert.Len(t, mappings, 1, "Should contain one widget mapping")
 
@@ -188,7 +191,7 @@ func TestGetWidgetMappings(t *testing.T) {
 
 	t.Run("should handle widget mapping with permissions", func(t *testing.T) {
 		// Reset registry
-		service.WidgetMappingRegistry = api.WidgetMappingRegistry{}
+		testRegistries.WidgetMappings.Replace(nil)
 		args := []interface{}{"arg1", "arg2", "arg3"}
 
 		permissions := []api.Permission{
@@ -220,9 +223,9 @@ func TestGetWidgetMappings(t *testing.T) {
 			},
 		}
 
-		service.WidgetMappingRegistry.AddWidgetMapping(widget)
+		testRegistries.AddWidgetMapping(widget)
 
-		mappings := service.GetWidgetMappings()
+		mappings := service.GetWidgetMappings(testRegistries)
 
 		assert.Len(t, mappings, 1, "Should contain one widget mapping")
 
@@ -234,7 +237,7 @@ func TestGetWidgetMappings(t *testing.T) {
 
 	t.Run("should handle duplicate widget keys by overwriting previous widget", func(t *testing.T) {
 		// Reset registry
-		service.WidgetMappingRegistry = api.WidgetMappingRegistry{}
+		testRegistries.WidgetMappings.Replace(nil)
 
 		// Create first widget
 		widget1 := api.WidgetModuleFederationMetadata{
@@ -272,8 +275,8 @@ func TestGetWidgetMappings(t *testing.T) {
 		assert.Equal(t, widget1.GetWidgetKey(), widget2.GetWidgetKey(), "Widgets should have the same key")
 
 		// Add first widget
-		service.WidgetMappingRegistry.AddWidgetMapping(widget1)
-		mappings := service.GetWidgetMappings()
+		testRegistries.AddWidgetMapping(widget1)
+		mappings := service.GetWidgetMappings(testRegistries)
 		assert.Len(t, mappings, 1, "Should have one widget after adding first widget")
 
 		// Verify first widget is in registry
@@ -283,8 +286,8 @@ func TestGetWidgetMappings(t *testing.T) {
 		assert.Equal(t, "first-icon", *retrievedWidget.Config.Icon, "Should have first widget icon")
 
 		// Add second widget with same key (should overwrite first)
-		service.WidgetMappingRegistry.AddWidgetMapping(widget2)
-		mappings = service.GetWidgetMappings()
+		testRegistries.AddWidgetMapping(widget2)
+		mappings = service.GetWidgetMappings(testRegistries)
 		assert.Len(t, mappings, 1, "Should still have one widget after adding duplicate")
 
 		// Verify second widget has overwritten first
@@ -297,7 +300,7 @@ func TestGetWidgetMappings(t *testing.T) {
 
 	t.Run("should handle duplicate keys with different import names", func(t *testing.T) {
 		// Reset registry
-		service.WidgetMappingRegistry = api.WidgetMappingRegistry{}
+		testRegistries.WidgetMappings.Replace(nil)
 
 		// Create first widget without import name
 		widget1 := api.WidgetModuleFederationMetadata{
@@ -337,10 +340,10 @@ func TestGetWidgetMappings(t *testing.T) {
 		assert.NotEqual(t, widget1.GetWidgetKey(), widget2.GetWidgetKey(), "Widgets should have different keys due to import name")
 
 		// Add both widgets
-		service.WidgetMappingRegistry.AddWidgetMapping(widget1)
-		service.WidgetMappingRegistry.AddWidgetMapping(widget2)
+		testRegistries.AddWidgetMapping(widget1)
+		testRegistries.AddWidgetMapping(widget2)
 
-		mappings := service.GetWidgetMappings()
+		mappings := service.GetWidgetMappings(testRegistries)
 		assert.Len(t, mappings, 2, "Should have two widgets with different keys")
 
 		// Verify both widgets exist
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/RedHatInsights/widget-layout-backend/api"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/database"
+	"github.com/redhatinsights/platform-go-middlewares/v2/identity"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// findOrgWidgetPolicy returns the widget policy of the organization, an empty one if the organization has none.
+func findOrgWidgetPolicy(orgID string) (api.OrgWidgetPolicy, error) {
+	policy := api.OrgWidgetPolicy{OrgId: orgID}
+	if orgID == "" {
+		return policy, nil
+	}
+	err := database.DB.Where("org_id = ?", orgID).First(&policy).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return api.OrgWidgetPolicy{OrgId: orgID}, nil
+	}
+	return policy, err
+}
+
+// checkWidgetPolicy enforces the widget policies of the base template of the template and of the organization of
+// the identity on the layouts of the template.
+func checkWidgetPolicy(reg *Registries, template api.DashboardTemplate, id identity.XRHID) (int, error) {
+	orgPolicy, err := findOrgWidgetPolicy(id.Identity.OrgID)
+	if err != nil {
+		logrus.Errorf("Failed to retrieve widget policy of organization %s: %v", id.Identity.OrgID, err)
+		return http.StatusInternalServerError, err
+	}
+	policy := orgPolicy.Policy
+	base, exists, err := resolveBaseTemplate(reg, template.TemplateBase.Name, id)
+	if err != nil {
+		logrus.Errorf("Failed to retrieve base template %s: %v", template.TemplateBase.Name, err)
+		return http.StatusInternalServerError, err
+	}
+	var baseConfig *api.DashboardTemplateConfig
+	if exists {
+		baseConfig = &base.TemplateConfig
+		if base.Policy != nil {
+			policy = base.Policy.Merge(policy)
+		}
+	}
+	if err := policy.Check(template.TemplateConfig, baseConfig); err != nil {
+		return http.StatusUnprocessableEntity, err
+	}
+	return http.StatusOK, nil
+}
+
+func GetOrgWidgetPolicy(id identity.XRHID) (api.OrgWidgetPolicy, int, error) {
+	if id.Identity.OrgID == "" {
+		return api.OrgWidgetPolicy{}, http.StatusForbidden, errors.New("the user does not belong to an organization")
+	}
+	policy, err := findOrgWidgetPolicy(id.Identity.OrgID)
+	if err != nil {
+		logrus.Errorf("Failed to retrieve widget policy of organization %s: %v", id.Identity.OrgID, err)
+		return api.OrgWidgetPolicy{}, http.StatusInternalServerError, err
+	}
+	return policy, http.StatusOK, nil
+}
+
+// PutOrgWidgetPolicy replaces the widget policy of the organization of the identity. Stored layouts are not
+// changed, the policy is enforced the next time they are stored.
+func PutOrgWidgetPolicy(policy api.WidgetPolicy, id identity.XRHID) (api.OrgWidgetPolicy, int, error) {
+	if status, err := checkOrgAdmin(id); err != nil {
+		return api.OrgWidgetPolicy{}, status, err
+	}
+	if err := policy.IsValid(); err != nil {
+		return api.OrgWidgetPolicy{}, http.StatusBadRequest, err
+	}
+	orgPolicy := api.OrgWidgetPolicy{
+		OrgId:     id.Identity.OrgID,
+		Policy:    policy,
+		UpdatedBy: id.Identity.User.UserID,
+		UpdatedAt: time.Now(),
+	}
+	err := database.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "org_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"policy", "updated_by", "updated_at"}),
+	}).Create(&orgPolicy).Error
+	if err != nil {
+		logrus.Errorf("Failed to store widget policy of organization %s: %v", id.Identity.OrgID, err)
+		return api.OrgWidgetPolicy{}, http.StatusInternalServerError, err
+	}
+	stored, err := findOrgWidgetPolicy(id.Identity.OrgID)
+	if err != nil {
+		logrus.Errorf("Failed to retrieve widget policy of organization %s: %v", id.Identity.OrgID, err)
+		return api.OrgWidgetPolicy{}, http.StatusInternalServerError, err
+	}
+	logrus.Infof("User %s stored the widget policy of organization %s", id.Identity.User.UserID, id.Identity.OrgID)
+	return stored, http.StatusOK, nil
+}
@@ -0,0 +1,193 @@
+package service_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/RedHatInsights/widget-layout-backend/api"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/database"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/service"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/test_util"
+	"github.com/redhatinsights/platform-go-middlewares/v2/identity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+)
+
+func addPolicyBaseTemplate(name string, policy api.WidgetPolicy, widgets ...string) {
+	testRegistries.AddBaseTemplate(api.BaseWidgetDashboardTemplate{
+		Name:           name,
+		DisplayName:    "Policy Base",
+		TemplateConfig: test_util.MockTemplateConfig(widgets...),
+		Policy:         &policy,
+	})
+}
+
+func TestWidgetPolicy(t *testing.T) {
+	t.Run("should not let users remove mandatory widgets of the base template", func(t *testing.T) {
+		addPolicyBaseTemplate("mandatory-policy-base", api.WidgetPolicy{MandatoryWidgets: []string{"mandatory-widget"}}, "mandatory-widget", "other-widget")
+		testUserID := test_util.GetUniqueUserID()
+		testIdentity := orgIdentity(testUserID, "", false)
+		template, _, err := service.ForkBaseTemplate(testRegistries, "mandatory-policy-base", testIdentity)
+		require.NoError(t, err)
+
+		_, status, err := service.UpdateDashboardTemplate(context.Background(), testRegistries, int64(template.ID), test_util.MockTemplateConfig("other-widget"), testIdentity, nil, false, nil)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, status)
+		assert.Contains(t, err.Error(), "mandatory rule: widget mandatory-widget cannot be removed")
+
+		_, status, err = service.PatchDashboardTemplateWidgets(context.Background(), testRegistries, int64(template.ID), []api.WidgetLayoutOperation{
+			{Op: api.WidgetOperationRemove, WidgetType: stringPtr("mandatory-widget")},
+		}, testIdentity, nil, nil)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, status)
+
+		_, status, err = service.UpdateDashboardTemplate(context.Background(), testRegistries, int64(template.ID), test_util.MockTemplateConfig("mandatory-widget"), testIdentity, nil, false, nil)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+	})
+
+	t.Run("should not let users move locked widgets of the base template", func(t *testing.T) {
+		addPolicyBaseTemplate("locked-policy-base", api.WidgetPolicy{LockedWidgets: []string{"locked-widget"}}, "locked-widget", "other-widget")
+		testUserID := test_util.GetUniqueUserID()
+		testIdentity := orgIdentity(testUserID, "", false)
+		template, _, err := service.ForkBaseTemplate(testRegistries, "locked-policy-base", testIdentity)
+		require.NoError(t, err)
+
+		_, status, err := service.UpdateDashboardTemplate(context.Background(), testRegistries, int64(template.ID), test_util.MockTemplateConfig("other-widget", "locked-widget"), testIdentity, nil, false, nil)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, status)
+		assert.Contains(t, err.Error(), "locked rule: widget locked-widget cannot be moved or resized")
+
+		// moving the other widgets around is fine
+		_, status, err = service.UpdateDashboardTemplate(context.Background(), testRegistries, int64(template.ID), test_util.MockTemplateConfig("locked-widget"), testIdentity, nil, false, nil)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+	})
+
+	t.Run("should enforce the widget policy of the organization on imports and copies", func(t *testing.T) {
+		orgID := "org-" + test_util.GetUniqueUserID()
+		_, status, err := service.PutOrgWidgetPolicy(api.WidgetPolicy{ForbiddenWidgets: []string{"forbidden-widget"}}, orgIdentity(test_util.GetUniqueUserID(), orgID, true))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+		member := orgIdentity(test_util.GetUniqueUserID(), orgID, false)
+
+		_, status, err = service.ImportDashboardTemplate(testRegistries, api.ImportWidgetLayoutJSONRequestBody{
+			TemplateBase:   api.DashboardTemplateBase{Name: "imported-policy-base", DisplayName: "Imported"},
+			TemplateConfig: test_util.MockTemplateConfig("forbidden-widget"),
+		}, member, nil,
+			false)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, status)
+		assert.Contains(t, err.Error(), "forbidden rule: widget forbidden-widget cannot be added")
+
+		// templates of other organizations are not bound to the policy, but their copies are
+		ownerID := test_util.GetUniqueUserID()
+		owner := orgIdentity(ownerID, "org-"+test_util.GetUniqueUserID(), false)
+		source := createTestTemplate(ownerID, "imported-policy-base", "Imported")
+		source.TemplateConfig = test_util.MockTemplateConfig("forbidden-widget")
+		require.NoError(t, database.DB.Create(&source).Error)
+		share, _, err := service.CreateDashboardTemplateShare(int64(source.ID), nil, owner)
+		require.NoError(t, err)
+
+		_, status, err = service.CopySharedDashboardTemplate(testRegistries, share.Token, member, nil)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, status)
+
+		_, status, err = service.CopySharedDashboardTemplate(testRegistries, share.Token, owner, nil)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+	})
+
+	// forbiddingMember returns a member of a new organization whose widget policy forbids forbidden-widget
+	forbiddingMember := func(t *testing.T) (string, identity.XRHID) {
+		orgID := "org-" + test_util.GetUniqueUserID()
+		_, _, err := service.PutOrgWidgetPolicy(api.WidgetPolicy{ForbiddenWidgets: []string{"forbidden-widget"}}, orgIdentity(test_util.GetUniqueUserID(), orgID, true))
+		require.NoError(t, err)
+		testUserID := test_util.GetUniqueUserID()
+		return testUserID, orgIdentity(testUserID, orgID, false)
+	}
+
+	t.Run("should enforce the widget policy when compacting", func(t *testing.T) {
+		testUserID, member := forbiddingMember(t)
+		// templates stored before the policy was put in place may still contain forbidden widgets
+		template := createTestTemplate(testUserID, "compact-policy-base", "Compact Policy Base")
+		template.TemplateConfig = test_util.MockTemplateConfig("other-widget", "forbidden-widget")
+		template.TemplateConfig.Sm = datatypes.NewJSONType([]api.WidgetItem{
+			{Width: 1, Height: 1, X: test_util.IntPTR(0), Y: test_util.IntPTR(3), WidgetType: "forbidden-widget"},
+		})
+		require.NoError(t, database.DB.Create(&template).Error)
+
+		_, status, err := service.CompactDashboardTemplate(testRegistries, int64(template.ID), member, nil)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, status)
+		assert.Contains(t, err.Error(), "forbidden rule: widget forbidden-widget cannot be added")
+	})
+
+	t.Run("should enforce the widget policy when restoring a revision", func(t *testing.T) {
+		testUserID, member := forbiddingMember(t)
+		template := createTestTemplate(testUserID, "restore-policy-base", "Restore Policy Base")
+		template.TemplateConfig = test_util.MockTemplateConfig("other-widget", "forbidden-widget")
+		require.NoError(t, database.DB.Create(&template).Error)
+		_, _, err := service.UpdateDashboardTemplate(context.Background(), testRegistries, int64(template.ID), test_util.MockTemplateConfig("other-widget"), member, nil, false, nil)
+		require.NoError(t, err)
+		revisions, _, err := service.GetTemplateRevisions(int64(template.ID), member)
+		require.NoError(t, err)
+		require.Len(t, revisions, 1)
+
+		_, status, err := service.RestoreTemplateRevision(testRegistries, int64(template.ID), int64(revisions[0].ID), member, nil)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, status)
+		var dbTemplate api.DashboardTemplate
+		require.NoError(t, database.DB.First(&dbTemplate, template.ID).Error)
+		assert.Equal(t, test_util.MockTemplateConfig("other-widget"), dbTemplate.TemplateConfig, "The template should not be restored")
+	})
+
+	t.Run("should enforce the widget policy when synchronizing with the base template", func(t *testing.T) {
+		_, member := forbiddingMember(t)
+		testRegistries.AddBaseTemplate(api.BaseWidgetDashboardTemplate{Name: "sync-policy-base", DisplayName: "Sync Policy Base", TemplateConfig: test_util.MockTemplateConfig("other-widget")})
+		fork, _, err := service.ForkBaseTemplate(testRegistries, "sync-policy-base", member)
+		require.NoError(t, err)
+		testRegistries.AddBaseTemplate(api.BaseWidgetDashboardTemplate{Name: "sync-policy-base", DisplayName: "Sync Policy Base", TemplateConfig: test_util.MockTemplateConfig("other-widget", "forbidden-widget")})
+
+		_, status, err := service.SyncDashboardTemplateBase(testRegistries, int64(fork.ID), member, nil)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, status)
+		assert.Contains(t, err.Error(), "forbidden rule: widget forbidden-widget cannot be added")
+	})
+
+	t.Run("should only let org admins change the widget policy of their organization", func(t *testing.T) {
+		orgID := "org-" + test_util.GetUniqueUserID()
+		member := orgIdentity(test_util.GetUniqueUserID(), orgID, false)
+		admin := orgIdentity(test_util.GetUniqueUserID(), orgID, true)
+
+		_, status, err := service.PutOrgWidgetPolicy(api.WidgetPolicy{ForbiddenWidgets: []string{"widget"}}, member)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusForbidden, status)
+
+		_, status, err = service.PutOrgWidgetPolicy(api.WidgetPolicy{MandatoryWidgets: []string{"widget"}, ForbiddenWidgets: []string{"widget"}}, admin)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusBadRequest, status)
+
+		policy, status, err := service.GetOrgWidgetPolicy(member)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Empty(t, policy.Policy.ForbiddenWidgets, "Organizations without a policy have an empty one")
+
+		_, _, err = service.PutOrgWidgetPolicy(api.WidgetPolicy{ForbiddenWidgets: []string{"widget"}}, admin)
+		require.NoError(t, err)
+		stored, _, err := service.PutOrgWidgetPolicy(api.WidgetPolicy{ForbiddenWidgets: []string{"other-widget"}}, admin)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"other-widget"}, stored.Policy.ForbiddenWidgets, "Storing the policy again should replace it")
+
+		policy, _, err = service.GetOrgWidgetPolicy(member)
+		require.NoError(t, err)
+		assert.Equal(t, stored.ID, policy.ID)
+		assert.Equal(t, []string{"other-widget"}, policy.Policy.ForbiddenWidgets)
+
+		_, status, err = service.GetOrgWidgetPolicy(orgIdentity(test_util.GetUniqueUserID(), "", false))
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusForbidden, status)
+	})
+}
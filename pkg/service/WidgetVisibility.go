@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"slices"
+
+	"github.com/RedHatInsights/widget-layout-backend/api"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/featureflags"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/layout"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/permissions"
+	"github.com/redhatinsights/platform-go-middlewares/v2/identity"
+)
+
+// widgetVisibility decides which widgets the caller of a single request can see, based on the
+// permissions and the feature flag of their widget mapping. Every widget is evaluated once,
+// widgets without a mapping are visible.
+type widgetVisibility struct {
+	mappings  api.WidgetMappingLookup
+	id        identity.XRHID
+	flags     *featureflags.RequestFlags
+	evaluated map[string]bool
+}
+
+func newWidgetVisibility(ctx context.Context, reg *Registries, id identity.XRHID) *widgetVisibility {
+	// the flags of the request are shared by the handler and the services, outside of a request they are evaluated here
+	flags, ok := featureflags.RequestFlagsFromContext(ctx)
+	if !ok {
+		flags = featureflags.NewRequestFlags(ctx, reg.FlagProvider, id)
+	}
+	return &widgetVisibility{
+		// a single snapshot keeps the decisions of the request consistent while the mappings are reloaded
+		mappings:  reg.WidgetMappings.Snapshot(),
+		id:        id,
+		flags:     flags,
+		evaluated: make(map[string]bool),
+	}
+}
+
+func (wv *widgetVisibility) permitted(widgetType string) bool {
+	if allowed, ok := wv.evaluated[widgetType]; ok {
+		return allowed
+	}
+	allowed := true
+	if mapping, ok := wv.mappings.Get(widgetType); ok {
+		if mapping.FeatureFlag != nil && *mapping.FeatureFlag != "" {
+			allowed = wv.flags.IsEnabled(*mapping.FeatureFlag)
+		}
+		if allowed && mapping.Config.Permissions != nil {
+			allowed = permissions.DefaultRegistry.Allowed(wv.id, *mapping.Config.Permissions)
+		}
+	}
+	wv.evaluated[widgetType] = allowed
+	return allowed
+}
+
+func (wv *widgetVisibility) filterConfig(tc api.DashboardTemplateConfig) api.DashboardTemplateConfig {
+	for _, gs := range []api.GridSizes{api.Sm, api.Md, api.Lg, api.Xl} {
+		items, err := tc.GetBreakpoint(gs)
+		if err != nil || items == nil {
+			continue
+		}
+		items = slices.DeleteFunc(slices.Clone(items), func(wi api.WidgetItem) bool { return !wv.permitted(wi.WidgetType) })
+		_ = tc.SetBreakpoint(gs, items)
+	}
+	return tc
+}
+
+// restoreHidden adds the stored widgets the caller cannot see back into a configuration they edited,
+// they were never part of the layouts the caller was shown and must survive a load-then-save.
+func (wv *widgetVisibility) restoreHidden(edited, stored api.DashboardTemplateConfig) api.DashboardTemplateConfig {
+	return layout.RestoreHiddenConfig(edited, stored, func(widgetType string) bool { return !wv.permitted(widgetType) })
+}
+
+// FilterPermittedWidgets removes the widgets the identity is not permitted to see from the layouts of the templates.
+// Only the returned templates are changed, the stored layouts keep all widgets.
+func FilterPermittedWidgets(ctx context.Context, reg *Registries, templates []api.DashboardTemplate, id identity.XRHID) []api.DashboardTemplate {
+	wv := newWidgetVisibility(ctx, reg, id)
+	filtered := make([]api.DashboardTemplate, 0, len(templates))
+	for _, template := range templates {
+		template.TemplateConfig = wv.filterConfig(template.TemplateConfig)
+		filtered = append(filtered, template)
+	}
+	return filtered
+}
+
+// FilterPermittedTemplateWidgets removes the widgets the identity is not permitted to see from the layouts of a single template.
+func FilterPermittedTemplateWidgets(ctx context.Context, reg *Registries, template api.DashboardTemplate, id identity.XRHID) api.DashboardTemplate {
+	template.TemplateConfig = newWidgetVisibility(ctx, reg, id).filterConfig(template.TemplateConfig)
+	return template
+}
+
+// FilterPermittedTemplateRevisions removes the widgets the identity is not permitted to see from the layouts of the revisions of a template.
+func FilterPermittedTemplateRevisions(ctx context.Context, reg *Registries, revisions []api.DashboardTemplateRevision, id identity.XRHID) []api.DashboardTemplateRevision {
+	wv := newWidgetVisibility(ctx, reg, id)
+	filtered := make([]api.DashboardTemplateRevision, 0, len(revisions))
+	for _, revision := range revisions {
+		revision.TemplateConfig = wv.filterConfig(revision.TemplateConfig)
+		filtered = append(filtered, revision)
+	}
+	return filtered
+}
+
+// FilterPermittedExportedTemplate removes the widgets the identity is not permitted to see from the layouts of an exported template.
+func FilterPermittedExportedTemplate(ctx context.Context, reg *Registries, template api.ExportWidgetDashboardTemplateResponse, id identity.XRHID) api.ExportWidgetDashboardTemplateResponse {
+	template.TemplateConfig = newWidgetVisibility(ctx, reg, id).filterConfig(template.TemplateConfig)
+	return template
+}
+
+// FilterPermittedDashboardArchive removes the widgets the identity is not permitted to see from the layouts of the templates of an archive.
+func FilterPermittedDashboardArchive(ctx context.Context, reg *Registries, archive api.DashboardArchive, id identity.XRHID) api.DashboardArchive {
+	wv := newWidgetVisibility(ctx, reg, id)
+	templates := make([]api.ArchivedDashboardTemplate, 0, len(archive.Templates))
+	for _, template := range archive.Templates {
+		template.TemplateConfig = wv.filterConfig(template.TemplateConfig)
+		templates = append(templates, template)
+	}
+	archive.Templates = templates
+	return archive
+}
+
+// FilterPermittedBaseTemplates removes the widgets the identity is not permitted to see from the layouts of the base templates.
+func FilterPermittedBaseTemplates(ctx context.Context, reg *Registries, templates []api.BaseWidgetDashboardTemplate, id identity.XRHID) []api.BaseWidgetDashboardTemplate {
+	wv := newWidgetVisibility(ctx, reg, id)
+	filtered := make([]api.BaseWidgetDashboardTemplate, 0, len(templates))
+	for _, template := range templates {
+		template.TemplateConfig = wv.filterConfig(template.TemplateConfig)
+		filtered = append(filtered, template)
+	}
+	return filtered
+}
+
+// FilterPermittedWidgetMappings returns the widget mappings the identity is permitted to see.
+func FilterPermittedWidgetMappings(ctx context.Context, reg *Registries, mappings map[string]api.WidgetModuleFederationMetadata, id identity.XRHID) map[string]api.WidgetModuleFederationMetadata {
+	wv := newWidgetVisibility(ctx, reg, id)
+	filtered := make(map[string]api.WidgetModuleFederationMetadata, len(mappings))
+	for key, mapping := range mappings {
+		if wv.permitted(key) {
+			filtered[key] = mapping
+		}
+	}
+	return filtered
+}
+
+// FilterPermittedBaseTemplateChanges removes the widgets the identity is not permitted to see from the changes of a base template.
+func FilterPermittedBaseTemplateChanges(ctx context.Context, reg *Registries, changes api.BaseTemplateChanges, id identity.XRHID) api.BaseTemplateChanges {
+	wv := newWidgetVisibility(ctx, reg, id)
+	changes.TemplateConfig = wv.filterConfig(changes.TemplateConfig)
+	changes.Added = slices.DeleteFunc(slices.Clone(changes.Added), func(widgetType string) bool { return !wv.permitted(widgetType) })
+	changes.Removed = slices.DeleteFunc(slices.Clone(changes.Removed), func(widgetType string) bool { return !wv.permitted(widgetType) })
+	return changes
+}
+
+// FilterPermittedSharedTemplateWidgets removes the widgets the identity is not permitted to see from the layouts of a shared template.
+func FilterPermittedSharedTemplateWidgets(ctx context.Context, reg *Registries, template api.SharedDashboardTemplate, id identity.XRHID) api.SharedDashboardTemplate {
+	template.TemplateConfig = newWidgetVisibility(ctx, reg, id).filterConfig(template.TemplateConfig)
+	return template
+}
@@ -0,0 +1,124 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/RedHatInsights/widget-layout-backend/api"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/service"
+	"github.com/RedHatInsights/widget-layout-backend/pkg/test_util"
+	"github.com/redhatinsights/platform-go-middlewares/v2/identity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/subpop/xrhidgen"
+	"gorm.io/datatypes"
+)
+
+func registerPermissionTestMappings(t *testing.T) {
+	testRegistries.WidgetMappings.Replace(nil)
+	t.Cleanup(func() { testRegistries.WidgetMappings.Replace(nil) })
+	testRegistries.AddWidgetMapping(api.WidgetModuleFederationMetadata{
+		Scope:  "landing",
+		Module: "./AdminWidget",
+		Config: api.WidgetConfiguration{
+			Title:       "Admin",
+			Permissions: &[]api.Permission{{Method: "isOrgAdmin"}},
+		},
+	})
+	testRegistries.AddWidgetMapping(api.WidgetModuleFederationMetadata{
+		Scope:  "landing",
+		Module: "./PublicWidget",
+		Config: api.WidgetConfiguration{Title: "Public"},
+	})
+}
+
+func TestFilterPermittedWidgets(t *testing.T) {
+	registerPermissionTestMappings(t)
+
+	items := datatypes.NewJSONType([]api.WidgetItem{
+		{Width: 1, Height: 2, WidgetType: "landing-./AdminWidget", X: test_util.IntPTR(0), Y: test_util.IntPTR(0)},
+		{Width: 1, Height: 2, WidgetType: "landing-./PublicWidget", X: test_util.IntPTR(0), Y: test_util.IntPTR(2)},
+		{Width: 1, Height: 2, WidgetType: "unmapped-widget", X: test_util.IntPTR(0), Y: test_util.IntPTR(4)},
+	})
+	template := api.DashboardTemplate{TemplateConfig: api.DashboardTemplateConfig{Sm: items, Md: items, Lg: items, Xl: items}}
+
+	t.Run("should remove widgets the user is not permitted to see", func(t *testing.T) {
+		isOrgAdmin := false
+		id := test_util.GenerateIdentityStructFromTemplate(xrhidgen.Identity{}, xrhidgen.User{IsOrgAdmin: &isOrgAdmin}, xrhidgen.Entitlements{})
+
+		filtered := service.FilterPermittedWidgets(context.Background(), testRegistries, []api.DashboardTemplate{template}, id)
+		require.Len(t, filtered, 1)
+		for _, layout := range [][]api.WidgetItem{filtered[0].TemplateConfig.Sm.Data(), filtered[0].TemplateConfig.Xl.Data()} {
+			require.Len(t, layout, 2)
+			assert.Equal(t, "landing-./PublicWidget", layout[0].WidgetType)
+			assert.Equal(t, "unmapped-widget", layout[1].WidgetType)
+		}
+		assert.Len(t, template.TemplateConfig.Sm.Data(), 3, "The original template should not change")
+	})
+
+	t.Run("should keep widgets the user is permitted to see", func(t *testing.T) {
+		isOrgAdmin := true
+		id := test_util.GenerateIdentityStructFromTemplate(xrhidgen.Identity{}, xrhidgen.User{IsOrgAdmin: &isOrgAdmin}, xrhidgen.Entitlements{})
+
+		filtered := service.FilterPermittedTemplateWidgets(context.Background(), testRegistries, template, id)
+		assert.Len(t, filtered.TemplateConfig.Lg.Data(), 3)
+	})
+}
+
+func TestFilterPermittedWidgetMappings(t *testing.T) {
+	registerPermissionTestMappings(t)
+
+	isOrgAdmin := false
+	id := test_util.GenerateIdentityStructFromTemplate(xrhidgen.Identity{}, xrhidgen.User{IsOrgAdmin: &isOrgAdmin}, xrhidgen.Entitlements{})
+
+	mappings := service.FilterPermittedWidgetMappings(context.Background(), testRegistries, service.GetWidgetMappings(testRegistries), id)
+	assert.Len(t, mappings, 1)
+	assert.Contains(t, mappings, "landing-./PublicWidget")
+	assert.Len(t, service.GetWidgetMappings(testRegistries), 2, "The registry should not change")
+}
+
+type staticFlags map[string]bool
+
+func (sf staticFlags) Flags(context.Context, identity.XRHID) (map[string]bool, error) {
+	return sf, nil
+}
+
+func TestFilterFeatureFlaggedWidgets(t *testing.T) {
+	registerPermissionTestMappings(t)
+	testRegistries.AddWidgetMapping(api.WidgetModuleFederationMetadata{
+		Scope:       "landing",
+		Module:      "./PreviewWidget",
+		FeatureFlag: stringPtr("landing.preview-widget"),
+		Config:      api.WidgetConfiguration{Title: "Preview"},
+	})
+	previous := testRegistries.FlagProvider
+	t.Cleanup(func() { testRegistries.FlagProvider = previous })
+
+	isOrgAdmin := true
+	id := test_util.GenerateIdentityStructFromTemplate(xrhidgen.Identity{}, xrhidgen.User{IsOrgAdmin: &isOrgAdmin}, xrhidgen.Entitlements{})
+	items := datatypes.NewJSONType([]api.WidgetItem{
+		{Width: 1, Height: 2, WidgetType: "landing-./PreviewWidget", X: test_util.IntPTR(0), Y: test_util.IntPTR(0)},
+		{Width: 1, Height: 2, WidgetType: "landing-./PublicWidget", X: test_util.IntPTR(0), Y: test_util.IntPTR(2)},
+	})
+	config := api.DashboardTemplateConfig{Sm: items, Md: items, Lg: items, Xl: items}
+
+	t.Run("should remove widgets whose flag is disabled", func(t *testing.T) {
+		testRegistries.FlagProvider = staticFlags{"landing.preview-widget": false}
+
+		filtered := service.FilterPermittedBaseTemplates(context.Background(), testRegistries, []api.BaseWidgetDashboardTemplate{{Name: "landing", TemplateConfig: config}}, id)
+		require.Len(t, filtered, 1)
+		require.Len(t, filtered[0].TemplateConfig.Md.Data(), 1)
+		assert.Equal(t, "landing-./PublicWidget", filtered[0].TemplateConfig.Md.Data()[0].WidgetType)
+
+		mappings := service.FilterPermittedWidgetMappings(context.Background(), testRegistries, service.GetWidgetMappings(testRegistries), id)
+		assert.NotContains(t, mappings, "landing-./PreviewWidget")
+		assert.Len(t, mappings, 2)
+	})
+
+	t.Run("should keep widgets whose flag is enabled", func(t *testing.T) {
+		testRegistries.FlagProvider = staticFlags{"landing.preview-widget": true}
+
+		filtered := service.FilterPermittedTemplateWidgets(context.Background(), testRegistries, api.DashboardTemplate{TemplateConfig: config}, id)
+		assert.Len(t, filtered.TemplateConfig.Sm.Data(), 2)
+	})
+}
@@ -3,3 +3,7 @@ package test_util
 func IntPTR(i int) *int {
 	return &i
 }
+
+func BoolPTR(b bool) *bool {
+	return &b
+}
@@ -63,3 +63,13 @@ func MockDashboardTemplateWithSpecificUser(userID string) api.DashboardTemplate
 	template.UserId = userID
 	return template
 }
+
+// MockTemplateConfig creates a template config with a 1x1 widget of each type stacked in the given order, the same layout is used for every breakpoint
+func MockTemplateConfig(widgetTypes ...string) api.DashboardTemplateConfig {
+	items := make([]api.WidgetItem, 0, len(widgetTypes))
+	for i, widgetType := range widgetTypes {
+		items = append(items, api.WidgetItem{Width: 1, Height: 1, X: IntPTR(0), Y: IntPTR(i), WidgetType: widgetType})
+	}
+	layout := datatypes.NewJSONType(items)
+	return api.DashboardTemplateConfig{Sm: layout, Md: layout, Lg: layout, Xl: layout}
+}